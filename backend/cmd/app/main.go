@@ -8,7 +8,9 @@ import (
 	"mola-web/internal/builder"
 	"mola-web/pkg/cache"
 	"mola-web/pkg/database"
+	"mola-web/pkg/scheduler"
 	"mola-web/pkg/server"
+	"mola-web/pkg/storage"
 	"os"
 	"os/signal"
 	"time"
@@ -25,12 +27,19 @@ func main() {
 
 	rdb := cache.InitCache(cfg.RedisConfig)
 
-	publicRoutes := builder.BuildPublicRoutes(cfg, db, rdb)
-	privateRoutes := builder.BuildPrivateRoutes(cfg, db, rdb)
+	fileStorage, err := storage.New(cfg.Storage)
+	checkError(err)
+
+	publicRoutes := builder.BuildPublicRoutes(cfg, db, rdb, fileStorage)
+	privateRoutes := builder.BuildPrivateRoutes(cfg, db, rdb, fileStorage)
+
+	jobs := scheduler.NewScheduler(builder.BuildJobs(cfg, db, rdb, fileStorage))
+	jobs.Start()
 
 	srv := server.NewServer(cfg, publicRoutes, privateRoutes)
 	runServer(srv, cfg.PORT)
 	waitForShutdown(srv)
+	jobs.Stop()
 }
 
 func checkError(err error) {
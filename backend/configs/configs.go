@@ -16,6 +16,10 @@ type Config struct {
 	MidtransConfig  MidtransConfig  `envPrefix:"MIDTRANS_"`
 	GoogleConfig    GoogleConfig    `envPrefix:"GOOGLE_"`
 	SMPTGmailConfig SMPTGmailConfig `envPrefix:"SMTP_GMAIL_"`
+	AbandonedCart   AbandonedCartConfig `envPrefix:"ABANDONED_CART_"`
+	Loyalty         LoyaltyConfig   `envPrefix:"LOYALTY_"`
+	Image           ImageConfig     `envPrefix:"IMAGE_"`
+	Storage         StorageConfig   `envPrefix:"STORAGE_"`
 }
 
 type RedisConfig struct {
@@ -52,6 +56,49 @@ type SMPTGmailConfig struct {
 	Password string `env:"PASSWORD" envDefault:""`
 }
 
+type AbandonedCartConfig struct {
+	AfterHours            int    `env:"AFTER_HOURS" envDefault:"24"`
+	ReminderIntervalHours int    `env:"REMINDER_INTERVAL_HOURS" envDefault:"24"`
+	MaxReminders          int    `env:"MAX_REMINDERS" envDefault:"2"`
+	ConversionWindowHours int    `env:"CONVERSION_WINDOW_HOURS" envDefault:"168"`
+	CheckIntervalMinutes  int    `env:"CHECK_INTERVAL_MINUTES" envDefault:"30"`
+	CartURL               string `env:"CART_URL" envDefault:"http://molla.my.id/cart"`
+	TemplatePath          string `env:"TEMPLATE_PATH" envDefault:"/var/www/mola-web/backend/template/abandoned_cart_email.html"`
+}
+
+type LoyaltyConfig struct {
+	SpendPerPoint        float64 `env:"SPEND_PER_POINT" envDefault:"10000"` // belanja (Rp) untuk 1 poin
+	PointValue           float64 `env:"POINT_VALUE" envDefault:"100"`       // nilai tukar 1 poin (Rp)
+	ExpiryDays           int     `env:"EXPIRY_DAYS" envDefault:"365"`       // 0 berarti tidak kedaluwarsa
+	MinRedeemPoints      int     `env:"MIN_REDEEM_POINTS" envDefault:"100"`
+	MaxRedeemPercent     float64 `env:"MAX_REDEEM_PERCENT" envDefault:"50"` // maksimal persen dari total order
+	CheckIntervalMinutes int     `env:"CHECK_INTERVAL_MINUTES" envDefault:"60"`
+}
+
+type ImageConfig struct {
+	MaxUploadMB  int    `env:"MAX_UPLOAD_MB" envDefault:"10"`
+	MinDimension int    `env:"MIN_DIMENSION" envDefault:"200"`  // sisi terpendek minimal (px)
+	MaxDimension int    `env:"MAX_DIMENSION" envDefault:"8000"` // sisi terpanjang maksimal (px)
+	JPEGQuality  int    `env:"JPEG_QUALITY" envDefault:"85"`
+	CwebpPath    string `env:"CWEBP_PATH" envDefault:"cwebp"` // kosong berarti tanpa WebP
+}
+
+// StorageConfig memilih tempat penyimpanan file upload. Driver s3 bisa
+// dipakai untuk AWS S3 maupun MinIO.
+type StorageConfig struct {
+	Driver       string `env:"DRIVER" envDefault:"local"` // local atau s3
+	LocalRoot    string `env:"LOCAL_ROOT" envDefault:"/var/www/mola-web/backend/public"`
+	LocalBaseURL string `env:"LOCAL_BASE_URL" envDefault:"/static"`
+
+	S3Endpoint     string `env:"S3_ENDPOINT" envDefault:"http://localhost:9000"`
+	S3Region       string `env:"S3_REGION" envDefault:"us-east-1"`
+	S3Bucket       string `env:"S3_BUCKET" envDefault:"mola-web"`
+	S3AccessKey    string `env:"S3_ACCESS_KEY" envDefault:""`
+	S3SecretKey    string `env:"S3_SECRET_KEY" envDefault:""`
+	S3UsePathStyle bool   `env:"S3_USE_PATH_STYLE" envDefault:"true"` // MinIO memakai path style
+	S3PublicURL    string `env:"S3_PUBLIC_URL" envDefault:""`        // CDN di depan bucket, kosong berarti URL endpoint
+}
+
 func NewConfig(envPath string) (*Config, error) {
 	err := godotenv.Load(envPath)
 	if err != nil {
@@ -1,74 +1,126 @@
 package builder
 
 import (
+	"context"
 	"mola-web/configs"
 	"mola-web/internal/http/handler"
 	"mola-web/internal/http/router"
 	"mola-web/internal/repository"
 	"mola-web/internal/service"
 	"mola-web/pkg/cache"
+	"mola-web/pkg/imaging"
 	"mola-web/pkg/route"
+	"mola-web/pkg/scheduler"
+	"mola-web/pkg/storage"
 	"mola-web/pkg/token"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
 
-func BuildPublicRoutes(cfg *configs.Config, db *gorm.DB, rdb *redis.Client) []route.Route  {
+func BuildPublicRoutes(cfg *configs.Config, db *gorm.DB, rdb *redis.Client, fileStorage storage.Storage) []route.Route  {
 	cacheable := cache.NewCacheable(rdb)
+	suggestionIndex := cache.NewSuggestionIndex(rdb)
+	imageProcessor := imaging.NewProcessor(cfg.Image)
 	userRepository := repository.NewUserRepository(db)
 	productRepository := repository.NewProductRepository(db)
+	searchLogRepository := repository.NewSearchLogRepository(db)
+	slugRepository := repository.NewSlugRepository(db)
+	categoryRepository := repository.NewCategoryRepository(db)
+	optionRepository := repository.NewOptionRepository(db)
+	inventoryRepository := repository.NewInventoryRepository(db)
 	cartRepository := repository.NewCartRepository(db)
 	orderRepository := repository.NewOrderRepository(db)
 	transactionRepository := repository.NewTransactionRepository(db)
 	salesReportRepository := repository.NewSalesReportRepository(db)
 	variantRepository := repository.NewProductVariantRepository(db)
+	cartAbandonmentRepository := repository.NewCartAbandonmentRepository(db)
+	wishlistRepository := repository.NewWishlistRepository(db)
+	voucherRepository := repository.NewVoucherRepository(db)
+	saleCampaignRepository := repository.NewSaleCampaignRepository(db)
+	loyaltyRepository := repository.NewLoyaltyRepository(db)
+	referralRepository := repository.NewReferralRepository(db)
+	storeCreditRepository := repository.NewStoreCreditRepository(db)
 
 
 	tokenUseCase := token.NewTokenUseCase(cfg.JWT.SecretKey)
-	userService := service.NewUserService(db, userRepository, tokenUseCase, cacheable, cfg.GoogleConfig, cfg.SMPTGmailConfig)
-	productService := service.NewProductService(db, productRepository, variantRepository, tokenUseCase, cacheable)
-	cartService := service.NewCartService(db, cartRepository, orderRepository, productRepository, variantRepository ,tokenUseCase, cacheable, cfg.MidtransConfig)
-	orderService := service.NewOrderService(db, orderRepository, cartRepository, cartService, productService, cacheable, tokenUseCase, cfg.MidtransConfig)
-	transactionService := service.NewTransactionService(db, productRepository, transactionRepository, orderRepository, variantRepository,tokenUseCase, cacheable, cfg.MidtransConfig)
+	saleCampaignService := service.NewSaleCampaignService(db, saleCampaignRepository, cacheable)
+	loyaltyService := service.NewLoyaltyService(db, loyaltyRepository, cfg.Loyalty)
+	referralService := service.NewReferralService(db, referralRepository, userRepository, voucherRepository)
+	storeCreditService := service.NewStoreCreditService(db, storeCreditRepository, cfg.MidtransConfig)
+	guestCartService := service.NewGuestCartService(db, cartRepository, productRepository, variantRepository, saleCampaignService, tokenUseCase, cacheable)
+	userService := service.NewUserService(db, userRepository, tokenUseCase, cacheable, cfg.GoogleConfig, cfg.SMPTGmailConfig, guestCartService, loyaltyService, referralService)
+	productService := service.NewProductService(db, productRepository, variantRepository, searchLogRepository, slugRepository, categoryRepository, optionRepository, inventoryRepository, saleCampaignService, tokenUseCase, cacheable, suggestionIndex, imageProcessor, fileStorage)
+	voucherService := service.NewVoucherService(db, voucherRepository, cartRepository, saleCampaignService, cacheable)
+	cartService := service.NewCartService(db, cartRepository, orderRepository, productRepository, variantRepository, voucherService, saleCampaignService, tokenUseCase, cacheable, cfg.MidtransConfig)
+	cartAbandonmentService := service.NewCartAbandonmentService(db, cartAbandonmentRepository, cartRepository, cacheable, cfg.AbandonedCart, cfg.SMPTGmailConfig)
+	orderService := service.NewOrderService(db, orderRepository, cartRepository, cartService, productService, cartAbandonmentService, voucherService, saleCampaignService, loyaltyService, referralService, storeCreditService, cacheable, tokenUseCase, cfg.MidtransConfig)
+	transactionService := service.NewTransactionService(db, productRepository, transactionRepository, orderRepository, variantRepository, voucherRepository, saleCampaignService, loyaltyService, referralService, storeCreditService, tokenUseCase, cacheable, cfg.MidtransConfig)
 	salesReportService := service.NewSalesReportService(db, salesReportRepository)
+	wishlistService := service.NewWishlistService(db, wishlistRepository, cartRepository, productRepository, variantRepository, cartService, saleCampaignService, cacheable)
 
 	userHandler := handler.NewUserHandler(userService)
 	productHandler := handler.NewProductHandler(productService)
 	cartHandler := handler.NewCartHandler(cartService, db)
+	guestCartHandler := handler.NewGuestCartHandler(guestCartService)
 	orderHandler := handler.NewOrderHandler(orderService)
 	transactionHandler := handler.NewTransactionHandler(transactionService)
 	salesReportHandler := handler.NewSalesReportHandler(salesReportService)
+	wishlistHandler := handler.NewWishlistHandler(wishlistService)
 
-	return router.PublicRoutes(userHandler,productHandler, cartHandler, orderHandler, transactionHandler, salesReportHandler)
+	return router.PublicRoutes(userHandler,productHandler, cartHandler, guestCartHandler, orderHandler, transactionHandler, salesReportHandler, wishlistHandler)
 }
-func BuildPrivateRoutes(cfg *configs.Config, db *gorm.DB, rdb *redis.Client) []route.Route {
+func BuildPrivateRoutes(cfg *configs.Config, db *gorm.DB, rdb *redis.Client, fileStorage storage.Storage) []route.Route {
 	cacheable := cache.NewCacheable(rdb)
+	suggestionIndex := cache.NewSuggestionIndex(rdb)
+	imageProcessor := imaging.NewProcessor(cfg.Image)
 	userRepository := repository.NewUserRepository(db)
 	tokenUseCase := token.NewTokenUseCase(cfg.JWT.SecretKey)
-	userService := service.NewUserService(db, userRepository, tokenUseCase, cacheable, cfg.GoogleConfig, cfg.SMPTGmailConfig)
-	userHandler := handler.NewUserHandler(userService)
 	productRepository := repository.NewProductRepository(db)
+	searchLogRepository := repository.NewSearchLogRepository(db)
+	slugRepository := repository.NewSlugRepository(db)
+	categoryRepository := repository.NewCategoryRepository(db)
+	optionRepository := repository.NewOptionRepository(db)
+	inventoryRepository := repository.NewInventoryRepository(db)
 	variantRepository := repository.NewProductVariantRepository(db)
-	productService := service.NewProductService(db, productRepository, variantRepository, tokenUseCase, cacheable)
+	cartRepository := repository.NewCartRepository(db)
+	saleCampaignRepository := repository.NewSaleCampaignRepository(db)
+	saleCampaignService := service.NewSaleCampaignService(db, saleCampaignRepository, cacheable)
+	loyaltyRepository := repository.NewLoyaltyRepository(db)
+	loyaltyService := service.NewLoyaltyService(db, loyaltyRepository, cfg.Loyalty)
+	voucherRepository := repository.NewVoucherRepository(db)
+	referralRepository := repository.NewReferralRepository(db)
+	storeCreditRepository := repository.NewStoreCreditRepository(db)
+	referralService := service.NewReferralService(db, referralRepository, userRepository, voucherRepository)
+	storeCreditService := service.NewStoreCreditService(db, storeCreditRepository, cfg.MidtransConfig)
+	guestCartService := service.NewGuestCartService(db, cartRepository, productRepository, variantRepository, saleCampaignService, tokenUseCase, cacheable)
+	userService := service.NewUserService(db, userRepository, tokenUseCase, cacheable, cfg.GoogleConfig, cfg.SMPTGmailConfig, guestCartService, loyaltyService, referralService)
+	userHandler := handler.NewUserHandler(userService)
+	productService := service.NewProductService(db, productRepository, variantRepository, searchLogRepository, slugRepository, categoryRepository, optionRepository, inventoryRepository, saleCampaignService, tokenUseCase, cacheable, suggestionIndex, imageProcessor, fileStorage)
 	productHandler := handler.NewProductHandler(productService)
 	transactionRepository := repository.NewTransactionRepository(db)
 	salesReportRepository := repository.NewSalesReportRepository(db)
-	categoryRepository := repository.NewCategoryRepository(db)
 	colorRepository := repository.NewColorRepository(db)
 	sizeRepository := repository.NewSizeRepository(db)
+	cartAbandonmentRepository := repository.NewCartAbandonmentRepository(db)
+	wishlistRepository := repository.NewWishlistRepository(db)
 
 
 
-	cartRepository := repository.NewCartRepository(db)
 	orderRepository := repository.NewOrderRepository(db)
-	cartService := service.NewCartService(db, cartRepository, orderRepository, productRepository, variantRepository, tokenUseCase, cacheable, cfg.MidtransConfig)
-	orderService := service.NewOrderService(db, orderRepository, cartRepository, cartService, productService, cacheable, tokenUseCase, cfg.MidtransConfig)
-	transactionService := service.NewTransactionService(db, productRepository, transactionRepository, orderRepository, variantRepository, tokenUseCase, cacheable, cfg.MidtransConfig)
+	voucherService := service.NewVoucherService(db, voucherRepository, cartRepository, saleCampaignService, cacheable)
+	cartService := service.NewCartService(db, cartRepository, orderRepository, productRepository, variantRepository, voucherService, saleCampaignService, tokenUseCase, cacheable, cfg.MidtransConfig)
+	cartAbandonmentService := service.NewCartAbandonmentService(db, cartAbandonmentRepository, cartRepository, cacheable, cfg.AbandonedCart, cfg.SMPTGmailConfig)
+	orderService := service.NewOrderService(db, orderRepository, cartRepository, cartService, productService, cartAbandonmentService, voucherService, saleCampaignService, loyaltyService, referralService, storeCreditService, cacheable, tokenUseCase, cfg.MidtransConfig)
+	transactionService := service.NewTransactionService(db, productRepository, transactionRepository, orderRepository, variantRepository, voucherRepository, saleCampaignService, loyaltyService, referralService, storeCreditService, tokenUseCase, cacheable, cfg.MidtransConfig)
 	salesReportService := service.NewSalesReportService(db, salesReportRepository)
-	categoryService := service.NewCategoryService(db, categoryRepository, tokenUseCase, cacheable)
-	colorService := service.NewColorService(db, colorRepository, tokenUseCase, cacheable)
-	sizeService := service.NewSizeService(db, sizeRepository, tokenUseCase, cacheable)
+	categoryService := service.NewCategoryService(db, categoryRepository, slugRepository, tokenUseCase, cacheable)
+	colorService := service.NewColorService(db, colorRepository, optionRepository, tokenUseCase, cacheable)
+	sizeService := service.NewSizeService(db, sizeRepository, optionRepository, tokenUseCase, cacheable)
+	optionService := service.NewOptionService(db, optionRepository, cacheable)
+	inventoryService := service.NewInventoryService(inventoryRepository)
+	wishlistService := service.NewWishlistService(db, wishlistRepository, cartRepository, productRepository, variantRepository, cartService, saleCampaignService, cacheable)
 
 
 	cartHandler := handler.NewCartHandler(cartService, db)
@@ -78,7 +130,93 @@ func BuildPrivateRoutes(cfg *configs.Config, db *gorm.DB, rdb *redis.Client) []r
 	categoryHandler := handler.NewCategoryHandler(categoryService)	
 	colorHandler := handler.NewColorHandler(colorService)
 	sizeHandler := handler.NewSizeHandler(sizeService)
+	optionHandler := handler.NewOptionHandler(optionService)
+	inventoryHandler := handler.NewInventoryHandler(inventoryService)
+	cartAbandonmentHandler := handler.NewCartAbandonmentHandler(cartAbandonmentService)
+	wishlistHandler := handler.NewWishlistHandler(wishlistService)
+	voucherHandler := handler.NewVoucherHandler(voucherService)
+	saleCampaignHandler := handler.NewSaleCampaignHandler(saleCampaignService)
+	referralHandler := handler.NewReferralHandler(referralService)
+	storeCreditHandler := handler.NewStoreCreditHandler(storeCreditService)
 
 
-	return router.PrivateRoutes(userHandler, productHandler, categoryHandler, colorHandler, sizeHandler, cartHandler, orderHandler, transactionHandler, salesReportHandler)
-}
\ No newline at end of file
+	return router.PrivateRoutes(userHandler, productHandler, categoryHandler, colorHandler, sizeHandler, optionHandler, inventoryHandler, cartHandler, orderHandler, transactionHandler, salesReportHandler, cartAbandonmentHandler, wishlistHandler, voucherHandler, saleCampaignHandler, referralHandler, storeCreditHandler)
+}
+
+func BuildJobs(cfg *configs.Config, db *gorm.DB, rdb *redis.Client, fileStorage storage.Storage) []scheduler.Job {
+	cacheable := cache.NewCacheable(rdb)
+	cartRepository := repository.NewCartRepository(db)
+	cartAbandonmentRepository := repository.NewCartAbandonmentRepository(db)
+	saleCampaignRepository := repository.NewSaleCampaignRepository(db)
+	loyaltyRepository := repository.NewLoyaltyRepository(db)
+	productRepository := repository.NewProductRepository(db)
+	variantRepository := repository.NewProductVariantRepository(db)
+	searchLogRepository := repository.NewSearchLogRepository(db)
+	slugRepository := repository.NewSlugRepository(db)
+	categoryRepository := repository.NewCategoryRepository(db)
+	optionRepository := repository.NewOptionRepository(db)
+	inventoryRepository := repository.NewInventoryRepository(db)
+	suggestionIndex := cache.NewSuggestionIndex(rdb)
+	imageProcessor := imaging.NewProcessor(cfg.Image)
+	tokenUseCase := token.NewTokenUseCase(cfg.JWT.SecretKey)
+
+	cartAbandonmentService := service.NewCartAbandonmentService(db, cartAbandonmentRepository, cartRepository, cacheable, cfg.AbandonedCart, cfg.SMPTGmailConfig)
+	saleCampaignService := service.NewSaleCampaignService(db, saleCampaignRepository, cacheable)
+	loyaltyService := service.NewLoyaltyService(db, loyaltyRepository, cfg.Loyalty)
+	productService := service.NewProductService(db, productRepository, variantRepository, searchLogRepository, slugRepository, categoryRepository, optionRepository, inventoryRepository, saleCampaignService, tokenUseCase, cacheable, suggestionIndex, imageProcessor, fileStorage)
+
+	// Cek pergantian kampanye promo setiap menit
+	saleCampaignInterval := time.Minute
+	lastSaleCampaignCheck := time.Now()
+	// Cek produk yang mulai atau berhenti tayang setiap menit
+	lastPublishCheck := time.Now()
+
+	return []scheduler.Job{
+		{
+			Name:     "abandoned-carts",
+			Interval: time.Duration(cfg.AbandonedCart.CheckIntervalMinutes) * time.Minute,
+			Run: func(ctx context.Context) error {
+				if err := cartAbandonmentService.MarkAbandonedCarts(ctx); err != nil {
+					return err
+				}
+				return cartAbandonmentService.SendReminders(ctx)
+			},
+		},
+		{
+			Name:     "sale-campaigns",
+			Interval: saleCampaignInterval,
+			Run: func(ctx context.Context) error {
+				now := time.Now()
+				if err := saleCampaignService.InvalidateOnBoundary(ctx, lastSaleCampaignCheck, now); err != nil {
+					return err
+				}
+				lastSaleCampaignCheck = now
+				return nil
+			},
+		},
+		{
+			Name:     "product-publishing",
+			Interval: time.Minute,
+			Run: func(ctx context.Context) error {
+				now := time.Now()
+				if err := productService.InvalidateOnPublishBoundary(ctx, lastPublishCheck, now); err != nil {
+					return err
+				}
+				lastPublishCheck = now
+				return nil
+			},
+		},
+		{
+			Name:     "loyalty-expiry",
+			Interval: time.Duration(cfg.Loyalty.CheckIntervalMinutes) * time.Minute,
+			Run:      loyaltyService.ExpirePoints,
+		},
+		{
+			// Bobot penjualan dan query populer di indeks autocomplete
+			Name:       "search-suggestions",
+			Interval:   time.Hour,
+			Run:        productService.RebuildSuggestions,
+			RunOnStart: true,
+		},
+	}
+}
@@ -11,6 +11,7 @@ type Cart struct {
 	ID        uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	UserID    uuid.UUID      `gorm:"type:uuid;not null;index" json:"user_id"`
 	Status    string         `gorm:"type:varchar(20);not null;default:active" json:"status"` // active, checkout, abandoned
+	VoucherCode *string      `gorm:"type:varchar(50)" json:"voucher_code,omitempty"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
@@ -30,6 +31,7 @@ type CartItem struct {
 	ProductID uuid.UUID      `gorm:"type:uuid;not null;index" json:"product_id"`
 	ProductVariantID *uuid.UUID `gorm:"type:uuid" json:"product_variant_id,omitempty"`
 	Quantity  int            `gorm:"not null;default:1;check:quantity > 0" json:"quantity"`
+	Price     float64        `gorm:"type:numeric(12,2);not null;default:0" json:"price"` // harga saat item dimasukkan ke keranjang
 	Note      string         `gorm:"type:text" json:"note,omitempty"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
@@ -39,6 +41,7 @@ type CartItem struct {
 	Cart    *Cart    `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"cart,omitempty"`
 	Product *Product `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"product,omitempty"`
 	ProductVariant *ProductVariant `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:NO ACTION;" json:"product_variant,omitempty"`
+	Components     []CartItemComponent `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"components,omitempty"`
 }
 
 func (CartItem) TableName() string {
@@ -0,0 +1,47 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CartAbandonment struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	CartID         uuid.UUID  `gorm:"type:uuid;not null;index" json:"cart_id"`
+	UserID         uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	CartTotal      float64    `gorm:"type:numeric(12,2);not null;default:0" json:"cart_total"`
+	AbandonedAt    time.Time  `gorm:"not null;index" json:"abandoned_at"`
+	RemindersSent  int        `gorm:"not null;default:0" json:"reminders_sent"`
+	LastRemindedAt *time.Time `json:"last_reminded_at,omitempty"`
+	ConvertedAt    *time.Time `json:"converted_at,omitempty"`
+	OrderID        *uuid.UUID `gorm:"type:uuid" json:"order_id,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	// Relationships
+	Cart      *Cart          `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"cart,omitempty"`
+	User      *User          `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"user,omitempty"`
+	Reminders []CartReminder `gorm:"foreignKey:AbandonmentID;constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"reminders,omitempty"`
+}
+
+func (CartAbandonment) TableName() string {
+	return "cart_abandonments"
+}
+
+// CartReminder mencatat setiap email pengingat yang dikirim agar tidak dikirim
+// ulang. Pengiriman yang gagal tetap dicatat dengan status failed.
+type CartReminder struct {
+	ID            uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	AbandonmentID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_cart_reminders_sequence" json:"abandonment_id"`
+	Sequence      int       `gorm:"not null;uniqueIndex:idx_cart_reminders_sequence" json:"sequence"`
+	Email         string    `gorm:"type:varchar(255);not null" json:"email"`
+	Status        string    `gorm:"type:varchar(20);not null;default:sent" json:"status"` // sent, failed
+	Error         *string   `gorm:"type:text" json:"error,omitempty"`
+	SentAt        time.Time `gorm:"not null" json:"sent_at"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (CartReminder) TableName() string {
+	return "cart_reminders"
+}
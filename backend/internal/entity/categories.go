@@ -10,9 +10,12 @@ import (
 type Category struct {
 	ID        uint           `gorm:"primaryKey,autoincrement" json:"id"`
 	Name      string         `gorm:"type:varchar(50);not null" json:"name"`
+	Slug      *string        `gorm:"type:varchar(150);uniqueIndex" json:"slug"`
+	ParentID  *uint          `gorm:"index" json:"parent_id"` // nil berarti kategori utama
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+	Parent    *Category      `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:NO ACTION;" json:"parent,omitempty"`
 	Products  []Product      `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:NO ACTION;" json:"products,omitempty"`
 }
 
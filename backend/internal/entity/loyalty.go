@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LoyaltyTransaction satu baris buku besar poin. Points bertanda: positif
+// untuk poin masuk, negatif untuk poin keluar. Remaining hanya dipakai pada
+// baris poin masuk untuk melacak sisa poin yang belum terpakai/kedaluwarsa.
+type LoyaltyTransaction struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	OrderID     *uuid.UUID `gorm:"type:uuid;index" json:"order_id,omitempty"`
+	Type        string     `gorm:"type:varchar(20);not null" json:"type"` // earn, redeem, reverse, restore, expire
+	Points      int        `gorm:"not null" json:"points"`
+	Remaining   int        `gorm:"not null;default:0" json:"remaining"`
+	ExpiresAt   *time.Time `gorm:"index" json:"expires_at,omitempty"`
+	Description string     `gorm:"type:varchar(255)" json:"description"`
+	CreatedAt   time.Time  `json:"created_at"`
+
+	User  *User  `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"user,omitempty"`
+	Order *Order `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:SET NULL;" json:"order,omitempty"`
+}
+
+func (LoyaltyTransaction) TableName() string {
+	return "loyalty_transactions"
+}
@@ -0,0 +1,42 @@
+package entity
+
+import "time"
+
+// Tipe opsi bawaan hasil migrasi tabel colors dan sizes. Nilainya tetap
+// disinkronkan dengan tabel lama supaya ColorID dan SizeID varian terisi.
+const (
+	OptionTypeColor = "Color"
+	OptionTypeSize  = "Size"
+)
+
+// OptionType jenis pilihan varian, misal warna, ukuran, bahan atau motif.
+type OptionType struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name      string    `gorm:"type:varchar(50);not null;uniqueIndex" json:"name"`
+	SortOrder int       `gorm:"not null;default:0" json:"sort_order"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Values []OptionValue `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"values,omitempty"`
+}
+
+func (OptionType) TableName() string {
+	return "public.option_types"
+}
+
+type OptionValue struct {
+	ID           uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	OptionTypeID uint   `gorm:"not null;uniqueIndex:idx_option_values_value" json:"option_type_id"`
+	Value        string `gorm:"type:varchar(50);not null;uniqueIndex:idx_option_values_value" json:"value"`
+	SortOrder    int    `gorm:"not null;default:0" json:"sort_order"`
+	// LegacyID id di tabel colors atau sizes untuk tipe Color dan Size
+	LegacyID  *uint     `gorm:"index" json:"legacy_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	OptionType *OptionType `json:"option_type,omitempty"`
+}
+
+func (OptionValue) TableName() string {
+	return "public.option_values"
+}
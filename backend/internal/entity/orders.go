@@ -14,6 +14,11 @@ type Order struct {
 	Status        string         `gorm:"type:varchar(20);default:pending" json:"status"`
 	IsPaid        bool           `gorm:"default:false" json:"is_paid"`
 	TotalAmount   float64        `gorm:"type:numeric(12,2);not null" json:"total_amount"`
+	DiscountAmount float64       `gorm:"type:numeric(12,2);not null;default:0" json:"discount_amount"`
+	VoucherCode   *string        `gorm:"type:varchar(50)" json:"voucher_code,omitempty"`
+	PointsRedeemed int           `gorm:"not null;default:0" json:"points_redeemed"`
+	PointsDiscount float64       `gorm:"type:numeric(12,2);not null;default:0" json:"points_discount"`
+	StoreCreditUsed float64      `gorm:"type:numeric(12,2);not null;default:0" json:"store_credit_used"`
 	TotalWeight   float64        `gorm:"type:numeric(12,2);not null" json:"total_weight"`
 	PaymentStatus string         `gorm:"type:varchar(30);default:uninitialized" json:"payment_status"`
 	TokenMidtrans *string        `gorm:"type:varchar(100)" json:"token_midtrans"`
@@ -27,6 +32,7 @@ type Order struct {
 	// Relationships
 	User      *User      `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"user,omitempty"`
 	Payments  []Payment  `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"payments,omitempty"`
+	VoucherRedemptions []VoucherRedemption `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"voucher_redemptions,omitempty"`
 }
 
 func (b *Order) TableName() string {
@@ -42,9 +48,14 @@ type OrderItem struct {
 	Price            float64   `gorm:"not null" json:"price"`
 	Subtotal         float64   `gorm:"not null" json:"subtotal"`
 	Note             *string   `gorm:"type:text" json:"note,omitempty"`
+	SaleCampaignID   *uuid.UUID `gorm:"type:uuid;index" json:"sale_campaign_id,omitempty"`
+	// Salinan SKU dan berat varian/produk saat checkout
+	SKU              *string   `gorm:"column:sku;type:varchar(64)" json:"sku,omitempty"`
+	Weight           float64   `gorm:"type:numeric(12,2);not null;default:0" json:"weight"`
 
 	Product        *Product        `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:NO ACTION;" json:"product,omitempty"`
 	ProductVariant *ProductVariant `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:NO ACTION;" json:"product_variant,omitempty"`
+	Components     []OrderItemComponent `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"components,omitempty"`
 
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
@@ -0,0 +1,60 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProductBundleItem adalah komponen dari produk bundle. Jika
+// ComponentVariantID kosong dan komponen punya varian, pembeli memilih
+// variannya di keranjang.
+type ProductBundleItem struct {
+	ID                 uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	BundleID           uuid.UUID  `gorm:"type:uuid;not null;index" json:"bundle_id"`
+	ComponentID        uuid.UUID  `gorm:"type:uuid;not null;index" json:"component_id"`
+	ComponentVariantID *uuid.UUID `gorm:"type:uuid" json:"component_variant_id,omitempty"`
+	Quantity           int        `gorm:"not null;default:1" json:"quantity"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+
+	Component        *Product        `gorm:"foreignKey:ComponentID;constraint:OnUpdate:NO ACTION,OnDelete:NO ACTION;" json:"component,omitempty"`
+	ComponentVariant *ProductVariant `gorm:"foreignKey:ComponentVariantID;constraint:OnUpdate:NO ACTION,OnDelete:NO ACTION;" json:"component_variant,omitempty"`
+}
+
+func (ProductBundleItem) TableName() string {
+	return "public.product_bundle_items"
+}
+
+// CartItemComponent menyimpan varian yang dipilih pembeli untuk setiap
+// komponen bundle.
+type CartItemComponent struct {
+	ID               uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	CartItemID       uuid.UUID  `gorm:"type:uuid;not null;index" json:"cart_item_id"`
+	BundleItemID     uuid.UUID  `gorm:"type:uuid;not null" json:"bundle_item_id"`
+	ProductVariantID *uuid.UUID `gorm:"type:uuid" json:"product_variant_id,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+func (CartItemComponent) TableName() string {
+	return "cart_item_components"
+}
+
+// OrderItemComponent mencatat komponen bundle yang terjual. Nama produk
+// dan varian disalin agar riwayat order tetap terbaca.
+type OrderItemComponent struct {
+	ID               uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OrderItemID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"order_item_id"`
+	ProductID        uuid.UUID  `gorm:"type:uuid;not null" json:"product_id"`
+	ProductVariantID *uuid.UUID `gorm:"type:uuid" json:"product_variant_id,omitempty"`
+	Quantity         int        `gorm:"not null" json:"quantity"` // total unit, sudah dikali jumlah bundle
+	ProductName      string     `gorm:"type:varchar(100);not null" json:"product_name"`
+	VariantName      string     `gorm:"type:varchar(100)" json:"variant_name"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+func (OrderItemComponent) TableName() string {
+	return "order_item_components"
+}
@@ -4,20 +4,36 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
+// Status publikasi produk. Produk hanya tampil di katalog jika published
+// dan waktu sekarang berada di antara PublishAt dan UnpublishAt.
+const (
+	ProductStatusDraft     = "draft"
+	ProductStatusPublished = "published"
+	ProductStatusArchived  = "archived"
+)
+
 // Product model
 type Product struct {
 	ID          uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	Name        string         `gorm:"type:varchar(100);not null" json:"name"`
+	Slug        *string        `gorm:"type:varchar(150);uniqueIndex" json:"slug"`
+	SKU         *string        `gorm:"column:sku;type:varchar(64);uniqueIndex:idx_products_sku,where:deleted_at IS NULL" json:"sku"`
+	Barcode     *string        `gorm:"type:varchar(64);uniqueIndex:idx_products_barcode,where:deleted_at IS NULL" json:"barcode"` // EAN-13 atau Code128
 	CategoryID  *uint          `gorm:"type:serial,not null"  json:"category_id"`
 	Description *string        `gorm:"type:text" json:"description"`
 	ImageURL    *string        `gorm:"type:text" json:"image_url"`
 	HasVariant  bool           `gorm:"default:false" json:"has_variant"`
+	ProductType string         `gorm:"type:varchar(20);not null;default:simple" json:"product_type"` // simple atau bundle
 	Stock       int            `gorm:"default:0" json:"stock"`
 	Price       float64        `gorm:"type:numeric(12,2);not null" json:"price"`
 	Weight      float64        `gorm:"type:numeric(12,2);not null" json:"weight"`
+	Status      string         `gorm:"type:varchar(20);not null;default:published;index" json:"status"` // draft, published atau archived
+	PublishAt   *time.Time     `gorm:"index" json:"publish_at"`
+	UnpublishAt *time.Time     `gorm:"index" json:"unpublish_at"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
@@ -27,6 +43,9 @@ type Product struct {
 	OrderItems     []OrderItem      `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:NO ACTION;" json:"order_items,omitempty"`
 	ProductReviews []ProductReview  `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"product_reviews,omitempty"`
 	Variants       []ProductVariant `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:NO ACTION;" json:"variants,omitempty"`
+	BundleItems    []ProductBundleItem `gorm:"foreignKey:BundleID;constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"bundle_items,omitempty"`
+	PriceTiers     []ProductPriceTier  `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"price_tiers,omitempty"`
+	Images         []ProductImage      `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"images,omitempty"`
 }
 
 func (Product) TableName() string {
@@ -36,9 +55,18 @@ func (Product) TableName() string {
 type ProductVariant struct {
 	ID        uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	ProductID uuid.UUID      `gorm:"type:uuid;not null" json:"product_id"`
-	ColorID   *uint          `gorm:"not null" json:"color_id"`
-	SizeID    *uint          `gorm:"not null" json:"size_id"`
+	// ColorID dan SizeID salinan opsi Color dan Size untuk kode lama,
+	// kosong jika varian tidak memakai opsi tersebut
+	ColorID   *uint          `json:"color_id"`
+	SizeID    *uint          `json:"size_id"`
 	Stock     int            `gorm:"default:0" json:"stock"`
+	// Override milik varian, kosong berarti mengikuti produk
+	Price          *float64   `gorm:"type:numeric(12,2)" json:"price"`
+	CompareAtPrice *float64   `gorm:"type:numeric(12,2)" json:"compare_at_price"`
+	Weight         *float64   `gorm:"type:numeric(12,2)" json:"weight"`
+	SKU            *string    `gorm:"column:sku;type:varchar(64);uniqueIndex:idx_product_variants_sku,where:deleted_at IS NULL" json:"sku"`
+	Barcode        *string    `gorm:"type:varchar(64);uniqueIndex:idx_product_variants_barcode_unique,where:deleted_at IS NULL" json:"barcode"`
+	ImageID        *uuid.UUID `gorm:"type:uuid" json:"image_id"` // gambar dari galeri produk
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
@@ -47,12 +75,62 @@ type ProductVariant struct {
 	Product *Product `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"product,omitempty"`
 	Color   *Color   `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:NO ACTION;" json:"color,omitempty"`
 	Size    *Size    `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:NO ACTION;" json:"size,omitempty"`
+	Image   *ProductImage `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:SET NULL;" json:"image,omitempty"`
+	Options []OptionValue `gorm:"many2many:product_variant_options;constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"options,omitempty"`
 }
 
 func (ProductVariant) TableName() string {
 	return "public.product_variants"
 }
 
+// ProductImage satu gambar di galeri produk. Product.ImageURL selalu diisi
+// URL gambar utama supaya keranjang dan order tetap memakai satu gambar.
+type ProductImage struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ProductID uuid.UUID `gorm:"type:uuid;not null;index" json:"product_id"`
+	URL       string    `gorm:"type:text;not null" json:"url"`
+	AltText   *string   `gorm:"type:varchar(255)" json:"alt_text"`
+	SortOrder int       `gorm:"not null;default:0" json:"sort_order"`
+	IsPrimary bool      `gorm:"not null;default:false" json:"is_primary"`
+	// Renditions kosong untuk gambar lama yang diupload sebelum ada proses
+	// resize
+	Renditions datatypes.JSONSlice[ImageRendition] `gorm:"type:jsonb" json:"renditions"`
+	CreatedAt  time.Time                           `json:"created_at"`
+	UpdatedAt  time.Time                           `json:"updated_at"`
+}
+
+// ImageRendition satu ukuran gambar (thumbnail, medium, large).
+type ImageRendition struct {
+	Name    string  `json:"name"`
+	Key     string  `json:"key,omitempty"` // key di storage, kosong untuk gambar lama
+	URL     string  `json:"url"`
+	WebPKey *string `json:"webp_key,omitempty"`
+	WebPURL *string `json:"webp_url,omitempty"`
+	Width   int     `json:"width"`
+	Height  int     `json:"height"`
+}
+
+func (ProductImage) TableName() string {
+	return "public.product_images"
+}
+
+// ProductPriceTier harga grosir berdasarkan jumlah pembelian. Tier dengan
+// CustomerGroup hanya berlaku untuk user di grup tersebut.
+type ProductPriceTier struct {
+	ID            uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ProductID     uuid.UUID `gorm:"type:uuid;not null;index" json:"product_id"`
+	MinQuantity   int       `gorm:"not null" json:"min_quantity"`
+	MaxQuantity   *int      `json:"max_quantity"` // nil berarti tanpa batas atas
+	Price         float64   `gorm:"type:numeric(12,2);not null" json:"price"`
+	CustomerGroup *string   `gorm:"type:varchar(50)" json:"customer_group"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func (ProductPriceTier) TableName() string {
+	return "public.product_price_tiers"
+}
+
 type ProductReview struct {
 	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	ProductID uuid.UUID `gorm:"type:uuid;not null" json:"product_id"`
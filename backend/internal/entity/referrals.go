@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Referral mencatat user yang mendaftar memakai kode referral user lain.
+// Hadiah diberikan ke kedua pihak saat order pertama referee lunas.
+type Referral struct {
+	ID                uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ReferrerID        uuid.UUID  `gorm:"type:uuid;not null;index" json:"referrer_id"`
+	RefereeID         uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"referee_id"`
+	Code              string     `gorm:"type:varchar(20);not null" json:"code"`
+	Status            string     `gorm:"type:varchar(20);not null;default:pending" json:"status"` // pending, converted, reversed
+	OrderID           *uuid.UUID `gorm:"type:uuid" json:"order_id,omitempty"`
+	ReferrerReward    float64    `gorm:"type:numeric(12,2);not null;default:0" json:"referrer_reward"`
+	RefereeReward     float64    `gorm:"type:numeric(12,2);not null;default:0" json:"referee_reward"`
+	ReferrerVoucherID *uuid.UUID `gorm:"type:uuid" json:"referrer_voucher_id,omitempty"`
+	RefereeVoucherID  *uuid.UUID `gorm:"type:uuid" json:"referee_voucher_id,omitempty"`
+	ConvertedAt       *time.Time `json:"converted_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+
+	Referrer        *User    `gorm:"foreignKey:ReferrerID;constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"referrer,omitempty"`
+	Referee         *User    `gorm:"foreignKey:RefereeID;constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"referee,omitempty"`
+	Order           *Order   `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:SET NULL;" json:"order,omitempty"`
+	ReferrerVoucher *Voucher `gorm:"foreignKey:ReferrerVoucherID;constraint:OnUpdate:NO ACTION,OnDelete:SET NULL;" json:"referrer_voucher,omitempty"`
+	RefereeVoucher  *Voucher `gorm:"foreignKey:RefereeVoucherID;constraint:OnUpdate:NO ACTION,OnDelete:SET NULL;" json:"referee_voucher,omitempty"`
+}
+
+func (Referral) TableName() string {
+	return "referrals"
+}
+
+// ReferralSettings aturan hadiah referral, hanya ada satu baris.
+type ReferralSettings struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	IsActive        bool      `gorm:"not null;default:true" json:"is_active"`
+	ReferrerReward  float64   `gorm:"type:numeric(12,2);not null;default:25000" json:"referrer_reward"`
+	RefereeReward   float64   `gorm:"type:numeric(12,2);not null;default:25000" json:"referee_reward"`
+	MinOrderAmount  float64   `gorm:"type:numeric(12,2);not null;default:0" json:"min_order_amount"` // minimal order pertama referee
+	RewardMinSpend  float64   `gorm:"type:numeric(12,2);not null;default:0" json:"reward_min_spend"` // minimal belanja voucher hadiah
+	RewardValidDays int       `gorm:"not null;default:30" json:"reward_valid_days"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+func (ReferralSettings) TableName() string {
+	return "referral_settings"
+}
@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SaleCampaign struct {
+	ID        uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Name      string         `gorm:"type:varchar(100);not null" json:"name"`
+	StartsAt  time.Time      `gorm:"not null;index" json:"starts_at"`
+	EndsAt    time.Time      `gorm:"not null;index" json:"ends_at"`
+	StockCap  *int           `json:"stock_cap"` // total unit promo, kosong berarti tanpa batas
+	SoldCount int            `gorm:"not null;default:0" json:"sold_count"`
+	IsActive  bool           `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+
+	Items []SaleCampaignItem `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"items,omitempty"`
+}
+
+func (SaleCampaign) TableName() string {
+	return "sale_campaigns"
+}
+
+// SaleCampaignItem berlaku untuk satu varian, atau semua varian produk jika
+// ProductVariantID kosong. Isi salah satu dari SalePrice atau DiscountPercent.
+type SaleCampaignItem struct {
+	ID               uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	SaleCampaignID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"sale_campaign_id"`
+	ProductID        uuid.UUID  `gorm:"type:uuid;not null;index" json:"product_id"`
+	ProductVariantID *uuid.UUID `gorm:"type:uuid" json:"product_variant_id,omitempty"`
+	SalePrice        *float64   `gorm:"type:numeric(12,2)" json:"sale_price"`
+	DiscountPercent  *float64   `gorm:"type:numeric(5,2)" json:"discount_percent"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+
+	SaleCampaign   *SaleCampaign   `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"sale_campaign,omitempty"`
+	Product        *Product        `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"product,omitempty"`
+	ProductVariant *ProductVariant `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"product_variant,omitempty"`
+}
+
+func (SaleCampaignItem) TableName() string {
+	return "sale_campaign_items"
+}
@@ -0,0 +1,21 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SearchLog satu pencarian produk. Query disimpan sudah dinormalisasi supaya
+// pencarian yang sama bisa dikelompokkan di laporan.
+type SearchLog struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Query       string    `gorm:"type:varchar(100);not null;index" json:"query"`
+	ResultCount int64     `gorm:"not null;default:0" json:"result_count"`
+	Fuzzy       bool      `gorm:"not null;default:false" json:"fuzzy"`
+	CreatedAt   time.Time `gorm:"index" json:"created_at"`
+}
+
+func (SearchLog) TableName() string {
+	return "search_logs"
+}
@@ -0,0 +1,23 @@
+package entity
+
+import "time"
+
+const (
+	SlugEntityProduct  = "product"
+	SlugEntityCategory = "category"
+)
+
+// SlugRedirect slug lama produk atau kategori yang sudah diganti. URL lama
+// diarahkan ke entitas lewat EntityID sehingga rename berkali-kali tidak
+// membuat rantai redirect.
+type SlugRedirect struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	EntityType string    `gorm:"type:varchar(20);not null;uniqueIndex:idx_slug_redirects_old_slug" json:"entity_type"`
+	OldSlug    string    `gorm:"type:varchar(150);not null;uniqueIndex:idx_slug_redirects_old_slug" json:"old_slug"`
+	EntityID   string    `gorm:"type:varchar(36);not null;index" json:"entity_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (SlugRedirect) TableName() string {
+	return "public.slug_redirects"
+}
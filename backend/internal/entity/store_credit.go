@@ -0,0 +1,70 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GiftCard kode saldo toko. Kartu dibuat admin atau dibeli user lewat
+// Midtrans, lalu saldonya dipindah ke dompet user yang menukarkannya.
+type GiftCard struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Code          string     `gorm:"type:varchar(30);uniqueIndex;not null" json:"code"`
+	Amount        float64    `gorm:"type:numeric(12,2);not null" json:"amount"`
+	Status        string     `gorm:"type:varchar(20);not null;default:active" json:"status"` // pending, active, redeemed, disabled, cancelled
+	IssuedByID    *uuid.UUID `gorm:"type:uuid" json:"issued_by_id,omitempty"`
+	PurchasedByID *uuid.UUID `gorm:"type:uuid;index" json:"purchased_by_id,omitempty"`
+	RedeemedByID  *uuid.UUID `gorm:"type:uuid;index" json:"redeemed_by_id,omitempty"`
+	RedeemedAt    *time.Time `json:"redeemed_at,omitempty"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	Note          *string    `gorm:"type:varchar(255)" json:"note,omitempty"`
+	TokenMidtrans *string    `gorm:"type:varchar(100)" json:"token_midtrans,omitempty"`
+	PaymentUrl    *string    `gorm:"type:varchar(255)" json:"payment_url,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	PurchasedBy *User `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:SET NULL;" json:"purchased_by,omitempty"`
+	RedeemedBy  *User `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:SET NULL;" json:"redeemed_by,omitempty"`
+}
+
+func (GiftCard) TableName() string {
+	return "gift_cards"
+}
+
+// StoreCreditWallet saldo kredit toko per user. Baris ini dikunci setiap
+// kali saldo berubah supaya saldo dan buku besar selalu sama.
+type StoreCreditWallet struct {
+	UserID    uuid.UUID `gorm:"type:uuid;primary_key" json:"user_id"`
+	Balance   float64   `gorm:"type:numeric(12,2);not null;default:0" json:"balance"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	User *User `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"user,omitempty"`
+}
+
+func (StoreCreditWallet) TableName() string {
+	return "store_credit_wallets"
+}
+
+// StoreCreditTransaction satu baris buku besar kredit toko. Baris tidak
+// pernah diubah, koreksi dicatat sebagai baris baru. Amount bertanda:
+// positif untuk kredit masuk, negatif untuk kredit keluar.
+type StoreCreditTransaction struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID       uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Type         string     `gorm:"type:varchar(20);not null" json:"type"` // gift_card, refund, checkout, restore
+	Amount       float64    `gorm:"type:numeric(12,2);not null" json:"amount"`
+	BalanceAfter float64    `gorm:"type:numeric(12,2);not null" json:"balance_after"`
+	OrderID      *uuid.UUID `gorm:"type:uuid;index" json:"order_id,omitempty"`
+	GiftCardID   *uuid.UUID `gorm:"type:uuid;index" json:"gift_card_id,omitempty"`
+	Description  string     `gorm:"type:varchar(255)" json:"description"`
+	CreatedAt    time.Time  `json:"created_at"`
+
+	User     *User     `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"user,omitempty"`
+	Order    *Order    `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:SET NULL;" json:"order,omitempty"`
+	GiftCard *GiftCard `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:SET NULL;" json:"gift_card,omitempty"`
+}
+
+func (StoreCreditTransaction) TableName() string {
+	return "store_credit_transactions"
+}
@@ -16,6 +16,9 @@ type User struct {
 	ResetToken    string         `gorm:"type:text" json:"-"`
 	ResetTokenExp time.Time      `json:"-"`
 	Role          string         `gorm:"type:varchar(20);not null;default:admin" json:"role"`
+	CustomerGroup *string        `gorm:"type:varchar(50)" json:"customer_group,omitempty"` // mis. reseller, untuk harga grosir
+	ReferralCode  *string        `gorm:"type:varchar(20);uniqueIndex" json:"referral_code,omitempty"`
+	ReferredByID  *uuid.UUID     `gorm:"type:uuid;index" json:"referred_by_id,omitempty"`
 	CreatedAt     time.Time      `json:"created_at"`
 	UpdatedAt     time.Time      `json:"updated_at"`
 	DeletedAt     gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
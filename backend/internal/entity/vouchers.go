@@ -0,0 +1,76 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Voucher struct {
+	ID           uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Code         string         `gorm:"type:varchar(50);not null;uniqueIndex:idx_vouchers_code,where:deleted_at IS NULL" json:"code"` // selalu huruf besar
+	Description  *string        `gorm:"type:text" json:"description"`
+	DiscountType string         `gorm:"type:varchar(20);not null" json:"discount_type"` // percentage, fixed
+	Value        float64        `gorm:"type:numeric(12,2);not null" json:"value"`
+	MinSpend     float64        `gorm:"type:numeric(12,2);not null;default:0" json:"min_spend"`
+	MaxDiscount  *float64       `gorm:"type:numeric(12,2)" json:"max_discount"`
+	StartsAt     *time.Time     `json:"starts_at"`
+	EndsAt       *time.Time     `json:"ends_at"`
+	UsageLimit   *int           `json:"usage_limit"`    // total pemakaian, kosong berarti tanpa batas
+	PerUserLimit *int           `json:"per_user_limit"` // pemakaian per user, kosong berarti tanpa batas
+	IsActive     bool           `gorm:"not null;default:true" json:"is_active"`
+	UserID       *uuid.UUID     `gorm:"type:uuid;index" json:"user_id,omitempty"` // voucher pribadi, mis. hadiah referral
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+
+	// Relationships, kosong berarti berlaku untuk semua produk
+	Products   []VoucherProduct  `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"products,omitempty"`
+	Categories []VoucherCategory `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"categories,omitempty"`
+}
+
+func (Voucher) TableName() string {
+	return "vouchers"
+}
+
+type VoucherProduct struct {
+	VoucherID uuid.UUID `gorm:"type:uuid;primaryKey" json:"voucher_id"`
+	ProductID uuid.UUID `gorm:"type:uuid;primaryKey" json:"product_id"`
+
+	Product *Product `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"product,omitempty"`
+}
+
+func (VoucherProduct) TableName() string {
+	return "voucher_products"
+}
+
+type VoucherCategory struct {
+	VoucherID  uuid.UUID `gorm:"type:uuid;primaryKey" json:"voucher_id"`
+	CategoryID uint      `gorm:"primaryKey" json:"category_id"`
+
+	Category *Category `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"category,omitempty"`
+}
+
+func (VoucherCategory) TableName() string {
+	return "voucher_categories"
+}
+
+type VoucherRedemption struct {
+	ID             uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	VoucherID      uuid.UUID `gorm:"type:uuid;not null;index" json:"voucher_id"`
+	UserID         uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	OrderID        uuid.UUID `gorm:"type:uuid;not null;index" json:"order_id"`
+	Code           string    `gorm:"type:varchar(50);not null" json:"code"`
+	DiscountAmount float64   `gorm:"type:numeric(12,2);not null" json:"discount_amount"`
+	Status         string    `gorm:"type:varchar(20);not null;default:applied" json:"status"` // applied, cancelled
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+
+	Voucher *Voucher `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:NO ACTION;" json:"voucher,omitempty"`
+	Order   *Order   `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"order,omitempty"`
+}
+
+func (VoucherRedemption) TableName() string {
+	return "voucher_redemptions"
+}
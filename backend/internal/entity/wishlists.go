@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Wishlist struct {
+	ID         uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID     uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+	ShareToken *string        `gorm:"type:varchar(64);uniqueIndex" json:"share_token,omitempty"` // kosong jika tidak dibagikan
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+
+	// Relationships
+	User  *User          `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"user,omitempty"`
+	Items []WishlistItem `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"items,omitempty"`
+}
+
+func (Wishlist) TableName() string {
+	return "wishlists"
+}
+
+type WishlistItem struct {
+	ID               uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	WishlistID       uuid.UUID  `gorm:"type:uuid;not null;index" json:"wishlist_id"`
+	ProductID        uuid.UUID  `gorm:"type:uuid;not null;index" json:"product_id"`
+	ProductVariantID *uuid.UUID `gorm:"type:uuid" json:"product_variant_id,omitempty"`
+	Note             string     `gorm:"type:text" json:"note,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+
+	// Relationships
+	Wishlist       *Wishlist       `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"wishlist,omitempty"`
+	Product        *Product        `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"product,omitempty"`
+	ProductVariant *ProductVariant `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:CASCADE;" json:"product_variant,omitempty"`
+}
+
+func (WishlistItem) TableName() string {
+	return "wishlist_items"
+}
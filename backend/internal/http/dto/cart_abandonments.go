@@ -0,0 +1,30 @@
+package dto
+
+type AbandonedCartReport struct {
+	Start                  string  `json:"start" gorm:"-"`
+	End                    string  `json:"end" gorm:"-"`
+	AbandonedCarts         int64   `json:"abandoned_carts"`
+	AbandonedValue         float64 `json:"abandoned_value"`
+	RemindedCarts          int64   `json:"reminded_carts"`
+	RemindersSent          int64   `json:"reminders_sent"`
+	ConvertedCarts         int64   `json:"converted_carts"`
+	ConvertedAfterReminder int64   `json:"converted_after_reminder"`
+	ConvertedRevenue       float64 `json:"converted_revenue"`
+	ConversionRate         float64 `json:"conversion_rate" gorm:"-"` // persen
+}
+
+// AbandonedCartEmail adalah data yang diisi ke template email pengingat.
+type AbandonedCartEmail struct {
+	Name        string
+	Items       []AbandonedCartEmailItem
+	TotalAmount string
+	CartURL     string
+	Sequence    int
+}
+
+type AbandonedCartEmailItem struct {
+	Name     string
+	Quantity int
+	Price    string
+	Subtotal string
+}
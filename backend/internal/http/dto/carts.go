@@ -7,6 +7,12 @@ type AddToCartRequest struct {
 	ProductVariantID *uuid.UUID `json:"product_variant_id,omitempty"`
 	Quantity         int        `json:"quantity" validate:"required,min=1"`
 	Note             string     `json:"note"`
+	BundleSelections []BundleSelection `json:"bundle_selections,omitempty"` // wajib untuk produk bundle
+}
+
+type BundleSelection struct {
+	BundleItemID     uuid.UUID  `json:"bundle_item_id" validate:"required"`
+	ProductVariantID *uuid.UUID `json:"product_variant_id"`
 }
 
 type AddToCartItemsRequest struct {
@@ -17,7 +23,11 @@ type AddToCartItemsRequest struct {
 type GetCartItemsResponse struct {
 	CartID        uuid.UUID   `json:"cart_id"`
 	CartItems     []CartItems `json:"cart_items"`
-	TotalAmount   float64     `json:"total_amount"`
+	Subtotal      float64     `json:"subtotal"`
+	VoucherCode   *string     `json:"voucher_code,omitempty"`
+	VoucherError  string      `json:"voucher_error,omitempty"` // voucher tersimpan tapi tidak berlaku lagi
+	DiscountAmount float64    `json:"discount_amount"`
+	TotalAmount   float64     `json:"total_amount"` // setelah diskon
 	TotalPaid     float64     `json:"total_paid"`
 	TotalWeight   float64     `json:"total_weight"`
 	ColorName     *string     `json:"color_name,omitempty"`
@@ -31,18 +41,68 @@ type CartItems struct {
 	Product     *GetProductByID `json:"product"`
 	Note        *string         `json:"note"`
 	Subtotal    float64         `json:"subtotal"`
+	SaleCampaignID *uuid.UUID   `json:"sale_campaign_id,omitempty"`
+	Components  []CartItemComponent `json:"components,omitempty"` // hanya untuk produk bundle
+	PriceTier   *PriceTierInfo  `json:"price_tier,omitempty"` // tier grosir yang dipakai
+}
+
+type CartItemComponent struct {
+	BundleItemID     uuid.UUID  `json:"bundle_item_id"`
+	ProductID        uuid.UUID  `json:"product_id"`
+	ProductName      string     `json:"product_name"`
+	ProductVariantID *uuid.UUID `json:"product_variant_id,omitempty"`
+	Color            string     `json:"color,omitempty"`
+	Size             string     `json:"size,omitempty"`
+	Quantity         int        `json:"quantity"` // per bundle
 }
 
 type UpdateCartItemRequest struct {
-	ID        uuid.UUID `json:"cart_item_id" validate:"required"`
-	CartID    uuid.UUID `json:"cart_id" validate:"required"`
-	ProductID uuid.UUID `json:"product_id" validate:"required"`
+	ID               uuid.UUID  `json:"cart_item_id" validate:"required"`
+	CartID           uuid.UUID  `json:"-"` // dari path, dicocokkan dengan keranjang user
 	ProductVariantID *uuid.UUID `json:"product_variant_id,omitempty"`
-	Quantity  int       `json:"quantity" validate:"required,min=1"`
-	Note      *string   `json:"note"`
+	Quantity         int        `json:"quantity" validate:"required,min=1"`
+	Note             *string    `json:"note"`
+	BundleSelections []BundleSelection `json:"bundle_selections,omitempty"`
+}
+
+type UpdateGuestCartItemRequest struct {
+	Quantity int     `json:"quantity" validate:"required,min=1"`
+	Note     *string `json:"note"`
 }
 
 type UpdateCartRequest struct {
 	ID     uuid.UUID `json:"id" validate:"required"`
 	Status string    `json:"status" validate:"required"`
 }
+
+
+type ValidateCartRequest struct {
+	SelectedItems []uuid.UUID `json:"selected_items"`
+}
+
+type CartValidationResponse struct {
+	CartID      uuid.UUID            `json:"cart_id"`
+	Valid       bool                 `json:"valid"`
+	TotalAmount float64              `json:"total_amount"`
+	Items       []CartItemValidation `json:"items"`
+}
+
+type CartItemValidation struct {
+	CartItemID       uuid.UUID           `json:"cart_item_id"`
+	ProductID        uuid.UUID           `json:"product_id"`
+	ProductVariantID *uuid.UUID          `json:"product_variant_id,omitempty"`
+	ProductName      string              `json:"product_name"`
+	Status           string              `json:"status"` // ok, price_changed, insufficient_stock, unavailable, variant_required
+	Message          string              `json:"message,omitempty"`
+	Quantity         int                 `json:"quantity"`
+	AvailableStock   int                 `json:"available_stock"`
+	PreviousPrice    float64             `json:"previous_price"`
+	CurrentPrice     float64             `json:"current_price"`
+	Suggestion       *CartItemSuggestion `json:"suggestion,omitempty"`
+}
+
+type CartItemSuggestion struct {
+	Action     string      `json:"action"` // accept_price, reduce_quantity, remove_item, select_variant
+	Quantity   *int        `json:"quantity,omitempty"`
+	VariantIDs []uuid.UUID `json:"variant_ids,omitempty"`
+}
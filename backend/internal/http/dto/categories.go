@@ -1,16 +1,32 @@
 package dto
 
 type GetAllCategories struct {
-	ID   uint   `json:"id"`
-	Name string `json:"name"`
+	ID       uint    `json:"id"`
+	Name     string  `json:"name"`
+	Slug     *string `json:"slug"`
+	ParentID *uint   `json:"parent_id"`
+}
+
+// CategoryTree kategori beserta subkategorinya.
+type CategoryTree struct {
+	ID       uint            `json:"id"`
+	Name     string          `json:"name"`
+	Slug     *string         `json:"slug"`
+	ParentID *uint           `json:"parent_id"`
+	Children []*CategoryTree `json:"children"`
 }
 type CreateCategoryRequest struct {
-	Name string `json:"name"`
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`      // kosong berarti dibuat dari nama
+	ParentID *uint  `json:"parent_id"` // kosong berarti kategori utama
 }
 
 type UpdateCategoryRequest struct {
-	ID   uint   `json:"id"`
-	Name string `json:"name"`
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`
+	KeepSlug bool   `json:"keep_slug"` // pertahankan slug lama walaupun nama berubah
+	ParentID *uint  `json:"parent_id"` // kosong berarti tidak dipindah, 0 berarti jadi kategori utama
 }
 
 type DeleteCategoryRequest struct {
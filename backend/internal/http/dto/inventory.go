@@ -0,0 +1,41 @@
+package dto
+
+import "github.com/google/uuid"
+
+const (
+	InventoryItemProduct = "product"
+	InventoryItemVariant = "variant"
+)
+
+// InventoryLookupResponse hasil scan SKU atau barcode. Variant hanya terisi
+// jika kode milik varian, Stock selalu stok barang yang di-scan.
+type InventoryLookupResponse struct {
+	Type    string              `json:"type"`
+	Code    string              `json:"code"`
+	Stock   int                 `json:"stock"`
+	Product InventoryProduct    `json:"product"`
+	Variant *ProductVariantInfo `json:"variant,omitempty"`
+}
+
+type InventoryProduct struct {
+	ID         uuid.UUID `json:"id"`
+	Name       string    `json:"name"`
+	Slug       *string   `json:"slug,omitempty"`
+	SKU        *string   `json:"sku,omitempty"`
+	Barcode    *string   `json:"barcode,omitempty"`
+	ImageURL   *string   `json:"image_url,omitempty"`
+	HasVariant bool      `json:"has_variant"`
+	Price      float64   `json:"price"`
+	Stock      int       `json:"stock"`
+}
+
+// LabelSheetRequest isi salah satu dari ProductID atau VariantID per item.
+type LabelSheetRequest struct {
+	Items []LabelSheetItem `json:"items"`
+}
+
+type LabelSheetItem struct {
+	ProductID *uuid.UUID `json:"product_id,omitempty"`
+	VariantID *uuid.UUID `json:"variant_id,omitempty"`
+	Copies    int        `json:"copies"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type LoyaltySummary struct {
+	Balance          int                          `json:"balance"`
+	PointValue       float64                      `json:"point_value"` // nilai tukar 1 poin (Rp)
+	MinRedeemPoints  int                          `json:"min_redeem_points"`
+	MaxRedeemPercent float64                      `json:"max_redeem_percent"`
+	History          []LoyaltyTransactionResponse `json:"history"`
+}
+
+type LoyaltyTransactionResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	OrderID     *uuid.UUID `json:"order_id,omitempty"`
+	Type        string     `json:"type"`
+	Points      int        `json:"points"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	Description string     `json:"description"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
@@ -0,0 +1,39 @@
+package dto
+
+type OptionTypeInfo struct {
+	ID        uint              `json:"id"`
+	Name      string            `json:"name"`
+	SortOrder int               `json:"sort_order"`
+	Values    []OptionValueInfo `json:"values"`
+}
+
+type OptionValueInfo struct {
+	ID        uint   `json:"id"`
+	Value     string `json:"value"`
+	SortOrder int    `json:"sort_order"`
+}
+
+type CreateOptionTypeRequest struct {
+	ID        uint   `json:"-"`
+	Name      string `json:"name" validate:"required"`
+	SortOrder int    `json:"sort_order"`
+}
+
+type UpdateOptionTypeRequest struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	SortOrder int    `json:"sort_order"`
+}
+
+type CreateOptionValueRequest struct {
+	ID           uint   `json:"-"`
+	OptionTypeID uint   `json:"option_type_id"`
+	Value        string `json:"value" validate:"required"`
+	SortOrder    int    `json:"sort_order"`
+}
+
+type UpdateOptionValueRequest struct {
+	ID        uint   `json:"id"`
+	Value     string `json:"value"`
+	SortOrder int    `json:"sort_order"`
+}
@@ -20,6 +20,11 @@ type ShowOrderResponse struct {
 	OrderCode     string       `json:"order_code"`
 	Status        string       `json:"status"`
 	TotalAmount   float64      `json:"total_amount"`
+	DiscountAmount float64     `json:"discount_amount"`
+	VoucherCode   *string      `json:"voucher_code,omitempty"`
+	PointsRedeemed int         `json:"points_redeemed"`
+	PointsDiscount float64     `json:"points_discount"`
+	StoreCreditUsed float64    `json:"store_credit_used"`
 	TotalPaid     float64      `json:"total_paid"`
 	TotalWeight   float64      `json:"total_weight"`
 	PaymentStatus string       `json:"payment_status"`
@@ -32,6 +37,11 @@ type GetAllOrdersResponse struct {
 	OrderCode     string       `json:"order_code"`
 	Status        string       `json:"status"`
 	TotalAmount   float64      `json:"total_amount"`
+	DiscountAmount float64     `json:"discount_amount"`
+	VoucherCode   *string      `json:"voucher_code,omitempty"`
+	PointsRedeemed int         `json:"points_redeemed"`
+	PointsDiscount float64     `json:"points_discount"`
+	StoreCreditUsed float64    `json:"store_credit_used"`
 	TotalPaid     float64      `json:"total_paid"`
 	TotalWeight   float64      `json:"total_weight"`
 	PaymentStatus string       `json:"payment_status"`
@@ -43,6 +53,15 @@ type OrderItems struct {
 	Subtotal  float64                  `json:"subtotal"`
 	Note      *string                  `json:"note"`
 	Product   *GetProductByIDShowOrder `json:"product"`
+	Components []OrderItemComponent    `json:"components,omitempty"` // isi bundle
+}
+
+type OrderItemComponent struct {
+	ProductID        uuid.UUID  `json:"product_id"`
+	ProductVariantID *uuid.UUID `json:"product_variant_id,omitempty"`
+	ProductName      string     `json:"product_name"`
+	VariantName      string     `json:"variant_name,omitempty"`
+	Quantity         int        `json:"quantity"`
 }
 
 type GetPaymentStatusResponse struct {
@@ -60,4 +79,6 @@ type GetOrdersPaidResponse struct {
 
 type CheckoutRequest struct {
 	SelectedItems []uuid.UUID `json:"selected_items"`
+	RedeemPoints  int         `json:"redeem_points"` // opsional, poin loyalti yang ditukar
+	StoreCredit   float64     `json:"store_credit"`  // opsional, kredit toko yang dipakai membayar
 }
\ No newline at end of file
@@ -0,0 +1,20 @@
+package dto
+
+// ProductImportReport hasil validasi atau import CSV produk. Applied hanya
+// true jika bukan dry run dan tidak ada error sama sekali.
+type ProductImportReport struct {
+	DryRun          bool                 `json:"dry_run"`
+	Applied         bool                 `json:"applied"`
+	Rows            int                  `json:"rows"`
+	ProductsCreated int                  `json:"products_created"`
+	ProductsUpdated int                  `json:"products_updated"`
+	VariantsCreated int                  `json:"variants_created"`
+	VariantsUpdated int                  `json:"variants_updated"`
+	Errors          []ProductImportError `json:"errors"`
+}
+
+type ProductImportError struct {
+	Line    int    `json:"line"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
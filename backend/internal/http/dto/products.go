@@ -2,6 +2,7 @@ package dto
 
 import (
 	"mime/multipart"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -9,68 +10,256 @@ import (
 type GetAllProducts struct {
 	ID           uuid.UUID `json:"id"`
 	Name         string    `json:"name"`
+	Slug         *string   `json:"slug"`
+	SKU          *string   `json:"sku"`
+	Barcode      *string   `json:"barcode"`
 	Stock        int       `json:"stock"`
 	Weight       float64   `json:"weight"`
-	Price        float64   `json:"price"`
+	Price        float64   `json:"price"` // harga efektif, sudah termasuk promo
+	OriginalPrice float64    `json:"original_price"`
+	SalePrice    *float64   `json:"sale_price"`
+	SaleEndsAt   *time.Time `json:"sale_ends_at"`
 	Description  *string   `json:"description"`
 	ImageURL     *string   `json:"image_url"`
+	Images       []ProductImageInfo `json:"images"`
 	CategoryID   *uint     `json:"category_id"`
 	CategoryName *string   `json:"category_name"`
 	HasVariant   bool      `json:"has_variant"`
+	ProductType  string    `json:"product_type"`
+	Status       string     `json:"status"`
+	PublishAt    *time.Time `json:"publish_at"`
+	UnpublishAt  *time.Time `json:"unpublish_at"`
 	Variants     []ProductVariantInfo  `json:"variants,omitempty"`
+	BundleItems  []BundleItemInfo `json:"bundle_items,omitempty"` // hanya untuk produk bundle
+	Highlight    *SearchHighlight `json:"highlight,omitempty"`    // hanya untuk hasil pencarian
+}
+
+// ProductListQuery parameter listing produk dari query string.
+type ProductListQuery struct {
+	Page       int
+	Limit      int
+	Sort       string // relevance, newest, price_asc, price_desc, best_selling, rating
+	Search     string
+	Fuzzy      bool // diisi service: cari dengan kemiripan trigram, bukan full-text
+	CategoryID *uint
+	ColorIDs   []uint
+	SizeIDs    []uint
+	MinPrice   *float64
+	MaxPrice   *float64
+	InStock    bool
+	Admin      bool   // diisi service: listing admin, produk yang belum tayang ikut
+	Status     string // hanya untuk listing admin, kosong berarti semua status
+}
+
+type ProductListResponse struct {
+	Products   []*GetAllProducts `json:"products"`
+	Pagination Pagination        `json:"pagination"`
+	Facets     ProductFacets     `json:"facets"`
+	Fuzzy      bool              `json:"fuzzy,omitempty"` // hasil pencarian dari kemiripan ejaan
+}
+
+// SearchHighlight potongan teks yang cocok dengan kata kunci, ditandai <mark>.
+type SearchHighlight struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type Pagination struct {
+	Page       int   `json:"page"`
+	Limit      int   `json:"limit"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"total_pages"`
+}
+
+// ProductFacets jumlah produk per pilihan filter. Jumlah di setiap facet
+// dihitung dengan filter lain tetap berlaku, kecuali filter facet itu sendiri.
+type ProductFacets struct {
+	Categories []FacetCount `json:"categories"`
+	Colors     []FacetCount `json:"colors"`
+	Sizes      []FacetCount `json:"sizes"`
+}
+
+type FacetCount struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
 }
 
 type GetProductByCategoryID struct {
 	ID           uuid.UUID `json:"id"`
 	Name         string    `json:"name"`
+	Slug         *string   `json:"slug"`
+	SKU          *string   `json:"sku"`
+	Barcode      *string   `json:"barcode"`
 	Stock        int       `json:"stock"`
 	Weight       float64   `json:"weight"`
-	Price        float64   `json:"price"`
+	Price        float64   `json:"price"` // harga efektif, sudah termasuk promo
+	OriginalPrice float64    `json:"original_price"`
+	SalePrice    *float64   `json:"sale_price"`
+	SaleEndsAt   *time.Time `json:"sale_ends_at"`
 	Description  *string   `json:"description"`
 	ImageURL     *string   `json:"image_url"`
+	Images       []ProductImageInfo `json:"images"`
 	CategoryID   *uint     `json:"category_id"`
 	CategoryName *string   `json:"category_name"`
 	HasVariant   bool      `json:"has_variant"`
+	ProductType  string    `json:"product_type"`
 	Variants     []ProductVariantInfo  `json:"variants,omitempty"`
+	BundleItems  []BundleItemInfo `json:"bundle_items,omitempty"` // hanya untuk produk bundle
 }
 
 type GetProductByName struct {
 	ID           uuid.UUID             `json:"id"`
 	Name         string                `json:"name"`
+	Slug         *string               `json:"slug"`
+	SKU          *string   `json:"sku"`
+	Barcode      *string   `json:"barcode"`
 	Weight       float64               `json:"weight"`
-	Price        float64               `json:"price"`
+	Price        float64               `json:"price"` // harga efektif, sudah termasuk promo
+	OriginalPrice float64    `json:"original_price"`
+	SalePrice    *float64   `json:"sale_price"`
+	SaleEndsAt   *time.Time `json:"sale_ends_at"`
 	Stock        int                   `json:"stock"`
 	Description  *string               `json:"description"`
 	ImageURL     *string               `json:"image_url"`
+	Images       []ProductImageInfo `json:"images"`
 	CategoryID   *uint                 `json:"category_id"`
 	CategoryName *string               `json:"category_name"`
 	HasVariant   bool                  `json:"has_variant"`
+	ProductType  string    `json:"product_type"`
 	Variants     []ProductVariantInfo  `json:"variants,omitempty"` // hanya muncul jika ada variasi
+	BundleItems  []BundleItemInfo `json:"bundle_items,omitempty"` // hanya untuk produk bundle
+}
+
+
+type ProductImageInfo struct {
+	ID        uuid.UUID `json:"id"`
+	URL       string    `json:"url"`
+	AltText   *string   `json:"alt_text"`
+	SortOrder int       `json:"sort_order"`
+	IsPrimary bool      `json:"is_primary"`
+	Renditions map[string]ImageRenditionInfo `json:"renditions"` // thumbnail, medium, large
+}
+
+type ImageRenditionInfo struct {
+	URL     string  `json:"url"`
+	WebPURL *string `json:"webp_url,omitempty"`
+	Width   int     `json:"width"`
+	Height  int     `json:"height"`
+}
+
+type UploadProductImagesRequest struct {
+	ProductID uuid.UUID
+	Images    []*multipart.FileHeader
+	AltTexts  []string // urutannya sama dengan Images
+	Primary   bool     // gambar pertama yang diupload dijadikan gambar utama
 }
 
+type ReorderProductImagesRequest struct {
+	ProductID uuid.UUID   `json:"-"`
+	ImageIDs  []uuid.UUID `json:"image_ids"` // seluruh gambar produk sesuai urutan baru
+}
+
+type UpdateProductImageRequest struct {
+	ProductID uuid.UUID `json:"-"`
+	ImageID   uuid.UUID `json:"-"`
+	AltText   *string   `json:"alt_text"`
+	IsPrimary bool      `json:"is_primary"`
+}
 
 type ProductVariantInfo struct {
 	ID      uuid.UUID `json:"id"`
+	// ColorID, SizeID, Color dan Size tetap dikirim untuk klien lama,
+	// daftar lengkap opsi ada di Options
 	ColorID *uint      `json:"color_id"`
 	SizeID  *uint      `json:"size_id"`
 	Color   string   `json:"color"`
 	Size    string   `json:"size"`
+	Options []VariantOptionInfo `json:"options,omitempty"`
 	Stock   int       `json:"stock"`
+	// Price dan Weight sudah memakai nilai produk jika varian tidak punya
+	// nilai sendiri
+	Price          float64    `json:"price"`
+	CompareAtPrice *float64   `json:"compare_at_price,omitempty"`
+	Weight         float64    `json:"weight"`
+	SKU            *string    `json:"sku,omitempty"`
+	Barcode        *string    `json:"barcode,omitempty"`
+	ImageID        *uuid.UUID `json:"image_id,omitempty"`
+	ImageURL       *string    `json:"image_url,omitempty"`
+	SalePrice  *float64   `json:"sale_price,omitempty"`
+	SaleEndsAt *time.Time `json:"sale_ends_at,omitempty"`
 }
 
+type VariantOptionInfo struct {
+	OptionTypeID  uint   `json:"option_type_id"`
+	OptionType    string `json:"option_type"`
+	OptionValueID uint   `json:"option_value_id"`
+	Value         string `json:"value"`
+}
+
+type BundleItemInfo struct {
+	ID               uuid.UUID            `json:"id"`
+	ProductID        uuid.UUID            `json:"product_id"`
+	ProductName      string               `json:"product_name"`
+	ProductVariantID *uuid.UUID           `json:"product_variant_id,omitempty"` // varian tetap dari admin
+	Quantity         int                  `json:"quantity"`
+	RequiresVariant  bool                 `json:"requires_variant"` // pembeli harus memilih varian
+	Variants         []ProductVariantInfo `json:"variants,omitempty"`
+}
 
 type GetProductByID struct {
 	ID           uuid.UUID `json:"id"`
 	Name         string    `json:"name"`
+	Slug         *string   `json:"slug"`
+	SKU          *string   `json:"sku"`
+	Barcode      *string   `json:"barcode"`
 	Stock        int       `json:"stock"`
 	Weight       float64   `json:"weight"`
-	Price        float64   `json:"price"`
+	Price        float64   `json:"price"` // harga efektif, sudah termasuk promo
+	OriginalPrice float64    `json:"original_price"`
+	SalePrice    *float64   `json:"sale_price"`
+	SaleEndsAt   *time.Time `json:"sale_ends_at"`
 	Description  *string   `json:"description"`
 	ImageURL     *string   `json:"image_url"`
+	Images       []ProductImageInfo `json:"images"`
 	CategoryID   *uint     `json:"category_id"`
 	CategoryName *string   `json:"category_name"`
 	HasVariant   bool      `json:"has_variant"`
+	ProductType  string    `json:"product_type"`
+	Status       string     `json:"status"`
+	PublishAt    *time.Time `json:"publish_at"`
+	UnpublishAt  *time.Time `json:"unpublish_at"`
 	Variants     []ProductVariantInfo  `json:"variants,omitempty"`
+	BundleItems  []BundleItemInfo `json:"bundle_items,omitempty"` // hanya untuk produk bundle
+	PriceTiers   []PriceTierInfo  `json:"price_tiers,omitempty"`
+}
+
+type PriceTierInfo struct {
+	MinQuantity   int     `json:"min_quantity"`
+	MaxQuantity   *int    `json:"max_quantity"`
+	Price         float64 `json:"price"`
+	CustomerGroup *string `json:"customer_group,omitempty"`
+}
+
+// UpdateProductPublicationRequest status dan jadwal tayang produk. Nilai
+// jadwal yang kosong menghapus jadwal.
+type UpdateProductPublicationRequest struct {
+	ProductID   uuid.UUID  `json:"-"`
+	Status      string     `json:"status"`
+	PublishAt   *time.Time `json:"publish_at"`
+	UnpublishAt *time.Time `json:"unpublish_at"`
+}
+
+type UpdatePriceTiersRequest struct {
+	ProductID uuid.UUID          `json:"-"`
+	Tiers     []PriceTierRequest `json:"tiers"` // kosong berarti hapus semua tier
+}
+
+type PriceTierRequest struct {
+	MinQuantity   int     `json:"min_quantity" validate:"required,min=1"`
+	MaxQuantity   *int    `json:"max_quantity"`
+	Price         float64 `json:"price" validate:"required"`
+	CustomerGroup *string `json:"customer_group"`
 }
 
 type GetProductByIDShowOrder struct {
@@ -100,45 +289,91 @@ type GetProductReviewResponse struct {
 
 type CreateProductRequest struct {
 	Name        string     `json:"name" validate:"required"`
+	Slug        string     `json:"slug"` // kosong berarti dibuat dari nama
 	CategoryID  *uint      `json:"category_id"`
 	Description *string    `json:"description"`
 	ImageURL    *string    `json:"image_url"`
 	Image       *multipart.FileHeader
 	HasVariant  bool       `json:"has_variant"`
+	ProductType string     `json:"product_type"`
 	Price       float64    `json:"price" validate:"required,min=0"`
 	Weight      float64    `json:"weight"`
+	SKU         string     `json:"sku"`     // kosong berarti dibuat otomatis
+	Barcode     string     `json:"barcode"` // kosong berarti dibuat otomatis (EAN-13)
 	Stock   int   `json:"stock" validate:"min=0"`
+	// Kosong berarti draft, produk baru tidak langsung tampil di katalog
+	Status      string     `json:"status"`
+	PublishAt   *time.Time `json:"publish_at"`
+	UnpublishAt *time.Time `json:"unpublish_at"`
 
 	// digunakan jika HasVariant == true
 	Variants []CreateProductVariantRequest `json:"variants,omitempty" validate:"omitempty,dive"`
+
+	// digunakan jika ProductType == bundle
+	BundleItems []BundleItemRequest `json:"bundle_items,omitempty" validate:"omitempty,dive"`
 }
 
+// Opsi varian diisi lewat OptionValueIDs, ColorID dan SizeID masih
+// diterima untuk klien lama.
 type CreateProductVariantRequest struct {
-	ColorID *uint    `json:"color_id" validate:"required"`
-	SizeID  *uint    `json:"size_id" validate:"required"`
+	ColorID *uint    `json:"color_id"`
+	SizeID  *uint    `json:"size_id"`
+	OptionValueIDs []uint `json:"option_value_ids"`
 	Stock   int      `json:"stock" validate:"required,min=0"`
+	ProductVariantOverrides
+}
+
+// ProductVariantOverrides nilai milik varian, kosong berarti mengikuti
+// produk.
+type ProductVariantOverrides struct {
+	Price          *float64   `json:"price"`
+	CompareAtPrice *float64   `json:"compare_at_price"`
+	Weight         *float64   `json:"weight"`
+	SKU            *string    `json:"sku"`
+	Barcode        *string    `json:"barcode"`
+	ImageID        *uuid.UUID `json:"image_id"` // gambar dari galeri produk
 }
 
 type UpdateProductRequest struct {
 	ID          uuid.UUID `json:"id" validate:"required"`
 	Name        string     `json:"name" validate:"required"`
+	Slug        string     `json:"slug"`      // kosong berarti dibuat ulang dari nama jika nama berubah
+	KeepSlug    bool       `json:"keep_slug"` // pertahankan slug lama walaupun nama berubah
 	CategoryID  *uint      `json:"category_id"`
 	Description *string    `json:"description"`
 	ImageURL    *string    `json:"image_url"`
 	Image       *multipart.FileHeader
 	HasVariant  bool       `json:"has_variant"`
+	ProductType string     `json:"product_type"`
 	Price       float64    `json:"price" validate:"required,min=0"`
 	Weight      float64    `json:"weight"`
+	SKU         string     `json:"sku"`     // kosong berarti dibuat otomatis
+	Barcode     string     `json:"barcode"` // kosong berarti dibuat otomatis (EAN-13)
 	Stock   int   `json:"stock" validate:"min=0"`
+	// Kosong berarti draft, produk baru tidak langsung tampil di katalog
+	Status      string     `json:"status"`
+	PublishAt   *time.Time `json:"publish_at"`
+	UnpublishAt *time.Time `json:"unpublish_at"`
 
 	// digunakan jika HasVariant == true
 	Variants []UpdateProductVariantRequest `json:"variants,omitempty" validate:"omitempty,dive"`
+
+	// digunakan jika ProductType == bundle
+	BundleItems []BundleItemRequest `json:"bundle_items,omitempty" validate:"omitempty,dive"`
 }
+type BundleItemRequest struct {
+	ProductID        uuid.UUID  `json:"product_id" validate:"required"`
+	ProductVariantID *uuid.UUID `json:"product_variant_id,omitempty"` // kosong berarti dipilih pembeli
+	Quantity         int        `json:"quantity" validate:"required,min=1"`
+}
+
 type UpdateProductVariantRequest struct {
 	ID      *uuid.UUID `json:"id"` // NULL jika varian baru
 	ColorID *uint `json:"color_id"`
 	SizeID  *uint `json:"size_id"`
+	OptionValueIDs []uint `json:"option_value_ids"`
 	Stock   int   `json:"stock" validate:"min=0"`
+	ProductVariantOverrides
 }
 type DeleteProductRequest struct {
 	ID uuid.UUID `json:"id" validate:"required"`
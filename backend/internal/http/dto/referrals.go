@@ -0,0 +1,63 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReferralSettingsRequest struct {
+	IsActive        *bool   `json:"is_active"`
+	ReferrerReward  float64 `json:"referrer_reward"`
+	RefereeReward   float64 `json:"referee_reward"`
+	MinOrderAmount  float64 `json:"min_order_amount"`
+	RewardMinSpend  float64 `json:"reward_min_spend"`
+	RewardValidDays int     `json:"reward_valid_days"`
+}
+
+type ReferralSettingsResponse struct {
+	IsActive        bool      `json:"is_active"`
+	ReferrerReward  float64   `json:"referrer_reward"`
+	RefereeReward   float64   `json:"referee_reward"`
+	MinOrderAmount  float64   `json:"min_order_amount"`
+	RewardMinSpend  float64   `json:"reward_min_spend"`
+	RewardValidDays int       `json:"reward_valid_days"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type ReferralReport struct {
+	TotalReferrals int                  `json:"total_referrals"`
+	Converted      int                  `json:"converted"`
+	ConversionRate float64              `json:"conversion_rate"` // persen
+	TotalRewards   float64              `json:"total_rewards"`
+	Referrals      []ReferralReportItem `json:"referrals"`
+}
+
+type ReferralReportItem struct {
+	ID             uuid.UUID  `json:"id"`
+	ReferrerID     uuid.UUID  `json:"referrer_id"`
+	ReferrerName   string     `json:"referrer_name"`
+	RefereeID      uuid.UUID  `json:"referee_id"`
+	RefereeName    string     `json:"referee_name"`
+	Code           string     `json:"code"`
+	Status         string     `json:"status"`
+	OrderCode      *string    `json:"order_code,omitempty"`
+	ReferrerReward float64    `json:"referrer_reward"`
+	RefereeReward  float64    `json:"referee_reward"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ConvertedAt    *time.Time `json:"converted_at,omitempty"`
+}
+
+type ReferralSummary struct {
+	Code      string           `json:"code"`
+	Invited   int              `json:"invited"`
+	Converted int              `json:"converted"`
+	Rewards   []ReferralReward `json:"rewards"`
+}
+
+type ReferralReward struct {
+	VoucherCode string     `json:"voucher_code"`
+	Amount      float64    `json:"amount"`
+	MinSpend    float64    `json:"min_spend"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
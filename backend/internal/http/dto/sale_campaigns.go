@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateSaleCampaignRequest struct {
+	Name     string                    `json:"name" validate:"required"`
+	StartsAt time.Time                 `json:"starts_at" validate:"required"`
+	EndsAt   time.Time                 `json:"ends_at" validate:"required"`
+	StockCap *int                      `json:"stock_cap"`
+	IsActive *bool                     `json:"is_active"`
+	Items    []SaleCampaignItemRequest `json:"items" validate:"required"`
+}
+
+type SaleCampaignItemRequest struct {
+	ProductID        uuid.UUID  `json:"product_id" validate:"required"`
+	ProductVariantID *uuid.UUID `json:"product_variant_id,omitempty"`
+	SalePrice        *float64   `json:"sale_price"`
+	DiscountPercent  *float64   `json:"discount_percent"`
+}
+
+type UpdateSaleCampaignRequest struct {
+	ID uuid.UUID `json:"-"`
+	CreateSaleCampaignRequest
+}
+
+type SaleCampaignResponse struct {
+	ID        uuid.UUID                  `json:"id"`
+	Name      string                     `json:"name"`
+	StartsAt  time.Time                  `json:"starts_at"`
+	EndsAt    time.Time                  `json:"ends_at"`
+	StockCap  *int                       `json:"stock_cap"`
+	SoldCount int                        `json:"sold_count"`
+	IsActive  bool                       `json:"is_active"`
+	Status    string                     `json:"status"` // scheduled, running, ended, sold_out, inactive
+	Items     []SaleCampaignItemResponse `json:"items"`
+}
+
+type SaleCampaignItemResponse struct {
+	ID               uuid.UUID  `json:"id"`
+	ProductID        uuid.UUID  `json:"product_id"`
+	ProductName      string     `json:"product_name"`
+	ProductVariantID *uuid.UUID `json:"product_variant_id,omitempty"`
+	SalePrice        *float64   `json:"sale_price"`
+	DiscountPercent  *float64   `json:"discount_percent"`
+}
@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ProductSuggestion struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+type CategorySuggestion struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+type SuggestResponse struct {
+	Products   []ProductSuggestion  `json:"products"`
+	Categories []CategorySuggestion `json:"categories"`
+	Queries    []string             `json:"queries"`
+}
+
+// ProductSuggestionWeight bobot produk di indeks autocomplete, Sold adalah
+// jumlah terjual dari order yang sudah dibayar.
+type ProductSuggestionWeight struct {
+	ID   uuid.UUID
+	Name string
+	Sold int64
+}
+
+type CategorySuggestionWeight struct {
+	ID       uint
+	Name     string
+	Products int64
+	Sold     int64
+}
+
+type SearchQueryStat struct {
+	Query          string    `json:"query"`
+	Searches       int64     `json:"searches"`
+	LastSearchedAt time.Time `json:"last_searched_at"`
+}
+
+type SearchReport struct {
+	From              time.Time         `json:"from"`
+	TotalSearches     int64             `json:"total_searches"`
+	ZeroResultCount   int64             `json:"zero_result_count"`
+	TopQueries        []SearchQueryStat `json:"top_queries"`
+	ZeroResultQueries []SearchQueryStat `json:"zero_result_queries"`
+}
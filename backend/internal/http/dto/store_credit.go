@@ -0,0 +1,64 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type StoreCreditSummary struct {
+	Balance float64                          `json:"balance"`
+	History []StoreCreditTransactionResponse `json:"history"`
+}
+
+type StoreCreditTransactionResponse struct {
+	ID           uuid.UUID  `json:"id"`
+	OrderID      *uuid.UUID `json:"order_id,omitempty"`
+	GiftCardID   *uuid.UUID `json:"gift_card_id,omitempty"`
+	Type         string     `json:"type"`
+	Amount       float64    `json:"amount"`
+	BalanceAfter float64    `json:"balance_after"`
+	Description  string     `json:"description"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+type IssueGiftCardRequest struct {
+	IssuedByID uuid.UUID  `json:"-"`
+	Amount     float64    `json:"amount" validate:"required"`
+	Quantity   int        `json:"quantity"` // opsional, default 1
+	ExpiresAt  *time.Time `json:"expires_at"`
+	Note       *string    `json:"note"`
+}
+
+type PurchaseGiftCardRequest struct {
+	Amount float64 `json:"amount" validate:"required"`
+}
+
+type RedeemGiftCardRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type GiftCardResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	Code        string     `json:"code"`
+	Amount      float64    `json:"amount"`
+	Status      string     `json:"status"`
+	PurchasedBy *string    `json:"purchased_by,omitempty"`
+	RedeemedBy  *string    `json:"redeemed_by,omitempty"`
+	RedeemedAt  *time.Time `json:"redeemed_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	Note        *string    `json:"note,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type GiftCardPurchaseResponse struct {
+	GiftCardID  uuid.UUID `json:"gift_card_id"`
+	Token       string    `json:"token"`
+	RedirectURL string    `json:"redirect_url"`
+}
+
+type RefundToCreditRequest struct {
+	OrderID uuid.UUID `json:"-"`
+	Amount  float64   `json:"amount" validate:"required"`
+	Reason  string    `json:"reason" validate:"required"`
+}
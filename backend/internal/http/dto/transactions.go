@@ -19,6 +19,7 @@ type TransactionResponse struct {
 type SnapRsponse struct {
 	Token       string `json:"token"`
 	RedirectURL string `json:"redirect_url"`
+	PaidWithCredit bool `json:"paid_with_credit"` // true jika tagihan lunas tanpa Midtrans (kredit toko, voucher, atau poin)
 }
 
 type MidtransNotification struct {
@@ -3,8 +3,9 @@ package dto
 import "github.com/google/uuid"
 
 type LoginRequest struct {
-	Email    string `json:"email" validate:"required"`
-	Password string `json:"password" validate:"required"`
+	Email      string `json:"email" validate:"required"`
+	Password   string `json:"password" validate:"required"`
+	GuestToken string `json:"guest_token,omitempty"`
 }
 
 type RegisterRequest struct {
@@ -12,6 +13,7 @@ type RegisterRequest struct {
 	Email    string `json:"email" validate:"required"`
 	Password string `json:"password" validate:"required"`
 	PhoneNumber string `json:"phone_number" validate:"required"`
+	ReferralCode string `json:"referral_code,omitempty"` // opsional
 }
 
 type GetUserProfileResponse struct {
@@ -21,6 +23,9 @@ type GetUserProfileResponse struct {
 	FullName  string    `json:"full_name"`
 	Phone     string    `json:"phone"`
 	// Address   string    `json:"address"`
+	ReferralCode string   `json:"referral_code"`
+	Referral  *ReferralSummary `json:"referral,omitempty"`
+	Loyalty   *LoyaltySummary `json:"loyalty,omitempty"`
 }
 type GetAllUserResponse struct {
 	UserID    uuid.UUID `json:"user_id"`
@@ -30,6 +35,12 @@ type GetAllUserResponse struct {
 	FullName  string    `json:"full_name"`
 	Phone     string    `json:"phone"`
 	Role      string    `json:"role"`
+	CustomerGroup *string `json:"customer_group,omitempty"`
+}
+
+type UpdateCustomerGroupRequest struct {
+	UserID        uuid.UUID `json:"-"`
+	CustomerGroup *string   `json:"customer_group"` // null untuk menghapus grup
 }
 type UpdateUserProfileRequest struct {
 	ProfileID uuid.UUID 
@@ -65,7 +76,9 @@ type UserLogs struct{
 }
 
 type GoogleLoginRequest struct {
-	IdToken string `json:"id_token"`
+	IdToken    string `json:"id_token"`
+	GuestToken string `json:"guest_token,omitempty"`
+	ReferralCode string `json:"referral_code,omitempty"` // hanya dipakai saat akun baru dibuat
 }
 type ResetPasswordRequest struct {
 	Token       string `json:"token" form:"token" validate:"required"`
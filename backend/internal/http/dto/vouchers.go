@@ -0,0 +1,59 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateVoucherRequest struct {
+	Code         string      `json:"code" validate:"required"`
+	Description  *string     `json:"description"`
+	DiscountType string      `json:"discount_type" validate:"required"` // percentage, fixed
+	Value        float64     `json:"value" validate:"required"`
+	MinSpend     float64     `json:"min_spend"`
+	MaxDiscount  *float64    `json:"max_discount"`
+	StartsAt     *time.Time  `json:"starts_at"`
+	EndsAt       *time.Time  `json:"ends_at"`
+	UsageLimit   *int        `json:"usage_limit"`
+	PerUserLimit *int        `json:"per_user_limit"`
+	IsActive     *bool       `json:"is_active"`
+	ProductIDs   []uuid.UUID `json:"product_ids"`
+	CategoryIDs  []uint      `json:"category_ids"`
+}
+
+type UpdateVoucherRequest struct {
+	ID uuid.UUID `json:"-"`
+	CreateVoucherRequest
+}
+
+type VoucherResponse struct {
+	ID           uuid.UUID   `json:"id"`
+	Code         string      `json:"code"`
+	Description  *string     `json:"description"`
+	DiscountType string      `json:"discount_type"`
+	Value        float64     `json:"value"`
+	MinSpend     float64     `json:"min_spend"`
+	MaxDiscount  *float64    `json:"max_discount"`
+	StartsAt     *time.Time  `json:"starts_at"`
+	EndsAt       *time.Time  `json:"ends_at"`
+	UsageLimit   *int        `json:"usage_limit"`
+	PerUserLimit *int        `json:"per_user_limit"`
+	IsActive     bool        `json:"is_active"`
+	UserID       *uuid.UUID  `json:"user_id,omitempty"`
+	UsedCount    int64       `json:"used_count"`
+	ProductIDs   []uuid.UUID `json:"product_ids"`
+	CategoryIDs  []uint      `json:"category_ids"`
+}
+
+type ApplyVoucherRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// VoucherDiscount adalah hasil perhitungan voucher terhadap item keranjang.
+type VoucherDiscount struct {
+	VoucherID        uuid.UUID `json:"voucher_id"`
+	Code             string    `json:"code"`
+	EligibleSubtotal float64   `json:"eligible_subtotal"`
+	DiscountAmount   float64   `json:"discount_amount"`
+}
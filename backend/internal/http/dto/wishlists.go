@@ -0,0 +1,37 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AddWishlistItemRequest struct {
+	ProductID        uuid.UUID  `json:"product_id" validate:"required"`
+	ProductVariantID *uuid.UUID `json:"product_variant_id,omitempty"`
+	Note             string     `json:"note"`
+}
+
+type MoveWishlistItemToCartRequest struct {
+	Quantity         int               `json:"quantity"`                    // default 1
+	BundleSelections []BundleSelection `json:"bundle_selections,omitempty"` // wajib untuk produk bundle
+}
+
+type WishlistResponse struct {
+	ID         uuid.UUID              `json:"id"`
+	OwnerName  string                 `json:"owner_name,omitempty"`
+	ShareToken *string                `json:"share_token,omitempty"`
+	Items      []WishlistItemResponse `json:"items"`
+}
+
+type WishlistItemResponse struct {
+	ID        uuid.UUID           `json:"wishlist_item_id"`
+	Product   *GetProductByID     `json:"product"`
+	Variant   *ProductVariantInfo `json:"variant,omitempty"`
+	Price     float64             `json:"price"`
+	Stock     int                 `json:"stock"`
+	InStock   bool                `json:"in_stock"`
+	Available bool                `json:"available"` // false jika produk tidak tayang
+	Note      *string             `json:"note"`
+	AddedAt   time.Time           `json:"added_at"`
+}
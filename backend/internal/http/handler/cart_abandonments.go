@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"mola-web/internal/service"
+	"mola-web/pkg/response"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type CartAbandonmentHandler struct {
+	cartAbandonmentService service.CartAbandonmentService
+}
+
+func NewCartAbandonmentHandler(cartAbandonmentService service.CartAbandonmentService) CartAbandonmentHandler {
+	return CartAbandonmentHandler{
+		cartAbandonmentService: cartAbandonmentService,
+	}
+}
+
+func (h *CartAbandonmentHandler) GetReport(ctx echo.Context) error {
+	report, err := h.cartAbandonmentService.GetReport(ctx.Request().Context(), ctx.QueryParam("start"), ctx.QueryParam("end"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"report": report,
+	}))
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"mola-web/internal/http/dto"
 	"mola-web/internal/service"
 	"mola-web/pkg/response"
@@ -36,7 +37,9 @@ func (h *CartHandler) AddToCart(ctx echo.Context) error {
 
 	// Tambah item ke cart user
 	err := h.cartService.AddToCart(ctx.Request().Context(), userID, &req)
-	if err != nil {
+	if errors.Is(err, service.ErrProductNotFound) {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, "Product not found"))
+	} else if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
 	}
 
@@ -60,20 +63,52 @@ func (h *CartHandler) GetCart(ctx echo.Context) error {
 	}))
 }
 
+func (h *CartHandler) Validate(ctx echo.Context) error {
+	userId, ok := ctx.Get("user_id").(uuid.UUID)
+	if !ok {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Unauthorized"))
+	}
+
+	req := new(dto.ValidateCartRequest)
+	if err := ctx.Bind(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	result, err := h.cartService.ValidateCart(ctx.Request().Context(), userId, req)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"validation": result,
+	}))
+}
+
 func (h *CartHandler) UpdateCartItem(ctx echo.Context) error {
 	userId, ok := ctx.Get("user_id").(uuid.UUID)
 	if !ok {
 		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Unauthorized"))
 	}
 	
-	cartId := uuid.MustParse(ctx.Param("cartID"))
+	cartId, err := uuid.Parse(ctx.Param("cartID"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid cart ID"))
+	}
 	req := new(dto.UpdateCartItemRequest)
-	req.CartID = cartId
 	if err := ctx.Bind(req); err != nil {
 		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
 	}
-	err := h.cartService.UpdateCartItem(ctx.Request().Context(), userId, req)
-	if err != nil {
+	// Path berisi ID keranjang, atau ID item jika body tidak menyertakannya
+	if req.ID == uuid.Nil || req.ID == cartId {
+		req.ID = cartId
+	} else {
+		req.CartID = cartId
+	}
+	err = h.cartService.UpdateCartItem(ctx.Request().Context(), userId, req)
+	if errors.Is(err, service.ErrCartItemNotFound) {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	} else if errors.Is(err, service.ErrProductNotFound) {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, "Product not found"))
+	} else if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
 	}
 	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
@@ -87,9 +122,14 @@ func (h *CartHandler) RemoveCartItem(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Unauthorized"))
 	}
 	
-	req := uuid.MustParse(ctx.Param("cartID"))
-	err := h.cartService.RemoveCartItem(ctx.Request().Context(), userId, req)
+	req, err := uuid.Parse(ctx.Param("cartID"))
 	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid cart item ID"))
+	}
+	err = h.cartService.RemoveCartItem(ctx.Request().Context(), userId, req)
+	if errors.Is(err, service.ErrCartItemNotFound) {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	} else if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
 	}
 	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"mola-web/internal/http/dto"
 	"mola-web/internal/service"
 	"mola-web/pkg/response"
@@ -31,6 +32,29 @@ func (h CategoryHandler) GetAll(ctx echo.Context) error {
 	}))
 }
 
+func (h CategoryHandler) GetTree(ctx echo.Context) error {
+	categories, err := h.categoryService.GetTree(ctx.Request().Context())
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"categories": categories,
+	}))
+}
+
+// categoryErrorStatus status HTTP untuk kesalahan validasi kategori.
+func categoryErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrCategoryNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrCategoryHasChildren), errors.Is(err, service.ErrCategoryHasProducts):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrParentCategoryNotFound), errors.Is(err, service.ErrCategoryCycle), isSlugError(err):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
 func (h CategoryHandler) Create(ctx echo.Context) error {
 	request := new(dto.CreateCategoryRequest)
 	if err := ctx.Bind(request); err != nil {
@@ -38,7 +62,8 @@ func (h CategoryHandler) Create(ctx echo.Context) error {
 	}
 	err := h.categoryService.Create(ctx.Request().Context(), request)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		code := categoryErrorStatus(err)
+		return ctx.JSON(code, response.ErrorResponse(code, err.Error()))
 	}
 	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
 		"category": request.Name,
@@ -58,7 +83,8 @@ func (h CategoryHandler) Update(ctx echo.Context) error{
 	request.ID = uint(categoryID)
 	err = h.categoryService.Update(ctx.Request().Context(), request)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		code := categoryErrorStatus(err)
+		return ctx.JSON(code, response.ErrorResponse(code, err.Error()))
 	}
 	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
 		"category": request.Name,
@@ -73,7 +99,8 @@ func (h CategoryHandler) Delete(ctx echo.Context) error {
 	}
 	err = h.categoryService.Delete(ctx.Request().Context(), uint(categoryID))
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		code := categoryErrorStatus(err)
+		return ctx.JSON(code, response.ErrorResponse(code, err.Error()))
 	}
 	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
 		"category": categoryID,
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"errors"
+	"mola-web/internal/http/dto"
+	"mola-web/internal/service"
+	"mola-web/pkg/response"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	guestTokenHeader = "X-Guest-Token"
+	guestTokenCookie = "guest_token"
+)
+
+type GuestCartHandler struct {
+	guestCartService service.GuestCartService
+}
+
+func NewGuestCartHandler(guestCartService service.GuestCartService) GuestCartHandler {
+	return GuestCartHandler{guestCartService: guestCartService}
+}
+
+// guestTokenFromRequest membaca token tamu dari header, lalu dari cookie.
+func guestTokenFromRequest(ctx echo.Context) string {
+	if guestToken := ctx.Request().Header.Get(guestTokenHeader); guestToken != "" {
+		return guestToken
+	}
+	if cookie, err := ctx.Cookie(guestTokenCookie); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
+
+func setGuestTokenCookie(ctx echo.Context, guestToken string) {
+	ctx.SetCookie(&http.Cookie{
+		Name:     guestTokenCookie,
+		Value:    guestToken,
+		Path:     "/",
+		Expires:  time.Now().Add(7 * 24 * time.Hour),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func (h *GuestCartHandler) AddToCart(ctx echo.Context) error {
+	var req dto.AddToCartRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	// Buat token tamu baru jika belum ada atau tidak valid
+	guestToken := guestTokenFromRequest(ctx)
+	guestID, err := h.guestCartService.ResolveGuest(guestToken)
+	if err != nil {
+		guestID, guestToken, err = h.guestCartService.NewGuest()
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		}
+		setGuestTokenCookie(ctx, guestToken)
+	}
+
+	if err := h.guestCartService.AddToCart(ctx.Request().Context(), guestID, &req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"cart":        req,
+		"guest_token": guestToken,
+	}))
+}
+
+func (h *GuestCartHandler) GetCart(ctx echo.Context) error {
+	guestID, err := h.guestCartService.ResolveGuest(guestTokenFromRequest(ctx))
+	if err != nil {
+		return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+			"cart": dto.GetCartItemsResponse{CartItems: []dto.CartItems{}},
+		}))
+	}
+
+	cart, err := h.guestCartService.GetCart(ctx.Request().Context(), guestID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"cart": cart,
+	}))
+}
+
+func (h *GuestCartHandler) UpdateCartItem(ctx echo.Context) error {
+	guestID, err := h.guestCartService.ResolveGuest(guestTokenFromRequest(ctx))
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "invalid guest token"))
+	}
+	itemID, err := uuid.Parse(ctx.Param("itemID"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid cart item ID"))
+	}
+
+	req := new(dto.UpdateGuestCartItemRequest)
+	if err := ctx.Bind(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	err = h.guestCartService.UpdateCartItem(ctx.Request().Context(), guestID, itemID, req)
+	if errors.Is(err, service.ErrGuestCartItemNotFound) {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	} else if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"cart": req,
+	}))
+}
+
+func (h *GuestCartHandler) RemoveCartItem(ctx echo.Context) error {
+	guestID, err := h.guestCartService.ResolveGuest(guestTokenFromRequest(ctx))
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, "invalid guest token"))
+	}
+	itemID, err := uuid.Parse(ctx.Param("itemID"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid cart item ID"))
+	}
+
+	err = h.guestCartService.RemoveCartItem(ctx.Request().Context(), guestID, itemID)
+	if errors.Is(err, service.ErrGuestCartItemNotFound) {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	} else if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"cart": itemID,
+	}))
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"errors"
+	"mola-web/internal/http/dto"
+	"mola-web/internal/service"
+	"mola-web/pkg/response"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type InventoryHandler struct {
+	inventoryService service.InventoryService
+}
+
+func NewInventoryHandler(inventoryService service.InventoryService) InventoryHandler {
+	return InventoryHandler{inventoryService: inventoryService}
+}
+
+func (h InventoryHandler) Lookup(ctx echo.Context) error {
+	result, err := h.inventoryService.Lookup(ctx.Request().Context(), ctx.QueryParam("code"))
+	if errors.Is(err, service.ErrInventoryCodeRequired) {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	if errors.Is(err, service.ErrInventoryCodeNotFound) {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"item": result,
+	}))
+}
+
+func (h InventoryHandler) LabelSheet(ctx echo.Context) error {
+	request := new(dto.LabelSheetRequest)
+	if err := ctx.Bind(request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	pdf, err := h.inventoryService.LabelSheet(ctx.Request().Context(), request)
+	if errors.Is(err, service.ErrInvalidLabelRequest) {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	if errors.Is(err, service.ErrLabelItemNotFound) {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	ctx.Response().Header().Set(echo.HeaderContentDisposition, `inline; filename="labels.pdf"`)
+	return ctx.Blob(http.StatusOK, "application/pdf", pdf)
+}
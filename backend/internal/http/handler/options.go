@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"errors"
+	"mola-web/internal/http/dto"
+	"mola-web/internal/service"
+	"mola-web/pkg/response"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type OptionHandler struct {
+	optionService service.OptionService
+}
+
+func NewOptionHandler(optionService service.OptionService) OptionHandler {
+	return OptionHandler{optionService: optionService}
+}
+
+func optionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrOptionTypeNotFound), errors.Is(err, service.ErrOptionValueNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrOptionInUse), errors.Is(err, service.ErrBuiltinOptionType):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func (h OptionHandler) GetAll(ctx echo.Context) error {
+	options, err := h.optionService.GetAll(ctx.Request().Context())
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"options": options,
+	}))
+}
+
+func (h OptionHandler) CreateType(ctx echo.Context) error {
+	request := new(dto.CreateOptionTypeRequest)
+	if err := ctx.Bind(request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	if request.Name == "" {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "name is required"))
+	}
+	err := h.optionService.CreateType(ctx.Request().Context(), request)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"id":   request.ID,
+		"name": request.Name,
+	}))
+}
+
+func (h OptionHandler) UpdateType(ctx echo.Context) error {
+	optionTypeID, err := strconv.ParseUint(ctx.Param("optionTypeID"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid option type ID"))
+	}
+	request := new(dto.UpdateOptionTypeRequest)
+	if err := ctx.Bind(request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	request.ID = uint(optionTypeID)
+	err = h.optionService.UpdateType(ctx.Request().Context(), request)
+	if err != nil {
+		code := optionErrorStatus(err)
+		return ctx.JSON(code, response.ErrorResponse(code, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"id": request.ID,
+	}))
+}
+
+func (h OptionHandler) DeleteType(ctx echo.Context) error {
+	optionTypeID, err := strconv.ParseUint(ctx.Param("optionTypeID"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid option type ID"))
+	}
+	err = h.optionService.DeleteType(ctx.Request().Context(), uint(optionTypeID))
+	if err != nil {
+		code := optionErrorStatus(err)
+		return ctx.JSON(code, response.ErrorResponse(code, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"id": optionTypeID,
+	}))
+}
+
+func (h OptionHandler) CreateValue(ctx echo.Context) error {
+	optionTypeID, err := strconv.ParseUint(ctx.Param("optionTypeID"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid option type ID"))
+	}
+	request := new(dto.CreateOptionValueRequest)
+	if err := ctx.Bind(request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	if request.Value == "" {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "value is required"))
+	}
+	request.OptionTypeID = uint(optionTypeID)
+	err = h.optionService.CreateValue(ctx.Request().Context(), request)
+	if err != nil {
+		code := optionErrorStatus(err)
+		return ctx.JSON(code, response.ErrorResponse(code, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"id":    request.ID,
+		"value": request.Value,
+	}))
+}
+
+func (h OptionHandler) UpdateValue(ctx echo.Context) error {
+	valueID, err := strconv.ParseUint(ctx.Param("valueID"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid option value ID"))
+	}
+	request := new(dto.UpdateOptionValueRequest)
+	if err := ctx.Bind(request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	request.ID = uint(valueID)
+	err = h.optionService.UpdateValue(ctx.Request().Context(), request)
+	if err != nil {
+		code := optionErrorStatus(err)
+		return ctx.JSON(code, response.ErrorResponse(code, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"id": request.ID,
+	}))
+}
+
+func (h OptionHandler) DeleteValue(ctx echo.Context) error {
+	valueID, err := strconv.ParseUint(ctx.Param("valueID"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid option value ID"))
+	}
+	err = h.optionService.DeleteValue(ctx.Request().Context(), uint(valueID))
+	if err != nil {
+		code := optionErrorStatus(err)
+		return ctx.JSON(code, response.ErrorResponse(code, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"id": valueID,
+	}))
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"mola-web/internal/http/dto"
 	"mola-web/internal/service"
 	"mola-web/pkg/response"
@@ -42,8 +43,15 @@ func (h *OrderHandler) Checkout(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid request"))
 	}
 
-	redirectURL, err := h.orderService.Checkout(ctx.Request().Context(), userID, email, name,  req.SelectedItems)
-	if err != nil {
+	redirectURL, err := h.orderService.Checkout(ctx.Request().Context(), userID, email, name,  req.SelectedItems, req.RedeemPoints, req.StoreCredit)
+	var validationErr *service.CartValidationError
+	if errors.As(err, &validationErr) {
+		return ctx.JSON(http.StatusConflict, response.ErrorResponseWithData(http.StatusConflict, err.Error(), map[string]interface{}{
+			"validation": validationErr.Result,
+		}))
+	} else if errors.Is(err, service.ErrInsufficientPoints) || errors.Is(err, service.ErrInsufficientCredit) {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	} else if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
 	}
 
@@ -1,13 +1,19 @@
 package handler
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"log"
 	"mola-web/internal/http/dto"
 	"mola-web/internal/service"
 	"mola-web/pkg/response"
 	"net/http"
+	"net/url"
+	"path"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
@@ -22,24 +28,146 @@ func NewProductHandler(productService service.ProductService) ProductHandler {
 }
 
 func (h *ProductHandler) GetAll(ctx echo.Context) error {
-	products, err := h.productService.GetAll(ctx.Request().Context())
+	query, err := productListQueryFromRequest(ctx)
 	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	result, err := h.productService.GetAll(ctx.Request().Context(), query)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"products":   result.Products,
+		"pagination": result.Pagination,
+		"facets":     result.Facets,
+	}))
+}
+
+// Search mencari produk berdasarkan parameter q dengan filter yang sama
+// seperti listing produk.
+func (h *ProductHandler) Search(ctx echo.Context) error {
+	query, err := productListQueryFromRequest(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	query.Search = ctx.QueryParam("q")
+	result, err := h.productService.Search(ctx.Request().Context(), query)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"products":   result.Products,
+		"pagination": result.Pagination,
+		"facets":     result.Facets,
+		"fuzzy":      result.Fuzzy,
+	}))
+}
+
+// Suggest saran autocomplete untuk prefix q.
+func (h *ProductHandler) Suggest(ctx echo.Context) error {
+	result, err := h.productService.Suggest(ctx.Request().Context(), ctx.QueryParam("q"))
+	if errors.Is(err, service.ErrEmptySearchQuery) {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	} else if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
 	}
-	if len(products) == 0 {
-		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, "Product not found"))
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"suggestions": result,
+	}))
+}
+
+// GetSearchReport query terpopuler dan query tanpa hasil dalam beberapa hari
+// terakhir (default 30).
+func (h *ProductHandler) GetSearchReport(ctx echo.Context) error {
+	var days int
+	if value := ctx.QueryParam("days"); value != "" {
+		var err error
+		if days, err = strconv.Atoi(value); err != nil {
+			return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "invalid days"))
+		}
+	}
+	report, err := h.productService.GetSearchReport(ctx.Request().Context(), days)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
 	}
 	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
-		"products": products,
+		"report": report,
 	}))
 }
 
+// productListQueryFromRequest membaca parameter listing. ID warna dan ukuran
+// bisa dikirim dipisah koma (color_ids=1,2) atau berulang.
+func productListQueryFromRequest(ctx echo.Context) (dto.ProductListQuery, error) {
+	query := dto.ProductListQuery{
+		Sort:    ctx.QueryParam("sort"),
+		InStock: ctx.QueryParam("in_stock") == "true",
+	}
+	var err error
+	if value := ctx.QueryParam("page"); value != "" {
+		if query.Page, err = strconv.Atoi(value); err != nil {
+			return query, errors.New("invalid page")
+		}
+	}
+	if value := ctx.QueryParam("limit"); value != "" {
+		if query.Limit, err = strconv.Atoi(value); err != nil {
+			return query, errors.New("invalid limit")
+		}
+	}
+	if value := ctx.QueryParam("category_id"); value != "" {
+		categoryID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return query, errors.New("invalid category_id")
+		}
+		id := uint(categoryID)
+		query.CategoryID = &id
+	}
+	if query.ColorIDs, err = parseUintList(ctx.QueryParams()["color_ids"]); err != nil {
+		return query, errors.New("invalid color_ids")
+	}
+	if query.SizeIDs, err = parseUintList(ctx.QueryParams()["size_ids"]); err != nil {
+		return query, errors.New("invalid size_ids")
+	}
+	if value := ctx.QueryParam("min_price"); value != "" {
+		price, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return query, errors.New("invalid min_price")
+		}
+		query.MinPrice = &price
+	}
+	if value := ctx.QueryParam("max_price"); value != "" {
+		price, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return query, errors.New("invalid max_price")
+		}
+		query.MaxPrice = &price
+	}
+	return query, nil
+}
+
+func parseUintList(values []string) ([]uint, error) {
+	var results []uint
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			id, err := strconv.ParseUint(part, 10, 32)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, uint(id))
+		}
+	}
+	return results, nil
+}
+
 func (h *ProductHandler) GetByCategoryID(ctx echo.Context) error {
 	categoryID, err := strconv.ParseUint(ctx.Param("categoryID"), 10, 32)
 	if err != nil {
 		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid todo ID"))
 	}
-	products, err := h.productService.GetProductByCategoryID(ctx.Request().Context(), uint(categoryID))
+	products, err := h.productService.GetProductByCategoryID(ctx.Request().Context(), uint(categoryID), ctx.QueryParam("include_descendants") == "true")
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
 	}
@@ -71,7 +199,9 @@ func (h *ProductHandler) GetByID(ctx echo.Context) error {
 		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, "Product not found"))
 	}
 	product, err := h.productService.GetByID(ctx.Request().Context(), productID)
-	if err != nil {
+	if errors.Is(err, service.ErrProductNotFound) {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, "Product not found"))
+	} else if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
 	}
 	if product == nil {
@@ -82,6 +212,120 @@ func (h *ProductHandler) GetByID(ctx echo.Context) error {
 	}))
 }
 
+// GetBySlug slug lama diarahkan (301) ke slug aktif supaya URL lama tetap
+// berlaku untuk mesin pencari.
+func (h *ProductHandler) GetBySlug(ctx echo.Context) error {
+	slug := ctx.Param("slug")
+	product, err := h.productService.GetBySlug(ctx.Request().Context(), slug)
+	if errors.Is(err, service.ErrSlugNotFound) || errors.Is(err, service.ErrProductNotFound) {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, "Product not found"))
+	} else if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	if product.Slug != nil && *product.Slug != slug {
+		return redirectToSlug(ctx, path.Dir(ctx.Request().URL.Path), *product.Slug, "")
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"product": product,
+	}))
+}
+
+func (h *ProductHandler) GetByCategorySlug(ctx echo.Context) error {
+	slug := ctx.Param("slug")
+	current, products, err := h.productService.GetProductByCategorySlug(ctx.Request().Context(), slug, ctx.QueryParam("include_descendants") == "true")
+	if errors.Is(err, service.ErrSlugNotFound) {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, "Category not found"))
+	} else if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	if current != slug {
+		return redirectToSlug(ctx, path.Dir(path.Dir(ctx.Request().URL.Path)), current, "/products")
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"products": products,
+	}))
+}
+
+func isSlugError(err error) bool {
+	return errors.Is(err, service.ErrInvalidSlug) || errors.Is(err, service.ErrSlugTaken)
+}
+
+func isVariantError(err error) bool {
+	return errors.Is(err, service.ErrOptionValueNotFound) || errors.Is(err, service.ErrDuplicateOptionType) ||
+		errors.Is(err, service.ErrVariantNoOptions) || errors.Is(err, service.ErrSKUTaken) ||
+		errors.Is(err, service.ErrInvalidVariantImage) || errors.Is(err, service.ErrInvalidVariantOverride)
+}
+
+func isPublicationError(err error) bool {
+	return errors.Is(err, service.ErrInvalidProductStatus) || errors.Is(err, service.ErrInvalidPublishWindow)
+}
+
+// timeFormValue waktu RFC3339 dari form, nil jika kosong.
+func timeFormValue(ctx echo.Context, key string) (*time.Time, error) {
+	value := ctx.FormValue(key)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an RFC3339 timestamp", key)
+	}
+	return &parsed, nil
+}
+
+func isInventoryCodeError(err error) bool {
+	return errors.Is(err, service.ErrSKUTaken) || errors.Is(err, service.ErrBarcodeTaken) ||
+		errors.Is(err, service.ErrInvalidSKU) || errors.Is(err, service.ErrInvalidBarcode)
+}
+
+// variantOverridesFromForm membaca harga, berat, SKU, barcode dan gambar
+// varian ke-i dari form. found bernilai true jika ada yang diisi.
+func variantOverridesFromForm(ctx echo.Context, i int) (overrides dto.ProductVariantOverrides, found bool, err error) {
+	for field, target := range map[string]**float64{
+		"price":            &overrides.Price,
+		"compare_at_price": &overrides.CompareAtPrice,
+		"weight":           &overrides.Weight,
+	} {
+		key := fmt.Sprintf("variants[%d].%s", i, field)
+		if value := ctx.FormValue(key); value != "" {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return overrides, false, fmt.Errorf("invalid %s", key)
+			}
+			*target = &parsed
+			found = true
+		}
+	}
+	if sku := ctx.FormValue(fmt.Sprintf("variants[%d].sku", i)); sku != "" {
+		overrides.SKU = &sku
+		found = true
+	}
+	if barcode := ctx.FormValue(fmt.Sprintf("variants[%d].barcode", i)); barcode != "" {
+		overrides.Barcode = &barcode
+		found = true
+	}
+	key := fmt.Sprintf("variants[%d].image_id", i)
+	if value := ctx.FormValue(key); value != "" {
+		imageID, err := uuid.Parse(value)
+		if err != nil {
+			return overrides, false, fmt.Errorf("invalid %s", key)
+		}
+		overrides.ImageID = &imageID
+		found = true
+	}
+	return overrides, found, nil
+}
+
+// redirectToSlug mengganti slug di path request dengan slug aktif dan
+// mempertahankan query string.
+func redirectToSlug(ctx echo.Context, prefix string, slug string, suffix string) error {
+	location := prefix + "/" + url.PathEscape(slug) + suffix
+	if query := ctx.Request().URL.RawQuery; query != "" {
+		location += "?" + query
+	}
+	return ctx.Redirect(http.StatusMovedPermanently, location)
+}
+
 func (h *ProductHandler) UpdateStock(ctx echo.Context) error {
 	productID, err := uuid.Parse(ctx.Param("productID"))
 	if err != nil {
@@ -100,10 +344,140 @@ func (h *ProductHandler) UpdateStock(ctx echo.Context) error {
 	}))
 }
 
+func (h *ProductHandler) UpdatePriceTiers(ctx echo.Context) error {
+	productID, err := uuid.Parse(ctx.Param("productID"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid product ID"))
+	}
+
+	var req dto.UpdatePriceTiersRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	req.ProductID = productID
+
+	if err := h.productService.UpdatePriceTiers(ctx.Request().Context(), &req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"productID": productID,
+	}))
+}
+
+// UploadImages menerima beberapa file sekaligus di field "images". Alt text
+// opsional dikirim berulang di field "alt_texts" sesuai urutan file.
+func (h *ProductHandler) UploadImages(ctx echo.Context) error {
+	productID, err := uuid.Parse(ctx.Param("productID"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid product ID"))
+	}
+	form, err := ctx.MultipartForm()
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	req := dto.UploadProductImagesRequest{
+		ProductID: productID,
+		Images:    form.File["images"],
+		AltTexts:  form.Value["alt_texts"],
+		Primary:   ctx.FormValue("primary") == "true",
+	}
+	images, err := h.productService.UploadImages(ctx.Request().Context(), &req)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"images": images,
+	}))
+}
+
+func (h *ProductHandler) ReorderImages(ctx echo.Context) error {
+	productID, err := uuid.Parse(ctx.Param("productID"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid product ID"))
+	}
+	var req dto.ReorderProductImagesRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	req.ProductID = productID
+
+	images, err := h.productService.ReorderImages(ctx.Request().Context(), &req)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"images": images,
+	}))
+}
+
+func (h *ProductHandler) UpdateImage(ctx echo.Context) error {
+	productID, err := uuid.Parse(ctx.Param("productID"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid product ID"))
+	}
+	imageID, err := uuid.Parse(ctx.Param("imageID"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid image ID"))
+	}
+	var req dto.UpdateProductImageRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	req.ProductID = productID
+	req.ImageID = imageID
+
+	images, err := h.productService.UpdateImage(ctx.Request().Context(), &req)
+	if errors.Is(err, service.ErrProductImageNotFound) {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	} else if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"images": images,
+	}))
+}
+
+func (h *ProductHandler) DeleteImage(ctx echo.Context) error {
+	productID, err := uuid.Parse(ctx.Param("productID"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid product ID"))
+	}
+	imageID, err := uuid.Parse(ctx.Param("imageID"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid image ID"))
+	}
+
+	err = h.productService.DeleteImage(ctx.Request().Context(), productID, imageID)
+	if errors.Is(err, service.ErrProductImageNotFound) {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	} else if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"imageID": imageID,
+	}))
+}
+
 func (h *ProductHandler) Create(ctx echo.Context) error {
 	var req dto.CreateProductRequest
 
 	req.Name = ctx.FormValue("name")
+	req.Slug = ctx.FormValue("slug")
+	req.SKU = ctx.FormValue("sku")
+	req.Barcode = ctx.FormValue("barcode")
+	req.Status = ctx.FormValue("status")
+	publishAt, err := timeFormValue(ctx, "publish_at")
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	req.PublishAt = publishAt
+	unpublishAt, err := timeFormValue(ctx, "unpublish_at")
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	req.UnpublishAt = unpublishAt
 	description := ctx.FormValue("description")
 	imageURL := ctx.FormValue("image_url")
 	hasVariantStr := ctx.FormValue("has_variant")
@@ -171,8 +545,9 @@ func (h *ProductHandler) Create(ctx echo.Context) error {
 			colorKey := fmt.Sprintf("variants[%d].color_id", i)
 			sizeKey := fmt.Sprintf("variants[%d].size_id", i)
 			stockKey := fmt.Sprintf("variants[%d].stock", i)
+			optionsKey := fmt.Sprintf("variants[%d].option_value_ids", i)
 			log.Println("colorKey", colorKey, "sizeKey", sizeKey, "stockKey", stockKey)
-			if ctx.FormValue(colorKey) == "" && ctx.FormValue(sizeKey) == "" {
+			if ctx.FormValue(colorKey) == "" && ctx.FormValue(sizeKey) == "" && ctx.FormValue(optionsKey) == "" {
 				log.Println("break")
 				break
 			}
@@ -194,6 +569,23 @@ func (h *ProductHandler) Create(ctx echo.Context) error {
 					valid = true
 				}
 			}
+			// Nilai opsi lain dipisah koma, misalnya "3,7"
+			if optionsStr := ctx.FormValue(optionsKey); optionsStr != "" {
+				ids, err := parseUintList([]string{optionsStr})
+				if err != nil {
+					return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "invalid "+optionsKey))
+				}
+				variant.OptionValueIDs = ids
+				valid = true
+			}
+			overrides, hasOverrides, err := variantOverridesFromForm(ctx, i)
+			if err != nil {
+				return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+			}
+			variant.ProductVariantOverrides = overrides
+			if hasOverrides {
+				valid = true
+			}
 			if stockStr := ctx.FormValue(stockKey); stockStr != "" {
 				if val, err := strconv.Atoi(stockStr); err == nil {
 					variant.Stock = val
@@ -209,12 +601,19 @@ func (h *ProductHandler) Create(ctx echo.Context) error {
 	}
 	log.Println("reqVariant", req.Variants)
 
+	// Produk bundle beserta komponennya
+	req.ProductType = ctx.FormValue("product_type")
+	req.BundleItems = bundleItemsFromForm(ctx)
+
 	// Validasi wajib
 	if req.Name == "" || req.Price <= 0 {
 		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "name and valid price are required"))
 	}
 
 	err = h.productService.Create(ctx.Request().Context(), &req)
+	if isSlugError(err) || isVariantError(err) || isInventoryCodeError(err) || isPublicationError(err) {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
 	}
@@ -238,6 +637,10 @@ func (h *ProductHandler) Update(ctx echo.Context) error {
 
 	// Ambil nilai form
 	req.Name = ctx.FormValue("name")
+	req.Slug = ctx.FormValue("slug")
+	req.KeepSlug = ctx.FormValue("keep_slug") == "true"
+	req.SKU = ctx.FormValue("sku")
+	req.Barcode = ctx.FormValue("barcode")
 	description := ctx.FormValue("description")
 	imageURL := ctx.FormValue("image_url")
 	hasVariantStr := ctx.FormValue("has_variant")
@@ -308,8 +711,9 @@ func (h *ProductHandler) Update(ctx echo.Context) error {
 			colorKey := fmt.Sprintf("variants[%d].color_id", i)
 			sizeKey := fmt.Sprintf("variants[%d].size_id", i)
 			stockKey := fmt.Sprintf("variants[%d].stock", i)
+			optionsKey := fmt.Sprintf("variants[%d].option_value_ids", i)
 
-			if ctx.FormValue(colorKey) == "" && ctx.FormValue(sizeKey) == "" && ctx.FormValue(idKey) == "" {
+			if ctx.FormValue(colorKey) == "" && ctx.FormValue(sizeKey) == "" && ctx.FormValue(optionsKey) == "" && ctx.FormValue(idKey) == "" {
 				log.Println("break")
 				break
 			}
@@ -341,6 +745,23 @@ func (h *ProductHandler) Update(ctx echo.Context) error {
 				}
 			}
 
+			// Nilai opsi lain dipisah koma, misalnya "3,7"
+			if optionsStr := ctx.FormValue(optionsKey); optionsStr != "" {
+				ids, err := parseUintList([]string{optionsStr})
+				if err != nil {
+					return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "invalid "+optionsKey))
+				}
+				variant.OptionValueIDs = ids
+				valid = true
+			}
+			overrides, hasOverrides, err := variantOverridesFromForm(ctx, i)
+			if err != nil {
+				return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+			}
+			variant.ProductVariantOverrides = overrides
+			if hasOverrides {
+				valid = true
+			}
 			if stockStr := ctx.FormValue(stockKey); stockStr != "" {
 				if val, err := strconv.Atoi(stockStr); err == nil {
 					variant.Stock = val
@@ -354,6 +775,10 @@ func (h *ProductHandler) Update(ctx echo.Context) error {
 		}
 	}
 
+	// Produk bundle beserta komponennya
+	req.ProductType = ctx.FormValue("product_type")
+	req.BundleItems = bundleItemsFromForm(ctx)
+
 	// Validasi dasar
 	if req.Name == "" || req.Price <= 0 {
 		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "name and valid price are required"))
@@ -361,6 +786,9 @@ func (h *ProductHandler) Update(ctx echo.Context) error {
 
 	// Jalankan service update
 	err = h.productService.Update(ctx.Request().Context(), &req)
+	if isSlugError(err) || isVariantError(err) || isInventoryCodeError(err) {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
 	}
@@ -370,6 +798,34 @@ func (h *ProductHandler) Update(ctx echo.Context) error {
 	}))
 }
 
+// bundleItemsFromForm membaca bundle_items[i].product_id,
+// bundle_items[i].product_variant_id dan bundle_items[i].quantity.
+func bundleItemsFromForm(ctx echo.Context) []dto.BundleItemRequest {
+	var items []dto.BundleItemRequest
+	for i := 0; ; i++ {
+		productStr := ctx.FormValue(fmt.Sprintf("bundle_items[%d].product_id", i))
+		if productStr == "" {
+			break
+		}
+		var item dto.BundleItemRequest
+		if productID, err := uuid.Parse(productStr); err == nil {
+			item.ProductID = productID
+		}
+		if variantStr := ctx.FormValue(fmt.Sprintf("bundle_items[%d].product_variant_id", i)); variantStr != "" {
+			if variantID, err := uuid.Parse(variantStr); err == nil {
+				item.ProductVariantID = &variantID
+			}
+		}
+		item.Quantity = 1
+		if quantityStr := ctx.FormValue(fmt.Sprintf("bundle_items[%d].quantity", i)); quantityStr != "" {
+			if quantity, err := strconv.Atoi(quantityStr); err == nil {
+				item.Quantity = quantity
+			}
+		}
+		items = append(items, item)
+	}
+	return items
+}
 
 func (h *ProductHandler) Delete(ctx echo.Context) error {
 	productID, err := uuid.Parse(ctx.Param("productID"))
@@ -417,4 +873,109 @@ func (h *ProductHandler) GetReviews(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
 		"reviews": reviews,
 	}))
-}
\ No newline at end of file
+}
+const maxProductImportSize = 10 << 20
+
+func (h *ProductHandler) ExportCSV(ctx echo.Context) error {
+	var buf bytes.Buffer
+	if err := h.productService.ExportCSV(ctx.Request().Context(), &buf); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	ctx.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="products.csv"`)
+	return ctx.Blob(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+// ImportCSV selalu dry run kecuali dry_run=false, sehingga admin melihat
+// laporan validasi dulu sebelum perubahan disimpan.
+func (h *ProductHandler) ImportCSV(ctx echo.Context) error {
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "file is required"))
+	}
+	if file.Size > maxProductImportSize {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "file must be at most 10 MB"))
+	}
+	src, err := file.Open()
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	defer src.Close()
+
+	dryRun := ctx.QueryParam("dry_run") != "false" && ctx.FormValue("dry_run") != "false"
+	report, err := h.productService.ImportCSV(ctx.Request().Context(), src, dryRun)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	if len(report.Errors) > 0 {
+		return ctx.JSON(http.StatusUnprocessableEntity, response.ErrorResponseWithData(http.StatusUnprocessableEntity, "import has errors, nothing was saved", map[string]interface{}{
+			"report": report,
+		}))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"report": report,
+	}))
+}
+
+func (h *ProductHandler) AdminGetAll(ctx echo.Context) error {
+	query, err := productListQueryFromRequest(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	query.Status = ctx.QueryParam("status")
+	result, err := h.productService.AdminGetAll(ctx.Request().Context(), query)
+	if errors.Is(err, service.ErrInvalidProductStatus) {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	} else if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"products":   result.Products,
+		"pagination": result.Pagination,
+		"facets":     result.Facets,
+	}))
+}
+
+func (h *ProductHandler) AdminGetByID(ctx echo.Context) error {
+	productID, err := uuid.Parse(ctx.Param("productID"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid product ID"))
+	}
+	product, err := h.productService.AdminGetByID(ctx.Request().Context(), productID)
+	if errors.Is(err, service.ErrProductNotFound) {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, "Product not found"))
+	} else if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"product": product,
+	}))
+}
+
+func (h *ProductHandler) UpdatePublication(ctx echo.Context) error {
+	productID, err := uuid.Parse(ctx.Param("productID"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid product ID"))
+	}
+	request := new(dto.UpdateProductPublicationRequest)
+	if err := ctx.Bind(request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	request.ProductID = productID
+	if request.Status == "" {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "status is required"))
+	}
+	err = h.productService.UpdatePublication(ctx.Request().Context(), request)
+	if isPublicationError(err) {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	} else if errors.Is(err, service.ErrProductNotFound) {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, "Product not found"))
+	} else if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"productID":    productID,
+		"status":       request.Status,
+		"publish_at":   request.PublishAt,
+		"unpublish_at": request.UnpublishAt,
+	}))
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"mola-web/internal/http/dto"
+	"mola-web/internal/service"
+	"mola-web/pkg/response"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ReferralHandler struct {
+	referralService service.ReferralService
+}
+
+func NewReferralHandler(referralService service.ReferralService) ReferralHandler {
+	return ReferralHandler{referralService}
+}
+
+func (h ReferralHandler) GetReport(ctx echo.Context) error {
+	report, err := h.referralService.GetReport(ctx.Request().Context())
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"report": report,
+	}))
+}
+
+func (h ReferralHandler) GetSettings(ctx echo.Context) error {
+	settings, err := h.referralService.GetSettings(ctx.Request().Context())
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"settings": settings,
+	}))
+}
+
+func (h ReferralHandler) UpdateSettings(ctx echo.Context) error {
+	request := new(dto.ReferralSettingsRequest)
+	if err := ctx.Bind(request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	if err := h.referralService.UpdateSettings(ctx.Request().Context(), request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	settings, err := h.referralService.GetSettings(ctx.Request().Context())
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"settings": settings,
+	}))
+}
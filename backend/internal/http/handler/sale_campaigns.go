@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"errors"
+	"mola-web/internal/http/dto"
+	"mola-web/internal/service"
+	"mola-web/pkg/response"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type SaleCampaignHandler struct {
+	saleCampaignService service.SaleCampaignService
+}
+
+func NewSaleCampaignHandler(saleCampaignService service.SaleCampaignService) SaleCampaignHandler {
+	return SaleCampaignHandler{saleCampaignService}
+}
+
+func (h SaleCampaignHandler) GetAll(ctx echo.Context) error {
+	campaigns, err := h.saleCampaignService.GetAll(ctx.Request().Context())
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"sale_campaigns": campaigns,
+	}))
+}
+
+func (h SaleCampaignHandler) Create(ctx echo.Context) error {
+	request := new(dto.CreateSaleCampaignRequest)
+	if err := ctx.Bind(request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	if err := h.saleCampaignService.Create(ctx.Request().Context(), request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"sale_campaign": request.Name,
+	}))
+}
+
+func (h SaleCampaignHandler) Update(ctx echo.Context) error {
+	campaignID, err := uuid.Parse(ctx.Param("campaignID"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid sale campaign ID"))
+	}
+	request := new(dto.UpdateSaleCampaignRequest)
+	if err := ctx.Bind(request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	request.ID = campaignID
+
+	err = h.saleCampaignService.Update(ctx.Request().Context(), request)
+	if errors.Is(err, service.ErrSaleCampaignNotFound) {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	} else if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"sale_campaign": request.Name,
+	}))
+}
+
+func (h SaleCampaignHandler) Delete(ctx echo.Context) error {
+	campaignID, err := uuid.Parse(ctx.Param("campaignID"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid sale campaign ID"))
+	}
+
+	err = h.saleCampaignService.Delete(ctx.Request().Context(), campaignID)
+	if errors.Is(err, service.ErrSaleCampaignNotFound) {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	} else if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"sale_campaign": campaignID,
+	}))
+}
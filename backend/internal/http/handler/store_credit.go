@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"errors"
+	"mola-web/internal/http/dto"
+	"mola-web/internal/service"
+	"mola-web/pkg/response"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type StoreCreditHandler struct {
+	storeCreditService service.StoreCreditService
+}
+
+func NewStoreCreditHandler(storeCreditService service.StoreCreditService) StoreCreditHandler {
+	return StoreCreditHandler{storeCreditService}
+}
+
+func (h StoreCreditHandler) GetStoreCredit(ctx echo.Context) error {
+	userID, ok := ctx.Get("user_id").(uuid.UUID)
+	if !ok {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Unauthorized"))
+	}
+
+	summary, err := h.storeCreditService.GetSummary(ctx.Request().Context(), userID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"store_credit": summary,
+	}))
+}
+
+func (h StoreCreditHandler) RedeemGiftCard(ctx echo.Context) error {
+	userID, ok := ctx.Get("user_id").(uuid.UUID)
+	if !ok {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Unauthorized"))
+	}
+
+	request := new(dto.RedeemGiftCardRequest)
+	if err := ctx.Bind(request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	summary, err := h.storeCreditService.RedeemGiftCard(ctx.Request().Context(), userID, request)
+	if errors.Is(err, service.ErrGiftCardNotFound) {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	} else if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"store_credit": summary,
+	}))
+}
+
+func (h StoreCreditHandler) PurchaseGiftCard(ctx echo.Context) error {
+	userID, ok := ctx.Get("user_id").(uuid.UUID)
+	if !ok {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Unauthorized"))
+	}
+	email := ctx.Get("email").(string)
+	name := ctx.Get("name").(string)
+
+	request := new(dto.PurchaseGiftCardRequest)
+	if err := ctx.Bind(request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	result, err := h.storeCreditService.PurchaseGiftCard(ctx.Request().Context(), userID, email, name, request)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"gift_card": result,
+	}))
+}
+
+func (h StoreCreditHandler) GetPurchasedGiftCards(ctx echo.Context) error {
+	userID, ok := ctx.Get("user_id").(uuid.UUID)
+	if !ok {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Unauthorized"))
+	}
+
+	cards, err := h.storeCreditService.GetPurchasedGiftCards(ctx.Request().Context(), userID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"gift_cards": cards,
+	}))
+}
+
+func (h StoreCreditHandler) GetAllGiftCards(ctx echo.Context) error {
+	cards, err := h.storeCreditService.GetAllGiftCards(ctx.Request().Context())
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"gift_cards": cards,
+	}))
+}
+
+func (h StoreCreditHandler) IssueGiftCards(ctx echo.Context) error {
+	userID, ok := ctx.Get("user_id").(uuid.UUID)
+	if !ok {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Unauthorized"))
+	}
+
+	request := new(dto.IssueGiftCardRequest)
+	if err := ctx.Bind(request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	request.IssuedByID = userID
+
+	cards, err := h.storeCreditService.IssueGiftCards(ctx.Request().Context(), request)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"gift_cards": cards,
+	}))
+}
+
+func (h StoreCreditHandler) DisableGiftCard(ctx echo.Context) error {
+	giftCardID, err := uuid.Parse(ctx.Param("giftCardID"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid gift card ID"))
+	}
+
+	err = h.storeCreditService.DisableGiftCard(ctx.Request().Context(), giftCardID)
+	if errors.Is(err, service.ErrGiftCardNotFound) {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	} else if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"gift_card": giftCardID,
+	}))
+}
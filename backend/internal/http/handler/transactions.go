@@ -10,6 +10,7 @@ import (
 	"mola-web/pkg/response"
 	"net/http"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"gorm.io/datatypes"
 )
@@ -63,6 +64,23 @@ func (h *TransactionHandler) Refund(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, response.SuccessResponse(fmt.Sprintf("success refund %s", request.TransactionID), map[string]interface{}{}))
 }
 
+func (h *TransactionHandler) RefundToCredit(ctx echo.Context) error {
+	orderID, err := uuid.Parse(ctx.Param("orderID"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid order ID"))
+	}
+	request := new(dto.RefundToCreditRequest)
+	if err := ctx.Bind(request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	request.OrderID = orderID
+
+	if err := h.TransactionService.RefundToCredit(ctx.Request().Context(), request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse(fmt.Sprintf("success refund %s as store credit", orderID), map[string]interface{}{}))
+}
+
 func (h *TransactionHandler) GetAllTransactions(ctx echo.Context) error {
 	transactions, err := h.TransactionService.GetAll(ctx.Request().Context())
 	if err != nil {
@@ -27,7 +27,9 @@ func (h *UserHandler) Register(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
 	}
 
-	if err := h.userService.Register(ctx.Request().Context(), req); err != nil {
+	if err := h.userService.Register(ctx.Request().Context(), req); errors.Is(err, service.ErrInvalidReferralCode) {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	} else if err != nil {
 		return ctx.JSON(http.StatusConflict, response.ErrorResponse(http.StatusConflict, err.Error()))
 	}
 
@@ -41,6 +43,9 @@ func (h *UserHandler) Login(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest,
 			response.ErrorResponse(http.StatusBadRequest, err.Error()))
 	}
+	if loginRequest.GuestToken == "" {
+		loginRequest.GuestToken = guestTokenFromRequest(ctx)
+	}
 
 	token, err := h.userService.Login(ctx.Request().Context(), loginRequest)
 	if err != nil {
@@ -57,9 +62,14 @@ func (h *UserHandler) GoogleLogin(ctx echo.Context) error {
 	if err := ctx.Bind(&req); err != nil {
 		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
 	}
+	if req.GuestToken == "" {
+		req.GuestToken = guestTokenFromRequest(ctx)
+	}
 
 	token, err := h.userService.GoogleLogin(ctx.Request().Context(), &req)
-	if err != nil {
+	if errors.Is(err, service.ErrInvalidReferralCode) {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	} else if err != nil {
 		return ctx.JSON(http.StatusUnauthorized, response.ErrorResponse(http.StatusUnauthorized, err.Error()))
 	}
 
@@ -118,6 +128,28 @@ func (h *UserHandler) UpdateUserProfile(ctx echo.Context) error {
 	}))
 }
 
+func (h *UserHandler) UpdateCustomerGroup(ctx echo.Context) error {
+	userID, err := uuid.Parse(ctx.Param("userID"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid user ID"))
+	}
+
+	var req dto.UpdateCustomerGroupRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	req.UserID = userID
+
+	if err := h.userService.UpdateCustomerGroup(ctx.Request().Context(), &req); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"userID":         userID,
+		"customer_group": req.CustomerGroup,
+	}))
+}
+
 // func (h *UserHandler) GetUserAddress(ctx echo.Context) error {
 // 	userID, ok := ctx.Get("user_id").(uuid.UUID)
 // 	if !ok {
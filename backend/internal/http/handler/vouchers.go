@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"errors"
+	"mola-web/internal/http/dto"
+	"mola-web/internal/service"
+	"mola-web/pkg/response"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type VoucherHandler struct {
+	voucherService service.VoucherService
+}
+
+func NewVoucherHandler(voucherService service.VoucherService) VoucherHandler {
+	return VoucherHandler{voucherService}
+}
+
+func (h VoucherHandler) GetAll(ctx echo.Context) error {
+	vouchers, err := h.voucherService.GetAll(ctx.Request().Context())
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"vouchers": vouchers,
+	}))
+}
+
+func (h VoucherHandler) Create(ctx echo.Context) error {
+	request := new(dto.CreateVoucherRequest)
+	if err := ctx.Bind(request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	err := h.voucherService.Create(ctx.Request().Context(), request)
+	if errors.Is(err, service.ErrVoucherCodeTaken) {
+		return ctx.JSON(http.StatusConflict, response.ErrorResponse(http.StatusConflict, err.Error()))
+	} else if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"voucher": request.Code,
+	}))
+}
+
+func (h VoucherHandler) Update(ctx echo.Context) error {
+	voucherID, err := uuid.Parse(ctx.Param("voucherID"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid voucher ID"))
+	}
+	request := new(dto.UpdateVoucherRequest)
+	if err := ctx.Bind(request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	request.ID = voucherID
+
+	err = h.voucherService.Update(ctx.Request().Context(), request)
+	if errors.Is(err, service.ErrVoucherNotFound) {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	} else if errors.Is(err, service.ErrVoucherCodeTaken) {
+		return ctx.JSON(http.StatusConflict, response.ErrorResponse(http.StatusConflict, err.Error()))
+	} else if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"voucher": request.Code,
+	}))
+}
+
+func (h VoucherHandler) Delete(ctx echo.Context) error {
+	voucherID, err := uuid.Parse(ctx.Param("voucherID"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid voucher ID"))
+	}
+
+	err = h.voucherService.Delete(ctx.Request().Context(), voucherID)
+	if errors.Is(err, service.ErrVoucherNotFound) {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	} else if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"voucher": voucherID,
+	}))
+}
+
+func (h VoucherHandler) ApplyToCart(ctx echo.Context) error {
+	userID, ok := ctx.Get("user_id").(uuid.UUID)
+	if !ok {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Unauthorized"))
+	}
+	request := new(dto.ApplyVoucherRequest)
+	if err := ctx.Bind(request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	discount, err := h.voucherService.ApplyToCart(ctx.Request().Context(), userID, request.Code)
+	if errors.Is(err, service.ErrVoucherNotFound) {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	} else if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"voucher": discount,
+	}))
+}
+
+func (h VoucherHandler) RemoveFromCart(ctx echo.Context) error {
+	userID, ok := ctx.Get("user_id").(uuid.UUID)
+	if !ok {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Unauthorized"))
+	}
+
+	if err := h.voucherService.RemoveFromCart(ctx.Request().Context(), userID); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", nil))
+}
@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"errors"
+	"mola-web/internal/http/dto"
+	"mola-web/internal/service"
+	"mola-web/pkg/response"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type WishlistHandler struct {
+	wishlistService service.WishlistService
+}
+
+func NewWishlistHandler(wishlistService service.WishlistService) WishlistHandler {
+	return WishlistHandler{wishlistService: wishlistService}
+}
+
+func (h *WishlistHandler) GetWishlist(ctx echo.Context) error {
+	userID, ok := ctx.Get("user_id").(uuid.UUID)
+	if !ok {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Unauthorized"))
+	}
+
+	wishlist, err := h.wishlistService.GetWishlist(ctx.Request().Context(), userID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"wishlist": wishlist,
+	}))
+}
+
+func (h *WishlistHandler) AddItem(ctx echo.Context) error {
+	userID, ok := ctx.Get("user_id").(uuid.UUID)
+	if !ok {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Unauthorized"))
+	}
+
+	var req dto.AddWishlistItemRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	if err := h.wishlistService.AddItem(ctx.Request().Context(), userID, &req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"wishlist": req,
+	}))
+}
+
+func (h *WishlistHandler) RemoveItem(ctx echo.Context) error {
+	userID, ok := ctx.Get("user_id").(uuid.UUID)
+	if !ok {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Unauthorized"))
+	}
+	itemID, err := uuid.Parse(ctx.Param("itemID"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid wishlist item ID"))
+	}
+
+	err = h.wishlistService.RemoveItem(ctx.Request().Context(), userID, itemID)
+	if errors.Is(err, service.ErrWishlistItemNotFound) {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	} else if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"wishlist": itemID,
+	}))
+}
+
+func (h *WishlistHandler) MoveToCart(ctx echo.Context) error {
+	userID, ok := ctx.Get("user_id").(uuid.UUID)
+	if !ok {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Unauthorized"))
+	}
+	itemID, err := uuid.Parse(ctx.Param("itemID"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid wishlist item ID"))
+	}
+
+	req := new(dto.MoveWishlistItemToCartRequest)
+	if err := ctx.Bind(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	err = h.wishlistService.MoveToCart(ctx.Request().Context(), userID, itemID, req)
+	if errors.Is(err, service.ErrWishlistItemNotFound) {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	} else if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"wishlist": itemID,
+	}))
+}
+
+func (h *WishlistHandler) SaveForLater(ctx echo.Context) error {
+	userID, ok := ctx.Get("user_id").(uuid.UUID)
+	if !ok {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Unauthorized"))
+	}
+	cartItemID, err := uuid.Parse(ctx.Param("cartID"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Invalid cart item ID"))
+	}
+
+	err = h.wishlistService.SaveForLater(ctx.Request().Context(), userID, cartItemID)
+	if errors.Is(err, service.ErrCartItemNotFound) {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	} else if err != nil {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"cart": cartItemID,
+	}))
+}
+
+func (h *WishlistHandler) EnableShare(ctx echo.Context) error {
+	userID, ok := ctx.Get("user_id").(uuid.UUID)
+	if !ok {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Unauthorized"))
+	}
+
+	shareToken, err := h.wishlistService.EnableShare(ctx.Request().Context(), userID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"share_token": shareToken,
+	}))
+}
+
+func (h *WishlistHandler) DisableShare(ctx echo.Context) error {
+	userID, ok := ctx.Get("user_id").(uuid.UUID)
+	if !ok {
+		return ctx.JSON(http.StatusBadRequest, response.ErrorResponse(http.StatusBadRequest, "Unauthorized"))
+	}
+
+	if err := h.wishlistService.DisableShare(ctx.Request().Context(), userID); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", nil))
+}
+
+func (h *WishlistHandler) GetSharedWishlist(ctx echo.Context) error {
+	wishlist, err := h.wishlistService.GetSharedWishlist(ctx.Request().Context(), ctx.Param("shareToken"))
+	if errors.Is(err, service.ErrWishlistNotFound) {
+		return ctx.JSON(http.StatusNotFound, response.ErrorResponse(http.StatusNotFound, err.Error()))
+	} else if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, response.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+	return ctx.JSON(http.StatusOK, response.SuccessResponse("success", map[string]interface{}{
+		"wishlist": wishlist,
+	}))
+}
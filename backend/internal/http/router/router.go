@@ -10,9 +10,11 @@ func PublicRoutes(
 	userHandler handler.UserHandler,
 	productHandler handler.ProductHandler,
 	cartHandler handler.CartHandler,
+	guestCartHandler handler.GuestCartHandler,
 	orderHandler handler.OrderHandler,
 	transactionHandler handler.TransactionHandler,
 	salesReportHandler handler.SalesReportHandler,
+	wishlistHandler handler.WishlistHandler,
 ) []route.Route {
 	return []route.Route{
 		
@@ -51,11 +53,31 @@ func PublicRoutes(
 			Path:    "/products/category/:categoryID",
 			Handler: productHandler.GetByCategoryID,
 		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/products/search",
+			Handler: productHandler.Search,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/products/suggest",
+			Handler: productHandler.Suggest,
+		},
 		{
 			Method:  http.MethodGet,
 			Path:    "/products/name/:name",
 			Handler: productHandler.GetByName,
 		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/products/slug/:slug",
+			Handler: productHandler.GetBySlug,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/categories/slug/:slug/products",
+			Handler: productHandler.GetByCategorySlug,
+		},
 		{
 			Method:  http.MethodGet,
 			Path:    "/products/:productID",
@@ -71,6 +93,31 @@ func PublicRoutes(
 			Path:    "/products/review/:productID",
 			Handler: productHandler.GetReviews,
 		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/guest/carts",
+			Handler: guestCartHandler.GetCart,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/guest/carts",
+			Handler: guestCartHandler.AddToCart,
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/guest/carts/:itemID",
+			Handler: guestCartHandler.UpdateCartItem,
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/guest/carts/:itemID",
+			Handler: guestCartHandler.RemoveCartItem,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/wishlists/shared/:shareToken",
+			Handler: wishlistHandler.GetSharedWishlist,
+		},
 		
 	}
 }
@@ -81,10 +128,18 @@ func PrivateRoutes(
 	categoryHandler handler.CategoryHandler,
 	colorHandler handler.ColorHandler,
 	sizeHandler handler.SizeHandler,
+	optionHandler handler.OptionHandler,
+	inventoryHandler handler.InventoryHandler,
 	cartHandler handler.CartHandler,
 	orderHandler handler.OrderHandler,
 	transactionHandler handler.TransactionHandler,
 	salesReportHandler handler.SalesReportHandler,
+	cartAbandonmentHandler handler.CartAbandonmentHandler,
+	wishlistHandler handler.WishlistHandler,
+	voucherHandler handler.VoucherHandler,
+	saleCampaignHandler handler.SaleCampaignHandler,
+	referralHandler handler.ReferralHandler,
+	storeCreditHandler handler.StoreCreditHandler,
 ) []route.Route {
 	return []route.Route{
 		{
@@ -105,12 +160,48 @@ func PrivateRoutes(
 			Handler: userHandler.UpdateUserProfile,
 			Roles:   []string{"admin", "user"},
 		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/admin/users/:userID/customer-group",
+			Handler: userHandler.UpdateCustomerGroup,
+			Roles:   []string{"admin"},
+		},
 		{
 			Method:  http.MethodPost,
 			Path:    "/admin/products",
 			Handler: productHandler.Create,
 			Roles:   []string{"admin"},
 		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/admin/products",
+			Handler: productHandler.AdminGetAll,
+			Roles:   []string{"admin"},
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/admin/products/:productID",
+			Handler: productHandler.AdminGetByID,
+			Roles:   []string{"admin"},
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/admin/products/:productID/publication",
+			Handler: productHandler.UpdatePublication,
+			Roles:   []string{"admin"},
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/admin/products/export",
+			Handler: productHandler.ExportCSV,
+			Roles:   []string{"admin"},
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/admin/products/import",
+			Handler: productHandler.ImportCSV,
+			Roles:   []string{"admin"},
+		},
 		{
 			Method:  http.MethodPut,
 			Path:    "/admin/products/:productID",
@@ -123,6 +214,36 @@ func PrivateRoutes(
 			Handler: productHandler.UpdateStock,
 			Roles:   []string{"admin"},
 		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/admin/products/:productID/price-tiers",
+			Handler: productHandler.UpdatePriceTiers,
+			Roles:   []string{"admin"},
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/admin/products/:productID/images",
+			Handler: productHandler.UploadImages,
+			Roles:   []string{"admin"},
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/admin/products/:productID/images/order",
+			Handler: productHandler.ReorderImages,
+			Roles:   []string{"admin"},
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/admin/products/:productID/images/:imageID",
+			Handler: productHandler.UpdateImage,
+			Roles:   []string{"admin"},
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/admin/products/:productID/images/:imageID",
+			Handler: productHandler.DeleteImage,
+			Roles:   []string{"admin"},
+		},
 		{
 			Method:  http.MethodDelete,
 			Path:    "/admin/products/:productID",
@@ -141,6 +262,12 @@ func PrivateRoutes(
 			Handler: categoryHandler.GetAll,
 			Roles:   []string{"admin", "user"},//masih bingung
 		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/categories/tree",
+			Handler: categoryHandler.GetTree,
+			Roles:   []string{"admin", "user"},
+		},
 		{
 			Method:  http.MethodDelete,
 			Path:    "/admin/categories/:categoryID",
@@ -201,6 +328,60 @@ func PrivateRoutes(
 			Handler: sizeHandler.Update,
 			Roles:   []string{"admin"},
 		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/options",
+			Handler: optionHandler.GetAll,
+			Roles:   []string{"admin", "user"},
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/admin/options",
+			Handler: optionHandler.CreateType,
+			Roles:   []string{"admin"},
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/admin/options/:optionTypeID",
+			Handler: optionHandler.UpdateType,
+			Roles:   []string{"admin"},
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/admin/options/:optionTypeID",
+			Handler: optionHandler.DeleteType,
+			Roles:   []string{"admin"},
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/admin/options/:optionTypeID/values",
+			Handler: optionHandler.CreateValue,
+			Roles:   []string{"admin"},
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/admin/options/values/:valueID",
+			Handler: optionHandler.UpdateValue,
+			Roles:   []string{"admin"},
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/admin/options/values/:valueID",
+			Handler: optionHandler.DeleteValue,
+			Roles:   []string{"admin"},
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/admin/inventory/lookup",
+			Handler: inventoryHandler.Lookup,
+			Roles:   []string{"admin"},
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/admin/inventory/labels",
+			Handler: inventoryHandler.LabelSheet,
+			Roles:   []string{"admin"},
+		},
 		{
 			Method:  http.MethodPost,
 			Path:    "/carts",
@@ -213,6 +394,12 @@ func PrivateRoutes(
 			Handler: cartHandler.GetCart,
 			Roles:   []string{"admin", "user"},
 		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/carts/validate",
+			Handler: cartHandler.Validate,
+			Roles:   []string{"admin", "user"},
+		},
 		{
 			Method:  http.MethodPut,
 			Path:    "/carts/:cartID",
@@ -225,6 +412,60 @@ func PrivateRoutes(
 			Handler: cartHandler.RemoveCartItem,
 			Roles:   []string{"admin", "user"},
 		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/carts/voucher",
+			Handler: voucherHandler.ApplyToCart,
+			Roles:   []string{"admin", "user"},
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/carts/voucher",
+			Handler: voucherHandler.RemoveFromCart,
+			Roles:   []string{"admin", "user"},
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/carts/:cartID/save-for-later",
+			Handler: wishlistHandler.SaveForLater,
+			Roles:   []string{"admin", "user"},
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/wishlists",
+			Handler: wishlistHandler.GetWishlist,
+			Roles:   []string{"admin", "user"},
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/wishlists/items",
+			Handler: wishlistHandler.AddItem,
+			Roles:   []string{"admin", "user"},
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/wishlists/items/:itemID",
+			Handler: wishlistHandler.RemoveItem,
+			Roles:   []string{"admin", "user"},
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/wishlists/items/:itemID/move-to-cart",
+			Handler: wishlistHandler.MoveToCart,
+			Roles:   []string{"admin", "user"},
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/wishlists/share",
+			Handler: wishlistHandler.EnableShare,
+			Roles:   []string{"admin", "user"},
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/wishlists/share",
+			Handler: wishlistHandler.DisableShare,
+			Roles:   []string{"admin", "user"},
+		},
 		{
 			Method:  http.MethodPost,
 			Path:    "/orders/checkout",
@@ -249,11 +490,137 @@ func PrivateRoutes(
 			Handler: transactionHandler.Refund,
 			Roles:   []string{"admin", "user"},
 		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/admin/orders/:orderID/refund-credit",
+			Handler: transactionHandler.RefundToCredit,
+			Roles:   []string{"admin"},
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/users/store-credit",
+			Handler: storeCreditHandler.GetStoreCredit,
+			Roles:   []string{"admin", "user"},
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/users/store-credit/redeem",
+			Handler: storeCreditHandler.RedeemGiftCard,
+			Roles:   []string{"admin", "user"},
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/gift-cards",
+			Handler: storeCreditHandler.GetPurchasedGiftCards,
+			Roles:   []string{"admin", "user"},
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/gift-cards/purchase",
+			Handler: storeCreditHandler.PurchaseGiftCard,
+			Roles:   []string{"admin", "user"},
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/admin/gift-cards",
+			Handler: storeCreditHandler.GetAllGiftCards,
+			Roles:   []string{"admin"},
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/admin/gift-cards",
+			Handler: storeCreditHandler.IssueGiftCards,
+			Roles:   []string{"admin"},
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/admin/gift-cards/:giftCardID",
+			Handler: storeCreditHandler.DisableGiftCard,
+			Roles:   []string{"admin"},
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/admin/search/report",
+			Handler: productHandler.GetSearchReport,
+			Roles:   []string{"admin"},
+		},
 		{
 			Method:  http.MethodGet,
 			Path:    "/admin/sales-report",
 			Handler: salesReportHandler.GetSalesReport,
 			Roles:   []string{"admin"},
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/admin/vouchers",
+			Handler: voucherHandler.GetAll,
+			Roles:   []string{"admin"},
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/admin/vouchers",
+			Handler: voucherHandler.Create,
+			Roles:   []string{"admin"},
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/admin/vouchers/:voucherID",
+			Handler: voucherHandler.Update,
+			Roles:   []string{"admin"},
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/admin/vouchers/:voucherID",
+			Handler: voucherHandler.Delete,
+			Roles:   []string{"admin"},
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/admin/sale-campaigns",
+			Handler: saleCampaignHandler.GetAll,
+			Roles:   []string{"admin"},
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/admin/sale-campaigns",
+			Handler: saleCampaignHandler.Create,
+			Roles:   []string{"admin"},
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/admin/sale-campaigns/:campaignID",
+			Handler: saleCampaignHandler.Update,
+			Roles:   []string{"admin"},
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/admin/sale-campaigns/:campaignID",
+			Handler: saleCampaignHandler.Delete,
+			Roles:   []string{"admin"},
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/admin/referrals",
+			Handler: referralHandler.GetReport,
+			Roles:   []string{"admin"},
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/admin/referrals/settings",
+			Handler: referralHandler.GetSettings,
+			Roles:   []string{"admin"},
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/admin/referrals/settings",
+			Handler: referralHandler.UpdateSettings,
+			Roles:   []string{"admin"},
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/admin/abandoned-carts/report",
+			Handler: cartAbandonmentHandler.GetReport,
+			Roles:   []string{"admin"},
 		},{
 			Method:  http.MethodGet,
 			Path:    "/admin/orders",
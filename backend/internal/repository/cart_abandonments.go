@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"mola-web/internal/entity"
+	"mola-web/internal/http/dto"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CartAbandonmentRepository interface {
+	FindIdleCarts(db *gorm.DB, idleSince time.Time) ([]entity.Cart, error)
+	Create(db *gorm.DB, abandonment *entity.CartAbandonment) error
+	Update(db *gorm.DB, abandonment *entity.CartAbandonment) error
+	FindDueReminders(db *gorm.DB, remindBefore time.Time, maxReminders int) ([]entity.CartAbandonment, error)
+	CreateReminder(db *gorm.DB, reminder *entity.CartReminder) error
+	MarkReminderFailed(db *gorm.DB, reminderID uuid.UUID, reason string) error
+	FindLatestUnconverted(db *gorm.DB, cartID uuid.UUID, abandonedSince time.Time) (*entity.CartAbandonment, error)
+	GetReport(ctx context.Context, start time.Time, end time.Time) (*dto.AbandonedCartReport, error)
+}
+
+type cartAbandonmentRepository struct {
+	db *gorm.DB
+}
+
+func NewCartAbandonmentRepository(db *gorm.DB) CartAbandonmentRepository {
+	return &cartAbandonmentRepository{db}
+}
+
+// FindIdleCarts mengambil keranjang aktif yang masih berisi item dan tidak
+// disentuh sejak idleSince. Aktivitas terakhir dihitung dari keranjang dan
+// item-itemnya, termasuk item yang sudah dihapus.
+func (r *cartAbandonmentRepository) FindIdleCarts(db *gorm.DB, idleSince time.Time) ([]entity.Cart, error) {
+	var carts []entity.Cart
+	if err := db.
+		Preload("CartItems").
+		Preload("CartItems.Product").
+		Preload("CartItems.ProductVariant").
+		Where("status = ?", "active").
+		Where("EXISTS (SELECT 1 FROM cart_items ci WHERE ci.cart_id = carts.id AND ci.deleted_at IS NULL)").
+		Where(`GREATEST(carts.updated_at, (
+			SELECT MAX(GREATEST(ci.updated_at, ci.deleted_at)) FROM cart_items ci WHERE ci.cart_id = carts.id
+		)) < ?`, idleSince).
+		Find(&carts).Error; err != nil {
+		return nil, err
+	}
+	return carts, nil
+}
+
+func (r *cartAbandonmentRepository) Create(db *gorm.DB, abandonment *entity.CartAbandonment) error {
+	if err := db.Create(abandonment).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *cartAbandonmentRepository) Update(db *gorm.DB, abandonment *entity.CartAbandonment) error {
+	if err := db.Model(&entity.CartAbandonment{}).Where("id = ?", abandonment.ID).Updates(abandonment).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindDueReminders mengambil keranjang terbengkalai yang belum dibeli, belum
+// mencapai batas pengingat, dan pengingat terakhirnya sebelum remindBefore.
+func (r *cartAbandonmentRepository) FindDueReminders(db *gorm.DB, remindBefore time.Time, maxReminders int) ([]entity.CartAbandonment, error) {
+	var abandonments []entity.CartAbandonment
+	if err := db.
+		Preload("User").
+		Preload("Cart.CartItems").
+		Preload("Cart.CartItems.Product").
+		Preload("Cart.CartItems.ProductVariant").
+		Preload("Cart.CartItems.ProductVariant.Color").
+		Preload("Cart.CartItems.ProductVariant.Size").
+		Joins("JOIN carts ON carts.id = cart_abandonments.cart_id AND carts.deleted_at IS NULL").
+		Where("carts.status = ?", "abandoned").
+		Where("cart_abandonments.converted_at IS NULL").
+		Where("cart_abandonments.reminders_sent < ?", maxReminders).
+		Where("(cart_abandonments.last_reminded_at IS NULL OR cart_abandonments.last_reminded_at < ?)", remindBefore).
+		Find(&abandonments).Error; err != nil {
+		return nil, err
+	}
+	return abandonments, nil
+}
+
+func (r *cartAbandonmentRepository) CreateReminder(db *gorm.DB, reminder *entity.CartReminder) error {
+	if err := db.Create(reminder).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *cartAbandonmentRepository) MarkReminderFailed(db *gorm.DB, reminderID uuid.UUID, reason string) error {
+	if err := db.Model(&entity.CartReminder{}).
+		Where("id = ?", reminderID).
+		Updates(map[string]interface{}{"status": "failed", "error": reason}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *cartAbandonmentRepository) FindLatestUnconverted(db *gorm.DB, cartID uuid.UUID, abandonedSince time.Time) (*entity.CartAbandonment, error) {
+	var abandonment entity.CartAbandonment
+	if err := db.
+		Where("cart_id = ? AND converted_at IS NULL AND abandoned_at >= ?", cartID, abandonedSince).
+		Order("abandoned_at DESC").
+		First(&abandonment).Error; err != nil {
+		return nil, err
+	}
+	return &abandonment, nil
+}
+
+func (r *cartAbandonmentRepository) GetReport(ctx context.Context, start time.Time, end time.Time) (*dto.AbandonedCartReport, error) {
+	var report dto.AbandonedCartReport
+	err := r.db.WithContext(ctx).Table("cart_abandonments").
+		Select(`COUNT(*) AS abandoned_carts,
+			COALESCE(SUM(cart_total), 0) AS abandoned_value,
+			COUNT(*) FILTER (WHERE reminders_sent > 0) AS reminded_carts,
+			COALESCE(SUM(reminders_sent), 0) AS reminders_sent,
+			COUNT(*) FILTER (WHERE converted_at IS NOT NULL) AS converted_carts,
+			COUNT(*) FILTER (WHERE converted_at IS NOT NULL AND reminders_sent > 0) AS converted_after_reminder,
+			COALESCE((SELECT SUM(o.total_amount) FROM orders o WHERE o.id IN (
+				SELECT ca.order_id FROM cart_abandonments ca
+				WHERE ca.order_id IS NOT NULL AND ca.abandoned_at >= ? AND ca.abandoned_at < ?
+			)), 0) AS converted_revenue`, start, end).
+		Where("abandoned_at >= ? AND abandoned_at < ?", start, end).
+		Scan(&report).Error
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
@@ -19,6 +19,11 @@ type CartRepository interface {
 	UpdateCart(db *gorm.DB, req *entity.Cart) error
 	ClearCart(db *gorm.DB, cartID uuid.UUID) error
 	RemoveCartItem(db *gorm.DB, req *uuid.UUID) error
+	ReactivateCart(db *gorm.DB, userID uuid.UUID) error
+	GetCartItemByUserID(db *gorm.DB, userID uuid.UUID, cartItemID uuid.UUID) (*entity.CartItem, error)
+	SetVoucherCode(db *gorm.DB, cartID uuid.UUID, code *string) error
+	GetCartItemsByProductID(db *gorm.DB, cartID uuid.UUID, productID uuid.UUID) ([]entity.CartItem, error)
+	ReplaceCartItemComponents(db *gorm.DB, cartItemID uuid.UUID, components []entity.CartItemComponent) error
 }
 
 type cartRepository struct {
@@ -37,7 +42,7 @@ func (r *cartRepository) AddToCart(db *gorm.DB, userID uuid.UUID, req *entity.Ca
 }
 func (r *cartRepository) GetCartByUserID(db *gorm.DB, userID uuid.UUID) (*entity.Cart, error) {
 	var req *entity.Cart
-	if err := db.Where("user_id = ?", userID).First(&req).Error; err != nil {
+	if err := db.Preload("User").Where("user_id = ?", userID).First(&req).Error; err != nil {
 		return nil, err
 	}
 	return req, nil
@@ -51,6 +56,16 @@ func (r *cartRepository) GetCartItemsByUserID(db *gorm.DB, userID uuid.UUID) (*e
 		Preload("CartItems.ProductVariant").
 		Preload("CartItems.ProductVariant.Color").
 		Preload("CartItems.ProductVariant.Size").
+		Preload("CartItems.ProductVariant.Image").
+		Preload("CartItems.Components").
+		Preload("CartItems.Product.BundleItems").
+		Preload("CartItems.Product.BundleItems.Component").
+		Preload("CartItems.Product.BundleItems.Component.Variants").
+		Preload("CartItems.Product.BundleItems.Component.Variants.Color").
+		Preload("CartItems.Product.BundleItems.Component.Variants.Size").
+		Preload("CartItems.Product.BundleItems.Component.Variants.Options.OptionType").
+		Preload("CartItems.Product.PriceTiers").
+		Preload("User").
 		Where("user_id = ?", userID).
 		Take(&req).Error; err != nil {
 		return nil, err
@@ -118,3 +133,60 @@ func (r *cartRepository) RemoveCartItem(db *gorm.DB, req *uuid.UUID) error {
 	}
 	return nil
 }
+
+// ReactivateCart mengembalikan keranjang terbengkalai menjadi aktif agar
+// pengingat berhenti dikirim.
+func (r *cartRepository) ReactivateCart(db *gorm.DB, userID uuid.UUID) error {
+	if err := db.Model(&entity.Cart{}).
+		Where("user_id = ? AND status = ?", userID, "abandoned").
+		Update("status", "active").Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *cartRepository) GetCartItemByUserID(db *gorm.DB, userID uuid.UUID, cartItemID uuid.UUID) (*entity.CartItem, error) {
+	var cartItem entity.CartItem
+	if err := db.
+		Preload("Components").
+		Joins("JOIN carts ON carts.id = cart_items.cart_id AND carts.deleted_at IS NULL").
+		Where("cart_items.id = ? AND carts.user_id = ?", cartItemID, userID).
+		First(&cartItem).Error; err != nil {
+		return nil, err
+	}
+	return &cartItem, nil
+}
+
+func (r *cartRepository) SetVoucherCode(db *gorm.DB, cartID uuid.UUID, code *string) error {
+	if err := db.Model(&entity.Cart{}).Where("id = ?", cartID).Update("voucher_code", code).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *cartRepository) GetCartItemsByProductID(db *gorm.DB, cartID uuid.UUID, productID uuid.UUID) ([]entity.CartItem, error) {
+	var cartItems []entity.CartItem
+	if err := db.
+		Preload("Components").
+		Where("cart_id = ? AND product_id = ?", cartID, productID).
+		Find(&cartItems).Error; err != nil {
+		return nil, err
+	}
+	return cartItems, nil
+}
+
+func (r *cartRepository) ReplaceCartItemComponents(db *gorm.DB, cartItemID uuid.UUID, components []entity.CartItemComponent) error {
+	if err := db.Where("cart_item_id = ?", cartItemID).Delete(&entity.CartItemComponent{}).Error; err != nil {
+		return err
+	}
+	for i := range components {
+		components[i].ID = uuid.Nil
+		components[i].CartItemID = cartItemID
+	}
+	if len(components) > 0 {
+		if err := db.Create(&components).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -9,11 +9,25 @@ import (
 
 type CategoryRepository interface {
 	GetAll(ctx context.Context) ([]entity.Category, error)
+	GetByID(db *gorm.DB, id uint) (*entity.Category, error)
+	GetSubtreeIDs(db *gorm.DB, id uint) ([]uint, error)
+	CountChildren(db *gorm.DB, id uint) (int64, error)
+	CountProducts(db *gorm.DB, id uint) (int64, error)
 	Create(db *gorm.DB, category *entity.Category) error
 	Update(db *gorm.DB, category *entity.Category) error
 	Delete(db *gorm.DB, id uint) error
 }
 
+// categorySubtreeSQL id kategori beserta seluruh turunannya. UNION (bukan
+// UNION ALL) menghentikan rekursi seandainya data terlanjur berputar.
+const categorySubtreeSQL = `WITH RECURSIVE subtree AS (
+		SELECT id FROM public.categories WHERE id = ? AND deleted_at IS NULL
+		UNION
+		SELECT c.id FROM public.categories c
+		JOIN subtree s ON c.parent_id = s.id
+		WHERE c.deleted_at IS NULL
+	) SELECT id FROM subtree`
+
 type categoryRepository struct {
 	db *gorm.DB
 }
@@ -24,12 +38,44 @@ func NewCategoryRepository(db *gorm.DB) CategoryRepository {
 
 func (r *categoryRepository) GetAll(ctx context.Context) ([]entity.Category, error) {
 	var categories []entity.Category
-	if err := r.db.WithContext(ctx).Find(&categories).Error; err != nil {
+	if err := r.db.WithContext(ctx).Order("name ASC").Find(&categories).Error; err != nil {
 		return nil, err
 	}
 	return categories, nil
 }
 
+func (r *categoryRepository) GetByID(db *gorm.DB, id uint) (*entity.Category, error) {
+	var category entity.Category
+	if err := db.First(&category, id).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *categoryRepository) GetSubtreeIDs(db *gorm.DB, id uint) ([]uint, error) {
+	ids := []uint{}
+	if err := db.Raw(categorySubtreeSQL, id).Scan(&ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *categoryRepository) CountChildren(db *gorm.DB, id uint) (int64, error) {
+	var count int64
+	if err := db.Model(&entity.Category{}).Where("parent_id = ?", id).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *categoryRepository) CountProducts(db *gorm.DB, id uint) (int64, error) {
+	var count int64
+	if err := db.Model(&entity.Product{}).Where("category_id = ?", id).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
 func (r *categoryRepository) Create(db *gorm.DB, category *entity.Category) error {
 	if err := db.Create(category).Error; err != nil {
 		return err
@@ -38,7 +84,8 @@ func (r *categoryRepository) Create(db *gorm.DB, category *entity.Category) erro
 }
 
 func (r *categoryRepository) Update(db *gorm.DB, category *entity.Category) error {
-	if err := db.Model(&entity.Category{}).Where("id = ?", category.ID).Updates(category).Error; err != nil {
+	// parent_id dipilih eksplisit supaya kategori bisa dipindah ke level utama (NULL)
+	if err := db.Model(&entity.Category{}).Where("id = ?", category.ID).Select("name", "slug", "parent_id").Updates(category).Error; err != nil {
 		return err
 	}
 	return nil
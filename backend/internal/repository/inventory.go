@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"mola-web/internal/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// inventoryBarcodeSequence nomor urut barcode EAN-13 buatan sistem.
+const inventoryBarcodeSequence = "public.inventory_barcode_seq"
+
+type InventoryRepository interface {
+	Lookup(ctx context.Context, code string) (*entity.Product, *entity.ProductVariant, error)
+	CodeInUse(db *gorm.DB, code string, excludeProductID *uuid.UUID, excludeVariantID *uuid.UUID) (bool, error)
+	NextBarcodeNumber(db *gorm.DB) (int64, error)
+	GetProductsByIDs(ctx context.Context, ids []uuid.UUID) ([]entity.Product, error)
+	GetVariantsByIDs(ctx context.Context, ids []uuid.UUID) ([]entity.ProductVariant, error)
+}
+
+type inventoryRepository struct {
+	db *gorm.DB
+}
+
+func NewInventoryRepository(db *gorm.DB) InventoryRepository {
+	return &inventoryRepository{db}
+}
+
+func preloadInventoryVariant(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Product").
+		Preload("Color").
+		Preload("Size").
+		Preload("Options.OptionType").
+		Preload("Image")
+}
+
+// Lookup mencari SKU atau barcode di varian lalu di produk. Produk yang
+// ditemukan lewat varian ikut dikembalikan.
+func (r *inventoryRepository) Lookup(ctx context.Context, code string) (*entity.Product, *entity.ProductVariant, error) {
+	var variant entity.ProductVariant
+	err := r.db.WithContext(ctx).
+		Scopes(preloadInventoryVariant).
+		Where("(sku = ? OR barcode = ?)", code, code).
+		First(&variant).Error
+	if err == nil {
+		if variant.Product == nil {
+			return nil, nil, gorm.ErrRecordNotFound
+		}
+		return variant.Product, &variant, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, err
+	}
+
+	// Komponen bundle dimuat untuk menghitung stok bundle
+	var product entity.Product
+	if err := r.db.WithContext(ctx).
+		Preload("Category").
+		Preload("BundleItems").
+		Preload("BundleItems.Component").
+		Preload("BundleItems.Component.Variants").
+		Where("(sku = ? OR barcode = ?)", code, code).
+		First(&product).Error; err != nil {
+		return nil, nil, err
+	}
+	return &product, nil, nil
+}
+
+// CodeInUse SKU dan barcode produk maupun varian tidak boleh sama supaya
+// hasil scan selalu menunjuk satu barang.
+func (r *inventoryRepository) CodeInUse(db *gorm.DB, code string, excludeProductID *uuid.UUID, excludeVariantID *uuid.UUID) (bool, error) {
+	var count int64
+	query := db.Model(&entity.Product{}).Where("(sku = ? OR barcode = ?)", code, code)
+	if excludeProductID != nil {
+		query = query.Where("id <> ?", *excludeProductID)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	query = db.Model(&entity.ProductVariant{}).Where("(sku = ? OR barcode = ?)", code, code)
+	if excludeVariantID != nil {
+		query = query.Where("id <> ?", *excludeVariantID)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *inventoryRepository) NextBarcodeNumber(db *gorm.DB) (int64, error) {
+	var number int64
+	if err := db.Raw("SELECT nextval('" + inventoryBarcodeSequence + "')").Scan(&number).Error; err != nil {
+		return 0, err
+	}
+	return number, nil
+}
+
+func (r *inventoryRepository) GetProductsByIDs(ctx context.Context, ids []uuid.UUID) ([]entity.Product, error) {
+	var products []entity.Product
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+func (r *inventoryRepository) GetVariantsByIDs(ctx context.Context, ids []uuid.UUID) ([]entity.ProductVariant, error) {
+	var variants []entity.ProductVariant
+	if err := r.db.WithContext(ctx).
+		Scopes(preloadInventoryVariant).
+		Where("id IN ?", ids).
+		Find(&variants).Error; err != nil {
+		return nil, err
+	}
+	return variants, nil
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"mola-web/internal/entity"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LoyaltyRepository interface {
+	Create(db *gorm.DB, entry *entity.LoyaltyTransaction) error
+	GetBalance(db *gorm.DB, userID uuid.UUID) (int, error)
+	GetHistory(db *gorm.DB, userID uuid.UUID, limit int) ([]entity.LoyaltyTransaction, error)
+	GetByOrderID(db *gorm.DB, orderID uuid.UUID) ([]entity.LoyaltyTransaction, error)
+	GetOpenCreditsForUpdate(db *gorm.DB, userID uuid.UUID, now time.Time) ([]entity.LoyaltyTransaction, error)
+	GetExpiredCredits(db *gorm.DB, now time.Time) ([]entity.LoyaltyTransaction, error)
+	UpdateRemaining(db *gorm.DB, id uuid.UUID, remaining int) error
+}
+
+type loyaltyRepository struct {
+	db *gorm.DB
+}
+
+func NewLoyaltyRepository(db *gorm.DB) LoyaltyRepository {
+	return &loyaltyRepository{db}
+}
+
+func (r *loyaltyRepository) Create(db *gorm.DB, entry *entity.LoyaltyTransaction) error {
+	if err := db.Create(entry).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *loyaltyRepository) GetBalance(db *gorm.DB, userID uuid.UUID) (int, error) {
+	var balance int
+	if err := db.Model(&entity.LoyaltyTransaction{}).
+		Select("COALESCE(SUM(points), 0)").
+		Where("user_id = ?", userID).
+		Scan(&balance).Error; err != nil {
+		return 0, err
+	}
+	return balance, nil
+}
+
+func (r *loyaltyRepository) GetHistory(db *gorm.DB, userID uuid.UUID, limit int) ([]entity.LoyaltyTransaction, error) {
+	var entries []entity.LoyaltyTransaction
+	if err := db.
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *loyaltyRepository) GetByOrderID(db *gorm.DB, orderID uuid.UUID) ([]entity.LoyaltyTransaction, error) {
+	var entries []entity.LoyaltyTransaction
+	if err := db.
+		Where("order_id = ?", orderID).
+		Order("created_at ASC").
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// GetOpenCreditsForUpdate mengambil poin masuk yang masih tersisa, yang
+// paling cepat kedaluwarsa lebih dulu, dan mengunci barisnya sampai
+// transaksi selesai.
+func (r *loyaltyRepository) GetOpenCreditsForUpdate(db *gorm.DB, userID uuid.UUID, now time.Time) ([]entity.LoyaltyTransaction, error) {
+	var entries []entity.LoyaltyTransaction
+	if err := db.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND remaining > 0", userID).
+		Where("expires_at IS NULL OR expires_at > ?", now).
+		Order("expires_at ASC NULLS LAST").
+		Order("created_at ASC").
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *loyaltyRepository) GetExpiredCredits(db *gorm.DB, now time.Time) ([]entity.LoyaltyTransaction, error) {
+	var entries []entity.LoyaltyTransaction
+	if err := db.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("remaining > 0 AND expires_at IS NOT NULL AND expires_at <= ?", now).
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *loyaltyRepository) UpdateRemaining(db *gorm.DB, id uuid.UUID, remaining int) error {
+	if err := db.Model(&entity.LoyaltyTransaction{}).
+		Where("id = ?", id).
+		Update("remaining", remaining).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"mola-web/internal/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// legacyOptionTables tabel lama untuk tipe opsi bawaan.
+var legacyOptionTables = map[string]string{
+	entity.OptionTypeColor: "public.colors",
+	entity.OptionTypeSize:  "public.sizes",
+}
+
+type OptionRepository interface {
+	GetAll(ctx context.Context) ([]entity.OptionType, error)
+	GetTypeByID(db *gorm.DB, id uint) (*entity.OptionType, error)
+	GetTypeByName(db *gorm.DB, name string) (*entity.OptionType, error)
+	GetValueByID(db *gorm.DB, id uint) (*entity.OptionValue, error)
+	GetValuesByIDs(db *gorm.DB, ids []uint) ([]entity.OptionValue, error)
+	GetLegacyValue(db *gorm.DB, typeName string, legacyID uint) (*entity.OptionValue, error)
+	CountValueUsage(db *gorm.DB, valueIDs []uint) (int64, error)
+	CreateType(db *gorm.DB, optionType *entity.OptionType) error
+	UpdateType(db *gorm.DB, optionType *entity.OptionType) error
+	DeleteType(db *gorm.DB, id uint) error
+	CreateValue(db *gorm.DB, value *entity.OptionValue) error
+	UpdateValue(db *gorm.DB, value *entity.OptionValue) error
+	DeleteValue(db *gorm.DB, id uint) error
+	UpsertLegacyValue(db *gorm.DB, typeName string, legacyID uint, name string) error
+	CreateLegacyRow(db *gorm.DB, typeName string, name string) (uint, error)
+	RenameLegacyRow(db *gorm.DB, typeName string, legacyID uint, name string) error
+}
+
+type optionRepository struct {
+	db *gorm.DB
+}
+
+func NewOptionRepository(db *gorm.DB) OptionRepository {
+	return &optionRepository{db}
+}
+
+func (r *optionRepository) GetAll(ctx context.Context) ([]entity.OptionType, error) {
+	optionTypes := []entity.OptionType{}
+	if err := r.db.WithContext(ctx).
+		Preload("Values", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sort_order ASC, value ASC")
+		}).
+		Order("sort_order ASC, name ASC").
+		Find(&optionTypes).Error; err != nil {
+		return nil, err
+	}
+	return optionTypes, nil
+}
+
+func (r *optionRepository) GetTypeByID(db *gorm.DB, id uint) (*entity.OptionType, error) {
+	var optionType entity.OptionType
+	if err := db.Preload("Values").First(&optionType, id).Error; err != nil {
+		return nil, err
+	}
+	return &optionType, nil
+}
+
+func (r *optionRepository) GetTypeByName(db *gorm.DB, name string) (*entity.OptionType, error) {
+	var optionType entity.OptionType
+	if err := db.Where("name = ?", name).First(&optionType).Error; err != nil {
+		return nil, err
+	}
+	return &optionType, nil
+}
+
+func (r *optionRepository) GetValueByID(db *gorm.DB, id uint) (*entity.OptionValue, error) {
+	var value entity.OptionValue
+	if err := db.Preload("OptionType").First(&value, id).Error; err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
+
+func (r *optionRepository) GetValuesByIDs(db *gorm.DB, ids []uint) ([]entity.OptionValue, error) {
+	values := []entity.OptionValue{}
+	if len(ids) == 0 {
+		return values, nil
+	}
+	if err := db.Preload("OptionType").Where("id IN ?", ids).Find(&values).Error; err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func (r *optionRepository) GetLegacyValue(db *gorm.DB, typeName string, legacyID uint) (*entity.OptionValue, error) {
+	var value entity.OptionValue
+	if err := db.Preload("OptionType").
+		Joins("JOIN public.option_types t ON t.id = option_values.option_type_id").
+		Where("t.name = ? AND option_values.legacy_id = ?", typeName, legacyID).
+		First(&value).Error; err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
+
+// CountValueUsage jumlah varian aktif yang memakai salah satu nilai opsi.
+func (r *optionRepository) CountValueUsage(db *gorm.DB, valueIDs []uint) (int64, error) {
+	var count int64
+	if len(valueIDs) == 0 {
+		return 0, nil
+	}
+	if err := db.Table("public.product_variant_options o").
+		Joins("JOIN public.product_variants v ON v.id = o.product_variant_id AND v.deleted_at IS NULL").
+		Where("o.option_value_id IN ?", valueIDs).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *optionRepository) CreateType(db *gorm.DB, optionType *entity.OptionType) error {
+	if err := db.Omit(clause.Associations).Create(optionType).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *optionRepository) UpdateType(db *gorm.DB, optionType *entity.OptionType) error {
+	if err := db.Model(&entity.OptionType{}).Where("id = ?", optionType.ID).
+		Select("name", "sort_order").Updates(optionType).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *optionRepository) DeleteType(db *gorm.DB, id uint) error {
+	if err := db.Delete(&entity.OptionType{}, id).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *optionRepository) CreateValue(db *gorm.DB, value *entity.OptionValue) error {
+	if err := db.Omit(clause.Associations).Create(value).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *optionRepository) UpdateValue(db *gorm.DB, value *entity.OptionValue) error {
+	if err := db.Model(&entity.OptionValue{}).Where("id = ?", value.ID).
+		Select("value", "sort_order").Updates(value).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *optionRepository) DeleteValue(db *gorm.DB, id uint) error {
+	if err := db.Delete(&entity.OptionValue{}, id).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// UpsertLegacyValue menyalin warna atau ukuran dari tabel lama ke nilai opsi.
+// Nilai dengan nama yang sama dipakai ulang.
+func (r *optionRepository) UpsertLegacyValue(db *gorm.DB, typeName string, legacyID uint, name string) error {
+	optionType, err := r.GetTypeByName(db, typeName)
+	if err != nil {
+		return err
+	}
+	// Nilai yang sudah terhubung ke baris lama cukup diganti namanya
+	result := db.Model(&entity.OptionValue{}).
+		Where("option_type_id = ? AND legacy_id = ?", optionType.ID, legacyID).
+		Update("value", name)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+	value := &entity.OptionValue{OptionTypeID: optionType.ID, Value: name, LegacyID: &legacyID}
+	if err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "option_type_id"}, {Name: "value"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"legacy_id": gorm.Expr("COALESCE(option_values.legacy_id, EXCLUDED.legacy_id)")}),
+	}).Omit(clause.Associations).Create(value).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func legacyOptionTable(typeName string) (string, error) {
+	table, ok := legacyOptionTables[typeName]
+	if !ok {
+		return "", fmt.Errorf("option type %q has no legacy table", typeName)
+	}
+	return table, nil
+}
+
+// CreateLegacyRow membuat baris di tabel colors atau sizes untuk nilai opsi
+// baru bertipe Color atau Size.
+func (r *optionRepository) CreateLegacyRow(db *gorm.DB, typeName string, name string) (uint, error) {
+	table, err := legacyOptionTable(typeName)
+	if err != nil {
+		return 0, err
+	}
+	var id uint
+	if err := db.Raw("INSERT INTO "+table+" (name, created_at, updated_at) VALUES (?, NOW(), NOW()) RETURNING id", name).Scan(&id).Error; err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *optionRepository) RenameLegacyRow(db *gorm.DB, typeName string, legacyID uint, name string) error {
+	table, err := legacyOptionTable(typeName)
+	if err != nil {
+		return err
+	}
+	if err := db.Table(table).Where("id = ?", legacyID).Updates(map[string]interface{}{"name": name, "updated_at": gorm.Expr("NOW()")}).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type OrderRepository interface {
@@ -16,6 +17,7 @@ type OrderRepository interface {
 	CreateOrderItem(db *gorm.DB, orderItem *entity.OrderItem) error
 	ShowOrder(ctx context.Context, userID uuid.UUID) ([]entity.Order, error)
 	GetOrderByID(ctx context.Context, id uuid.UUID) (*entity.Order, error)
+	GetOrderForUpdate(db *gorm.DB, id uuid.UUID) (*entity.Order, error)
 	GetOrderByIDAndProductID(ctx context.Context, idOrder uuid.UUID, idProduct uuid.UUID) (*entity.Order, error)
 	GetOrderItemsByOrderID(ctx context.Context, id uuid.UUID) ([]entity.OrderItem, error)
 	GetPendingPaymentStatusByUserID(db *gorm.DB, id uuid.UUID) (*dto.GetPaymentStatusResponse, error)
@@ -43,6 +45,8 @@ func (r *orderRepository) GetAll(ctx context.Context) ([]entity.Order, error) {
 		Preload("OrderItems.ProductVariant").
 		Preload("OrderItems.ProductVariant.Color").
 		Preload("OrderItems.ProductVariant.Size").
+		Preload("OrderItems.ProductVariant.Image").
+		Preload("OrderItems.Components").
 		Find(&orders).Error; err != nil {
 		return nil, err
 	}
@@ -70,6 +74,8 @@ func (r *orderRepository) ShowOrder(ctx context.Context, userID uuid.UUID) ([]en
 		Preload("OrderItems.ProductVariant").
 		Preload("OrderItems.ProductVariant.Color").
 		Preload("OrderItems.ProductVariant.Size").
+		Preload("OrderItems.ProductVariant.Image").
+		Preload("OrderItems.Components").
 		Where("user_id = ?", userID).Find(&orders).Error; err != nil {
 		return nil, err
 	}
@@ -78,7 +84,19 @@ func (r *orderRepository) ShowOrder(ctx context.Context, userID uuid.UUID) ([]en
 
 func (r *orderRepository) GetOrderByID(ctx context.Context, id uuid.UUID) (*entity.Order, error) {
 	var order entity.Order
-	if err := r.db.WithContext(ctx).Preload("OrderItems.Product").First(&order, id).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("OrderItems.Product").Preload("OrderItems.Components").First(&order, id).Error; err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// GetOrderForUpdate mengunci baris order sampai transaksi selesai agar
+// refund yang berjalan bersamaan tidak melewati batas nilai order.
+func (r *orderRepository) GetOrderForUpdate(db *gorm.DB, id uuid.UUID) (*entity.Order, error) {
+	var order entity.Order
+	if err := db.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&order, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
 	return &order, nil
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"mola-web/internal/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// preloadProductImages memuat galeri sesuai urutan tampil.
+func preloadProductImages(db *gorm.DB) *gorm.DB {
+	return db.Order("sort_order ASC").Order("created_at ASC")
+}
+
+func (r *productRepository) CreateImages(db *gorm.DB, images []entity.ProductImage) error {
+	if len(images) == 0 {
+		return nil
+	}
+	if err := db.Create(&images).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetImagesForUpdate mengambil galeri produk dan menguncinya supaya urutan
+// dan gambar utama tidak diubah bersamaan.
+func (r *productRepository) GetImagesForUpdate(db *gorm.DB, productID uuid.UUID) ([]entity.ProductImage, error) {
+	images := []entity.ProductImage{}
+	if err := preloadProductImages(db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ?", productID).
+		Find(&images).Error; err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+func (r *productRepository) UpdateImage(db *gorm.DB, image *entity.ProductImage) error {
+	if err := db.Model(&entity.ProductImage{}).
+		Where("id = ?", image.ID).
+		Select("*").
+		Omit("id", "product_id", "created_at").
+		Updates(image).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *productRepository) DeleteImage(db *gorm.DB, id uuid.UUID) error {
+	if err := db.Delete(&entity.ProductImage{}, id).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *productRepository) SetImageURL(db *gorm.DB, productID uuid.UUID, imageURL *string) error {
+	if err := db.Model(&entity.Product{}).Where("id = ?", productID).Update("image_url", imageURL).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,271 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"mola-web/internal/entity"
+	"mola-web/internal/http/dto"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// productSearchConfig konfigurasi teks Postgres, harus sama dengan yang
+// dipakai trigger search_vector.
+const productSearchConfig = "simple"
+
+const (
+	productFacetCategory = "category"
+	productFacetColor    = "color"
+	productFacetSize     = "size"
+)
+
+// productInStockCondition produk simple/bervarian dianggap tersedia jika ada
+// stok, bundle jika setiap komponennya cukup untuk satu paket.
+const productInStockCondition = `(
+	(products.product_type = 'bundle' AND NOT EXISTS (
+		SELECT 1 FROM public.product_bundle_items bi
+		JOIN public.products c ON c.id = bi.component_id
+		LEFT JOIN public.product_variants cv ON cv.id = bi.component_variant_id AND cv.deleted_at IS NULL
+		WHERE bi.bundle_id = products.id
+		AND CASE
+			WHEN bi.component_variant_id IS NOT NULL THEN COALESCE(cv.stock, 0)
+			WHEN c.has_variant THEN COALESCE((SELECT MAX(v.stock) FROM public.product_variants v WHERE v.product_id = c.id AND v.deleted_at IS NULL), 0)
+			ELSE c.stock
+		END < bi.quantity
+	))
+	OR (products.product_type <> 'bundle' AND NOT products.has_variant AND products.stock > 0)
+	OR (products.product_type <> 'bundle' AND products.has_variant AND EXISTS (
+		SELECT 1 FROM public.product_variants v
+		WHERE v.product_id = products.id AND v.deleted_at IS NULL AND v.stock > 0
+	))
+)`
+
+// productVisibleCondition produk yang tampil di katalog publik
+const productVisibleCondition = `(products.status = 'published'
+	AND (products.publish_at IS NULL OR products.publish_at <= NOW())
+	AND (products.unpublish_at IS NULL OR products.unpublish_at > NOW()))`
+
+func productVisible(db *gorm.DB) *gorm.DB {
+	return db.Where(productVisibleCondition)
+}
+
+// productListFilter menerapkan filter listing. Filter milik facet yang
+// sedang dihitung (skip) tidak diterapkan.
+func productListFilter(query dto.ProductListQuery, skip string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if !query.Admin {
+			db = db.Scopes(productVisible)
+		} else if query.Status != "" {
+			db = db.Where("products.status = ?", query.Status)
+		}
+		if query.Search != "" {
+			if query.Fuzzy {
+				db = db.Where("products.name % ?", query.Search)
+			} else {
+				db = db.Where("products.search_vector @@ websearch_to_tsquery('"+productSearchConfig+"', ?)", query.Search)
+			}
+		}
+		if skip != productFacetCategory && query.CategoryID != nil {
+			db = db.Where("products.category_id = ?", *query.CategoryID)
+		}
+
+		// Warna dan ukuran harus cocok di varian yang sama
+		variantConditions := "v.product_id = products.id AND v.deleted_at IS NULL"
+		var variantArgs []interface{}
+		if skip != productFacetColor && len(query.ColorIDs) > 0 {
+			variantConditions += " AND v.color_id IN ?"
+			variantArgs = append(variantArgs, query.ColorIDs)
+		}
+		if skip != productFacetSize && len(query.SizeIDs) > 0 {
+			variantConditions += " AND v.size_id IN ?"
+			variantArgs = append(variantArgs, query.SizeIDs)
+		}
+		if len(variantArgs) > 0 {
+			db = db.Where("EXISTS (SELECT 1 FROM public.product_variants v WHERE "+variantConditions+")", variantArgs...)
+		}
+
+		if query.MinPrice != nil {
+			db = db.Where("products.price >= ?", *query.MinPrice)
+		}
+		if query.MaxPrice != nil {
+			db = db.Where("products.price <= ?", *query.MaxPrice)
+		}
+		if query.InStock {
+			db = db.Where(productInStockCondition)
+		}
+		return db
+	}
+}
+
+func productListSort(db *gorm.DB, query dto.ProductListQuery) *gorm.DB {
+	switch query.Sort {
+	case "relevance":
+		rank := "ts_rank_cd(products.search_vector, websearch_to_tsquery('" + productSearchConfig + "', ?)) DESC"
+		if query.Fuzzy {
+			rank = "similarity(products.name, ?) DESC"
+		}
+		db = db.Order(clause.OrderBy{Expression: clause.Expr{SQL: rank, Vars: []interface{}{query.Search}, WithoutParentheses: true}})
+	case "price_asc":
+		db = db.Order("products.price ASC")
+	case "price_desc":
+		db = db.Order("products.price DESC")
+	case "best_selling":
+		db = db.Joins(productSoldJoin).
+			Order("COALESCE(sales.sold, 0) DESC")
+	case "rating":
+		db = db.Joins(`LEFT JOIN (
+			SELECT product_id, AVG(rating) AS rating, COUNT(*) AS review_count
+			FROM public.product_reviews
+			WHERE deleted_at IS NULL
+			GROUP BY product_id
+		) ratings ON ratings.product_id = products.id`).
+			Order("COALESCE(ratings.rating, 0) DESC").
+			Order("COALESCE(ratings.review_count, 0) DESC")
+	}
+	return db.Order("products.created_at DESC").Order("products.id")
+}
+
+// List mengambil satu halaman produk sesuai filter dan urutan beserta
+// jumlah seluruh produk yang cocok.
+func (r *productRepository) List(ctx context.Context, query dto.ProductListQuery) ([]*entity.Product, int64, error) {
+	var total int64
+	if err := r.db.WithContext(ctx).
+		Model(&entity.Product{}).
+		Scopes(productListFilter(query, "")).
+		Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var ids []uuid.UUID
+	if err := productListSort(r.db.WithContext(ctx).Model(&entity.Product{}), query).
+		Scopes(productListFilter(query, "")).
+		Offset((query.Page-1)*query.Limit).
+		Limit(query.Limit).
+		Pluck("products.id", &ids).Error; err != nil {
+		return nil, 0, err
+	}
+	if len(ids) == 0 {
+		return []*entity.Product{}, total, nil
+	}
+
+	products := make([]*entity.Product, 0, len(ids))
+	if err := r.db.WithContext(ctx).
+		Preload("Category").
+		Preload("Variants").
+		Preload("Variants.Color").
+		Preload("Variants.Size").
+		Preload("Variants.Options.OptionType").
+		Preload("Variants.Image").
+		Preload("BundleItems").
+		Preload("BundleItems.Component").
+		Preload("BundleItems.Component.Variants").
+		Preload("BundleItems.Component.Variants.Color").
+		Preload("BundleItems.Component.Variants.Size").
+		Preload("BundleItems.Component.Variants.Options.OptionType").
+		Preload("Images", preloadProductImages).
+		Where("id IN ?", ids).
+		Find(&products).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Kembalikan ke urutan hasil query
+	position := make(map[uuid.UUID]int, len(ids))
+	for i, id := range ids {
+		position[id] = i
+	}
+	ordered := make([]*entity.Product, len(ids))
+	for _, product := range products {
+		ordered[position[product.ID]] = product
+	}
+	results := ordered[:0]
+	for _, product := range ordered {
+		if product != nil {
+			results = append(results, product)
+		}
+	}
+	return results, total, nil
+}
+
+func (r *productRepository) GetListFacets(ctx context.Context, query dto.ProductListQuery) (*dto.ProductFacets, error) {
+	facets := &dto.ProductFacets{
+		Categories: []dto.FacetCount{},
+		Colors:     []dto.FacetCount{},
+		Sizes:      []dto.FacetCount{},
+	}
+
+	if err := r.db.WithContext(ctx).
+		Model(&entity.Product{}).
+		Scopes(productListFilter(query, productFacetCategory)).
+		Joins("JOIN public.categories c ON c.id = products.category_id AND c.deleted_at IS NULL").
+		Select("c.id AS id, c.name AS name, COUNT(DISTINCT products.id) AS count").
+		Group("c.id, c.name").
+		Order("c.name").
+		Scan(&facets.Categories).Error; err != nil {
+		return nil, err
+	}
+
+	if err := r.db.WithContext(ctx).
+		Model(&entity.Product{}).
+		Scopes(productListFilter(query, productFacetColor)).
+		Joins("JOIN public.product_variants pv ON pv.product_id = products.id AND pv.deleted_at IS NULL").
+		Joins("JOIN public.colors c ON c.id = pv.color_id AND c.deleted_at IS NULL").
+		Select("c.id AS id, c.name AS name, COUNT(DISTINCT products.id) AS count").
+		Group("c.id, c.name").
+		Order("c.name").
+		Scan(&facets.Colors).Error; err != nil {
+		return nil, err
+	}
+
+	if err := r.db.WithContext(ctx).
+		Model(&entity.Product{}).
+		Scopes(productListFilter(query, productFacetSize)).
+		Joins("JOIN public.product_variants pv ON pv.product_id = products.id AND pv.deleted_at IS NULL").
+		Joins("JOIN public.sizes s ON s.id = pv.size_id AND s.deleted_at IS NULL").
+		Select("s.id AS id, s.name AS name, COUNT(DISTINCT products.id) AS count").
+		Group("s.id, s.name").
+		Order("s.name").
+		Scan(&facets.Sizes).Error; err != nil {
+		return nil, err
+	}
+	return facets, nil
+}
+
+// escapeHTMLSQL meng-escape teks sebelum diberi tag <mark> oleh ts_headline,
+// supaya isi nama dan deskripsi tidak terbaca sebagai HTML di frontend.
+func escapeHTMLSQL(column string) string {
+	return `replace(replace(replace(replace(` + column + `, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;')`
+}
+
+// GetSearchHighlights menandai kata kunci di nama dan deskripsi produk. Hasilnya
+// sudah di-escape sehingga aman dirender sebagai HTML.
+func (r *productRepository) GetSearchHighlights(ctx context.Context, ids []uuid.UUID, search string) (map[uuid.UUID]dto.SearchHighlight, error) {
+	var rows []struct {
+		ID          uuid.UUID
+		Name        string
+		Description string
+	}
+	if len(ids) == 0 {
+		return map[uuid.UUID]dto.SearchHighlight{}, nil
+	}
+	if err := r.db.WithContext(ctx).
+		Model(&entity.Product{}).
+		Select(`products.id AS id,
+			ts_headline('`+productSearchConfig+`', `+escapeHTMLSQL("products.name")+`, websearch_to_tsquery('`+productSearchConfig+`', @search), 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS name,
+			ts_headline('`+productSearchConfig+`', `+escapeHTMLSQL("coalesce(products.description, '')")+`, websearch_to_tsquery('`+productSearchConfig+`', @search), 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5') AS description`,
+			sql.Named("search", search)).
+		Where("products.id IN ?", ids).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	highlights := make(map[uuid.UUID]dto.SearchHighlight, len(rows))
+	for _, row := range rows {
+		highlights[row.ID] = dto.SearchHighlight{
+			Name:        row.Name,
+			Description: row.Description,
+		}
+	}
+	return highlights, nil
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"mola-web/internal/entity"
+	"mola-web/internal/http/dto"
+
+	"github.com/google/uuid"
+)
+
+// productSoldJoin jumlah terjual per produk dari order yang sudah dibayar.
+const productSoldJoin = `LEFT JOIN (
+	SELECT oi.product_id, SUM(oi.quantity) AS sold
+	FROM order_items oi
+	JOIN orders o ON o.id = oi.order_id
+	WHERE o.is_paid AND o.deleted_at IS NULL AND oi.deleted_at IS NULL
+	GROUP BY oi.product_id
+) sales ON sales.product_id = products.id`
+
+// GetSuggestionWeights bobot autocomplete produk yang tampil di katalog.
+// ids kosong berarti semua produk.
+func (r *productRepository) GetSuggestionWeights(ctx context.Context, ids []uuid.UUID) ([]dto.ProductSuggestionWeight, error) {
+	results := []dto.ProductSuggestionWeight{}
+	db := r.db.WithContext(ctx).
+		Model(&entity.Product{}).
+		Joins(productSoldJoin).
+		Scopes(productVisible).
+		Select("products.id AS id, products.name AS name, COALESCE(sales.sold, 0) AS sold")
+	if len(ids) > 0 {
+		db = db.Where("products.id IN ?", ids)
+	}
+	if err := db.Scan(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// GetCategorySuggestionWeights bobot autocomplete kategori dari jumlah produk
+// dan penjualannya. Kategori tanpa produk tidak disarankan.
+func (r *productRepository) GetCategorySuggestionWeights(ctx context.Context) ([]dto.CategorySuggestionWeight, error) {
+	results := []dto.CategorySuggestionWeight{}
+	if err := r.db.WithContext(ctx).
+		Model(&entity.Product{}).
+		Joins("JOIN public.categories c ON c.id = products.category_id AND c.deleted_at IS NULL").
+		Joins(productSoldJoin).
+		Scopes(productVisible).
+		Select("c.id AS id, c.name AS name, COUNT(products.id) AS products, COALESCE(SUM(sales.sold), 0) AS sold").
+		Group("c.id, c.name").
+		Scan(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -13,6 +13,7 @@ type ProductVariantRepository interface {
 	GetByProductID(db *gorm.DB, productID uuid.UUID) ([]*entity.ProductVariant, error)
 	GetStockProductVariant(db *gorm.DB, id uuid.UUID) (int64, error)
 	Update(db *gorm.DB, productVariant *entity.ProductVariant) error
+	ReplaceOptions(db *gorm.DB, productVariant *entity.ProductVariant, options []entity.OptionValue) error
 	UpdateStock(db *gorm.DB, id uuid.UUID, stock int) error
 	Delete(db *gorm.DB, id uuid.UUID) error
 	DeleteByProductID(db *gorm.DB, productID uuid.UUID) error
@@ -50,15 +51,33 @@ func (r *productVariantRepository) GetByID(db *gorm.DB, id uuid.UUID) (*entity.P
 	return &productVariant, nil
 }
 
+// Create ikut menyimpan relasi Options, nilai opsinya sendiri tidak diubah.
 func (r *productVariantRepository) Create(db *gorm.DB, productVariant *entity.ProductVariant) error {
-	if err := db.Create(productVariant).Error; err != nil {
+	if err := db.Omit("Options.*").Create(productVariant).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
+// Update menyimpan stok dan override varian, override kosong ikut disimpan
+// sebagai NULL.
 func (r *productVariantRepository) Update(db *gorm.DB, productVariant *entity.ProductVariant) error {
-	if err := db.Model(&entity.ProductVariant{}).Where("id = ?", productVariant.ID).Updates(productVariant).Error; err != nil {
+	if err := db.Model(&entity.ProductVariant{}).
+		Select("stock", "price", "compare_at_price", "weight", "sku", "barcode", "image_id").
+		Where("id = ?", productVariant.ID).
+		Updates(productVariant).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *productVariantRepository) ReplaceOptions(db *gorm.DB, productVariant *entity.ProductVariant, options []entity.OptionValue) error {
+	if err := db.Model(productVariant).Omit("Options.*").Association("Options").Replace(options); err != nil {
+		return err
+	}
+	// Salinan Color dan Size ikut diperbarui termasuk jika menjadi kosong
+	if err := db.Model(&entity.ProductVariant{}).Where("id = ?", productVariant.ID).
+		Updates(map[string]interface{}{"color_id": productVariant.ColorID, "size_id": productVariant.SizeID}).Error; err != nil {
 		return err
 	}
 	return nil
@@ -87,7 +106,7 @@ func (r *productVariantRepository) DeleteByProductID(db *gorm.DB, productID uuid
 
 func (r *productVariantRepository) GetByProductID(db *gorm.DB, productID uuid.UUID) ([]*entity.ProductVariant, error) {
 	var variants []*entity.ProductVariant
-	if err := db.Where("product_id = ?", productID).Find(&variants).Error; err != nil {
+	if err := db.Preload("Options").Where("product_id = ?", productID).Find(&variants).Error; err != nil {
 		return nil, err
 	}
 	return variants, nil
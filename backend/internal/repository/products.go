@@ -3,15 +3,23 @@ package repository
 import (
 	"context"
 	"mola-web/internal/entity"
+	"mola-web/internal/http/dto"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type ProductRepository interface {
-	GetAll(ctx context.Context) ([]*entity.Product, error)
+	List(ctx context.Context, query dto.ProductListQuery) ([]*entity.Product, int64, error)
+	GetListFacets(ctx context.Context, query dto.ProductListQuery) (*dto.ProductFacets, error)
+	GetSearchHighlights(ctx context.Context, ids []uuid.UUID, search string) (map[uuid.UUID]dto.SearchHighlight, error)
+	GetSuggestionWeights(ctx context.Context, ids []uuid.UUID) ([]dto.ProductSuggestionWeight, error)
+	GetCategorySuggestionWeights(ctx context.Context) ([]dto.CategorySuggestionWeight, error)
 	GetByID(db *gorm.DB, id uuid.UUID) (*entity.Product, error)
-	GetByCategoryID(ctx context.Context, categoryID uint) ([]entity.Product, error)
+	GetBySKU(db *gorm.DB, sku string) (*entity.Product, error)
+	GetAllForExport(ctx context.Context) ([]entity.Product, error)
+	GetByCategoryID(ctx context.Context, categoryID uint, includeDescendants bool) ([]entity.Product, error)
 	GetByName(ctx context.Context, name string) ([]*entity.Product, error)
 	GetStockProduct(db *gorm.DB, id uuid.UUID) (int64, error)
 	UpdateStockProduct(db *gorm.DB, stock int64, id uuid.UUID) error
@@ -19,7 +27,16 @@ type ProductRepository interface {
 	GetProductReviews(ctx context.Context, productID uuid.UUID) ([]entity.ProductReview, error)
 	Create(db *gorm.DB, product *entity.Product) error
 	Update(db *gorm.DB, product *entity.Product) error
+	UpdatePublication(db *gorm.DB, product *entity.Product) error
+	CountPublishBoundariesBetween(db *gorm.DB, from time.Time, to time.Time) (int64, error)
 	Delete(db *gorm.DB, id uuid.UUID) error
+	ReplaceBundleItems(db *gorm.DB, bundleID uuid.UUID, items []entity.ProductBundleItem) error
+	ReplacePriceTiers(db *gorm.DB, productID uuid.UUID, tiers []entity.ProductPriceTier) error
+	CreateImages(db *gorm.DB, images []entity.ProductImage) error
+	GetImagesForUpdate(db *gorm.DB, productID uuid.UUID) ([]entity.ProductImage, error)
+	UpdateImage(db *gorm.DB, image *entity.ProductImage) error
+	DeleteImage(db *gorm.DB, id uuid.UUID) error
+	SetImageURL(db *gorm.DB, productID uuid.UUID, imageURL *string) error
 }
 
 type productRepository struct {
@@ -30,29 +47,29 @@ func NewProductRepository(db *gorm.DB) ProductRepository {
 	return &productRepository{db}
 }
 
-func (r *productRepository) GetAll(ctx context.Context) ([]*entity.Product, error) {
-	products := make([]*entity.Product, 0)
-
-	if err := r.db.WithContext(ctx).
-		Preload("Category").
-		Preload("Variants").
-		Preload("Variants.Color").
-		Preload("Variants.Size").
-		Find(&products).Error; err != nil {
-		return nil, err
-	}
-	return products, nil
-}
-
-func (r *productRepository) GetByCategoryID(ctx context.Context, categoryID uint) ([]entity.Product, error) {
+func (r *productRepository) GetByCategoryID(ctx context.Context, categoryID uint, includeDescendants bool) ([]entity.Product, error) {
 	products := make([]entity.Product, 0)
 
-	if err := r.db.WithContext(ctx).
+	db := r.db.WithContext(ctx).Scopes(productVisible)
+	if includeDescendants {
+		db = db.Where("products.category_id IN ("+categorySubtreeSQL+")", categoryID)
+	} else {
+		db = db.Where("products.category_id = ?", categoryID)
+	}
+	if err := db.
 		Preload("Category").
 		Preload("Variants").
 		Preload("Variants.Color").
 		Preload("Variants.Size").
-		Where("products.category_id = ?", categoryID).
+		Preload("Variants.Options.OptionType").
+		Preload("Variants.Image").
+		Preload("BundleItems").
+		Preload("BundleItems.Component").
+		Preload("BundleItems.Component.Variants").
+		Preload("BundleItems.Component.Variants.Color").
+		Preload("BundleItems.Component.Variants.Size").
+		Preload("BundleItems.Component.Variants.Options.OptionType").
+		Preload("Images", preloadProductImages).
 		Find(&products).Error; err != nil {
 		return nil, err
 	}
@@ -68,7 +85,17 @@ func (r *productRepository) GetByName(ctx context.Context, name string) ([]*enti
 		Preload("Variants").
 		Preload("Variants.Color").
 		Preload("Variants.Size").
+		Preload("Variants.Options.OptionType").
+		Preload("Variants.Image").
+		Preload("BundleItems").
+		Preload("BundleItems.Component").
+		Preload("BundleItems.Component.Variants").
+		Preload("BundleItems.Component.Variants.Color").
+		Preload("BundleItems.Component.Variants.Size").
+		Preload("BundleItems.Component.Variants.Options.OptionType").
+		Preload("Images", preloadProductImages).
 		Where("products.name ILIKE ?", "%"+name+"%").
+		Scopes(productVisible).
 		Find(&products).Error; err != nil {
 		return nil, err
 	}
@@ -83,6 +110,18 @@ func (r *productRepository) GetByID(db *gorm.DB, id uuid.UUID) (*entity.Product,
 		Preload("Variants").
 		Preload("Variants.Color").
 		Preload("Variants.Size").
+		Preload("Variants.Options.OptionType").
+		Preload("Variants.Image").
+		Preload("BundleItems").
+		Preload("BundleItems.Component").
+		Preload("BundleItems.Component.Variants").
+		Preload("BundleItems.Component.Variants.Color").
+		Preload("BundleItems.Component.Variants.Size").
+		Preload("BundleItems.Component.Variants.Options.OptionType").
+		Preload("PriceTiers", func(db *gorm.DB) *gorm.DB {
+			return db.Order("min_quantity ASC")
+		}).
+		Preload("Images", preloadProductImages).
 		First(&product, "id = ? AND deleted_at IS NULL", id).Error
 
 	if err != nil {
@@ -91,6 +130,30 @@ func (r *productRepository) GetByID(db *gorm.DB, id uuid.UUID) (*entity.Product,
 	return &product, nil
 }
 
+func (r *productRepository) GetBySKU(db *gorm.DB, sku string) (*entity.Product, error) {
+	var product entity.Product
+	if err := db.First(&product, "sku = ?", sku).Error; err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// GetAllForExport semua produk beserta varian dan opsinya, urut dari yang
+// paling lama supaya hasil export stabil.
+func (r *productRepository) GetAllForExport(ctx context.Context) ([]entity.Product, error) {
+	products := []entity.Product{}
+	if err := r.db.WithContext(ctx).
+		Preload("Variants", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC, id ASC")
+		}).
+		Preload("Variants.Options.OptionType").
+		Order("created_at ASC, id ASC").
+		Find(&products).Error; err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
 func (r *productRepository) GetStockProduct(db *gorm.DB, id uuid.UUID) (int64, error) {
 	var stock int64
 	err := db.
@@ -143,6 +206,9 @@ func (r *productRepository) Create(db *gorm.DB, product *entity.Product) error {
 func (r *productRepository) Update(db *gorm.DB, product *entity.Product) error {
 	updateFields := map[string]interface{}{
 		"name":        product.Name,
+		"slug":        product.Slug,
+		"sku":         product.SKU,
+		"barcode":     product.Barcode,
 		"category_id": product.CategoryID,
 		"description": product.Description,
 		"image_url":   product.ImageURL,
@@ -151,6 +217,9 @@ func (r *productRepository) Update(db *gorm.DB, product *entity.Product) error {
 		"price":       product.Price,
 		"weight":      product.Weight,
 	}
+	if product.ProductType != "" {
+		updateFields["product_type"] = product.ProductType
+	}
 
 	if err := db.Model(&entity.Product{}).Where("id = ?", product.ID).Updates(updateFields).Error; err != nil {
 		return err
@@ -158,6 +227,27 @@ func (r *productRepository) Update(db *gorm.DB, product *entity.Product) error {
 	return nil
 }
 
+func (r *productRepository) UpdatePublication(db *gorm.DB, product *entity.Product) error {
+	return db.Model(&entity.Product{}).Where("id = ?", product.ID).Updates(map[string]interface{}{
+		"status":       product.Status,
+		"publish_at":   product.PublishAt,
+		"unpublish_at": product.UnpublishAt,
+	}).Error
+}
+
+// CountPublishBoundariesBetween menghitung produk published yang mulai atau
+// berhenti tayang dalam rentang (from, to].
+func (r *productRepository) CountPublishBoundariesBetween(db *gorm.DB, from time.Time, to time.Time) (int64, error) {
+	var count int64
+	if err := db.Model(&entity.Product{}).
+		Where("status = ?", entity.ProductStatusPublished).
+		Where("(publish_at > ? AND publish_at <= ?) OR (unpublish_at > ? AND unpublish_at <= ?)", from, to, from, to).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
 
 func (r *productRepository) Delete(db *gorm.DB, id uuid.UUID) error {
 	if err := db.Delete(&entity.Product{}, id).Error; err != nil {
@@ -165,3 +255,27 @@ func (r *productRepository) Delete(db *gorm.DB, id uuid.UUID) error {
 	}
 	return nil
 }
+
+func (r *productRepository) ReplaceBundleItems(db *gorm.DB, bundleID uuid.UUID, items []entity.ProductBundleItem) error {
+	if err := db.Where("bundle_id = ?", bundleID).Delete(&entity.ProductBundleItem{}).Error; err != nil {
+		return err
+	}
+	if len(items) > 0 {
+		if err := db.Create(&items).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *productRepository) ReplacePriceTiers(db *gorm.DB, productID uuid.UUID, tiers []entity.ProductPriceTier) error {
+	if err := db.Where("product_id = ?", productID).Delete(&entity.ProductPriceTier{}).Error; err != nil {
+		return err
+	}
+	if len(tiers) > 0 {
+		if err := db.Create(&tiers).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
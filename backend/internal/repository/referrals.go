@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"mola-web/internal/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReferralRepository interface {
+	GetSettings(db *gorm.DB) (*entity.ReferralSettings, error)
+	UpdateSettings(db *gorm.DB, settings *entity.ReferralSettings) error
+	Create(db *gorm.DB, referral *entity.Referral) error
+	Update(db *gorm.DB, referral *entity.Referral) error
+	GetPendingByRefereeForUpdate(db *gorm.DB, refereeID uuid.UUID) (*entity.Referral, error)
+	GetConvertedByOrderIDForUpdate(db *gorm.DB, orderID uuid.UUID) (*entity.Referral, error)
+	GetAll(ctx context.Context) ([]entity.Referral, error)
+	GetByUserID(db *gorm.DB, userID uuid.UUID) ([]entity.Referral, error)
+}
+
+type referralRepository struct {
+	db *gorm.DB
+}
+
+func NewReferralRepository(db *gorm.DB) ReferralRepository {
+	return &referralRepository{db}
+}
+
+// GetSettings mengambil baris pengaturan, dibuat dengan nilai default jika
+// belum ada.
+func (r *referralRepository) GetSettings(db *gorm.DB) (*entity.ReferralSettings, error) {
+	var settings entity.ReferralSettings
+	if err := db.FirstOrCreate(&settings, entity.ReferralSettings{ID: 1}).Error; err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+func (r *referralRepository) UpdateSettings(db *gorm.DB, settings *entity.ReferralSettings) error {
+	// Select("*") agar nilai nol (mis. is_active=false) ikut tersimpan
+	if err := db.Model(&entity.ReferralSettings{}).
+		Where("id = ?", settings.ID).
+		Select("*").
+		Omit("id").
+		Updates(settings).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *referralRepository) Create(db *gorm.DB, referral *entity.Referral) error {
+	if err := db.Create(referral).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *referralRepository) Update(db *gorm.DB, referral *entity.Referral) error {
+	if err := db.Model(&entity.Referral{}).
+		Where("id = ?", referral.ID).
+		Select("*").
+		Omit("id", "created_at", clause.Associations).
+		Updates(referral).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *referralRepository) GetPendingByRefereeForUpdate(db *gorm.DB, refereeID uuid.UUID) (*entity.Referral, error) {
+	var referral entity.Referral
+	if err := db.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("referee_id = ? AND status = ?", refereeID, "pending").
+		First(&referral).Error; err != nil {
+		return nil, err
+	}
+	return &referral, nil
+}
+
+func (r *referralRepository) GetConvertedByOrderIDForUpdate(db *gorm.DB, orderID uuid.UUID) (*entity.Referral, error) {
+	var referral entity.Referral
+	if err := db.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("order_id = ? AND status = ?", orderID, "converted").
+		First(&referral).Error; err != nil {
+		return nil, err
+	}
+	return &referral, nil
+}
+
+func (r *referralRepository) GetAll(ctx context.Context) ([]entity.Referral, error) {
+	var referrals []entity.Referral
+	if err := r.db.WithContext(ctx).
+		Preload("Referrer").
+		Preload("Referee").
+		Preload("Order").
+		Order("created_at DESC").
+		Find(&referrals).Error; err != nil {
+		return nil, err
+	}
+	return referrals, nil
+}
+
+// GetByUserID mengambil referral di mana user menjadi pengundang atau yang
+// diundang, beserta voucher hadiahnya.
+func (r *referralRepository) GetByUserID(db *gorm.DB, userID uuid.UUID) ([]entity.Referral, error) {
+	var referrals []entity.Referral
+	if err := db.
+		Preload("ReferrerVoucher").
+		Preload("RefereeVoucher").
+		Where("referrer_id = ? OR referee_id = ?", userID, userID).
+		Order("created_at DESC").
+		Find(&referrals).Error; err != nil {
+		return nil, err
+	}
+	return referrals, nil
+}
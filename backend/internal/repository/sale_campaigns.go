@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"mola-web/internal/entity"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SaleCampaignRepository interface {
+	GetAll(ctx context.Context) ([]entity.SaleCampaign, error)
+	GetByID(db *gorm.DB, id uuid.UUID) (*entity.SaleCampaign, error)
+	Create(db *gorm.DB, campaign *entity.SaleCampaign) error
+	Update(db *gorm.DB, campaign *entity.SaleCampaign) error
+	ReplaceItems(db *gorm.DB, campaignID uuid.UUID, items []entity.SaleCampaignItem) error
+	Delete(db *gorm.DB, id uuid.UUID) error
+	GetActiveItems(db *gorm.DB, productIDs []uuid.UUID, now time.Time) ([]entity.SaleCampaignItem, error)
+	IncrementSold(db *gorm.DB, campaignID uuid.UUID, quantity int) (int64, error)
+	ReleaseSoldByOrderID(db *gorm.DB, orderID uuid.UUID) error
+	CountBoundariesBetween(db *gorm.DB, from time.Time, to time.Time) (int64, error)
+}
+
+type saleCampaignRepository struct {
+	db *gorm.DB
+}
+
+func NewSaleCampaignRepository(db *gorm.DB) SaleCampaignRepository {
+	return &saleCampaignRepository{db}
+}
+
+func (r *saleCampaignRepository) GetAll(ctx context.Context) ([]entity.SaleCampaign, error) {
+	var campaigns []entity.SaleCampaign
+	if err := r.db.WithContext(ctx).
+		Preload("Items").
+		Preload("Items.Product").
+		Order("starts_at DESC").
+		Find(&campaigns).Error; err != nil {
+		return nil, err
+	}
+	return campaigns, nil
+}
+
+func (r *saleCampaignRepository) GetByID(db *gorm.DB, id uuid.UUID) (*entity.SaleCampaign, error) {
+	var campaign entity.SaleCampaign
+	if err := db.Where("id = ?", id).First(&campaign).Error; err != nil {
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+func (r *saleCampaignRepository) Create(db *gorm.DB, campaign *entity.SaleCampaign) error {
+	if err := db.Omit(clause.Associations).Create(campaign).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *saleCampaignRepository) Update(db *gorm.DB, campaign *entity.SaleCampaign) error {
+	// sold_count tidak ikut diubah dari form admin
+	if err := db.Model(&entity.SaleCampaign{}).
+		Where("id = ?", campaign.ID).
+		Select("*").
+		Omit("id", "sold_count", "created_at", "deleted_at", clause.Associations).
+		Updates(campaign).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *saleCampaignRepository) ReplaceItems(db *gorm.DB, campaignID uuid.UUID, items []entity.SaleCampaignItem) error {
+	if err := db.Where("sale_campaign_id = ?", campaignID).Delete(&entity.SaleCampaignItem{}).Error; err != nil {
+		return err
+	}
+	if len(items) > 0 {
+		if err := db.Create(&items).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *saleCampaignRepository) Delete(db *gorm.DB, id uuid.UUID) error {
+	if err := db.Delete(&entity.SaleCampaign{}, "id = ?", id).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetActiveItems mengambil item promo dari kampanye yang sedang berjalan dan
+// kuotanya belum habis.
+func (r *saleCampaignRepository) GetActiveItems(db *gorm.DB, productIDs []uuid.UUID, now time.Time) ([]entity.SaleCampaignItem, error) {
+	var items []entity.SaleCampaignItem
+	if len(productIDs) == 0 {
+		return items, nil
+	}
+	if err := db.
+		Joins("SaleCampaign").
+		Where("sale_campaign_items.product_id IN ?", productIDs).
+		Where(`"SaleCampaign".is_active = ? AND "SaleCampaign".starts_at <= ? AND "SaleCampaign".ends_at > ?`, true, now, now).
+		Where(`("SaleCampaign".stock_cap IS NULL OR "SaleCampaign".sold_count < "SaleCampaign".stock_cap)`).
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// IncrementSold menambah jumlah terjual hanya jika kuota masih cukup.
+// Jumlah baris 0 berarti kuota kampanye sudah habis.
+func (r *saleCampaignRepository) IncrementSold(db *gorm.DB, campaignID uuid.UUID, quantity int) (int64, error) {
+	result := db.Model(&entity.SaleCampaign{}).
+		Where("id = ? AND (stock_cap IS NULL OR sold_count + ? <= stock_cap)", campaignID, quantity).
+		Update("sold_count", gorm.Expr("sold_count + ?", quantity))
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// ReleaseSoldByOrderID mengembalikan kuota promo dari order yang batal.
+func (r *saleCampaignRepository) ReleaseSoldByOrderID(db *gorm.DB, orderID uuid.UUID) error {
+	if err := db.Exec(`
+		UPDATE sale_campaigns SET sold_count = GREATEST(sale_campaigns.sold_count - sold.quantity, 0)
+		FROM (
+			SELECT sale_campaign_id, SUM(quantity) AS quantity
+			FROM order_items
+			WHERE order_id = ? AND sale_campaign_id IS NOT NULL AND deleted_at IS NULL
+			GROUP BY sale_campaign_id
+		) AS sold
+		WHERE sale_campaigns.id = sold.sale_campaign_id`, orderID).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// CountBoundariesBetween menghitung kampanye yang mulai atau berakhir dalam
+// rentang (from, to].
+func (r *saleCampaignRepository) CountBoundariesBetween(db *gorm.DB, from time.Time, to time.Time) (int64, error) {
+	var count int64
+	if err := db.Model(&entity.SaleCampaign{}).
+		Where("(starts_at > ? AND starts_at <= ?) OR (ends_at > ? AND ends_at <= ?)", from, to, from, to).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"mola-web/internal/entity"
+	"mola-web/internal/http/dto"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SearchLogRepository interface {
+	Create(db *gorm.DB, searchLog *entity.SearchLog) error
+	Count(ctx context.Context, since time.Time, zeroResult bool) (int64, error)
+	GetTopQueries(ctx context.Context, since time.Time, zeroResult bool, limit int) ([]dto.SearchQueryStat, error)
+}
+
+type searchLogRepository struct {
+	db *gorm.DB
+}
+
+func NewSearchLogRepository(db *gorm.DB) SearchLogRepository {
+	return &searchLogRepository{db}
+}
+
+func (r *searchLogRepository) Create(db *gorm.DB, searchLog *entity.SearchLog) error {
+	if err := db.Create(searchLog).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// Count jumlah pencarian sejak waktu tertentu, zeroResult hanya menghitung
+// pencarian yang tidak menemukan produk.
+func (r *searchLogRepository) Count(ctx context.Context, since time.Time, zeroResult bool) (int64, error) {
+	var total int64
+	db := r.db.WithContext(ctx).
+		Model(&entity.SearchLog{}).
+		Where("created_at >= ?", since)
+	if zeroResult {
+		db = db.Where("result_count = 0")
+	}
+	if err := db.Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// GetTopQueries query yang paling sering dicari sejak waktu tertentu.
+func (r *searchLogRepository) GetTopQueries(ctx context.Context, since time.Time, zeroResult bool, limit int) ([]dto.SearchQueryStat, error) {
+	results := []dto.SearchQueryStat{}
+	db := r.db.WithContext(ctx).
+		Model(&entity.SearchLog{}).
+		Select("query, COUNT(*) AS searches, MAX(created_at) AS last_searched_at").
+		Where("created_at >= ?", since)
+	if zeroResult {
+		db = db.Where("result_count = 0")
+	} else {
+		db = db.Where("result_count > 0")
+	}
+	if err := db.Group("query").
+		Order("searches DESC").
+		Order("last_searched_at DESC").
+		Limit(limit).
+		Scan(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
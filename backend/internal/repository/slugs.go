@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"mola-web/internal/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// slugTables tabel pemilik slug untuk setiap jenis entitas.
+var slugTables = map[string]string{
+	entity.SlugEntityProduct:  "public.products",
+	entity.SlugEntityCategory: "public.categories",
+}
+
+type SlugRepository interface {
+	// Resolve mencari entitas dari slug aktif atau slug lama, mengembalikan
+	// ID dan slug aktifnya. gorm.ErrRecordNotFound jika tidak ada.
+	Resolve(ctx context.Context, entityType string, slug string) (string, string, error)
+	Exists(db *gorm.DB, entityType string, slug string, excludeID string) (bool, error)
+	CreateRedirect(db *gorm.DB, redirect *entity.SlugRedirect) error
+	DeleteRedirect(db *gorm.DB, entityType string, slug string) error
+}
+
+type slugRepository struct {
+	db *gorm.DB
+}
+
+func NewSlugRepository(db *gorm.DB) SlugRepository {
+	return &slugRepository{db}
+}
+
+func slugTable(entityType string) (string, error) {
+	table, ok := slugTables[entityType]
+	if !ok {
+		return "", fmt.Errorf("unknown slug entity %q", entityType)
+	}
+	return table, nil
+}
+
+func (r *slugRepository) Resolve(ctx context.Context, entityType string, slug string) (string, string, error) {
+	table, err := slugTable(entityType)
+	if err != nil {
+		return "", "", err
+	}
+	var result struct {
+		ID   string
+		Slug string
+	}
+	// Slug aktif didahulukan daripada redirect
+	query := fmt.Sprintf(`SELECT id, slug FROM (
+			SELECT t.id::text AS id, t.slug, 0 AS priority FROM %[1]s t
+			WHERE t.slug = @slug AND t.deleted_at IS NULL
+			UNION ALL
+			SELECT t.id::text, t.slug, 1 FROM public.slug_redirects r
+			JOIN %[1]s t ON t.id::text = r.entity_id
+			WHERE r.entity_type = @type AND r.old_slug = @slug AND t.deleted_at IS NULL
+		) s ORDER BY priority LIMIT 1`, table)
+	tx := r.db.WithContext(ctx).Raw(query, map[string]interface{}{"slug": slug, "type": entityType}).Scan(&result)
+	if tx.Error != nil {
+		return "", "", tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return "", "", gorm.ErrRecordNotFound
+	}
+	return result.ID, result.Slug, nil
+}
+
+// Exists ikut memeriksa data yang sudah di-soft delete karena unique index
+// tetap berlaku untuk baris tersebut.
+func (r *slugRepository) Exists(db *gorm.DB, entityType string, slug string, excludeID string) (bool, error) {
+	table, err := slugTable(entityType)
+	if err != nil {
+		return false, err
+	}
+	var count int64
+	if err := db.Table(table).
+		Where("slug = ?", slug).
+		Where("id::text <> ?", excludeID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// CreateRedirect memindahkan slug lama ke entitas baru jika slug itu pernah
+// dipakai entitas lain.
+func (r *slugRepository) CreateRedirect(db *gorm.DB, redirect *entity.SlugRedirect) error {
+	if err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "entity_type"}, {Name: "old_slug"}},
+		DoUpdates: clause.AssignmentColumns([]string{"entity_id", "created_at"}),
+	}).Create(redirect).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *slugRepository) DeleteRedirect(db *gorm.DB, entityType string, slug string) error {
+	if err := db.Where("entity_type = ? AND old_slug = ?", entityType, slug).Delete(&entity.SlugRedirect{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
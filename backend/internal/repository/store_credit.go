@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"mola-web/internal/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StoreCreditRepository interface {
+	GetBalance(db *gorm.DB, userID uuid.UUID) (float64, error)
+	GetWalletForUpdate(db *gorm.DB, userID uuid.UUID) (*entity.StoreCreditWallet, error)
+	UpdateBalance(db *gorm.DB, userID uuid.UUID, balance float64) error
+	CreateTransaction(db *gorm.DB, entry *entity.StoreCreditTransaction) error
+	GetHistory(db *gorm.DB, userID uuid.UUID, limit int) ([]entity.StoreCreditTransaction, error)
+	GetByOrderID(db *gorm.DB, orderID uuid.UUID) ([]entity.StoreCreditTransaction, error)
+
+	CreateGiftCard(db *gorm.DB, card *entity.GiftCard) error
+	UpdateGiftCard(db *gorm.DB, card *entity.GiftCard) error
+	GetGiftCardByID(db *gorm.DB, id uuid.UUID) (*entity.GiftCard, error)
+	GetGiftCardForUpdate(db *gorm.DB, id uuid.UUID) (*entity.GiftCard, error)
+	GetGiftCardByCodeForUpdate(db *gorm.DB, code string) (*entity.GiftCard, error)
+	GetAllGiftCards(ctx context.Context) ([]entity.GiftCard, error)
+	GetGiftCardsByPurchaser(db *gorm.DB, userID uuid.UUID) ([]entity.GiftCard, error)
+}
+
+type storeCreditRepository struct {
+	db *gorm.DB
+}
+
+func NewStoreCreditRepository(db *gorm.DB) StoreCreditRepository {
+	return &storeCreditRepository{db}
+}
+
+func (r *storeCreditRepository) GetBalance(db *gorm.DB, userID uuid.UUID) (float64, error) {
+	var balance float64
+	if err := db.Model(&entity.StoreCreditWallet{}).
+		Select("COALESCE(SUM(balance), 0)").
+		Where("user_id = ?", userID).
+		Scan(&balance).Error; err != nil {
+		return 0, err
+	}
+	return balance, nil
+}
+
+// GetWalletForUpdate membuat dompet kosong jika belum ada lalu mengunci
+// barisnya sampai transaksi selesai.
+func (r *storeCreditRepository) GetWalletForUpdate(db *gorm.DB, userID uuid.UUID) (*entity.StoreCreditWallet, error) {
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&entity.StoreCreditWallet{UserID: userID}).Error; err != nil {
+		return nil, err
+	}
+	wallet := new(entity.StoreCreditWallet)
+	if err := db.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ?", userID).
+		First(wallet).Error; err != nil {
+		return nil, err
+	}
+	return wallet, nil
+}
+
+func (r *storeCreditRepository) UpdateBalance(db *gorm.DB, userID uuid.UUID, balance float64) error {
+	if err := db.Model(&entity.StoreCreditWallet{}).
+		Where("user_id = ?", userID).
+		Update("balance", balance).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *storeCreditRepository) CreateTransaction(db *gorm.DB, entry *entity.StoreCreditTransaction) error {
+	if err := db.Create(entry).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *storeCreditRepository) GetHistory(db *gorm.DB, userID uuid.UUID, limit int) ([]entity.StoreCreditTransaction, error) {
+	var entries []entity.StoreCreditTransaction
+	if err := db.
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *storeCreditRepository) GetByOrderID(db *gorm.DB, orderID uuid.UUID) ([]entity.StoreCreditTransaction, error) {
+	var entries []entity.StoreCreditTransaction
+	if err := db.
+		Where("order_id = ?", orderID).
+		Order("created_at ASC").
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *storeCreditRepository) CreateGiftCard(db *gorm.DB, card *entity.GiftCard) error {
+	if err := db.Create(card).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *storeCreditRepository) UpdateGiftCard(db *gorm.DB, card *entity.GiftCard) error {
+	if err := db.Model(card).
+		Select("*").
+		Omit("id", "created_at", clause.Associations).
+		Updates(card).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *storeCreditRepository) GetGiftCardByID(db *gorm.DB, id uuid.UUID) (*entity.GiftCard, error) {
+	card := new(entity.GiftCard)
+	if err := db.Where("id = ?", id).First(card).Error; err != nil {
+		return nil, err
+	}
+	return card, nil
+}
+
+func (r *storeCreditRepository) GetGiftCardForUpdate(db *gorm.DB, id uuid.UUID) (*entity.GiftCard, error) {
+	card := new(entity.GiftCard)
+	if err := db.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).
+		First(card).Error; err != nil {
+		return nil, err
+	}
+	return card, nil
+}
+
+func (r *storeCreditRepository) GetGiftCardByCodeForUpdate(db *gorm.DB, code string) (*entity.GiftCard, error) {
+	card := new(entity.GiftCard)
+	if err := db.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("UPPER(code) = UPPER(?)", code).
+		First(card).Error; err != nil {
+		return nil, err
+	}
+	return card, nil
+}
+
+func (r *storeCreditRepository) GetAllGiftCards(ctx context.Context) ([]entity.GiftCard, error) {
+	var cards []entity.GiftCard
+	if err := r.db.WithContext(ctx).
+		Preload("PurchasedBy").
+		Preload("RedeemedBy").
+		Order("created_at DESC").
+		Find(&cards).Error; err != nil {
+		return nil, err
+	}
+	return cards, nil
+}
+
+func (r *storeCreditRepository) GetGiftCardsByPurchaser(db *gorm.DB, userID uuid.UUID) ([]entity.GiftCard, error) {
+	var cards []entity.GiftCard
+	if err := db.
+		Where("purchased_by_id = ?", userID).
+		Order("created_at DESC").
+		Find(&cards).Error; err != nil {
+		return nil, err
+	}
+	return cards, nil
+}
@@ -4,12 +4,15 @@ import (
 	"context"
 	"mola-web/internal/entity"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type TransactionRepository interface {
 	GetAll(ctx context.Context) ([]entity.Payment, error)
 	CreatePayment(db *gorm.DB, payment *entity.Payment) error
+	GetByTransactionID(db *gorm.DB, transactionID string) (*entity.Payment, error)
+	GetSettledAmount(db *gorm.DB, orderID uuid.UUID) (float64, error)
 }
 
 type transactionRepository struct {
@@ -36,3 +39,27 @@ func (r *transactionRepository) CreatePayment(db *gorm.DB, payment *entity.Payme
 	}
 	return nil
 }
+
+func (r *transactionRepository) GetByTransactionID(db *gorm.DB, transactionID string) (*entity.Payment, error) {
+	var payment entity.Payment
+	if err := db.Where("transaction_id = ?", transactionID).First(&payment).Error; err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// GetSettledAmount menjumlahkan nominal yang benar-benar diterima lewat
+// Midtrans untuk order. Notifikasi bisa datang lebih dari sekali untuk
+// transaksi yang sama, jadi setiap transaction_id hanya dihitung sekali.
+func (r *transactionRepository) GetSettledAmount(db *gorm.DB, orderID uuid.UUID) (float64, error) {
+	var amount float64
+	if err := db.Raw(`
+		SELECT COALESCE(SUM(amount), 0) FROM (
+			SELECT MAX(amount) AS amount FROM payments
+			WHERE order_id = ? AND transaction_status IN ('settlement', 'capture') AND deleted_at IS NULL
+			GROUP BY transaction_id
+		) settled`, orderID).Scan(&amount).Error; err != nil {
+		return 0, err
+	}
+	return amount, nil
+}
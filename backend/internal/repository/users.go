@@ -15,6 +15,9 @@ type UserRepository interface {
 	FindByResetToken(ctx context.Context, resetToken string) (*entity.User, error)
 	GetUserProfile(ctx context.Context, userID uuid.UUID) (*entity.User, error)
 	UpdateUser(db *gorm.DB, user *entity.User) error
+	SetCustomerGroup(db *gorm.DB, userID uuid.UUID, group *string) error
+	FindByReferralCode(db *gorm.DB, code string) (*entity.User, error)
+	SetReferralCode(db *gorm.DB, userID uuid.UUID, code string) error
 	// UpdateUserProfile(db *gorm.DB, userProfile *entity.UserProfile) error
 	// GetUserAddress(ctx context.Context, userID uuid.UUID) (*entity.UserAddress, error)
 	// UpdateUserAddress(db *gorm.DB, userAddress *entity.UserAddress) error
@@ -65,6 +68,22 @@ func (r *userRepository) GetUserProfile(ctx context.Context, userID uuid.UUID) (
 func (r *userRepository) UpdateUser(db *gorm.DB, user *entity.User) error {
 	return db.Save(user).Error
 }
+
+func (r *userRepository) FindByReferralCode(db *gorm.DB, code string) (*entity.User, error) {
+	user := new(entity.User)
+	if err := db.Where("referral_code = ?", code).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (r *userRepository) SetReferralCode(db *gorm.DB, userID uuid.UUID, code string) error {
+	return db.Model(&entity.User{}).Where("id = ?", userID).Update("referral_code", code).Error
+}
+
+func (r *userRepository) SetCustomerGroup(db *gorm.DB, userID uuid.UUID, group *string) error {
+	return db.Model(&entity.User{}).Where("id = ?", userID).Update("customer_group", group).Error
+}
 // func (r *userRepository) UpdateUserProfile(db *gorm.DB, userProfile *entity.UserProfile) error {
 // 	return db.Where("user_id = ?", userProfile.UserID).Save(userProfile).Error
 // }
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"mola-web/internal/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type VoucherRepository interface {
+	GetAll(ctx context.Context) ([]entity.Voucher, error)
+	GetByID(db *gorm.DB, id uuid.UUID) (*entity.Voucher, error)
+	GetByCode(db *gorm.DB, code string) (*entity.Voucher, error)
+	GetByCodeForUpdate(db *gorm.DB, code string) (*entity.Voucher, error)
+	Create(db *gorm.DB, voucher *entity.Voucher) error
+	Update(db *gorm.DB, voucher *entity.Voucher) error
+	ReplaceScopes(db *gorm.DB, voucherID uuid.UUID, products []entity.VoucherProduct, categories []entity.VoucherCategory) error
+	Delete(db *gorm.DB, id uuid.UUID) error
+	CountRedemptions(db *gorm.DB, voucherID uuid.UUID) (int64, error)
+	CountUserRedemptions(db *gorm.DB, voucherID uuid.UUID, userID uuid.UUID) (int64, error)
+	CreateRedemption(db *gorm.DB, redemption *entity.VoucherRedemption) error
+	CancelRedemptionsByOrderID(db *gorm.DB, orderID uuid.UUID) error
+}
+
+type voucherRepository struct {
+	db *gorm.DB
+}
+
+func NewVoucherRepository(db *gorm.DB) VoucherRepository {
+	return &voucherRepository{db}
+}
+
+func (r *voucherRepository) GetAll(ctx context.Context) ([]entity.Voucher, error) {
+	var vouchers []entity.Voucher
+	if err := r.db.WithContext(ctx).
+		Preload("Products").
+		Preload("Categories").
+		Order("created_at DESC").
+		Find(&vouchers).Error; err != nil {
+		return nil, err
+	}
+	return vouchers, nil
+}
+
+func (r *voucherRepository) GetByID(db *gorm.DB, id uuid.UUID) (*entity.Voucher, error) {
+	var voucher entity.Voucher
+	if err := db.Where("id = ?", id).First(&voucher).Error; err != nil {
+		return nil, err
+	}
+	return &voucher, nil
+}
+
+func (r *voucherRepository) GetByCode(db *gorm.DB, code string) (*entity.Voucher, error) {
+	var voucher entity.Voucher
+	if err := db.
+		Preload("Products").
+		Preload("Categories").
+		Where("code = ?", code).
+		First(&voucher).Error; err != nil {
+		return nil, err
+	}
+	return &voucher, nil
+}
+
+// GetByCodeForUpdate mengunci baris voucher sampai transaksi selesai agar
+// batas pemakaian tidak terlampaui oleh checkout bersamaan.
+func (r *voucherRepository) GetByCodeForUpdate(db *gorm.DB, code string) (*entity.Voucher, error) {
+	var voucher entity.Voucher
+	if err := db.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("code = ?", code).
+		First(&voucher).Error; err != nil {
+		return nil, err
+	}
+	return &voucher, nil
+}
+
+func (r *voucherRepository) Create(db *gorm.DB, voucher *entity.Voucher) error {
+	if err := db.Create(voucher).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *voucherRepository) Update(db *gorm.DB, voucher *entity.Voucher) error {
+	// Select("*") agar field kosong (mis. is_active=false, max_discount=nil) ikut tersimpan
+	if err := db.Model(&entity.Voucher{}).
+		Where("id = ?", voucher.ID).
+		Select("*").
+		Omit("id", "created_at", "deleted_at", clause.Associations).
+		Updates(voucher).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *voucherRepository) ReplaceScopes(db *gorm.DB, voucherID uuid.UUID, products []entity.VoucherProduct, categories []entity.VoucherCategory) error {
+	if err := db.Where("voucher_id = ?", voucherID).Delete(&entity.VoucherProduct{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("voucher_id = ?", voucherID).Delete(&entity.VoucherCategory{}).Error; err != nil {
+		return err
+	}
+	if len(products) > 0 {
+		if err := db.Create(&products).Error; err != nil {
+			return err
+		}
+	}
+	if len(categories) > 0 {
+		if err := db.Create(&categories).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *voucherRepository) Delete(db *gorm.DB, id uuid.UUID) error {
+	if err := db.Delete(&entity.Voucher{}, "id = ?", id).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *voucherRepository) CountRedemptions(db *gorm.DB, voucherID uuid.UUID) (int64, error) {
+	var count int64
+	if err := db.Model(&entity.VoucherRedemption{}).
+		Where("voucher_id = ? AND status <> ?", voucherID, "cancelled").
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *voucherRepository) CountUserRedemptions(db *gorm.DB, voucherID uuid.UUID, userID uuid.UUID) (int64, error) {
+	var count int64
+	if err := db.Model(&entity.VoucherRedemption{}).
+		Where("voucher_id = ? AND user_id = ? AND status <> ?", voucherID, userID, "cancelled").
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *voucherRepository) CreateRedemption(db *gorm.DB, redemption *entity.VoucherRedemption) error {
+	if err := db.Create(redemption).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *voucherRepository) CancelRedemptionsByOrderID(db *gorm.DB, orderID uuid.UUID) error {
+	if err := db.Model(&entity.VoucherRedemption{}).
+		Where("order_id = ? AND status <> ?", orderID, "cancelled").
+		Update("status", "cancelled").Error; err != nil {
+		return err
+	}
+	return nil
+}
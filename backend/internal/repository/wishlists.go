@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"mola-web/internal/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WishlistRepository interface {
+	Create(db *gorm.DB, wishlist *entity.Wishlist) error
+	GetByUserID(db *gorm.DB, userID uuid.UUID) (*entity.Wishlist, error)
+	GetItemsByUserID(db *gorm.DB, userID uuid.UUID) (*entity.Wishlist, error)
+	GetItemsByShareToken(db *gorm.DB, shareToken string) (*entity.Wishlist, error)
+	UpdateShareToken(db *gorm.DB, wishlistID uuid.UUID, shareToken *string) error
+	GetItemByID(db *gorm.DB, wishlistID uuid.UUID, itemID uuid.UUID) (*entity.WishlistItem, error)
+	GetItemByProduct(db *gorm.DB, wishlistID uuid.UUID, productID uuid.UUID, variantID *uuid.UUID) (*entity.WishlistItem, error)
+	AddItem(db *gorm.DB, item *entity.WishlistItem) error
+	RemoveItem(db *gorm.DB, itemID uuid.UUID) error
+}
+
+type wishlistRepository struct {
+	db *gorm.DB
+}
+
+func NewWishlistRepository(db *gorm.DB) WishlistRepository {
+	return &wishlistRepository{db}
+}
+
+func (r *wishlistRepository) Create(db *gorm.DB, wishlist *entity.Wishlist) error {
+	if err := db.Create(wishlist).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *wishlistRepository) GetByUserID(db *gorm.DB, userID uuid.UUID) (*entity.Wishlist, error) {
+	var wishlist entity.Wishlist
+	if err := db.Where("user_id = ?", userID).First(&wishlist).Error; err != nil {
+		return nil, err
+	}
+	return &wishlist, nil
+}
+
+func preloadWishlistItems(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("wishlist_items.created_at DESC")
+		}).
+		Preload("Items.Product").
+		Preload("Items.Product.Category").
+		Preload("Items.ProductVariant").
+		Preload("Items.ProductVariant.Color").
+		Preload("Items.ProductVariant.Size").
+		Preload("Items.ProductVariant.Image")
+}
+
+func (r *wishlistRepository) GetItemsByUserID(db *gorm.DB, userID uuid.UUID) (*entity.Wishlist, error) {
+	var wishlist entity.Wishlist
+	if err := preloadWishlistItems(db).Where("user_id = ?", userID).First(&wishlist).Error; err != nil {
+		return nil, err
+	}
+	return &wishlist, nil
+}
+
+func (r *wishlistRepository) GetItemsByShareToken(db *gorm.DB, shareToken string) (*entity.Wishlist, error) {
+	var wishlist entity.Wishlist
+	if err := preloadWishlistItems(db).Preload("User").Where("share_token = ?", shareToken).First(&wishlist).Error; err != nil {
+		return nil, err
+	}
+	return &wishlist, nil
+}
+
+func (r *wishlistRepository) UpdateShareToken(db *gorm.DB, wishlistID uuid.UUID, shareToken *string) error {
+	if err := db.Model(&entity.Wishlist{}).Where("id = ?", wishlistID).Update("share_token", shareToken).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *wishlistRepository) GetItemByID(db *gorm.DB, wishlistID uuid.UUID, itemID uuid.UUID) (*entity.WishlistItem, error) {
+	var item entity.WishlistItem
+	if err := db.Where("id = ? AND wishlist_id = ?", itemID, wishlistID).First(&item).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *wishlistRepository) GetItemByProduct(db *gorm.DB, wishlistID uuid.UUID, productID uuid.UUID, variantID *uuid.UUID) (*entity.WishlistItem, error) {
+	var item entity.WishlistItem
+	query := db.Where("wishlist_id = ? AND product_id = ?", wishlistID, productID)
+	if variantID != nil {
+		query = query.Where("product_variant_id = ?", *variantID)
+	} else {
+		query = query.Where("product_variant_id IS NULL")
+	}
+	if err := query.First(&item).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *wishlistRepository) AddItem(db *gorm.DB, item *entity.WishlistItem) error {
+	if err := db.Create(item).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *wishlistRepository) RemoveItem(db *gorm.DB, itemID uuid.UUID) error {
+	if err := db.Delete(&entity.WishlistItem{}, "id = ?", itemID).Error; err != nil {
+		return err
+	}
+	return nil
+}
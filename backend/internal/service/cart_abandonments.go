@@ -0,0 +1,313 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"mola-web/configs"
+	"mola-web/internal/entity"
+	"mola-web/internal/http/dto"
+	"mola-web/internal/repository"
+	"mola-web/pkg/cache"
+	"net/smtp"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// maxCartReminders adalah batas atas pengingat per keranjang terbengkalai,
+// berapapun nilai konfigurasi.
+const maxCartReminders = 2
+
+type CartAbandonmentService interface {
+	MarkAbandonedCarts(ctx context.Context) error
+	SendReminders(ctx context.Context) error
+	RecordConversion(db *gorm.DB, cartID uuid.UUID, orderID uuid.UUID) error
+	GetReport(ctx context.Context, start string, end string) (*dto.AbandonedCartReport, error)
+}
+
+type cartAbandonmentService struct {
+	DB              *gorm.DB
+	abandonmentRepo repository.CartAbandonmentRepository
+	cartRepo        repository.CartRepository
+	cacheable       cache.Cacheable
+	config          configs.AbandonedCartConfig
+	SMTPConfigs     configs.SMPTGmailConfig
+}
+
+func NewCartAbandonmentService(db *gorm.DB, abandonmentRepo repository.CartAbandonmentRepository, cartRepo repository.CartRepository, cacheable cache.Cacheable, config configs.AbandonedCartConfig, smtpConfigs configs.SMPTGmailConfig) CartAbandonmentService {
+	return &cartAbandonmentService{
+		DB:              db,
+		abandonmentRepo: abandonmentRepo,
+		cartRepo:        cartRepo,
+		cacheable:       cacheable,
+		config:          config,
+		SMTPConfigs:     smtpConfigs,
+	}
+}
+
+func (s *cartAbandonmentService) maxReminders() int {
+	if s.config.MaxReminders < maxCartReminders {
+		return s.config.MaxReminders
+	}
+	return maxCartReminders
+}
+
+func (s *cartAbandonmentService) MarkAbandonedCarts(ctx context.Context) error {
+	now := time.Now()
+	idleSince := now.Add(-time.Duration(s.config.AfterHours) * time.Hour)
+
+	carts, err := s.abandonmentRepo.FindIdleCarts(s.DB.WithContext(ctx), idleSince)
+	if err != nil {
+		return err
+	}
+
+	for _, cart := range carts {
+		var total float64
+		for _, item := range cart.CartItems {
+			if item.Product != nil {
+				total += variantPrice(item.Product, item.ProductVariant) * float64(item.Quantity)
+			}
+		}
+
+		err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := s.cartRepo.UpdateCart(tx, &entity.Cart{ID: cart.ID, Status: "abandoned"}); err != nil {
+				return err
+			}
+			return s.abandonmentRepo.Create(tx, &entity.CartAbandonment{
+				CartID:      cart.ID,
+				UserID:      cart.UserID,
+				CartTotal:   total,
+				AbandonedAt: now,
+			})
+		})
+		if err != nil {
+			log.Printf("ERROR: failed to mark cart %s as abandoned: %v", cart.ID, err)
+			continue
+		}
+		_ = s.cacheable.Delete("carts:" + cart.UserID.String())
+	}
+
+	return nil
+}
+
+func (s *cartAbandonmentService) SendReminders(ctx context.Context) error {
+	limit := s.maxReminders()
+	if limit <= 0 {
+		return nil
+	}
+	remindBefore := time.Now().Add(-time.Duration(s.config.ReminderIntervalHours) * time.Hour)
+
+	abandonments, err := s.abandonmentRepo.FindDueReminders(s.DB.WithContext(ctx), remindBefore, limit)
+	if err != nil {
+		return err
+	}
+
+	for _, abandonment := range abandonments {
+		if abandonment.User == nil || abandonment.Cart == nil || len(abandonment.Cart.CartItems) == 0 {
+			continue
+		}
+		if err := s.sendReminder(ctx, &abandonment); err != nil {
+			log.Printf("ERROR: failed to send cart reminder for %s: %v", abandonment.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// sendReminder mencatat pengingat lebih dulu lalu mengirim email setelah
+// transaksi selesai, supaya koneksi SMTP tidak menahan lock baris.
+func (s *cartAbandonmentService) sendReminder(ctx context.Context, abandonment *entity.CartAbandonment) error {
+	sequence := abandonment.RemindersSent + 1
+	msg, err := s.buildReminderEmail(abandonment.User, abandonment.Cart.CartItems, sequence)
+	if err != nil {
+		return err
+	}
+
+	tx := s.DB.WithContext(ctx).Begin()
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			log.Printf("PANIC RECOVERED: Rolling back transaction due to panic: %v", p)
+			panic(p)
+		} else if tx.Error != nil {
+			tx.Rollback()
+			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
+		}
+	}()
+
+	now := time.Now()
+
+	// Catat dulu sebelum kirim, unique index mencegah pengingat ganda
+	reminder := &entity.CartReminder{
+		AbandonmentID: abandonment.ID,
+		Sequence:      sequence,
+		Email:         abandonment.User.Email,
+		Status:        "sent",
+		SentAt:        now,
+	}
+	if err := s.abandonmentRepo.CreateReminder(tx, reminder); err != nil {
+		tx.Error = err
+		return err
+	}
+	if err := s.abandonmentRepo.Update(tx, &entity.CartAbandonment{
+		ID:             abandonment.ID,
+		RemindersSent:  sequence,
+		LastRemindedAt: &now,
+	}); err != nil {
+		tx.Error = err
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Error = err
+		return err
+	}
+
+	// Pengingat yang gagal tetap dihitung agar tidak dikirim berulang kali
+	if err := s.deliverReminderEmail(abandonment.User.Email, msg); err != nil {
+		if markErr := s.abandonmentRepo.MarkReminderFailed(s.DB.WithContext(ctx), reminder.ID, err.Error()); markErr != nil {
+			log.Printf("ERROR: failed to mark cart reminder %s as failed: %v", reminder.ID, markErr)
+		}
+		return err
+	}
+
+	log.Println("Email pengingat keranjang dikirim ke", abandonment.User.Email)
+	return nil
+}
+
+func (s *cartAbandonmentService) buildReminderEmail(user *entity.User, cartItems []entity.CartItem, sequence int) ([]byte, error) {
+	// Load template HTML
+	tmpl, err := template.ParseFiles(s.config.TemplatePath)
+	if err != nil {
+		return nil, err
+	}
+
+	data := dto.AbandonedCartEmail{
+		Name:     user.Name,
+		CartURL:  s.config.CartURL,
+		Sequence: sequence,
+	}
+	var total float64
+	for _, item := range cartItems {
+		if item.Product == nil {
+			continue
+		}
+		name := item.Product.Name
+		if item.ProductVariant != nil {
+			if item.ProductVariant.Color != nil {
+				name += " - " + item.ProductVariant.Color.Name
+			}
+			if item.ProductVariant.Size != nil {
+				name += " - " + item.ProductVariant.Size.Name
+			}
+		}
+		price := variantPrice(item.Product, item.ProductVariant)
+		subtotal := price * float64(item.Quantity)
+		total += subtotal
+		data.Items = append(data.Items, dto.AbandonedCartEmailItem{
+			Name:     name,
+			Quantity: item.Quantity,
+			Price:    formatRupiah(price),
+			Subtotal: formatRupiah(subtotal),
+		})
+	}
+	if len(data.Items) == 0 {
+		return nil, errors.New("cart has no available items")
+	}
+	data.TotalAmount = formatRupiah(total)
+
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, data); err != nil {
+		return nil, err
+	}
+
+	subject := "Subject: 🛒 Keranjang Anda masih menunggu\r\n"
+	if sequence > 1 {
+		subject = "Subject: ⏰ Jangan lewatkan barang di keranjang Anda\r\n"
+	}
+	mime := "MIME-version: 1.0;\r\nContent-Type: text/html; charset=\"UTF-8\";\r\n\r\n"
+	return []byte(subject + mime + "\r\n" + body.String()), nil
+}
+
+func (s *cartAbandonmentService) deliverReminderEmail(to string, msg []byte) error {
+	from := s.SMTPConfigs.Email
+	password := s.SMTPConfigs.Password
+
+	// Konfigurasi SMTP Gmail
+	smtpHost := "smtp.gmail.com"
+	smtpPort := "587"
+	auth := smtp.PlainAuth("", from, password, smtpHost)
+
+	return smtp.SendMail(smtpHost+":"+smtpPort, auth, from, []string{to}, msg)
+}
+
+// RecordConversion menandai keranjang terbengkalai sebagai terkonversi jika
+// checkout terjadi dalam jendela konversi.
+func (s *cartAbandonmentService) RecordConversion(db *gorm.DB, cartID uuid.UUID, orderID uuid.UUID) error {
+	now := time.Now()
+	since := now.Add(-time.Duration(s.config.ConversionWindowHours) * time.Hour)
+
+	abandonment, err := s.abandonmentRepo.FindLatestUnconverted(db, cartID, since)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	return s.abandonmentRepo.Update(db, &entity.CartAbandonment{
+		ID:          abandonment.ID,
+		ConvertedAt: &now,
+		OrderID:     &orderID,
+	})
+}
+
+func (s *cartAbandonmentService) GetReport(ctx context.Context, start string, end string) (*dto.AbandonedCartReport, error) {
+	endDate := time.Now()
+	if end != "" {
+		parsed, err := time.Parse("2006-01-02", end)
+		if err != nil {
+			return nil, errors.New("invalid end date, use YYYY-MM-DD")
+		}
+		endDate = parsed
+	}
+	startDate := endDate.AddDate(0, 0, -30)
+	if start != "" {
+		parsed, err := time.Parse("2006-01-02", start)
+		if err != nil {
+			return nil, errors.New("invalid start date, use YYYY-MM-DD")
+		}
+		startDate = parsed
+	}
+	if startDate.After(endDate) {
+		return nil, errors.New("start date must be before end date")
+	}
+
+	// Tanggal akhir inklusif
+	report, err := s.abandonmentRepo.GetReport(ctx, startDate, endDate.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	report.Start = startDate.Format("2006-01-02")
+	report.End = endDate.Format("2006-01-02")
+	if report.AbandonedCarts > 0 {
+		report.ConversionRate = float64(report.ConvertedCarts) / float64(report.AbandonedCarts) * 100
+	}
+	return report, nil
+}
+
+func formatRupiah(amount float64) string {
+	digits := fmt.Sprintf("%.0f", amount)
+	var out []byte
+	for i := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			out = append(out, '.')
+		}
+		out = append(out, digits[i])
+	}
+	return "Rp" + string(out)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"mola-web/configs"
 	"mola-web/internal/entity"
@@ -11,6 +12,7 @@ import (
 	"mola-web/internal/repository"
 	"mola-web/pkg/cache"
 	"mola-web/pkg/token"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -18,31 +20,61 @@ import (
 
 type CartService interface {
 	AddToCart(ctx context.Context, userID uuid.UUID, req *dto.AddToCartRequest) error
+	AddItem(db *gorm.DB, userID uuid.UUID, req *dto.AddToCartRequest) error
 	GetCartByUserID(db *gorm.DB, userID uuid.UUID) (*dto.GetCartItemsResponse, error)
+	GetSelectedCart(db *gorm.DB, userID uuid.UUID, selectedItems []uuid.UUID) (*dto.GetCartItemsResponse, error)
 	UpdateCartItem(ctx context.Context, userID uuid.UUID, req *dto.UpdateCartItemRequest) error
 	RemoveCartItem(ctx context.Context, userID uuid.UUID, req uuid.UUID) error
+	RemoveItem(db *gorm.DB, userID uuid.UUID, cartItemID uuid.UUID) error
+	ValidateCart(ctx context.Context, userID uuid.UUID, req *dto.ValidateCartRequest) (*dto.CartValidationResponse, error)
+	CheckCart(db *gorm.DB, userID uuid.UUID, selectedItems []uuid.UUID) (*dto.CartValidationResponse, error)
 }
+
+const (
+	CartItemStatusOK                = "ok"
+	CartItemStatusPriceChanged      = "price_changed"
+	CartItemStatusInsufficientStock = "insufficient_stock"
+	CartItemStatusUnavailable       = "unavailable"
+	CartItemStatusVariantRequired   = "variant_required"
+)
+
+var ErrCartItemNotFound = errors.New("cart item not found")
+
+// CartValidationError dikembalikan ketika keranjang tidak lolos validasi.
+// Result berisi status per item agar client bisa memperbaikinya.
+type CartValidationError struct {
+	Result *dto.CartValidationResponse
+}
+
+func (e *CartValidationError) Error() string {
+	return "cart validation failed"
+}
+
 type cartService struct {
-	DB          *gorm.DB
-	cartRepo    repository.CartRepository
-	orderRepo   repository.OrderRepository
-	productRepo repository.ProductRepository
-	variantRepo repository.ProductVariantRepository
-	cacheable   cache.Cacheable
-	token       token.TokenUseCase
-	config      configs.MidtransConfig
+	DB                  *gorm.DB
+	cartRepo            repository.CartRepository
+	orderRepo           repository.OrderRepository
+	productRepo         repository.ProductRepository
+	variantRepo         repository.ProductVariantRepository
+	voucherService      VoucherService
+	saleCampaignService SaleCampaignService
+	cacheable           cache.Cacheable
+	token               token.TokenUseCase
+	config              configs.MidtransConfig
 }
 
-func NewCartService(db *gorm.DB, cartRepo repository.CartRepository, orderRepo repository.OrderRepository, productRepo repository.ProductRepository, variantRepo repository.ProductVariantRepository, tokenUseCase token.TokenUseCase, cacheable cache.Cacheable, config configs.MidtransConfig) CartService {
+func NewCartService(db *gorm.DB, cartRepo repository.CartRepository, orderRepo repository.OrderRepository, productRepo repository.ProductRepository, variantRepo repository.ProductVariantRepository, voucherService VoucherService, saleCampaignService SaleCampaignService, tokenUseCase token.TokenUseCase, cacheable cache.Cacheable, config configs.MidtransConfig) CartService {
 	return &cartService{
-		DB:          db,
-		cartRepo:    cartRepo,
-		orderRepo:   orderRepo,
-		productRepo: productRepo,
-		cacheable:   cacheable,
-		token:       tokenUseCase,
-		config:      config,
-		variantRepo: variantRepo,
+		DB:                  db,
+		cartRepo:            cartRepo,
+		orderRepo:           orderRepo,
+		productRepo:         productRepo,
+		voucherService:      voucherService,
+		saleCampaignService: saleCampaignService,
+		cacheable:           cacheable,
+		token:               tokenUseCase,
+		config:              config,
+		variantRepo:         variantRepo,
 	}
 }
 
@@ -59,70 +91,124 @@ func (s *cartService) AddToCart(ctx context.Context, userID uuid.UUID, req *dto.
 		}
 	}()
 
+	if err := s.AddItem(tx, userID, req); err != nil {
+		tx.Error = err
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Error = err
+		return err
+	}
+
+	_ = s.cacheable.Delete("carts:" + userID.String())
+
+	return nil
+}
+
+// AddItem menambahkan item ke keranjang user memakai transaksi pemanggil.
+// Cache keranjang dihapus pemanggil setelah commit.
+func (s *cartService) AddItem(db *gorm.DB, userID uuid.UUID, req *dto.AddToCartRequest) error {
 	// Ambil atau buat keranjang
-	cart, err := s.cartRepo.GetCartByUserID(tx, userID)
+	cart, err := s.cartRepo.GetCartByUserID(db, userID)
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		cart = &entity.Cart{
 			UserID: userID,
 			Status: "active",
 		}
-		if err := s.cartRepo.AddToCart(tx, userID, cart); err != nil {
-			tx.Error = err
+		if err := s.cartRepo.AddToCart(db, userID, cart); err != nil {
 			return err
 		}
 	} else if err != nil {
 		return err
 	}
+	if err := s.cartRepo.ReactivateCart(db, userID); err != nil {
+		return err
+	}
 
 	// Validasi produk
-	product, err := s.productRepo.GetByID(tx, req.ProductID)
+	product, err := getVisibleProduct(db, s.productRepo, req.ProductID)
+	if err != nil {
+		return err
+	}
+	// Harga disimpan sesuai promo dan tier grosir yang berlaku saat ini
+	sales, err := s.saleCampaignService.PriceIndex(db, []uuid.UUID{product.ID})
 	if err != nil {
 		return err
 	}
+	group := s.customerGroup(db, userID)
 
 	var stockAvailable int
 	var variantID *uuid.UUID
 
-	if product.HasVariant {
+	if isBundle(product) {
+		// Bundle: pembeli memilih varian untuk setiap komponen
+		components, err := resolveBundleSelections(product, req.BundleSelections)
+		if err != nil {
+			return err
+		}
+		stockAvailable = bundleStock(product, components)
+
+		cartItemsData, err := findBundleCartItem(db, s.cartRepo, cart.ID, req.ProductID, components)
+		if err != nil {
+			return err
+		}
+		if cartItemsData == nil {
+			cartItem := &entity.CartItem{
+				CartID:     cart.ID,
+				ProductID:  req.ProductID,
+				Quantity:   req.Quantity,
+				Price:      unitPrice(product, nil, req.Quantity, group, sales),
+				Note:       req.Note,
+				Components: components,
+			}
+			if err := s.cartRepo.AddToCartItems(db, cartItem); err != nil {
+				return err
+			}
+		} else {
+			cartItemsData.Quantity += req.Quantity
+			cartItemsData.Price = unitPrice(product, nil, cartItemsData.Quantity, group, sales)
+			cartItemsData.Components = nil
+			if err := s.cartRepo.UpdateCartItems(db, cartItemsData); err != nil {
+				return err
+			}
+		}
+
+	} else if product.HasVariant {
 		// Produk memiliki varian, pastikan variant ID tersedia
 		if req.ProductVariantID == nil {
-			err = errors.New("product variant ID required")
-			tx.Error = err
-			return err
+			return errors.New("product variant ID required")
 		}
 
-		variant, err := s.variantRepo.GetByID(tx, *req.ProductVariantID)
+		variant, err := s.variantRepo.GetByID(db, *req.ProductVariantID)
 		if err != nil {
-			tx.Error = err
 			return err
 		}
 		if variant.ProductID != req.ProductID {
-			err = errors.New("variant does not belong to this product")
-			tx.Error = err
-			return err
+			return errors.New("variant does not belong to this product")
 		}
 
 		stockAvailable = variant.Stock
 		variantID = &variant.ID
-		cartItemsData, err := s.cartRepo.GetCartItemByCartIDAndProductIDAndVariantID(tx, cart.ID, req.ProductID, *variantID)
+		cartItemsData, err := s.cartRepo.GetCartItemByCartIDAndProductIDAndVariantID(db, cart.ID, req.ProductID, *variantID)
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			cartItem := &entity.CartItem{
 				CartID:           cart.ID,
 				ProductID:        req.ProductID,
 				ProductVariantID: variantID,
 				Quantity:         req.Quantity,
+				Price:            unitPrice(product, variant, req.Quantity, group, sales),
 				Note:             req.Note,
 			}
-			if err := s.cartRepo.AddToCartItems(tx, cartItem); err != nil {
-				tx.Error = err
+			if err := s.cartRepo.AddToCartItems(db, cartItem); err != nil {
 				return err
 			}
 		} else if err != nil {
 			return err
 		} else {
 			cartItemsData.Quantity += req.Quantity
-			if err := s.cartRepo.UpdateCartItems(tx, cartItemsData); err != nil {
-				tx.Error = err
+			cartItemsData.Price = unitPrice(product, variant, cartItemsData.Quantity, group, sales)
+			if err := s.cartRepo.UpdateCartItems(db, cartItemsData); err != nil {
 				return err
 			}
 		}
@@ -131,55 +217,58 @@ func (s *cartService) AddToCart(ctx context.Context, userID uuid.UUID, req *dto.
 		// Produk tanpa varian
 		stockAvailable = product.Stock
 
-		cartItemsData, err := s.cartRepo.GetCartItemByCartIDAndProductIDWithoutVariant(tx, cart.ID, req.ProductID)
+		cartItemsData, err := s.cartRepo.GetCartItemByCartIDAndProductIDWithoutVariant(db, cart.ID, req.ProductID)
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			cartItem := &entity.CartItem{
 				CartID:    cart.ID,
 				ProductID: req.ProductID,
 				Quantity:  req.Quantity,
+				Price:     unitPrice(product, nil, req.Quantity, group, sales),
 				Note:      req.Note,
 			}
-			if err := s.cartRepo.AddToCartItems(tx, cartItem); err != nil {
-				tx.Error = err
+			if err := s.cartRepo.AddToCartItems(db, cartItem); err != nil {
 				return err
 			}
 		} else if err != nil {
 			return err
 		} else {
 			cartItemsData.Quantity += req.Quantity
-			if err := s.cartRepo.UpdateCartItems(tx, cartItemsData); err != nil {
-				tx.Error = err
+			cartItemsData.Price = unitPrice(product, nil, cartItemsData.Quantity, group, sales)
+			if err := s.cartRepo.UpdateCartItems(db, cartItemsData); err != nil {
 				return err
 			}
 		}
 
 	}
 	if req.Quantity > stockAvailable {
-		err = errors.New("stock not enough")
-		tx.Error = err
-		return err
+		return errors.New("stock not enough")
 	}
+	return repriceCartLines(db, s.cartRepo, cart.ID, product, group, sales)
+}
 
-	if err := tx.Commit().Error; err != nil {
-		tx.Error = err
-		return err
+// pendingPaymentCart mengembalikan keranjang kosong berisi link pembayaran
+// jika user masih punya transaksi pending.
+func (s *cartService) pendingPaymentCart(db *gorm.DB, userID uuid.UUID) (*dto.GetCartItemsResponse, error) {
+	dataPayment, err := s.orderRepo.GetPendingPaymentStatusByUserID(db, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
 	}
-
-	_ = s.cacheable.Delete("carts:" + userID.String())
-
-	return nil
+	if dataPayment.PaymentStatus != "pending" {
+		return nil, nil
+	}
+	return &dto.GetCartItemsResponse{
+		PaymentUrl:    dataPayment.PaymentUrl,
+		TokenMidtrans: dataPayment.TokenMidtrans,
+	}, nil
 }
+
 func (s *cartService) GetCartByUserID(db *gorm.DB, userID uuid.UUID) (*dto.GetCartItemsResponse, error) {
 	// Cek apakah user memiliki transaksi pending
-	dataPayment, err := s.orderRepo.GetPendingPaymentStatusByUserID(db, userID)
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, err
-	}
-	if err == nil && dataPayment.PaymentStatus == "pending" {
-		return &dto.GetCartItemsResponse{
-			PaymentUrl:    dataPayment.PaymentUrl,
-			TokenMidtrans: dataPayment.TokenMidtrans,
-		}, nil
+	pending, err := s.pendingPaymentCart(db, userID)
+	if err != nil || pending != nil {
+		return pending, err
 	}
 
 	// Cek cache
@@ -201,75 +290,159 @@ func (s *cartService) GetCartByUserID(db *gorm.DB, userID uuid.UUID) (*dto.GetCa
 		return nil, err
 	}
 
-	// Bangun response
+	// Bangun response dengan harga promo yang sedang berlaku
+	sales, err := s.saleCampaignService.PriceIndex(db, cartProductIDs(res.CartItems))
+	if err != nil {
+		return nil, err
+	}
+	result := buildCartResponse(res.ID, res.CartItems, sales, customerGroupOf(res.User))
+
+	// Terapkan voucher yang tersimpan di keranjang
+	if res.VoucherCode != nil {
+		discount, err := s.voucherService.CalculateDiscount(db, *res.VoucherCode, userID, result.CartItems)
+		if err != nil {
+			result.VoucherCode = res.VoucherCode
+			result.VoucherError = err.Error()
+		} else {
+			applyVoucherDiscount(result, discount)
+		}
+	}
+
+	// Simpan ke cache
+	if cached, err := json.Marshal(result); err == nil {
+		_ = s.cacheable.Set(key, cached)
+	}
+
+	return result, nil
+}
+
+// GetSelectedCart membangun keranjang dari item yang dipilih untuk checkout
+// tanpa cache. Tier grosir dihitung dari item yang dipilih saja, voucher
+// tidak diterapkan karena dihitung ulang saat checkout.
+func (s *cartService) GetSelectedCart(db *gorm.DB, userID uuid.UUID, selectedItems []uuid.UUID) (*dto.GetCartItemsResponse, error) {
+	pending, err := s.pendingPaymentCart(db, userID)
+	if err != nil || pending != nil {
+		return pending, err
+	}
+
+	res, err := s.cartRepo.GetCartItemsByUserID(db, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("cart not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	selected := make(map[uuid.UUID]bool, len(selectedItems))
+	for _, id := range selectedItems {
+		selected[id] = true
+	}
+	cartItems := []entity.CartItem{}
+	for _, item := range res.CartItems {
+		if selected[item.ID] {
+			cartItems = append(cartItems, item)
+		}
+	}
+
+	sales, err := s.saleCampaignService.PriceIndex(db, cartProductIDs(cartItems))
+	if err != nil {
+		return nil, err
+	}
+	result := buildCartResponse(res.ID, cartItems, sales, customerGroupOf(res.User))
+	result.VoucherCode = res.VoucherCode
+	return result, nil
+}
+
+func cartProductIDs(cartItems []entity.CartItem) []uuid.UUID {
+	ids := []uuid.UUID{}
+	for _, item := range cartItems {
+		ids = append(ids, item.ProductID)
+	}
+	return ids
+}
+
+func buildCartResponse(cartID uuid.UUID, cartItems []entity.CartItem, sales *SalePriceIndex, group *string) *dto.GetCartItemsResponse {
 	items := []dto.CartItems{}
 	var totalAmount, totalWeight float64
+	quantities := tierQuantities(cartItems)
 
-	for _, dataItem := range res.CartItems {
+	for _, dataItem := range cartItems {
+		if dataItem.Product == nil {
+			continue
+		}
+		note := dataItem.Note
+		price, sale, tier := linePrice(dataItem.Product, dataItem.ProductVariant, quantities[dataItem.ProductID], group, sales)
 		item := dto.CartItems{
 			CartItemsID: dataItem.ID,
 			Quantity:    dataItem.Quantity,
-			Note:        &dataItem.Note,
+			Note:        &note,
 			Product: &dto.GetProductByID{
-				ID:           dataItem.Product.ID,
-				Name:         dataItem.Product.Name,
-				CategoryID:   dataItem.Product.CategoryID,
-				Description:  dataItem.Product.Description,
-				ImageURL:     dataItem.Product.ImageURL,
-				HasVariant:   dataItem.Product.HasVariant,
-				Price:        dataItem.Product.Price,
-				Stock:        dataItem.Product.Stock,
-				Weight:       dataItem.Product.Weight,
-				CategoryName: &dataItem.Product.Category.Name,
+				ID:            dataItem.Product.ID,
+				Name:          dataItem.Product.Name,
+				SKU:           dataItem.Product.SKU,
+				CategoryID:    dataItem.Product.CategoryID,
+				Description:   dataItem.Product.Description,
+				ImageURL:      variantImageURL(dataItem.Product, dataItem.ProductVariant),
+				HasVariant:    dataItem.Product.HasVariant,
+				Price:         price,
+				OriginalPrice: variantPrice(dataItem.Product, dataItem.ProductVariant),
+				Stock:         dataItem.Product.Stock,
+				Weight:        variantWeight(dataItem.Product, dataItem.ProductVariant),
 			},
-			Subtotal: float64(dataItem.Quantity) * dataItem.Product.Price,
+			Subtotal:  float64(dataItem.Quantity) * price,
+			PriceTier: priceTierInfo(tier),
+		}
+		item.Product.SalePrice, item.Product.SaleEndsAt = saleFields(sale)
+		item.Product.ProductType = dataItem.Product.ProductType
+		if isBundle(dataItem.Product) {
+			item.Product.Stock = bundleStock(dataItem.Product, dataItem.Components)
+			item.Components = cartComponentInfos(dataItem.Product, dataItem.Components)
+		}
+		if sale != nil {
+			item.SaleCampaignID = &sale.CampaignID
+		}
+		if dataItem.Product.Category != nil {
+			item.Product.CategoryName = &dataItem.Product.Category.Name
 		}
 
 		// Tambahkan info varian jika produk punya varian
-		if dataItem.Product.HasVariant {
-
-			log.Println("item.Product.Variants", dataItem.ProductVariant)
+		if dataItem.Product.HasVariant && dataItem.ProductVariant != nil {
 			var variantDTO dto.ProductVariantInfo
 			variantDTO.ID = dataItem.ProductVariant.ID
 			variantDTO.Stock = dataItem.ProductVariant.Stock
-
-			if dataItem.ProductVariant.ColorID != nil {
-				variantDTO.ColorID = dataItem.ProductVariant.ColorID
-			}
-			if dataItem.ProductVariant.SizeID != nil {
-				variantDTO.SizeID = dataItem.ProductVariant.SizeID
-			}
-			if dataItem.ProductVariant.Color.Name != "" {
+			variantDTO.ColorID = dataItem.ProductVariant.ColorID
+			variantDTO.SizeID = dataItem.ProductVariant.SizeID
+			variantDTO.SalePrice, variantDTO.SaleEndsAt = saleFields(sale)
+			if dataItem.ProductVariant.Color != nil {
 				variantDTO.Color = dataItem.ProductVariant.Color.Name
 			}
-			if dataItem.ProductVariant.Size.Name != "" {
+			if dataItem.ProductVariant.Size != nil {
 				variantDTO.Size = dataItem.ProductVariant.Size.Name
 			}
+			applyVariantOverrides(&variantDTO, dataItem.Product, *dataItem.ProductVariant)
 
 			item.Product.Variants = append(item.Product.Variants, variantDTO)
-
 		}
 
 		totalAmount += item.Subtotal
-		totalWeight += float64(dataItem.Quantity) * dataItem.Product.Weight
+		totalWeight += float64(dataItem.Quantity) * item.Product.Weight
 		items = append(items, item)
 	}
 
-	totalPaid := totalAmount * 30 / 100
-	result := &dto.GetCartItemsResponse{
-		CartID:      res.ID,
+	return &dto.GetCartItemsResponse{
+		CartID:      cartID,
 		TotalWeight: totalWeight,
+		Subtotal:    totalAmount,
 		TotalAmount: totalAmount,
-		TotalPaid:   totalPaid,
+		TotalPaid:   totalAmount * downPaymentRate,
 		CartItems:   items,
 	}
+}
 
-	// Simpan ke cache
-	if cached, err := json.Marshal(result); err == nil {
-		_ = s.cacheable.Set(key, cached)
-	}
-
-	return result, nil
+func applyVoucherDiscount(result *dto.GetCartItemsResponse, discount *dto.VoucherDiscount) {
+	result.VoucherCode = &discount.Code
+	result.DiscountAmount = discount.DiscountAmount
+	result.TotalAmount = result.Subtotal - discount.DiscountAmount
+	result.TotalPaid = result.TotalAmount * downPaymentRate
 }
 
 func (s *cartService) UpdateCartItem(ctx context.Context, userID uuid.UUID, req *dto.UpdateCartItemRequest) error {
@@ -285,26 +458,129 @@ func (s *cartService) UpdateCartItem(ctx context.Context, userID uuid.UUID, req
 		}
 	}()
 
-	cartItem := &entity.CartItem{
-		ID:        req.ID,
-		CartID:    req.CartID,
-		ProductID: req.ProductID,
-		Quantity:  req.Quantity,
+	if req.Quantity < 1 {
+		tx.Error = errors.New("quantity must be at least 1")
+		return tx.Error
 	}
 
-	if req.Note != nil {
-		cartItem.Note = *req.Note
+	// Item harus milik keranjang user yang login
+	cartItem, err := s.getOwnedCartItem(tx, userID, req.ID)
+	if err != nil {
+		tx.Error = err
+		return err
+	}
+	if req.CartID != uuid.Nil && req.CartID != cartItem.CartID {
+		tx.Error = ErrCartItemNotFound
+		return tx.Error
+	}
+
+	// Validasi ulang produk, varian dan stok seperti AddToCart
+	product, err := getVisibleProduct(tx, s.productRepo, cartItem.ProductID)
+	if err != nil {
+		tx.Error = err
+		return err
+	}
+
+	stockAvailable := product.Stock
+	var variantID *uuid.UUID
+	var variant *entity.ProductVariant
+	components := cartItem.Components
+	if isBundle(product) {
+		selections := bundleSelectionsOf(cartItem.Components)
+		if len(req.BundleSelections) > 0 {
+			selections = req.BundleSelections
+		}
+		components, err = resolveBundleSelections(product, selections)
+		if err != nil {
+			tx.Error = err
+			return err
+		}
+		stockAvailable = bundleStock(product, components)
+
+		// Ganti pilihan varian tidak boleh menduplikasi bundle lain di keranjang
+		if !sameComponents(components, cartItem.Components) {
+			existing, err := findBundleCartItem(tx, s.cartRepo, cartItem.CartID, product.ID, components)
+			if err != nil {
+				tx.Error = err
+				return err
+			}
+			if existing != nil && existing.ID != cartItem.ID {
+				tx.Error = errors.New("this bundle selection is already in the cart")
+				return tx.Error
+			}
+			if err := s.cartRepo.ReplaceCartItemComponents(tx, cartItem.ID, components); err != nil {
+				tx.Error = err
+				return err
+			}
+		}
+	} else if product.HasVariant {
+		variantID = cartItem.ProductVariantID
+		if req.ProductVariantID != nil {
+			variantID = req.ProductVariantID
+		}
+		if variantID == nil {
+			tx.Error = errors.New("product variant ID required")
+			return tx.Error
+		}
+
+		variant, err = s.variantRepo.GetByID(tx, *variantID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			tx.Error = errors.New("product variant not found")
+			return tx.Error
+		} else if err != nil {
+			tx.Error = err
+			return err
+		}
+		if variant.ProductID != product.ID {
+			tx.Error = errors.New("variant does not belong to this product")
+			return tx.Error
+		}
+		stockAvailable = variant.Stock
+
+		// Ganti varian tidak boleh menduplikasi item lain di keranjang
+		if !sameVariant(variantID, cartItem.ProductVariantID) {
+			_, err := s.cartRepo.GetCartItemByCartIDAndProductIDAndVariantID(tx, cartItem.CartID, product.ID, *variantID)
+			if err == nil {
+				tx.Error = errors.New("this product variant is already in the cart")
+				return tx.Error
+			} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+				tx.Error = err
+				return err
+			}
+		}
 	}
 
-	// Jika menggunakan varian, set juga ProductVariantID
-	if req.ProductVariantID != nil {
-		cartItem.ProductVariantID = req.ProductVariantID
+	if req.Quantity > stockAvailable {
+		tx.Error = errors.New("stock not enough")
+		return tx.Error
 	}
 
+	cartItem.ProductVariantID = variantID
+	cartItem.Quantity = req.Quantity
+	sales, err := s.saleCampaignService.PriceIndex(tx, []uuid.UUID{product.ID})
+	if err != nil {
+		tx.Error = err
+		return err
+	}
+	group := s.customerGroup(tx, userID)
+	cartItem.Price = unitPrice(product, variant, req.Quantity, group, sales)
+	if req.Note != nil {
+		cartItem.Note = *req.Note
+	}
+	// Komponen bundle sudah disimpan terpisah
+	cartItem.Components = nil
 	if err := s.cartRepo.UpdateCartItems(tx, cartItem); err != nil {
 		tx.Error = err
 		return err
 	}
+	if err := repriceCartLines(tx, s.cartRepo, cartItem.CartID, product, group, sales); err != nil {
+		tx.Error = err
+		return err
+	}
+	if err := s.cartRepo.ReactivateCart(tx, userID); err != nil {
+		tx.Error = err
+		return err
+	}
 
 	if err := tx.Commit().Error; err != nil {
 		tx.Error = err
@@ -331,7 +607,7 @@ func (s *cartService) RemoveCartItem(ctx context.Context, userID uuid.UUID, cart
 		}
 	}()
 
-	if err := s.cartRepo.RemoveCartItem(tx, &cartItemID); err != nil {
+	if err := s.RemoveItem(tx, userID, cartItemID); err != nil {
 		tx.Error = err
 		return err
 	}
@@ -347,3 +623,239 @@ func (s *cartService) RemoveCartItem(ctx context.Context, userID uuid.UUID, cart
 
 	return nil
 }
+
+// RemoveItem menghapus item keranjang milik user memakai transaksi pemanggil.
+func (s *cartService) RemoveItem(db *gorm.DB, userID uuid.UUID, cartItemID uuid.UUID) error {
+	cartItem, err := s.getOwnedCartItem(db, userID, cartItemID)
+	if err != nil {
+		return err
+	}
+	if err := s.cartRepo.RemoveCartItem(db, &cartItem.ID); err != nil {
+		return err
+	}
+
+	// Item lain dari produk yang sama bisa turun tier
+	product, err := s.productRepo.GetByID(db, cartItem.ProductID)
+	if err == nil {
+		sales, err := s.saleCampaignService.PriceIndex(db, []uuid.UUID{product.ID})
+		if err != nil {
+			return err
+		}
+		if err := repriceCartLines(db, s.cartRepo, cartItem.CartID, product, s.customerGroup(db, userID), sales); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return s.cartRepo.ReactivateCart(db, userID)
+}
+
+// getOwnedCartItem mengambil item dari keranjang milik user, item milik
+// user lain dianggap tidak ada.
+func (s *cartService) getOwnedCartItem(db *gorm.DB, userID uuid.UUID, cartItemID uuid.UUID) (*entity.CartItem, error) {
+	cartItem, err := s.cartRepo.GetCartItemByUserID(db, userID, cartItemID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCartItemNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return cartItem, nil
+}
+
+// customerGroup mengambil grup pelanggan pemilik keranjang untuk harga
+// grosir. Keranjang yang belum ada dianggap tanpa grup.
+func (s *cartService) customerGroup(db *gorm.DB, userID uuid.UUID) *string {
+	cart, err := s.cartRepo.GetCartByUserID(db, userID)
+	if err != nil {
+		return nil
+	}
+	return customerGroupOf(cart.User)
+}
+
+func (s *cartService) ValidateCart(ctx context.Context, userID uuid.UUID, req *dto.ValidateCartRequest) (*dto.CartValidationResponse, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			log.Printf("PANIC RECOVERED: Rolling back transaction due to panic: %v", p)
+			panic(p)
+		} else if tx.Error != nil {
+			tx.Rollback()
+			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
+		}
+	}()
+
+	result, err := s.CheckCart(tx, userID, req.SelectedItems)
+	if err != nil {
+		tx.Error = err
+		return nil, err
+	}
+
+	// Simpan harga terbaru agar perubahan harga dianggap sudah dikonfirmasi
+	for _, item := range result.Items {
+		if item.CurrentPrice == item.PreviousPrice || item.Status == CartItemStatusUnavailable {
+			continue
+		}
+		if err := s.cartRepo.UpdateCartItems(tx, &entity.CartItem{ID: item.CartItemID, Price: item.CurrentPrice}); err != nil {
+			tx.Error = err
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Error = err
+		return nil, err
+	}
+
+	_ = s.cacheable.Delete("carts:" + userID.String())
+
+	return result, nil
+}
+
+// CheckCart mencocokkan setiap item keranjang dengan stok dan harga terkini
+// tanpa mengubah data. Jika selectedItems kosong, semua item diperiksa.
+func (s *cartService) CheckCart(db *gorm.DB, userID uuid.UUID, selectedItems []uuid.UUID) (*dto.CartValidationResponse, error) {
+	cart, err := s.cartRepo.GetCartItemsByUserID(db, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("cart not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	sales, err := s.saleCampaignService.PriceIndex(db, cartProductIDs(cart.CartItems))
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make(map[uuid.UUID]bool, len(selectedItems))
+	for _, id := range selectedItems {
+		selected[id] = true
+	}
+
+	cartItems := []entity.CartItem{}
+	for _, item := range cart.CartItems {
+		if len(selected) == 0 || selected[item.ID] {
+			cartItems = append(cartItems, item)
+		}
+	}
+	// Tier grosir dihitung dari item yang akan dibayar saja
+	quantities := tierQuantities(cartItems)
+
+	result := &dto.CartValidationResponse{
+		CartID: cart.ID,
+		Valid:  true,
+		Items:  []dto.CartItemValidation{},
+	}
+	for _, item := range cartItems {
+		validation := s.checkCartItem(db, item, quantities[item.ProductID], sales, customerGroupOf(cart.User))
+		if validation.Status == CartItemStatusOK {
+			result.TotalAmount += validation.CurrentPrice * float64(item.Quantity)
+		} else {
+			result.Valid = false
+		}
+		result.Items = append(result.Items, validation)
+	}
+	if len(result.Items) == 0 {
+		return nil, errors.New("no selected items in cart")
+	}
+
+	return result, nil
+}
+
+func (s *cartService) checkCartItem(db *gorm.DB, item entity.CartItem, tierQuantity int, sales *SalePriceIndex, group *string) dto.CartItemValidation {
+	validation := dto.CartItemValidation{
+		CartItemID:       item.ID,
+		ProductID:        item.ProductID,
+		ProductVariantID: item.ProductVariantID,
+		Quantity:         item.Quantity,
+		PreviousPrice:    item.Price,
+		Status:           CartItemStatusOK,
+	}
+
+	// Produk sudah dihapus
+	product := item.Product
+	if product == nil {
+		validation.Status = CartItemStatusUnavailable
+		validation.Message = "product is no longer available"
+		validation.Suggestion = &dto.CartItemSuggestion{Action: "remove_item"}
+		return validation
+	}
+	validation.ProductName = product.Name
+	// Produk diarsipkan, kembali ke draft atau jadwal tayangnya lewat
+	if !productVisible(product, time.Now()) {
+		validation.Status = CartItemStatusUnavailable
+		validation.Message = "product is no longer available"
+		validation.Suggestion = &dto.CartItemSuggestion{Action: "remove_item"}
+		return validation
+	}
+	validation.CurrentPrice = unitPrice(product, item.ProductVariant, tierQuantity, group, sales)
+
+	stock := product.Stock
+	if isBundle(product) {
+		components, err := resolveBundleSelections(product, bundleSelectionsOf(item.Components))
+		if err != nil {
+			validation.Status = CartItemStatusVariantRequired
+			validation.Message = err.Error()
+			validation.Suggestion = &dto.CartItemSuggestion{Action: "select_variant"}
+			return validation
+		}
+		stock = bundleStock(product, components)
+	} else if product.HasVariant {
+		if item.ProductVariantID == nil {
+			validation.Status = CartItemStatusVariantRequired
+			validation.Message = "a product variant must be selected"
+			validation.Suggestion = &dto.CartItemSuggestion{
+				Action:     "select_variant",
+				VariantIDs: s.availableVariantIDs(db, product.ID, item.Quantity),
+			}
+			return validation
+		}
+		variant := item.ProductVariant
+		if variant == nil || variant.ProductID != product.ID {
+			validation.Status = CartItemStatusUnavailable
+			validation.Message = "product variant is no longer available"
+			validation.Suggestion = &dto.CartItemSuggestion{
+				Action:     "select_variant",
+				VariantIDs: s.availableVariantIDs(db, product.ID, item.Quantity),
+			}
+			return validation
+		}
+		stock = variant.Stock
+	}
+	validation.AvailableStock = stock
+
+	if stock < item.Quantity {
+		validation.Status = CartItemStatusInsufficientStock
+		if stock > 0 {
+			validation.Message = fmt.Sprintf("only %d item(s) left in stock", stock)
+			validation.Suggestion = &dto.CartItemSuggestion{Action: "reduce_quantity", Quantity: &stock}
+		} else {
+			validation.Message = "out of stock"
+			validation.Suggestion = &dto.CartItemSuggestion{Action: "remove_item"}
+		}
+		return validation
+	}
+
+	// Item lama belum punya harga tersimpan, anggap harga terkini
+	if item.Price > 0 && item.Price != validation.CurrentPrice {
+		validation.Status = CartItemStatusPriceChanged
+		validation.Message = fmt.Sprintf("price changed from %.2f to %.2f", item.Price, validation.CurrentPrice)
+		validation.Suggestion = &dto.CartItemSuggestion{Action: "accept_price"}
+	}
+
+	return validation
+}
+
+func (s *cartService) availableVariantIDs(db *gorm.DB, productID uuid.UUID, quantity int) []uuid.UUID {
+	variants, err := s.variantRepo.GetByProductID(db, productID)
+	if err != nil {
+		return nil
+	}
+	ids := []uuid.UUID{}
+	for _, variant := range variants {
+		if variant.Stock >= quantity {
+			ids = append(ids, variant.ID)
+		}
+	}
+	return ids
+}
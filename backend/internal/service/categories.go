@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"mola-web/internal/entity"
 	"mola-web/internal/http/dto"
@@ -14,8 +15,22 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	categoriesCacheKey   = "categories:Get-all"
+	categoryTreeCacheKey = "categories:tree"
+)
+
+var (
+	ErrCategoryNotFound       = errors.New("category not found")
+	ErrParentCategoryNotFound = errors.New("parent category not found")
+	ErrCategoryCycle          = errors.New("a category cannot be moved under itself or its subcategories")
+	ErrCategoryHasChildren    = errors.New("category still has subcategories, move or delete them first")
+	ErrCategoryHasProducts    = errors.New("category still has products, move them to another category first")
+)
+
 type CategoryService interface {
 	GetAll(ctx context.Context) ([]dto.GetAllCategories, error)
+	GetTree(ctx context.Context) ([]*dto.CategoryTree, error)
 	Create(ctx context.Context, category *dto.CreateCategoryRequest) error
 	Update(ctx context.Context, category *dto.UpdateCategoryRequest) error
 	Delete(ctx context.Context, id uint) error
@@ -24,21 +39,23 @@ type CategoryService interface {
 type categoryService struct {
 	DB           *gorm.DB
 	repo         repository.CategoryRepository
+	slugRepo     repository.SlugRepository
 	tokenUseCase token.TokenUseCase
 	cacheable    cache.Cacheable
 }
 
-func NewCategoryService(db *gorm.DB, repo repository.CategoryRepository, tokenUseCase token.TokenUseCase, cacheable cache.Cacheable) CategoryService {
+func NewCategoryService(db *gorm.DB, repo repository.CategoryRepository, slugRepo repository.SlugRepository, tokenUseCase token.TokenUseCase, cacheable cache.Cacheable) CategoryService {
 	return &categoryService{
 	DB:           db,
 	repo:         repo,
+	slugRepo:     slugRepo,
 	tokenUseCase: tokenUseCase,
 	cacheable:    cacheable,	
 	}
 }
 
 func (s *categoryService) GetAll(ctx context.Context) (results []dto.GetAllCategories, err error) {
-	key := "categories:Get-all"
+	key := categoriesCacheKey
 	data := s.cacheable.Get(key)
 	if data != "" {
 		err = json.Unmarshal([]byte(data), &results)
@@ -56,8 +73,10 @@ func (s *categoryService) GetAll(ctx context.Context) (results []dto.GetAllCateg
 	}
 	for _, value := range categories {
 		results = append(results, dto.GetAllCategories{
-			ID:   value.ID,
-			Name: value.Name,
+			ID:       value.ID,
+			Name:     value.Name,
+			Slug:     value.Slug,
+			ParentID: value.ParentID,
 		})
 	}
 	mashalledData, err := json.Marshal(results)
@@ -70,6 +89,87 @@ func (s *categoryService) GetAll(ctx context.Context) (results []dto.GetAllCateg
 	return results, nil
 }
 
+// GetTree mengembalikan kategori utama beserta subkategorinya, diurutkan
+// berdasarkan nama di setiap level.
+func (s *categoryService) GetTree(ctx context.Context) (results []*dto.CategoryTree, err error) {
+	data := s.cacheable.Get(categoryTreeCacheKey)
+	if data != "" {
+		if err := json.Unmarshal([]byte(data), &results); err != nil {
+			return nil, err
+		}
+		return results, nil
+	}
+
+	categories, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make(map[uint]*dto.CategoryTree, len(categories))
+	for _, value := range categories {
+		nodes[value.ID] = &dto.CategoryTree{
+			ID:       value.ID,
+			Name:     value.Name,
+			Slug:     value.Slug,
+			ParentID: value.ParentID,
+			Children: []*dto.CategoryTree{},
+		}
+	}
+	results = []*dto.CategoryTree{}
+	for _, value := range categories {
+		node := nodes[value.ID]
+		if value.ParentID != nil {
+			if parent, ok := nodes[*value.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		// Parent yang sudah dihapus membuat kategori tampil di level utama
+		results = append(results, node)
+	}
+
+	marshalledData, err := json.Marshal(results)
+	if err != nil {
+		return nil, err
+	}
+	_ = s.cacheable.Set(categoryTreeCacheKey, marshalledData)
+	return results, nil
+}
+
+func derefUint(value *uint) uint {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
+
+// validateParent memastikan parent ada dan, untuk kategori yang dipindah,
+// bukan kategori itu sendiri atau turunannya.
+func (s *categoryService) validateParent(tx *gorm.DB, id uint, parentID uint) error {
+	if _, err := s.repo.GetByID(tx, parentID); errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrParentCategoryNotFound
+	} else if err != nil {
+		return err
+	}
+	if id == 0 {
+		return nil
+	}
+	subtree, err := s.repo.GetSubtreeIDs(tx, id)
+	if err != nil {
+		return err
+	}
+	for _, subtreeID := range subtree {
+		if subtreeID == parentID {
+			return ErrCategoryCycle
+		}
+	}
+	return nil
+}
+
+func (s *categoryService) invalidateCategoryCaches() {
+	_ = s.cacheable.Delete(categoriesCacheKey)
+	_ = s.cacheable.Delete(categoryTreeCacheKey)
+}
+
 func (s *categoryService) Create(ctx context.Context, request *dto.CreateCategoryRequest) error {
 	tx := s.DB.WithContext(ctx).Begin()
 	defer func() {
@@ -82,11 +182,28 @@ func (s *categoryService) Create(ctx context.Context, request *dto.CreateCategor
 			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
 		}
 	}()
+	categorySlug, err := assignSlug(tx, s.slugRepo, slugChange{
+		EntityType: entity.SlugEntityCategory,
+		Name:       request.Name,
+		Requested:  request.Slug,
+	})
+	if err != nil {
+		tx.Error = err
+		return err
+	}
+	if request.ParentID != nil {
+		if err := s.validateParent(tx, 0, *request.ParentID); err != nil {
+			tx.Error = err
+			return err
+		}
+	}
 	category := &entity.Category{
-		Name: request.Name,
+		Name:     request.Name,
+		Slug:     &categorySlug,
+		ParentID: request.ParentID,
 	}
 
-	err := s.repo.Create(tx, category)
+	err = s.repo.Create(tx, category)
 	if err != nil {
 		tx.Error = err
 		return err
@@ -95,8 +212,7 @@ func (s *categoryService) Create(ctx context.Context, request *dto.CreateCategor
 		tx.Error = err
 		return err
 	}
-	key := "categories:Get-all"
-	_ = s.cacheable.Delete(key)
+	s.invalidateCategoryCaches()
 	return nil
 }
 
@@ -112,11 +228,51 @@ func (s *categoryService) Update(ctx context.Context, request *dto.UpdateCategor
 			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
 		}
 	}()
+	current, err := s.repo.GetByID(tx, request.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Error = ErrCategoryNotFound
+		return tx.Error
+	} else if err != nil {
+		tx.Error = err
+		return err
+	}
+	// Nama kosong tidak ikut diubah
+	name := request.Name
+	if name == "" {
+		name = current.Name
+	}
+	categorySlug, err := assignSlug(tx, s.slugRepo, slugChange{
+		EntityType: entity.SlugEntityCategory,
+		EntityID:   fmt.Sprint(current.ID),
+		Current:    current.Slug,
+		Name:       name,
+		Requested:  request.Slug,
+		Keep:       request.KeepSlug,
+	})
+	if err != nil {
+		tx.Error = err
+		return err
+	}
+	// ParentID kosong berarti tidak dipindah, 0 berarti jadi kategori utama
+	parentID := current.ParentID
+	if request.ParentID != nil {
+		parentID = nil
+		if *request.ParentID != 0 {
+			if err := s.validateParent(tx, current.ID, *request.ParentID); err != nil {
+				tx.Error = err
+				return err
+			}
+			parentID = request.ParentID
+		}
+	}
+	moved := derefUint(parentID) != derefUint(current.ParentID)
 	category := &entity.Category{
-		ID:   request.ID,
-		Name: request.Name,
+		ID:       request.ID,
+		Name:     name,
+		Slug:     &categorySlug,
+		ParentID: parentID,
 	}
-	err := s.repo.Update(tx, category)
+	err = s.repo.Update(tx, category)
 	if err != nil {
 		tx.Error = err
 		return err
@@ -125,8 +281,11 @@ func (s *categoryService) Update(ctx context.Context, request *dto.UpdateCategor
 		tx.Error = err
 		return err
 	}
-	key := "categories:Get-all"
-	_ = s.cacheable.Delete(key)
+	s.invalidateCategoryCaches()
+	// Produk per kategori yang menyertakan subkategori ikut berubah
+	if moved {
+		_ = s.cacheable.DeleteByPrefix(cache.CacheKeyProductsGetByCategoryId)
+	}
 	return nil
 }
 
@@ -142,10 +301,35 @@ func (s *categoryService) Delete(ctx context.Context, id uint) error {
 			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
 		}
 	}()
-	category := entity.Category{
-		ID: id,
+	if _, err := s.repo.GetByID(tx, id); errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Error = ErrCategoryNotFound
+		return tx.Error
+	} else if err != nil {
+		tx.Error = err
+		return err
 	}
-	err := s.repo.Delete(tx, category.ID)
+	// Kategori hanya boleh dihapus jika kosong supaya produk dan
+	// subkategori tidak kehilangan induknya
+	children, err := s.repo.CountChildren(tx, id)
+	if err != nil {
+		tx.Error = err
+		return err
+	}
+	if children > 0 {
+		tx.Error = ErrCategoryHasChildren
+		return tx.Error
+	}
+	products, err := s.repo.CountProducts(tx, id)
+	if err != nil {
+		tx.Error = err
+		return err
+	}
+	if products > 0 {
+		tx.Error = ErrCategoryHasProducts
+		return tx.Error
+	}
+
+	err = s.repo.Delete(tx, id)
 	if err != nil {
 		tx.Error = err
 		return err
@@ -154,8 +338,7 @@ func (s *categoryService) Delete(ctx context.Context, id uint) error {
 		tx.Error = err
 		return err
 	}
-	key := "categories:Get-all"
-	_ = s.cacheable.Delete(key)
+	s.invalidateCategoryCaches()
 	
 	return nil
 }
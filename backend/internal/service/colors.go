@@ -23,14 +23,16 @@ type ColorService interface {
 type colorService struct {
 	DB           *gorm.DB
 	repo         repository.ColorRepository
+	optionRepo   repository.OptionRepository
 	tokenUseCase token.TokenUseCase
 	cacheable    cache.Cacheable
 }
 
-func NewColorService(db *gorm.DB, repo repository.ColorRepository, tokenUseCase token.TokenUseCase, cacheable cache.Cacheable) ColorService {
+func NewColorService(db *gorm.DB, repo repository.ColorRepository, optionRepo repository.OptionRepository, tokenUseCase token.TokenUseCase, cacheable cache.Cacheable) ColorService {
 	return &colorService{
 		DB:           db,
 		repo:         repo,
+		optionRepo:   optionRepo,
 		tokenUseCase: tokenUseCase,
 		cacheable:    cacheable,
 	}
@@ -88,12 +90,17 @@ func (s *colorService) Create(ctx context.Context, request *dto.CreateColorReque
 		tx.Error = err
 		return err
 	}
+	if err := s.optionRepo.UpsertLegacyValue(tx, entity.OptionTypeColor, color.ID, color.Name); err != nil {
+		tx.Error = err
+		return err
+	}
 	if err := tx.Commit().Error; err != nil {
 		tx.Error = err
 		return err
 	}
 	key := "colors:Get-all"
 	_ = s.cacheable.Delete(key)
+	_ = s.cacheable.Delete(optionsCacheKey)
 	return nil
 }
 
@@ -118,12 +125,17 @@ func (s *colorService) Update(ctx context.Context, request *dto.UpdateColorReque
 		tx.Error = err
 		return err
 	}
+	if err := s.optionRepo.UpsertLegacyValue(tx, entity.OptionTypeColor, color.ID, color.Name); err != nil {
+		tx.Error = err
+		return err
+	}
 	if err := tx.Commit().Error; err != nil {
 		tx.Error = err
 		return err
 	}
 	key := "colors:Get-all"
 	_ = s.cacheable.Delete(key)
+	_ = s.cacheable.Delete(optionsCacheKey)
 	return nil
 
 }
@@ -148,11 +160,16 @@ func (s *colorService) Delete(ctx context.Context, id uint) error {
 		tx.Error = err
 		return err
 	}
+	if err := deleteLegacyOptionValue(tx, s.optionRepo, entity.OptionTypeColor, color.ID); err != nil {
+		tx.Error = err
+		return err
+	}
 	if err := tx.Commit().Error; err != nil {
 		tx.Error = err
 		return err
 	}
 	key := "colors:Get-all"
 	_ = s.cacheable.Delete(key)
+	_ = s.cacheable.Delete(optionsCacheKey)
 	return nil
 }
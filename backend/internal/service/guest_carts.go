@@ -0,0 +1,449 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"mola-web/internal/entity"
+	"mola-web/internal/http/dto"
+	"mola-web/internal/repository"
+	"mola-web/pkg/cache"
+	"mola-web/pkg/token"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const guestCartTTL = 7 * 24 * time.Hour
+
+var ErrGuestCartItemNotFound = errors.New("guest cart item not found")
+
+type GuestCartService interface {
+	NewGuest() (uuid.UUID, string, error)
+	ResolveGuest(guestToken string) (uuid.UUID, error)
+	AddToCart(ctx context.Context, guestID uuid.UUID, req *dto.AddToCartRequest) error
+	GetCart(ctx context.Context, guestID uuid.UUID) (*dto.GetCartItemsResponse, error)
+	UpdateCartItem(ctx context.Context, guestID uuid.UUID, itemID uuid.UUID, req *dto.UpdateGuestCartItemRequest) error
+	RemoveCartItem(ctx context.Context, guestID uuid.UUID, itemID uuid.UUID) error
+	MergeIntoUserCart(ctx context.Context, guestToken string, userID uuid.UUID) error
+}
+
+type guestCartService struct {
+	DB                  *gorm.DB
+	cartRepo            repository.CartRepository
+	productRepo         repository.ProductRepository
+	variantRepo         repository.ProductVariantRepository
+	saleCampaignService SaleCampaignService
+	cacheable           cache.Cacheable
+	token               token.TokenUseCase
+}
+
+// guestCart adalah isi keranjang tamu yang disimpan di Redis.
+type guestCart struct {
+	Items []guestCartItem `json:"items"`
+}
+
+type guestCartItem struct {
+	ID               uuid.UUID             `json:"id"`
+	ProductID        uuid.UUID             `json:"product_id"`
+	ProductVariantID *uuid.UUID            `json:"product_variant_id,omitempty"`
+	Quantity         int                   `json:"quantity"`
+	Note             string                `json:"note,omitempty"`
+	BundleSelections []dto.BundleSelection `json:"bundle_selections,omitempty"`
+	CreatedAt        time.Time             `json:"created_at"`
+}
+
+func NewGuestCartService(db *gorm.DB, cartRepo repository.CartRepository, productRepo repository.ProductRepository, variantRepo repository.ProductVariantRepository, saleCampaignService SaleCampaignService, tokenUseCase token.TokenUseCase, cacheable cache.Cacheable) GuestCartService {
+	return &guestCartService{
+		DB:                  db,
+		cartRepo:            cartRepo,
+		productRepo:         productRepo,
+		variantRepo:         variantRepo,
+		saleCampaignService: saleCampaignService,
+		cacheable:           cacheable,
+		token:               tokenUseCase,
+	}
+}
+
+func guestCartKey(guestID uuid.UUID) string {
+	return "guest-carts:" + guestID.String()
+}
+
+func (s *guestCartService) NewGuest() (uuid.UUID, string, error) {
+	guestID := uuid.New()
+	guestToken, err := s.token.GenerateGuestToken(guestID, time.Now().Add(guestCartTTL))
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+	return guestID, guestToken, nil
+}
+
+func (s *guestCartService) ResolveGuest(guestToken string) (uuid.UUID, error) {
+	if guestToken == "" {
+		return uuid.Nil, errors.New("guest token required")
+	}
+	return s.token.ParseGuestToken(guestToken)
+}
+
+func (s *guestCartService) load(guestID uuid.UUID) (*guestCart, error) {
+	cart := &guestCart{}
+	data := s.cacheable.Get(guestCartKey(guestID))
+	if data == "" {
+		return cart, nil
+	}
+	if err := json.Unmarshal([]byte(data), cart); err != nil {
+		return nil, err
+	}
+	return cart, nil
+}
+
+func (s *guestCartService) save(guestID uuid.UUID, cart *guestCart) error {
+	if len(cart.Items) == 0 {
+		return s.cacheable.Delete(guestCartKey(guestID))
+	}
+	data, err := json.Marshal(cart)
+	if err != nil {
+		return err
+	}
+	return s.cacheable.SetWithTTL(guestCartKey(guestID), data, guestCartTTL)
+}
+
+// availableStock memvalidasi produk dan varian seperti AddToCart lalu
+// mengembalikan produk beserta stok yang tersedia.
+func (s *guestCartService) availableStock(db *gorm.DB, productID uuid.UUID, variantID *uuid.UUID, selections []dto.BundleSelection) (*entity.Product, int, error) {
+	product, err := getVisibleProduct(db, s.productRepo, productID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if isBundle(product) {
+		components, err := resolveBundleSelections(product, selections)
+		if err != nil {
+			return nil, 0, err
+		}
+		return product, bundleStock(product, components), nil
+	}
+
+	if !product.HasVariant {
+		return product, product.Stock, nil
+	}
+	if variantID == nil {
+		return nil, 0, errors.New("product variant ID required")
+	}
+	variant, err := s.variantRepo.GetByID(db, *variantID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, 0, errors.New("product variant not found")
+	} else if err != nil {
+		return nil, 0, err
+	}
+	if variant.ProductID != productID {
+		return nil, 0, errors.New("variant does not belong to this product")
+	}
+	return product, variant.Stock, nil
+}
+
+func (s *guestCartService) AddToCart(ctx context.Context, guestID uuid.UUID, req *dto.AddToCartRequest) error {
+	if req.Quantity < 1 {
+		return errors.New("quantity must be at least 1")
+	}
+	cart, err := s.load(guestID)
+	if err != nil {
+		return err
+	}
+
+	product, err := getVisibleProduct(s.DB.WithContext(ctx), s.productRepo, req.ProductID)
+	if err != nil {
+		return err
+	}
+	var variantID *uuid.UUID
+	if product.HasVariant {
+		variantID = req.ProductVariantID
+	}
+
+	var selections []dto.BundleSelection
+	if isBundle(product) {
+		selections = bundleSelectionsOf(bundleComponents(product, req.BundleSelections))
+	}
+
+	_, stockAvailable, err := s.availableStock(s.DB.WithContext(ctx), req.ProductID, variantID, selections)
+	if err != nil {
+		return err
+	}
+
+	// Gabungkan dengan item yang sama jika sudah ada
+	quantity := req.Quantity
+	index := -1
+	for i, item := range cart.Items {
+		if item.ProductID == req.ProductID && sameVariant(item.ProductVariantID, variantID) &&
+			sameComponents(bundleComponents(product, item.BundleSelections), bundleComponents(product, selections)) {
+			index = i
+			quantity += item.Quantity
+			break
+		}
+	}
+	if quantity > stockAvailable {
+		return errors.New("stock not enough")
+	}
+
+	if index >= 0 {
+		cart.Items[index].Quantity = quantity
+		if req.Note != "" {
+			cart.Items[index].Note = req.Note
+		}
+	} else {
+		cart.Items = append(cart.Items, guestCartItem{
+			ID:               uuid.New(),
+			ProductID:        req.ProductID,
+			ProductVariantID: variantID,
+			Quantity:         req.Quantity,
+			Note:             req.Note,
+			BundleSelections: selections,
+			CreatedAt:        time.Now(),
+		})
+	}
+
+	return s.save(guestID, cart)
+}
+
+func (s *guestCartService) GetCart(ctx context.Context, guestID uuid.UUID) (*dto.GetCartItemsResponse, error) {
+	cart, err := s.load(guestID)
+	if err != nil {
+		return nil, err
+	}
+
+	db := s.DB.WithContext(ctx)
+	cartItems := make([]entity.CartItem, 0, len(cart.Items))
+	for _, item := range cart.Items {
+		product, err := getVisibleProduct(db, s.productRepo, item.ProductID)
+		if errors.Is(err, ErrProductNotFound) {
+			// Produk sudah dihapus atau tidak tayang, lewati
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		cartItem := entity.CartItem{
+			ID:               item.ID,
+			ProductID:        item.ProductID,
+			ProductVariantID: item.ProductVariantID,
+			Quantity:         item.Quantity,
+			Note:             item.Note,
+			Product:          product,
+		}
+		if isBundle(product) {
+			cartItem.Components = bundleComponents(product, item.BundleSelections)
+		}
+		if item.ProductVariantID != nil {
+			for i := range product.Variants {
+				if product.Variants[i].ID == *item.ProductVariantID {
+					cartItem.ProductVariant = &product.Variants[i]
+					break
+				}
+			}
+		}
+		cartItems = append(cartItems, cartItem)
+	}
+
+	sales, err := s.saleCampaignService.PriceIndex(db, cartProductIDs(cartItems))
+	if err != nil {
+		return nil, err
+	}
+	return buildCartResponse(guestID, cartItems, sales, nil), nil
+}
+
+func (s *guestCartService) UpdateCartItem(ctx context.Context, guestID uuid.UUID, itemID uuid.UUID, req *dto.UpdateGuestCartItemRequest) error {
+	if req.Quantity < 1 {
+		return errors.New("quantity must be at least 1")
+	}
+	cart, err := s.load(guestID)
+	if err != nil {
+		return err
+	}
+
+	for i, item := range cart.Items {
+		if item.ID != itemID {
+			continue
+		}
+		_, stockAvailable, err := s.availableStock(s.DB.WithContext(ctx), item.ProductID, item.ProductVariantID, item.BundleSelections)
+		if err != nil {
+			return err
+		}
+		if req.Quantity > stockAvailable {
+			return errors.New("stock not enough")
+		}
+		cart.Items[i].Quantity = req.Quantity
+		if req.Note != nil {
+			cart.Items[i].Note = *req.Note
+		}
+		return s.save(guestID, cart)
+	}
+
+	return ErrGuestCartItemNotFound
+}
+
+func (s *guestCartService) RemoveCartItem(ctx context.Context, guestID uuid.UUID, itemID uuid.UUID) error {
+	cart, err := s.load(guestID)
+	if err != nil {
+		return err
+	}
+
+	for i, item := range cart.Items {
+		if item.ID == itemID {
+			cart.Items = append(cart.Items[:i], cart.Items[i+1:]...)
+			return s.save(guestID, cart)
+		}
+	}
+
+	return ErrGuestCartItemNotFound
+}
+
+func (s *guestCartService) MergeIntoUserCart(ctx context.Context, guestToken string, userID uuid.UUID) (err error) {
+	guestID, err := s.ResolveGuest(guestToken)
+	if err != nil {
+		return err
+	}
+	guest, err := s.load(guestID)
+	if err != nil {
+		return err
+	}
+	if len(guest.Items) == 0 {
+		return nil
+	}
+
+	tx := s.DB.WithContext(ctx).Begin()
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			log.Printf("PANIC RECOVERED: Rolling back transaction due to panic: %v", p)
+			panic(p)
+		} else if tx.Error != nil {
+			tx.Rollback()
+			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
+		}
+	}()
+
+	// Ambil atau buat keranjang user
+	cart, err := s.cartRepo.GetCartByUserID(tx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		cart = &entity.Cart{
+			UserID: userID,
+			Status: "active",
+		}
+		if err := s.cartRepo.AddToCart(tx, userID, cart); err != nil {
+			tx.Error = err
+			return err
+		}
+	} else if err != nil {
+		tx.Error = err
+		return err
+	}
+	if err := s.cartRepo.ReactivateCart(tx, userID); err != nil {
+		tx.Error = err
+		return err
+	}
+
+	guestProductIDs := []uuid.UUID{}
+	for _, item := range guest.Items {
+		guestProductIDs = append(guestProductIDs, item.ProductID)
+	}
+	sales, err := s.saleCampaignService.PriceIndex(tx, guestProductIDs)
+	if err != nil {
+		tx.Error = err
+		return err
+	}
+	group := customerGroupOf(cart.User)
+
+	merged := map[uuid.UUID]*entity.Product{}
+	for _, item := range guest.Items {
+		product, stockAvailable, err := s.availableStock(tx, item.ProductID, item.ProductVariantID, item.BundleSelections)
+		if err != nil {
+			// Item yang sudah tidak valid tidak ikut digabung
+			log.Printf("WARNING: skipping guest cart item %s: %v", item.ID, err)
+			continue
+		}
+		merged[product.ID] = product
+
+		var existing *entity.CartItem
+		var components []entity.CartItemComponent
+		if isBundle(product) {
+			components = bundleComponents(product, item.BundleSelections)
+			existing, err = findBundleCartItem(tx, s.cartRepo, cart.ID, item.ProductID, components)
+			if err == nil && existing == nil {
+				err = gorm.ErrRecordNotFound
+			}
+		} else if item.ProductVariantID != nil {
+			existing, err = s.cartRepo.GetCartItemByCartIDAndProductIDAndVariantID(tx, cart.ID, item.ProductID, *item.ProductVariantID)
+		} else {
+			existing, err = s.cartRepo.GetCartItemByCartIDAndProductIDWithoutVariant(tx, cart.ID, item.ProductID)
+		}
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			tx.Error = err
+			return err
+		}
+
+		// Jumlahkan kuantitas, dibatasi stok yang tersedia
+		quantity := item.Quantity
+		if existing != nil {
+			quantity += existing.Quantity
+		}
+		if quantity > stockAvailable {
+			quantity = stockAvailable
+		}
+
+		if existing != nil {
+			if quantity <= existing.Quantity {
+				continue
+			}
+			existing.Quantity = quantity
+			existing.Price = unitPrice(product, productVariantByID(product, item.ProductVariantID), quantity, group, sales)
+			existing.Components = nil
+			if err := s.cartRepo.UpdateCartItems(tx, existing); err != nil {
+				tx.Error = err
+				return err
+			}
+			continue
+		}
+		if quantity < 1 {
+			continue
+		}
+		cartItem := &entity.CartItem{
+			CartID:           cart.ID,
+			ProductID:        item.ProductID,
+			ProductVariantID: item.ProductVariantID,
+			Quantity:         quantity,
+			Price:            unitPrice(product, productVariantByID(product, item.ProductVariantID), quantity, group, sales),
+			Note:             item.Note,
+			Components:       components,
+		}
+		if err := s.cartRepo.AddToCartItems(tx, cartItem); err != nil {
+			tx.Error = err
+			return err
+		}
+	}
+	// Tier grosir dihitung dari gabungan item tamu dan item user
+	for _, product := range merged {
+		if err := repriceCartLines(tx, s.cartRepo, cart.ID, product, group, sales); err != nil {
+			tx.Error = err
+			return err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Error = err
+		return err
+	}
+
+	_ = s.cacheable.Delete(guestCartKey(guestID))
+	_ = s.cacheable.Delete("carts:" + userID.String())
+
+	return nil
+}
+
+func sameVariant(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
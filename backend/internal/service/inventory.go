@@ -0,0 +1,203 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mola-web/internal/entity"
+	"mola-web/internal/http/dto"
+	"mola-web/internal/repository"
+	"mola-web/pkg/barcode"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	maxLabelCopies    = 100
+	maxLabelsPerSheet = 1000
+)
+
+var (
+	ErrInventoryCodeRequired = errors.New("code is required")
+	ErrInventoryCodeNotFound = errors.New("no product or variant with this sku or barcode")
+	ErrInvalidLabelRequest   = errors.New("invalid label request")
+	ErrLabelItemNotFound     = errors.New("label item not found")
+)
+
+type InventoryService interface {
+	Lookup(ctx context.Context, code string) (*dto.InventoryLookupResponse, error)
+	LabelSheet(ctx context.Context, request *dto.LabelSheetRequest) ([]byte, error)
+}
+
+type inventoryService struct {
+	repo repository.InventoryRepository
+}
+
+func NewInventoryService(repo repository.InventoryRepository) InventoryService {
+	return &inventoryService{repo: repo}
+}
+
+func (s *inventoryService) Lookup(ctx context.Context, code string) (*dto.InventoryLookupResponse, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return nil, ErrInventoryCodeRequired
+	}
+	product, variant, err := s.repo.Lookup(ctx, code)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInventoryCodeNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Stok bundle dihitung dari stok komponennya
+	stock := product.Stock
+	if isBundle(product) {
+		stock = bundleStock(product, nil)
+	}
+	result := &dto.InventoryLookupResponse{
+		Type:  dto.InventoryItemProduct,
+		Code:  code,
+		Stock: stock,
+		Product: dto.InventoryProduct{
+			ID:         product.ID,
+			Name:       product.Name,
+			Slug:       product.Slug,
+			SKU:        product.SKU,
+			Barcode:    product.Barcode,
+			ImageURL:   product.ImageURL,
+			HasVariant: product.HasVariant,
+			Price:      product.Price,
+			Stock:      stock,
+		},
+	}
+	if variant != nil {
+		info := dto.ProductVariantInfo{
+			ID:      variant.ID,
+			ColorID: variant.ColorID,
+			SizeID:  variant.SizeID,
+			Stock:   variant.Stock,
+		}
+		applyVariantOptions(&info, *variant)
+		applyVariantOverrides(&info, product, *variant)
+		result.Type = dto.InventoryItemVariant
+		result.Stock = variant.Stock
+		result.Variant = &info
+	}
+	return result, nil
+}
+
+// LabelSheet membuat PDF label barcode. Barcode dicetak apa adanya, SKU
+// dipakai jika barang belum punya barcode.
+func (s *inventoryService) LabelSheet(ctx context.Context, request *dto.LabelSheetRequest) ([]byte, error) {
+	if len(request.Items) == 0 {
+		return nil, fmt.Errorf("%w: items is required", ErrInvalidLabelRequest)
+	}
+	var productIDs, variantIDs []uuid.UUID
+	total := 0
+	for i := range request.Items {
+		item := &request.Items[i]
+		if (item.ProductID == nil) == (item.VariantID == nil) {
+			return nil, fmt.Errorf("%w: item %d must have either product_id or variant_id", ErrInvalidLabelRequest, i)
+		}
+		if item.Copies == 0 {
+			item.Copies = 1
+		}
+		if item.Copies < 0 || item.Copies > maxLabelCopies {
+			return nil, fmt.Errorf("%w: copies must be between 1 and %d", ErrInvalidLabelRequest, maxLabelCopies)
+		}
+		total += item.Copies
+		if item.ProductID != nil {
+			productIDs = append(productIDs, *item.ProductID)
+		} else {
+			variantIDs = append(variantIDs, *item.VariantID)
+		}
+	}
+	if total > maxLabelsPerSheet {
+		return nil, fmt.Errorf("%w: at most %d labels per request", ErrInvalidLabelRequest, maxLabelsPerSheet)
+	}
+
+	products := map[uuid.UUID]entity.Product{}
+	if len(productIDs) > 0 {
+		found, err := s.repo.GetProductsByIDs(ctx, productIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, product := range found {
+			products[product.ID] = product
+		}
+	}
+	variants := map[uuid.UUID]entity.ProductVariant{}
+	if len(variantIDs) > 0 {
+		found, err := s.repo.GetVariantsByIDs(ctx, variantIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, variant := range found {
+			variants[variant.ID] = variant
+		}
+	}
+
+	labels := make([]barcode.Label, 0, total)
+	for _, item := range request.Items {
+		var label barcode.Label
+		if item.ProductID != nil {
+			product, ok := products[*item.ProductID]
+			if !ok {
+				return nil, fmt.Errorf("%w: product %s", ErrLabelItemNotFound, item.ProductID)
+			}
+			label = barcode.Label{Title: product.Name}
+			label.Code, label.Caption = labelCodes(product.SKU, product.Barcode)
+		} else {
+			variant, ok := variants[*item.VariantID]
+			if !ok || variant.Product == nil {
+				return nil, fmt.Errorf("%w: variant %s", ErrLabelItemNotFound, item.VariantID)
+			}
+			label = barcode.Label{
+				Title:    variant.Product.Name,
+				Subtitle: variantOptionLabel(variant.Options),
+			}
+			label.Code, label.Caption = labelCodes(variant.SKU, variant.Barcode)
+		}
+		if label.Code == "" {
+			return nil, fmt.Errorf("%w: %s has no sku or barcode", ErrInvalidLabelRequest, label.Title)
+		}
+		for i := 0; i < item.Copies; i++ {
+			labels = append(labels, label)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := barcode.WriteLabelSheetPDF(&buf, labels); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// labelCodes kode yang dijadikan barcode beserta teks di bawahnya.
+func labelCodes(skuCode *string, barcodeCode *string) (string, string) {
+	switch {
+	case barcodeCode != nil && skuCode != nil:
+		return *barcodeCode, *skuCode
+	case barcodeCode != nil:
+		return *barcodeCode, *barcodeCode
+	case skuCode != nil:
+		return *skuCode, *skuCode
+	}
+	return "", ""
+}
+
+func variantOptionLabel(values []entity.OptionValue) string {
+	options := append([]entity.OptionValue{}, values...)
+	sort.SliceStable(options, func(i, j int) bool {
+		return optionTypeLess(options[i], options[j])
+	})
+	parts := make([]string, 0, len(options))
+	for _, option := range options {
+		parts = append(parts, option.Value)
+	}
+	return strings.Join(parts, " / ")
+}
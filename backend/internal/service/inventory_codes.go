@@ -0,0 +1,176 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"mola-web/internal/entity"
+	"mola-web/internal/repository"
+	"mola-web/pkg/barcode"
+	"mola-web/pkg/sku"
+	"sort"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrBarcodeTaken   = errors.New("barcode is already used by another product or variant")
+	ErrInvalidSKU     = errors.New("sku may only contain letters, digits, '-', '_', '.' and '/', at most 64 characters")
+	ErrInvalidBarcode = barcode.ErrInvalid
+)
+
+// codeRegistry menjaga SKU dan barcode tetap unik di produk dan varian
+// selama satu transaksi simpan produk.
+type codeRegistry struct {
+	tx   *gorm.DB
+	repo repository.InventoryRepository
+	seen map[string]struct{}
+}
+
+func newCodeRegistry(tx *gorm.DB, repo repository.InventoryRepository) *codeRegistry {
+	return &codeRegistry{tx: tx, repo: repo, seen: map[string]struct{}{}}
+}
+
+func (r *codeRegistry) inUse(code string, productID *uuid.UUID, variantID *uuid.UUID) (bool, error) {
+	if _, ok := r.seen[code]; ok {
+		return true, nil
+	}
+	return r.repo.CodeInUse(r.tx, code, productID, variantID)
+}
+
+// claimSKU memvalidasi SKU dari request, atau membuat SKU baru dari base
+// dengan akhiran angka jika sudah dipakai.
+func (r *codeRegistry) claimSKU(requested *string, base string, productID *uuid.UUID, variantID *uuid.UUID) (string, error) {
+	if requested != nil {
+		if !sku.Valid(*requested) {
+			return "", ErrInvalidSKU
+		}
+		used, err := r.inUse(*requested, productID, variantID)
+		if err != nil {
+			return "", err
+		}
+		if used {
+			return "", fmt.Errorf("%w: %s", ErrSKUTaken, *requested)
+		}
+		r.seen[*requested] = struct{}{}
+		return *requested, nil
+	}
+
+	if base == "" {
+		base = "PRODUCT"
+	}
+	candidate := base
+	for i := 2; ; i++ {
+		used, err := r.inUse(candidate, productID, variantID)
+		if err != nil {
+			return "", err
+		}
+		if !used {
+			break
+		}
+		candidate = sku.WithSuffix(base, i)
+	}
+	r.seen[candidate] = struct{}{}
+	return candidate, nil
+}
+
+// claimBarcode memvalidasi barcode dari request, atau membuat EAN-13
+// in-store (prefix 20) dari sequence database.
+func (r *codeRegistry) claimBarcode(requested *string, productID *uuid.UUID, variantID *uuid.UUID) (string, error) {
+	if requested != nil {
+		if _, err := barcode.Detect(*requested); err != nil {
+			return "", ErrInvalidBarcode
+		}
+		used, err := r.inUse(*requested, productID, variantID)
+		if err != nil {
+			return "", err
+		}
+		if used {
+			return "", fmt.Errorf("%w: %s", ErrBarcodeTaken, *requested)
+		}
+		r.seen[*requested] = struct{}{}
+		return *requested, nil
+	}
+
+	for {
+		number, err := r.repo.NextBarcodeNumber(r.tx)
+		if err != nil {
+			return "", err
+		}
+		code, err := barcode.MakeEAN13(barcode.InStorePrefix, number)
+		if err != nil {
+			return "", err
+		}
+		used, err := r.inUse(code, productID, variantID)
+		if err != nil {
+			return "", err
+		}
+		if !used {
+			r.seen[code] = struct{}{}
+			return code, nil
+		}
+	}
+}
+
+// assignProductCodes mengisi SKU dan barcode produk. Request kosong
+// mempertahankan kode lama, atau membuat kode baru jika belum ada.
+func (r *codeRegistry) assignProductCodes(product *entity.Product, requestedSKU string, requestedBarcode string, current *entity.Product) error {
+	var productID *uuid.UUID
+	requestSKU := trimmedOrNil(&requestedSKU)
+	requestBarcode := trimmedOrNil(&requestedBarcode)
+	if current != nil {
+		productID = &current.ID
+		if requestSKU == nil {
+			requestSKU = current.SKU
+		}
+		if requestBarcode == nil {
+			requestBarcode = current.Barcode
+		}
+	}
+
+	code, err := r.claimSKU(requestSKU, sku.Make(product.Name), productID, nil)
+	if err != nil {
+		return err
+	}
+	product.SKU = &code
+
+	code, err = r.claimBarcode(requestBarcode, productID, nil)
+	if err != nil {
+		return err
+	}
+	product.Barcode = &code
+	return nil
+}
+
+// assignVariantCodes mengisi SKU varian dari SKU produk dan nilai opsinya
+// (diurutkan sesuai urutan tipe opsi), serta barcode EAN-13 jika kosong.
+func (r *codeRegistry) assignVariantCodes(variant *entity.ProductVariant, productSKU string, existingID *uuid.UUID) error {
+	options := append([]entity.OptionValue(nil), variant.Options...)
+	sort.SliceStable(options, func(i, j int) bool {
+		return optionTypeLess(options[i], options[j])
+	})
+	values := make([]string, 0, len(options))
+	for _, option := range options {
+		values = append(values, option.Value)
+	}
+
+	code, err := r.claimSKU(variant.SKU, sku.Make(productSKU, values...), nil, existingID)
+	if err != nil {
+		return err
+	}
+	variant.SKU = &code
+
+	code, err = r.claimBarcode(variant.Barcode, nil, existingID)
+	if err != nil {
+		return err
+	}
+	variant.Barcode = &code
+	return nil
+}
+
+func optionTypeLess(a, b entity.OptionValue) bool {
+	if a.OptionType != nil && b.OptionType != nil && a.OptionType.SortOrder != b.OptionType.SortOrder {
+		return a.OptionType.SortOrder < b.OptionType.SortOrder
+	}
+	return a.OptionTypeID < b.OptionTypeID
+}
@@ -0,0 +1,304 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"mola-web/configs"
+	"mola-web/internal/entity"
+	"mola-web/internal/http/dto"
+	"mola-web/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	LoyaltyTypeEarn    = "earn"
+	LoyaltyTypeRedeem  = "redeem"
+	LoyaltyTypeReverse = "reverse" // poin dari order yang batal/refund ditarik kembali
+	LoyaltyTypeRestore = "restore" // poin yang ditukar dikembalikan karena order batal
+	LoyaltyTypeExpire  = "expire"
+)
+
+// loyaltyHistoryLimit jumlah riwayat poin yang ditampilkan di profil.
+const loyaltyHistoryLimit = 20
+
+var ErrInsufficientPoints = errors.New("insufficient loyalty points")
+
+type LoyaltyService interface {
+	GetSummary(ctx context.Context, userID uuid.UUID) (*dto.LoyaltySummary, error)
+	CalculateRedemption(db *gorm.DB, userID uuid.UUID, points int, orderTotal float64) (float64, error)
+	Redeem(db *gorm.DB, userID uuid.UUID, orderID uuid.UUID, points int) error
+	CreditOrder(db *gorm.DB, order *entity.Order) error
+	ReverseOrder(db *gorm.DB, orderID uuid.UUID) error
+	ExpirePoints(ctx context.Context) error
+}
+
+type loyaltyService struct {
+	DB          *gorm.DB
+	loyaltyRepo repository.LoyaltyRepository
+	cfg         configs.LoyaltyConfig
+}
+
+func NewLoyaltyService(db *gorm.DB, loyaltyRepo repository.LoyaltyRepository, cfg configs.LoyaltyConfig) LoyaltyService {
+	return &loyaltyService{
+		DB:          db,
+		loyaltyRepo: loyaltyRepo,
+		cfg:         cfg,
+	}
+}
+
+func (s *loyaltyService) expiresAt() *time.Time {
+	if s.cfg.ExpiryDays <= 0 {
+		return nil
+	}
+	expiresAt := time.Now().AddDate(0, 0, s.cfg.ExpiryDays)
+	return &expiresAt
+}
+
+func (s *loyaltyService) GetSummary(ctx context.Context, userID uuid.UUID) (*dto.LoyaltySummary, error) {
+	db := s.DB.WithContext(ctx)
+	balance, err := s.loyaltyRepo.GetBalance(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.loyaltyRepo.GetHistory(db, userID, loyaltyHistoryLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.LoyaltySummary{
+		Balance:          balance,
+		PointValue:       s.cfg.PointValue,
+		MinRedeemPoints:  s.cfg.MinRedeemPoints,
+		MaxRedeemPercent: s.cfg.MaxRedeemPercent,
+		History:          []dto.LoyaltyTransactionResponse{},
+	}
+	for _, entry := range entries {
+		result.History = append(result.History, dto.LoyaltyTransactionResponse{
+			ID:          entry.ID,
+			OrderID:     entry.OrderID,
+			Type:        entry.Type,
+			Points:      entry.Points,
+			ExpiresAt:   entry.ExpiresAt,
+			Description: entry.Description,
+			CreatedAt:   entry.CreatedAt,
+		})
+	}
+	return result, nil
+}
+
+// CalculateRedemption memvalidasi penukaran poin terhadap saldo dan batas
+// konfigurasi lalu mengembalikan nilai diskonnya.
+func (s *loyaltyService) CalculateRedemption(db *gorm.DB, userID uuid.UUID, points int, orderTotal float64) (float64, error) {
+	if points <= 0 {
+		return 0, nil
+	}
+	if points < s.cfg.MinRedeemPoints {
+		return 0, fmt.Errorf("minimum %d points to redeem", s.cfg.MinRedeemPoints)
+	}
+	balance, err := s.loyaltyRepo.GetBalance(db, userID)
+	if err != nil {
+		return 0, err
+	}
+	if points > balance {
+		return 0, ErrInsufficientPoints
+	}
+
+	discount := float64(points) * s.cfg.PointValue
+	maxDiscount := orderTotal * s.cfg.MaxRedeemPercent / 100
+	if discount > maxDiscount {
+		return 0, fmt.Errorf("at most %d points can be redeemed for this order", int(math.Floor(maxDiscount/s.cfg.PointValue)))
+	}
+	return discount, nil
+}
+
+// consume memotong sisa poin masuk mulai dari yang paling cepat
+// kedaluwarsa. Mengembalikan jumlah poin yang benar-benar terpotong.
+func (s *loyaltyService) consume(db *gorm.DB, userID uuid.UUID, points int) (int, error) {
+	credits, err := s.loyaltyRepo.GetOpenCreditsForUpdate(db, userID, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	consumed := 0
+	for _, credit := range credits {
+		if consumed >= points {
+			break
+		}
+		take := credit.Remaining
+		if take > points-consumed {
+			take = points - consumed
+		}
+		if err := s.loyaltyRepo.UpdateRemaining(db, credit.ID, credit.Remaining-take); err != nil {
+			return 0, err
+		}
+		consumed += take
+	}
+	return consumed, nil
+}
+
+func (s *loyaltyService) Redeem(db *gorm.DB, userID uuid.UUID, orderID uuid.UUID, points int) error {
+	consumed, err := s.consume(db, userID, points)
+	if err != nil {
+		return err
+	}
+	if consumed < points {
+		return ErrInsufficientPoints
+	}
+	return s.loyaltyRepo.Create(db, &entity.LoyaltyTransaction{
+		UserID:      userID,
+		OrderID:     &orderID,
+		Type:        LoyaltyTypeRedeem,
+		Points:      -points,
+		Description: "Redeemed at checkout",
+	})
+}
+
+// CreditOrder memberi poin untuk order yang sudah lunas. Aman dipanggil
+// berulang untuk notifikasi pembayaran yang sama.
+func (s *loyaltyService) CreditOrder(db *gorm.DB, order *entity.Order) error {
+	entries, err := s.loyaltyRepo.GetByOrderID(db, order.ID)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.Type == LoyaltyTypeEarn {
+			return nil
+		}
+	}
+
+	if s.cfg.SpendPerPoint <= 0 {
+		return nil
+	}
+	points := int(math.Floor(order.TotalAmount / s.cfg.SpendPerPoint))
+	if points <= 0 {
+		return nil
+	}
+	return s.loyaltyRepo.Create(db, &entity.LoyaltyTransaction{
+		UserID:      order.UserID,
+		OrderID:     &order.ID,
+		Type:        LoyaltyTypeEarn,
+		Points:      points,
+		Remaining:   points,
+		ExpiresAt:   s.expiresAt(),
+		Description: "Earned from order " + order.OrderCode,
+	})
+}
+
+// ReverseOrder menarik poin yang didapat dari order dan mengembalikan poin
+// yang ditukar di order tersebut. Poin yang sudah terpakai hanya ditarik
+// sebatas saldo yang tersisa.
+func (s *loyaltyService) ReverseOrder(db *gorm.DB, orderID uuid.UUID) error {
+	entries, err := s.loyaltyRepo.GetByOrderID(db, orderID)
+	if err != nil {
+		return err
+	}
+	var earn, redeem *entity.LoyaltyTransaction
+	reversed, restored := false, false
+	expired := 0
+	for i := range entries {
+		switch entries[i].Type {
+		case LoyaltyTypeEarn:
+			earn = &entries[i]
+		case LoyaltyTypeRedeem:
+			redeem = &entries[i]
+		case LoyaltyTypeReverse:
+			reversed = true
+		case LoyaltyTypeRestore:
+			restored = true
+		case LoyaltyTypeExpire:
+			expired -= entries[i].Points
+		}
+	}
+
+	if earn != nil && !reversed {
+		// Potong dari poin order ini dulu, poin yang sudah terpakai diambil
+		// dari poin lain. Poin yang sudah hangus tidak dihitung lagi.
+		taken := earn.Remaining
+		if err := s.loyaltyRepo.UpdateRemaining(db, earn.ID, 0); err != nil {
+			return err
+		}
+		if spent := earn.Points - expired - earn.Remaining; spent > 0 {
+			consumed, err := s.consume(db, earn.UserID, spent)
+			if err != nil {
+				return err
+			}
+			taken += consumed
+		}
+		if err := s.loyaltyRepo.Create(db, &entity.LoyaltyTransaction{
+			UserID:      earn.UserID,
+			OrderID:     &orderID,
+			Type:        LoyaltyTypeReverse,
+			Points:      -taken,
+			Description: "Reversed because the order was cancelled or refunded",
+		}); err != nil {
+			return err
+		}
+	}
+
+	if redeem != nil && !restored {
+		points := -redeem.Points
+		if err := s.loyaltyRepo.Create(db, &entity.LoyaltyTransaction{
+			UserID:      redeem.UserID,
+			OrderID:     &orderID,
+			Type:        LoyaltyTypeRestore,
+			Points:      points,
+			Remaining:   points,
+			ExpiresAt:   s.expiresAt(),
+			Description: "Returned because the order was cancelled or refunded",
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExpirePoints menghanguskan sisa poin yang sudah melewati masa berlaku.
+func (s *loyaltyService) ExpirePoints(ctx context.Context) error {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			log.Printf("PANIC RECOVERED: Rolling back transaction due to panic: %v", p)
+			panic(p)
+		} else if tx.Error != nil {
+			tx.Rollback()
+			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
+		}
+	}()
+
+	credits, err := s.loyaltyRepo.GetExpiredCredits(tx, time.Now())
+	if err != nil {
+		tx.Error = err
+		return err
+	}
+	for _, credit := range credits {
+		if err := s.loyaltyRepo.Create(tx, &entity.LoyaltyTransaction{
+			UserID:      credit.UserID,
+			OrderID:     credit.OrderID,
+			Type:        LoyaltyTypeExpire,
+			Points:      -credit.Remaining,
+			Description: "Points expired",
+		}); err != nil {
+			tx.Error = err
+			return err
+		}
+		if err := s.loyaltyRepo.UpdateRemaining(tx, credit.ID, 0); err != nil {
+			tx.Error = err
+			return err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Error = err
+		return err
+	}
+	if len(credits) > 0 {
+		log.Printf("INFO: expired loyalty points on %d ledger entries", len(credits))
+	}
+	return nil
+}
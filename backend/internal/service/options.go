@@ -0,0 +1,479 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mola-web/internal/entity"
+	"mola-web/internal/http/dto"
+	"mola-web/internal/repository"
+	"mola-web/pkg/cache"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const optionsCacheKey = "options:Get-all"
+
+var (
+	ErrOptionTypeNotFound  = errors.New("option type not found")
+	ErrOptionValueNotFound = errors.New("option value not found")
+	ErrOptionInUse         = errors.New("option is still used by product variants")
+	ErrBuiltinOptionType   = errors.New("built-in Color and Size option types cannot be renamed or deleted")
+	ErrDuplicateOptionType = errors.New("a variant can only have one value per option type")
+	ErrVariantNoOptions    = errors.New("a variant must have at least one option")
+)
+
+type OptionService interface {
+	GetAll(ctx context.Context) ([]dto.OptionTypeInfo, error)
+	CreateType(ctx context.Context, request *dto.CreateOptionTypeRequest) error
+	UpdateType(ctx context.Context, request *dto.UpdateOptionTypeRequest) error
+	DeleteType(ctx context.Context, id uint) error
+	CreateValue(ctx context.Context, request *dto.CreateOptionValueRequest) error
+	UpdateValue(ctx context.Context, request *dto.UpdateOptionValueRequest) error
+	DeleteValue(ctx context.Context, id uint) error
+}
+
+type optionService struct {
+	DB        *gorm.DB
+	repo      repository.OptionRepository
+	cacheable cache.Cacheable
+}
+
+func NewOptionService(db *gorm.DB, repo repository.OptionRepository, cacheable cache.Cacheable) OptionService {
+	return &optionService{
+		DB:        db,
+		repo:      repo,
+		cacheable: cacheable,
+	}
+}
+
+func isBuiltinOptionType(name string) bool {
+	return name == entity.OptionTypeColor || name == entity.OptionTypeSize
+}
+
+func (s *optionService) GetAll(ctx context.Context) (results []dto.OptionTypeInfo, err error) {
+	data := s.cacheable.Get(optionsCacheKey)
+	if data != "" {
+		if err := json.Unmarshal([]byte(data), &results); err != nil {
+			return nil, err
+		}
+		return results, nil
+	}
+
+	optionTypes, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	results = []dto.OptionTypeInfo{}
+	for _, optionType := range optionTypes {
+		info := dto.OptionTypeInfo{
+			ID:        optionType.ID,
+			Name:      optionType.Name,
+			SortOrder: optionType.SortOrder,
+			Values:    []dto.OptionValueInfo{},
+		}
+		for _, value := range optionType.Values {
+			info.Values = append(info.Values, dto.OptionValueInfo{
+				ID:        value.ID,
+				Value:     value.Value,
+				SortOrder: value.SortOrder,
+			})
+		}
+		results = append(results, info)
+	}
+
+	marshalledData, err := json.Marshal(results)
+	if err != nil {
+		return nil, err
+	}
+	_ = s.cacheable.Set(optionsCacheKey, marshalledData)
+	return results, nil
+}
+
+// invalidateOptionCaches ikut menghapus cache warna, ukuran dan produk
+// karena nama opsi tampil di sana.
+func (s *optionService) invalidateOptionCaches() {
+	invalidateOptionCaches(s.cacheable)
+}
+
+func invalidateOptionCaches(cacheable cache.Cacheable) {
+	_ = cacheable.Delete(optionsCacheKey)
+	_ = cacheable.Delete("colors:Get-all")
+	_ = cacheable.Delete("sizes:Get-all")
+	for _, key := range cache.ListCacheKeysProductToInvalidate {
+		_ = cacheable.DeleteByPrefix(key)
+	}
+}
+
+func (s *optionService) CreateType(ctx context.Context, request *dto.CreateOptionTypeRequest) error {
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		return errors.New("option type name is required")
+	}
+	optionType := &entity.OptionType{Name: name, SortOrder: request.SortOrder}
+	if err := s.repo.CreateType(s.DB.WithContext(ctx), optionType); err != nil {
+		return err
+	}
+	request.ID = optionType.ID
+	s.invalidateOptionCaches()
+	return nil
+}
+
+func (s *optionService) UpdateType(ctx context.Context, request *dto.UpdateOptionTypeRequest) error {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			log.Printf("PANIC RECOVERED: Rolling back transaction due to panic: %v", p)
+			panic(p)
+		} else if tx.Error != nil {
+			tx.Rollback()
+			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
+		}
+	}()
+
+	current, err := s.repo.GetTypeByID(tx, request.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Error = ErrOptionTypeNotFound
+		return tx.Error
+	} else if err != nil {
+		tx.Error = err
+		return err
+	}
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		name = current.Name
+	}
+	if isBuiltinOptionType(current.Name) && name != current.Name {
+		tx.Error = ErrBuiltinOptionType
+		return tx.Error
+	}
+	if err := s.repo.UpdateType(tx, &entity.OptionType{ID: current.ID, Name: name, SortOrder: request.SortOrder}); err != nil {
+		tx.Error = err
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Error = err
+		return err
+	}
+	s.invalidateOptionCaches()
+	return nil
+}
+
+func (s *optionService) DeleteType(ctx context.Context, id uint) error {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			log.Printf("PANIC RECOVERED: Rolling back transaction due to panic: %v", p)
+			panic(p)
+		} else if tx.Error != nil {
+			tx.Rollback()
+			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
+		}
+	}()
+
+	current, err := s.repo.GetTypeByID(tx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Error = ErrOptionTypeNotFound
+		return tx.Error
+	} else if err != nil {
+		tx.Error = err
+		return err
+	}
+	if isBuiltinOptionType(current.Name) {
+		tx.Error = ErrBuiltinOptionType
+		return tx.Error
+	}
+	valueIDs := []uint{}
+	for _, value := range current.Values {
+		valueIDs = append(valueIDs, value.ID)
+	}
+	used, err := s.repo.CountValueUsage(tx, valueIDs)
+	if err != nil {
+		tx.Error = err
+		return err
+	}
+	if used > 0 {
+		tx.Error = ErrOptionInUse
+		return tx.Error
+	}
+	if err := s.repo.DeleteType(tx, id); err != nil {
+		tx.Error = err
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Error = err
+		return err
+	}
+	s.invalidateOptionCaches()
+	return nil
+}
+
+// CreateValue nilai baru bertipe Color atau Size ikut dibuat di tabel lama
+// supaya tetap bisa dipakai lewat color_id dan size_id.
+func (s *optionService) CreateValue(ctx context.Context, request *dto.CreateOptionValueRequest) error {
+	value := strings.TrimSpace(request.Value)
+	if value == "" {
+		return errors.New("option value is required")
+	}
+
+	tx := s.DB.WithContext(ctx).Begin()
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			log.Printf("PANIC RECOVERED: Rolling back transaction due to panic: %v", p)
+			panic(p)
+		} else if tx.Error != nil {
+			tx.Rollback()
+			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
+		}
+	}()
+
+	optionType, err := s.repo.GetTypeByID(tx, request.OptionTypeID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Error = ErrOptionTypeNotFound
+		return tx.Error
+	} else if err != nil {
+		tx.Error = err
+		return err
+	}
+	optionValue := &entity.OptionValue{
+		OptionTypeID: optionType.ID,
+		Value:        value,
+		SortOrder:    request.SortOrder,
+	}
+	if isBuiltinOptionType(optionType.Name) {
+		legacyID, err := s.repo.CreateLegacyRow(tx, optionType.Name, value)
+		if err != nil {
+			tx.Error = err
+			return err
+		}
+		optionValue.LegacyID = &legacyID
+	}
+	if err := s.repo.CreateValue(tx, optionValue); err != nil {
+		tx.Error = err
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Error = err
+		return err
+	}
+	request.ID = optionValue.ID
+	s.invalidateOptionCaches()
+	return nil
+}
+
+func (s *optionService) UpdateValue(ctx context.Context, request *dto.UpdateOptionValueRequest) error {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			log.Printf("PANIC RECOVERED: Rolling back transaction due to panic: %v", p)
+			panic(p)
+		} else if tx.Error != nil {
+			tx.Rollback()
+			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
+		}
+	}()
+
+	current, err := s.repo.GetValueByID(tx, request.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Error = ErrOptionValueNotFound
+		return tx.Error
+	} else if err != nil {
+		tx.Error = err
+		return err
+	}
+	value := strings.TrimSpace(request.Value)
+	if value == "" {
+		value = current.Value
+	}
+	if err := s.repo.UpdateValue(tx, &entity.OptionValue{ID: current.ID, Value: value, SortOrder: request.SortOrder}); err != nil {
+		tx.Error = err
+		return err
+	}
+	if current.LegacyID != nil && current.OptionType != nil {
+		if err := s.repo.RenameLegacyRow(tx, current.OptionType.Name, *current.LegacyID, value); err != nil {
+			tx.Error = err
+			return err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Error = err
+		return err
+	}
+	s.invalidateOptionCaches()
+	return nil
+}
+
+func (s *optionService) DeleteValue(ctx context.Context, id uint) error {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			log.Printf("PANIC RECOVERED: Rolling back transaction due to panic: %v", p)
+			panic(p)
+		} else if tx.Error != nil {
+			tx.Rollback()
+			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
+		}
+	}()
+
+	if _, err := s.repo.GetValueByID(tx, id); errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Error = ErrOptionValueNotFound
+		return tx.Error
+	} else if err != nil {
+		tx.Error = err
+		return err
+	}
+	used, err := s.repo.CountValueUsage(tx, []uint{id})
+	if err != nil {
+		tx.Error = err
+		return err
+	}
+	if used > 0 {
+		tx.Error = ErrOptionInUse
+		return tx.Error
+	}
+	if err := s.repo.DeleteValue(tx, id); err != nil {
+		tx.Error = err
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Error = err
+		return err
+	}
+	s.invalidateOptionCaches()
+	return nil
+}
+
+// resolveVariantOptions menggabungkan option_value_ids dengan color_id dan
+// size_id lama menjadi daftar nilai opsi varian, lalu menentukan salinan
+// ColorID dan SizeID-nya.
+func resolveVariantOptions(tx *gorm.DB, repo repository.OptionRepository, colorID *uint, sizeID *uint, valueIDs []uint) ([]entity.OptionValue, *uint, *uint, error) {
+	values, err := repo.GetValuesByIDs(tx, valueIDs)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	found := map[uint]struct{}{}
+	for _, value := range values {
+		found[value.ID] = struct{}{}
+	}
+	for _, id := range valueIDs {
+		if _, ok := found[id]; !ok {
+			return nil, nil, nil, fmt.Errorf("%w: %d", ErrOptionValueNotFound, id)
+		}
+	}
+
+	for _, legacy := range []struct {
+		Type string
+		ID   *uint
+	}{
+		{entity.OptionTypeColor, colorID},
+		{entity.OptionTypeSize, sizeID},
+	} {
+		if legacy.ID == nil {
+			continue
+		}
+		value, err := repo.GetLegacyValue(tx, legacy.Type, *legacy.ID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, nil, fmt.Errorf("%w: %s %d", ErrOptionValueNotFound, strings.ToLower(legacy.Type), *legacy.ID)
+		} else if err != nil {
+			return nil, nil, nil, err
+		}
+		if _, ok := found[value.ID]; !ok {
+			values = append(values, *value)
+			found[value.ID] = struct{}{}
+		}
+	}
+	if len(values) == 0 {
+		return nil, nil, nil, ErrVariantNoOptions
+	}
+
+	var resolvedColorID, resolvedSizeID *uint
+	types := map[uint]struct{}{}
+	for _, value := range values {
+		if _, ok := types[value.OptionTypeID]; ok {
+			return nil, nil, nil, ErrDuplicateOptionType
+		}
+		types[value.OptionTypeID] = struct{}{}
+		if value.OptionType == nil || value.LegacyID == nil {
+			continue
+		}
+		switch value.OptionType.Name {
+		case entity.OptionTypeColor:
+			resolvedColorID = value.LegacyID
+		case entity.OptionTypeSize:
+			resolvedSizeID = value.LegacyID
+		}
+	}
+	return values, resolvedColorID, resolvedSizeID, nil
+}
+
+// variantOptionKey kunci kombinasi opsi varian, urutan nilai tidak
+// berpengaruh.
+func variantOptionKey(values []entity.OptionValue) string {
+	ids := make([]int, 0, len(values))
+	for _, value := range values {
+		ids = append(ids, int(value.ID))
+	}
+	sort.Ints(ids)
+	return fmt.Sprint(ids)
+}
+
+// applyVariantOptions mengisi daftar opsi varian. Color dan Size diisi dari
+// opsi jika relasi lamanya kosong.
+func applyVariantOptions(info *dto.ProductVariantInfo, variant entity.ProductVariant) {
+	options := append([]entity.OptionValue{}, variant.Options...)
+	sort.SliceStable(options, func(i, j int) bool {
+		return optionTypeLess(options[i], options[j])
+	})
+
+	info.Options = []dto.VariantOptionInfo{}
+	for _, option := range options {
+		optionInfo := dto.VariantOptionInfo{
+			OptionTypeID:  option.OptionTypeID,
+			OptionValueID: option.ID,
+			Value:         option.Value,
+		}
+		if option.OptionType != nil {
+			optionInfo.OptionType = option.OptionType.Name
+			switch {
+			case option.OptionType.Name == entity.OptionTypeColor && info.Color == "":
+				info.Color = option.Value
+			case option.OptionType.Name == entity.OptionTypeSize && info.Size == "":
+				info.Size = option.Value
+			}
+		}
+		info.Options = append(info.Options, optionInfo)
+	}
+}
+
+// deleteLegacyOptionValue menghapus nilai opsi milik warna atau ukuran yang
+// dihapus, selama belum dipakai varian.
+func deleteLegacyOptionValue(tx *gorm.DB, repo repository.OptionRepository, typeName string, legacyID uint) error {
+	value, err := repo.GetLegacyValue(tx, typeName, legacyID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	used, err := repo.CountValueUsage(tx, []uint{value.ID})
+	if err != nil {
+		return err
+	}
+	if used > 0 {
+		return nil
+	}
+	return repo.DeleteValue(tx, value.ID)
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"math"
 	"math/rand"
 	"mola-web/configs"
 	"mola-web/internal/entity"
@@ -21,12 +22,15 @@ import (
 	"gorm.io/gorm"
 )
 
+// downPaymentRate adalah porsi harga yang dibayar di muka lewat Midtrans.
+const downPaymentRate = 0.3
+
 type OrderService interface {
 	CreateOrder(ctx context.Context, order entity.Order) (uuid.UUID, error)
 	CreateOrderItem(ctx context.Context, orderItem entity.OrderItem) error
 	GetAllOrders(ctx context.Context) ([]dto.GetAllOrdersResponse, error)
 	GetAllOrdersPaid(ctx context.Context) ([]dto.GetOrdersPaidResponse, error)
-	Checkout(ctx context.Context, userID uuid.UUID, email string, name string, selectedItems []uuid.UUID) (*dto.SnapRsponse, error)
+	Checkout(ctx context.Context, userID uuid.UUID, email string, name string, selectedItems []uuid.UUID, redeemPoints int, storeCredit float64) (*dto.SnapRsponse, error)
 	SetAdminOrderStatus(ctx context.Context, id uuid.UUID, status string) error
 	ShowOrder(ctx context.Context, userID uuid.UUID) ([]dto.ShowOrderResponse, error)
 	ExpireUninitializedOrders() error
@@ -38,18 +42,30 @@ type orderService struct {
 	cartRepo       repository.CartRepository
 	cartService    CartService
 	productService ProductService
+	cartAbandonmentService CartAbandonmentService
+	voucherService VoucherService
+	saleCampaignService SaleCampaignService
+	loyaltyService LoyaltyService
+	referralService ReferralService
+	storeCreditService StoreCreditService
 	cacheable      cache.Cacheable
 	token          token.TokenUseCase
 	config         configs.MidtransConfig
 }
 
-func NewOrderService(db *gorm.DB, orderRepo repository.OrderRepository, cartRepo repository.CartRepository, cartService CartService, productService ProductService, cacheable cache.Cacheable, token token.TokenUseCase, config configs.MidtransConfig) OrderService {
+func NewOrderService(db *gorm.DB, orderRepo repository.OrderRepository, cartRepo repository.CartRepository, cartService CartService, productService ProductService, cartAbandonmentService CartAbandonmentService, voucherService VoucherService, saleCampaignService SaleCampaignService, loyaltyService LoyaltyService, referralService ReferralService, storeCreditService StoreCreditService, cacheable cache.Cacheable, token token.TokenUseCase, config configs.MidtransConfig) OrderService {
 	return &orderService{
 		DB:             db,
 		orderRepo:      orderRepo,
 		cartRepo:       cartRepo,
 		cartService:    cartService,
 		productService: productService,
+		cartAbandonmentService: cartAbandonmentService,
+		voucherService: voucherService,
+		saleCampaignService: saleCampaignService,
+		loyaltyService: loyaltyService,
+		referralService: referralService,
+		storeCreditService: storeCreditService,
 		cacheable:      cacheable,
 		token:          token,
 		config:         config,
@@ -140,7 +156,7 @@ func (s *orderService) CreateOrderItem(ctx context.Context, orderItem entity.Ord
 	return nil
 }
 
-func (s *orderService) Checkout(ctx context.Context, userID uuid.UUID, email string, name string, selectedItems []uuid.UUID) (*dto.SnapRsponse, error) {
+func (s *orderService) Checkout(ctx context.Context, userID uuid.UUID, email string, name string, selectedItems []uuid.UUID, redeemPoints int, storeCredit float64) (*dto.SnapRsponse, error) {
 	tx := s.DB.WithContext(ctx).Begin()
 	defer func() {
 		if p := recover(); p != nil {
@@ -152,7 +168,19 @@ func (s *orderService) Checkout(ctx context.Context, userID uuid.UUID, email str
 			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
 		}
 	}()
-	cartData, err := s.cartService.GetCartByUserID(tx, userID)
+	// Validasi stok dan harga terkini sebelum membuat order
+	validation, err := s.cartService.CheckCart(tx, userID, selectedItems)
+	if err != nil {
+		tx.Error = err
+		return nil, err
+	}
+	if !validation.Valid {
+		tx.Error = &CartValidationError{Result: validation}
+		return nil, tx.Error
+	}
+
+	// Harga dihitung ulang dari item yang dipilih, tidak dari cache
+	cartData, err := s.cartService.GetSelectedCart(tx, userID, selectedItems)
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		tx.Error = err
 		return nil, errors.New("cart not found")
@@ -161,29 +189,32 @@ func (s *orderService) Checkout(ctx context.Context, userID uuid.UUID, email str
 		return nil, err
 	}
 
-	filteredItems := []dto.CartItems{}
-	for _, item := range cartData.CartItems {
-		for _, selectedID := range selectedItems {
-			if item.CartItemsID == selectedID {
-				filteredItems = append(filteredItems, item)
-			}
-		}
-	}
+	filteredItems := cartData.CartItems
 	if len(filteredItems) == 0 {
 		tx.Error = errors.New("no selected items in cart")
 		return nil, tx.Error
 	}
 
+	// Hitung ulang voucher untuk item yang dipilih
+	var discount *dto.VoucherDiscount
+	if cartData.VoucherCode != nil {
+		discount, err = s.voucherService.CalculateDiscount(tx, *cartData.VoucherCode, userID, filteredItems)
+		if err != nil {
+			tx.Error = fmt.Errorf("voucher %s: %w", *cartData.VoucherCode, err)
+			return nil, tx.Error
+		}
+	}
+
 	orderCode := GenerateOrderCode()
-	var totalAmount float64
 	var total float64
 	var items []midtrans.ItemDetails
 	var enabledPaymentsTypes []snap.SnapPaymentType
 	enabledPaymentsTypes = append(enabledPaymentsTypes, snap.AllSnapPaymentType...)
+	var totalWeight float64
 	for i, item := range filteredItems {
 		itemTotal := float64(item.Product.Price) * float64(item.Quantity)
-		totalAmount += itemTotal * 0.3
 		total += itemTotal
+		totalWeight += item.Product.Weight * float64(item.Quantity)
 
 		productName := item.Product.Name
 		if item.Product.HasVariant {
@@ -200,10 +231,24 @@ func (s *orderService) Checkout(ctx context.Context, userID uuid.UUID, email str
 		items = append(items, midtrans.ItemDetails{
 			ID:    item.Product.ID.String(),
 			Name:  productName,
-			Price: int64(float64(item.Product.Price) * 0.3),
+			Price: int64(float64(item.Product.Price) * downPaymentRate),
 			Qty:   int32(item.Quantity),
 		})
-		if item.Product.HasVariant {
+		if item.Product.ProductType == ProductTypeBundle {
+			// Stok bundle dipotong dari setiap komponennya
+			for _, component := range item.Components {
+				quantity := int64(item.Quantity * component.Quantity)
+				if component.ProductVariantID != nil {
+					err = s.productService.UpdateStockProductVariantOnOrder(tx, *component.ProductVariantID, quantity)
+				} else {
+					err = s.productService.UpdateStockProductOnOrder(tx, component.ProductID, quantity)
+				}
+				if err != nil {
+					tx.Error = err
+					return nil, err
+				}
+			}
+		} else if item.Product.HasVariant {
 			for _, value := range item.Product.Variants {
 				err = s.productService.UpdateStockProductVariantOnOrder(tx, value.ID, int64(item.Quantity))
 				if err != nil {
@@ -219,21 +264,128 @@ func (s *orderService) Checkout(ctx context.Context, userID uuid.UUID, email str
 			}
 		}
 	}
+	// Diskon dikirim ke Midtrans sebagai item bernilai negatif, tidak boleh
+	// melebihi total item karena pembulatan per baris
+	if discount != nil {
+		items = append(items, midtrans.ItemDetails{
+			ID:    "VOUCHER-" + discount.Code,
+			Name:  "Voucher " + discount.Code,
+			Price: -min(int64(discount.DiscountAmount*downPaymentRate), itemsTotal(items)),
+			Qty:   1,
+		})
+	}
+
+	// Penukaran poin dihitung dari total setelah voucher
+	var pointsDiscount float64
+	if redeemPoints > 0 {
+		orderTotal := total
+		if discount != nil {
+			orderTotal -= discount.DiscountAmount
+		}
+		pointsDiscount, err = s.loyaltyService.CalculateRedemption(tx, userID, redeemPoints, orderTotal)
+		if err != nil {
+			tx.Error = err
+			return nil, err
+		}
+		items = append(items, midtrans.ItemDetails{
+			ID:    "POINTS",
+			Name:  fmt.Sprintf("Loyalty points (%d)", redeemPoints),
+			Price: -min(int64(pointsDiscount*downPaymentRate), itemsTotal(items)),
+			Qty:   1,
+		})
+	}
+
+	// Kredit toko membayar tagihan Midtrans, bukan mengurangi nilai order
+	amountDue := itemsTotal(items)
+	var creditUsed float64
+	if storeCredit > 0 {
+		creditUsed = math.Min(math.Floor(storeCredit), float64(amountDue))
+		balance, err := s.storeCreditService.GetBalance(tx, userID)
+		if err != nil {
+			tx.Error = err
+			return nil, err
+		}
+		if creditUsed > balance {
+			tx.Error = ErrInsufficientCredit
+			return nil, tx.Error
+		}
+		if creditUsed > 0 {
+			items = append(items, midtrans.ItemDetails{
+				ID:    "STORE-CREDIT",
+				Name:  "Store credit",
+				Price: -int64(creditUsed),
+				Qty:   1,
+			})
+		}
+	}
+	// Tagihan habis oleh voucher, poin, atau kredit toko
+	paidWithCredit := amountDue <= 0 || int64(creditUsed) == amountDue
+
 	order := entity.Order{
 		UserID:        userID,
 		OrderCode:     orderCode,
 		Status:        "pending",
 		IsPaid:        false,
 		TotalAmount:   float64(total),
-		// TotalWeight:   float64(cartData.TotalWeight),
+		TotalWeight:   totalWeight,
 		PaymentStatus: "pending",
 	}
+	if discount != nil {
+		order.TotalAmount = total - discount.DiscountAmount
+		order.DiscountAmount = discount.DiscountAmount
+		order.VoucherCode = &discount.Code
+	}
+	if pointsDiscount > 0 {
+		order.TotalAmount -= pointsDiscount
+		order.PointsRedeemed = redeemPoints
+		order.PointsDiscount = pointsDiscount
+	}
+	if creditUsed > 0 {
+		order.StoreCreditUsed = creditUsed
+	}
+	if paidWithCredit {
+		order.IsPaid = true
+		order.PaymentStatus = "lunas"
+	}
 	orderID, err := s.orderRepo.CreateOrder(tx, &order)
 	if err != nil {
 		tx.Error = err
 		return nil, err
 	}
 
+	if discount != nil {
+		if err := s.voucherService.Redeem(tx, discount, userID, orderID); err != nil {
+			tx.Error = fmt.Errorf("voucher %s: %w", discount.Code, err)
+			return nil, tx.Error
+		}
+		if err := s.cartRepo.SetVoucherCode(tx, cartData.CartID, nil); err != nil {
+			tx.Error = err
+			return nil, err
+		}
+	}
+
+	if pointsDiscount > 0 {
+		if err := s.loyaltyService.Redeem(tx, userID, orderID, redeemPoints); err != nil {
+			tx.Error = err
+			return nil, err
+		}
+	}
+
+	if err := s.storeCreditService.Debit(tx, userID, orderID, creditUsed); err != nil {
+		tx.Error = err
+		return nil, err
+	}
+
+	// Catat konversi jika keranjang sebelumnya terbengkalai
+	if err := s.cartAbandonmentService.RecordConversion(tx, cartData.CartID, orderID); err != nil {
+		tx.Error = err
+		return nil, err
+	}
+	if err := s.cartRepo.ReactivateCart(tx, userID); err != nil {
+		tx.Error = err
+		return nil, err
+	}
+
 	for _, item := range filteredItems {
 		orderItem := entity.OrderItem{
 			OrderID:          orderID,
@@ -243,6 +395,24 @@ func (s *orderService) Checkout(ctx context.Context, userID uuid.UUID, email str
 			Price:            item.Product.Price,
 			Subtotal:         item.Subtotal,
 			Note:             item.Note,
+			SaleCampaignID:   item.SaleCampaignID,
+			Weight:           item.Product.Weight,
+		}
+		for _, component := range item.Components {
+			orderItem.Components = append(orderItem.Components, entity.OrderItemComponent{
+				ProductID:        component.ProductID,
+				ProductVariantID: component.ProductVariantID,
+				Quantity:         item.Quantity * component.Quantity,
+				ProductName:      component.ProductName,
+				VariantName:      componentVariantName(component),
+			})
+		}
+		// Kuota promo dipotong saat checkout
+		if item.SaleCampaignID != nil {
+			if err := s.saleCampaignService.RecordSold(tx, *item.SaleCampaignID, item.Quantity); err != nil {
+				tx.Error = fmt.Errorf("%s: %w", item.Product.Name, err)
+				return nil, tx.Error
+			}
 		}
 		if item.Product.HasVariant {
 			for _, value := range item.Product.Variants {
@@ -255,6 +425,13 @@ func (s *orderService) Checkout(ctx context.Context, userID uuid.UUID, email str
 				orderItem.ProductVariantID = &value.ID
 			}
 		}
+		// SKU varian yang dipilih, produk tanpa varian memakai SKU produk
+		orderItem.SKU = item.Product.SKU
+		for _, variant := range item.Product.Variants {
+			if orderItem.ProductVariantID != nil && variant.ID == *orderItem.ProductVariantID && variant.SKU != nil {
+				orderItem.SKU = variant.SKU
+			}
+		}
 
 		if err := s.orderRepo.CreateOrderItem(tx, &orderItem); err != nil {
 			tx.Error = err
@@ -357,6 +534,26 @@ func (s *orderService) Checkout(ctx context.Context, userID uuid.UUID, email str
 	// 	return nil, err
 	// }
 
+	// Tagihan sudah lunas, tidak perlu ke Midtrans
+	if paidWithCredit {
+		if err := s.loyaltyService.CreditOrder(tx, &order); err != nil {
+			tx.Error = err
+			return nil, err
+		}
+		if err := s.referralService.ConvertOnPaid(tx, &order); err != nil {
+			tx.Error = err
+			return nil, err
+		}
+		if err := tx.Commit().Error; err != nil {
+			tx.Error = err
+			return nil, err
+		}
+		_ = s.cacheable.Delete("carts:" + userID.String())
+		_ = s.cacheable.Delete("orders:show-order:" + userID.String())
+		_ = s.cacheable.Delete("orders:all-orders")
+		return &dto.SnapRsponse{PaidWithCredit: true}, nil
+	}
+
 	m := snap.Client{}
 	isProduction := s.config.IsProduction == "true"
 	if isProduction {
@@ -365,13 +562,13 @@ func (s *orderService) Checkout(ctx context.Context, userID uuid.UUID, email str
 		m.New(s.config.ServerKey, midtrans.Sandbox)
 	}
 
-	// totalPaid := float64(cartData.TotalAmount) * 0.3
-	totalPaid := totalAmount
+	// Gross amount harus sama dengan jumlah semua item yang dikirim
+	totalPaid := itemsTotal(items)
 	log.Println("totalPaid: ", totalPaid)
 	req := &snap.Request{
 		TransactionDetails: midtrans.TransactionDetails{
 			OrderID:  orderID.String(),
-			GrossAmt: int64(totalPaid),
+			GrossAmt: totalPaid,
 		},
 		CustomerDetail: &midtrans.CustomerDetails{
 			FName: name,
@@ -384,7 +581,11 @@ func (s *orderService) Checkout(ctx context.Context, userID uuid.UUID, email str
 		},
 	}
 
-	snapResp, _ := m.CreateTransaction(req)
+	snapResp, snapErr := m.CreateTransaction(req)
+	if snapErr != nil {
+		tx.Error = snapErr
+		return nil, errors.New("failed to create payment: " + snapErr.GetMessage())
+	}
 	response := dto.SnapRsponse{
 		Token:       snapResp.Token,
 		RedirectURL: snapResp.RedirectURL,
@@ -404,6 +605,29 @@ func (s *orderService) Checkout(ctx context.Context, userID uuid.UUID, email str
 	return &response, nil
 }
 
+// itemsTotal jumlah tagihan dari item yang dikirim ke Midtrans.
+func itemsTotal(items []midtrans.ItemDetails) int64 {
+	var total int64
+	for _, item := range items {
+		total += item.Price * int64(item.Qty)
+	}
+	return total
+}
+
+func orderItemComponents(components []entity.OrderItemComponent) []dto.OrderItemComponent {
+	var results []dto.OrderItemComponent
+	for _, component := range components {
+		results = append(results, dto.OrderItemComponent{
+			ProductID:        component.ProductID,
+			ProductVariantID: component.ProductVariantID,
+			ProductName:      component.ProductName,
+			VariantName:      component.VariantName,
+			Quantity:         component.Quantity,
+		})
+	}
+	return results
+}
+
 func GenerateOrderCode() string {
 	now := time.Now()
 	date := now.Format("20060102")
@@ -435,6 +659,14 @@ func (s *orderService) SetAdminOrderStatus(ctx context.Context, id uuid.UUID, st
 	return nil
 }
 
+// orderItemWeight berat saat checkout, order lama belum menyimpannya.
+func orderItemWeight(item entity.OrderItem, product *entity.Product, variant *entity.ProductVariant) float64 {
+	if item.Weight > 0 {
+		return item.Weight
+	}
+	return variantWeight(product, variant)
+}
+
 func (s *orderService) ShowOrder(ctx context.Context, userID uuid.UUID) ([]dto.ShowOrderResponse, error) {
 	key := "orders:show-order:" + userID.String()
 	var results []dto.ShowOrderResponse
@@ -490,6 +722,7 @@ func (s *orderService) ShowOrder(ctx context.Context, userID uuid.UUID) ([]dto.S
 				if vari.Size != nil {
 					variantDTO.Size = vari.Size.Name
 				}
+				applyVariantOverrides(&variantDTO, product, *vari)
 				variants = append(variants, variantDTO)
 			}
 
@@ -501,18 +734,19 @@ func (s *orderService) ShowOrder(ctx context.Context, userID uuid.UUID) ([]dto.S
 					Name:         product.Name,
 					CategoryID:   product.CategoryID,
 					Description:  product.Description,
-					ImageURL:     product.ImageURL,
+					ImageURL:     variantImageURL(product, vari),
 					HasVariant:   product.HasVariant,
-					Price:        product.Price,
-					Weight:       product.Weight,
+					Price:        item.Price, // harga saat checkout, termasuk promo
+					Weight:       orderItemWeight(item, product, vari),
 					Stock:        product.Stock,
 					CategoryName: categoryName,
 					SizeName:     sizeName,
 					ColorName:    colorName,
 					Variants:     variants,
 				},
-				Subtotal: float64(item.Quantity) * product.Price,
+				Subtotal: item.Subtotal,
 			}
+			itemResp.Components = orderItemComponents(item.Components)
 			items = append(items, itemResp)
 		}
 
@@ -522,7 +756,12 @@ func (s *orderService) ShowOrder(ctx context.Context, userID uuid.UUID) ([]dto.S
 			OrderCode:     order.OrderCode,
 			Status:        order.Status,
 			TotalAmount:   order.TotalAmount,
-			TotalPaid:     float64(order.TotalAmount) * 0.3,
+			DiscountAmount: order.DiscountAmount,
+			VoucherCode:   order.VoucherCode,
+			PointsRedeemed: order.PointsRedeemed,
+			PointsDiscount: order.PointsDiscount,
+			StoreCreditUsed: order.StoreCreditUsed,
+			TotalPaid:     float64(order.TotalAmount) * downPaymentRate,
 			TotalWeight:   order.TotalWeight,
 			PaymentStatus: order.PaymentStatus,
 			OrderItems:    items,
@@ -590,6 +829,7 @@ func (s *orderService) GetAllOrders(ctx context.Context) ([]dto.GetAllOrdersResp
 				if vari.Size != nil {
 					variantDTO.Size = vari.Size.Name
 				}
+				applyVariantOverrides(&variantDTO, product, *vari)
 				variants = append(variants, variantDTO)
 
 			} else {
@@ -609,10 +849,10 @@ func (s *orderService) GetAllOrders(ctx context.Context) ([]dto.GetAllOrdersResp
 					Name:         product.Name,
 					CategoryID:   product.CategoryID,
 					Description:  product.Description,
-					ImageURL:     product.ImageURL,
+					ImageURL:     variantImageURL(product, vari),
 					HasVariant:   product.HasVariant,
-					Price:        product.Price,
-					Weight:       product.Weight,
+					Price:        item.Price, // harga saat checkout, termasuk promo
+					Weight:       orderItemWeight(item, product, vari),
 					Stock:        product.Stock,
 					CategoryName: categoryName,
 					SizeName:     sizeName,
@@ -620,6 +860,7 @@ func (s *orderService) GetAllOrders(ctx context.Context) ([]dto.GetAllOrdersResp
 					Variants:     variants,
 				},
 			}
+			itemResp.Components = orderItemComponents(item.Components)
 			items = append(items, itemResp)
 		}
 
@@ -630,7 +871,12 @@ func (s *orderService) GetAllOrders(ctx context.Context) ([]dto.GetAllOrdersResp
 			OrderCode:     order.OrderCode,
 			Status:        order.Status,
 			TotalAmount:   order.TotalAmount,
-			TotalPaid:     float64(order.TotalAmount) * 0.3,
+			DiscountAmount: order.DiscountAmount,
+			VoucherCode:   order.VoucherCode,
+			PointsRedeemed: order.PointsRedeemed,
+			PointsDiscount: order.PointsDiscount,
+			StoreCreditUsed: order.StoreCreditUsed,
+			TotalPaid:     float64(order.TotalAmount) * downPaymentRate,
 			TotalWeight:   order.TotalWeight,
 			PaymentStatus: order.PaymentStatus,
 			OrderItems:    items,
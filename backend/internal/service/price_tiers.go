@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mola-web/internal/entity"
+	"mola-web/internal/http/dto"
+	"mola-web/internal/repository"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func customerGroupOf(user *entity.User) *string {
+	if user == nil {
+		return nil
+	}
+	return user.CustomerGroup
+}
+
+// priceTierFor mencari tier termurah yang cocok dengan jumlah dan grup
+// pelanggan. Tier tanpa grup berlaku untuk semua pelanggan.
+func priceTierFor(product *entity.Product, quantity int, group *string) *entity.ProductPriceTier {
+	var best *entity.ProductPriceTier
+	for i := range product.PriceTiers {
+		tier := &product.PriceTiers[i]
+		if quantity < tier.MinQuantity || (tier.MaxQuantity != nil && quantity > *tier.MaxQuantity) {
+			continue
+		}
+		if tier.CustomerGroup != nil && (group == nil || !strings.EqualFold(*tier.CustomerGroup, *group)) {
+			continue
+		}
+		if best == nil || tier.Price < best.Price {
+			best = tier
+		}
+	}
+	return best
+}
+
+// tierQuantities menjumlahkan kuantitas item keranjang per produk. Tier
+// grosir berlaku per produk, jadi semua warna dan ukuran ikut dihitung.
+func tierQuantities(cartItems []entity.CartItem) map[uuid.UUID]int {
+	quantities := make(map[uuid.UUID]int, len(cartItems))
+	for _, item := range cartItems {
+		quantities[item.ProductID] += item.Quantity
+	}
+	return quantities
+}
+
+// repriceCartLines menyimpan ulang harga semua item keranjang untuk produk
+// yang sama setelah kuantitasnya berubah, supaya tier grosir yang dicapai
+// gabungan beberapa varian ikut tercatat.
+func repriceCartLines(db *gorm.DB, cartRepo repository.CartRepository, cartID uuid.UUID, product *entity.Product, group *string, sales *SalePriceIndex) error {
+	cartItems, err := cartRepo.GetCartItemsByProductID(db, cartID, product.ID)
+	if err != nil {
+		return err
+	}
+	quantity := tierQuantities(cartItems)[product.ID]
+	for _, item := range cartItems {
+		price := unitPrice(product, productVariantByID(product, item.ProductVariantID), quantity, group, sales)
+		if price == item.Price {
+			continue
+		}
+		if err := cartRepo.UpdateCartItems(db, &entity.CartItem{ID: item.ID, Price: price}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// linePrice menghitung harga satuan item keranjang dari harga varian jika
+// ada. Promo dan tier grosir tidak digabung, yang dipakai adalah harga
+// termurah di antara keduanya. quantity adalah total kuantitas produk di
+// keranjang, bukan kuantitas satu item.
+func linePrice(product *entity.Product, variant *entity.ProductVariant, quantity int, group *string, sales *SalePriceIndex) (float64, *ActiveSale, *entity.ProductPriceTier) {
+	price := variantPrice(product, variant)
+	sale := sales.Lookup(product.ID, variantIDOf(variant), price)
+	if sale != nil {
+		price = sale.Price
+	}
+	tier := priceTierFor(product, quantity, group)
+	if tier != nil && tier.Price < price {
+		return tier.Price, nil, tier
+	}
+	return price, sale, nil
+}
+
+func unitPrice(product *entity.Product, variant *entity.ProductVariant, quantity int, group *string, sales *SalePriceIndex) float64 {
+	price, _, _ := linePrice(product, variant, quantity, group, sales)
+	return price
+}
+
+func priceTierInfo(tier *entity.ProductPriceTier) *dto.PriceTierInfo {
+	if tier == nil {
+		return nil
+	}
+	return &dto.PriceTierInfo{
+		MinQuantity:   tier.MinQuantity,
+		MaxQuantity:   tier.MaxQuantity,
+		Price:         tier.Price,
+		CustomerGroup: tier.CustomerGroup,
+	}
+}
+
+func priceTierInfos(product *entity.Product) []dto.PriceTierInfo {
+	infos := []dto.PriceTierInfo{}
+	for i := range product.PriceTiers {
+		infos = append(infos, *priceTierInfo(&product.PriceTiers[i]))
+	}
+	return infos
+}
+
+// validatePriceTiers memastikan rentang jumlah dalam satu grup tidak saling
+// tumpang tindih.
+func validatePriceTiers(tiers []dto.PriceTierRequest) error {
+	byGroup := map[string][]dto.PriceTierRequest{}
+	for i, tier := range tiers {
+		if tier.MinQuantity < 1 {
+			return fmt.Errorf("tier %d: minimum quantity must be at least 1", i+1)
+		}
+		if tier.MaxQuantity != nil && *tier.MaxQuantity < tier.MinQuantity {
+			return fmt.Errorf("tier %d: maximum quantity must not be less than minimum quantity", i+1)
+		}
+		if tier.Price <= 0 {
+			return fmt.Errorf("tier %d: price must be greater than 0", i+1)
+		}
+		group := ""
+		if tier.CustomerGroup != nil {
+			group = strings.ToLower(strings.TrimSpace(*tier.CustomerGroup))
+		}
+		byGroup[group] = append(byGroup[group], tier)
+	}
+
+	for group, groupTiers := range byGroup {
+		sort.Slice(groupTiers, func(i, j int) bool {
+			return groupTiers[i].MinQuantity < groupTiers[j].MinQuantity
+		})
+		for i := 1; i < len(groupTiers); i++ {
+			prev := groupTiers[i-1]
+			if prev.MaxQuantity == nil || *prev.MaxQuantity >= groupTiers[i].MinQuantity {
+				if group == "" {
+					return errors.New("price tiers must not overlap")
+				}
+				return fmt.Errorf("price tiers for group %s must not overlap", group)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *productService) UpdatePriceTiers(ctx context.Context, req *dto.UpdatePriceTiersRequest) error {
+	if err := validatePriceTiers(req.Tiers); err != nil {
+		return err
+	}
+
+	tx := s.DB.WithContext(ctx).Begin()
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			log.Printf("PANIC RECOVERED: Rolling back transaction due to panic: %v", p)
+			panic(p)
+		} else if tx.Error != nil {
+			tx.Rollback()
+			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
+		}
+	}()
+
+	if _, err := s.repo.GetByID(tx, req.ProductID); errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Error = errors.New("product not found")
+		return tx.Error
+	} else if err != nil {
+		tx.Error = err
+		return err
+	}
+
+	tiers := []entity.ProductPriceTier{}
+	for _, tier := range req.Tiers {
+		var group *string
+		if tier.CustomerGroup != nil && strings.TrimSpace(*tier.CustomerGroup) != "" {
+			trimmed := strings.ToLower(strings.TrimSpace(*tier.CustomerGroup))
+			group = &trimmed
+		}
+		tiers = append(tiers, entity.ProductPriceTier{
+			ProductID:     req.ProductID,
+			MinQuantity:   tier.MinQuantity,
+			MaxQuantity:   tier.MaxQuantity,
+			Price:         tier.Price,
+			CustomerGroup: group,
+		})
+	}
+	if err := s.repo.ReplacePriceTiers(tx, req.ProductID, tiers); err != nil {
+		tx.Error = err
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Error = err
+		return err
+	}
+
+	if err := s.invalidateProductListCaches(); err != nil {
+		log.Println(err)
+	}
+	// Total keranjang yang berisi produk ini ikut berubah
+	if err := s.cacheable.DeleteByPrefix("carts:"); err != nil {
+		log.Printf("WARNING: Failed to invalidate cart caches: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"fmt"
+	"mola-web/internal/entity"
+	"mola-web/internal/http/dto"
+	"mola-web/internal/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	ProductTypeSimple = "simple"
+	ProductTypeBundle = "bundle"
+)
+
+func isBundle(product *entity.Product) bool {
+	return product != nil && product.ProductType == ProductTypeBundle
+}
+
+// bundleStockKey membedakan stok per varian, atau per produk jika
+// komponen tidak punya varian (variantID = uuid.Nil).
+type bundleStockKey struct {
+	productID uuid.UUID
+	variantID uuid.UUID
+}
+
+func componentStock(component *entity.Product, variantID *uuid.UUID) int {
+	if variantID == nil {
+		if !component.HasVariant {
+			return component.Stock
+		}
+		// Varian belum dipilih, pakai total stok semua varian
+		total := 0
+		for _, variant := range component.Variants {
+			total += variant.Stock
+		}
+		return total
+	}
+	for _, variant := range component.Variants {
+		if variant.ID == *variantID {
+			return variant.Stock
+		}
+	}
+	return 0
+}
+
+// bundleStock menghitung jumlah bundle yang bisa dijual dari stok
+// komponennya. components berisi varian pilihan pembeli, boleh kosong.
+func bundleStock(product *entity.Product, components []entity.CartItemComponent) int {
+	chosen := make(map[uuid.UUID]*uuid.UUID, len(components))
+	for _, component := range components {
+		chosen[component.BundleItemID] = component.ProductVariantID
+	}
+
+	demand := map[bundleStockKey]int{}
+	available := map[bundleStockKey]int{}
+	for _, item := range product.BundleItems {
+		if item.Component == nil || item.Quantity < 1 {
+			return 0
+		}
+		variantID := item.ComponentVariantID
+		if variantID == nil {
+			variantID = chosen[item.ID]
+		}
+		key := bundleStockKey{productID: item.ComponentID}
+		if variantID != nil {
+			key.variantID = *variantID
+		}
+		demand[key] += item.Quantity
+		available[key] = componentStock(item.Component, variantID)
+	}
+	if len(demand) == 0 {
+		return 0
+	}
+
+	stock := -1
+	for key, quantity := range demand {
+		if n := available[key] / quantity; stock < 0 || n < stock {
+			stock = n
+		}
+	}
+	return stock
+}
+
+// bundleComponents menyusun varian per komponen dari pilihan pembeli tanpa
+// validasi. Varian tetap dari admin selalu didahulukan.
+func bundleComponents(product *entity.Product, selections []dto.BundleSelection) []entity.CartItemComponent {
+	chosen := make(map[uuid.UUID]*uuid.UUID, len(selections))
+	for _, selection := range selections {
+		chosen[selection.BundleItemID] = selection.ProductVariantID
+	}
+	components := []entity.CartItemComponent{}
+	for _, item := range product.BundleItems {
+		variantID := item.ComponentVariantID
+		if variantID == nil && item.Component != nil && item.Component.HasVariant {
+			variantID = chosen[item.ID]
+		}
+		components = append(components, entity.CartItemComponent{
+			BundleItemID:     item.ID,
+			ProductVariantID: variantID,
+		})
+	}
+	return components
+}
+
+// resolveBundleSelections memastikan setiap komponen yang bervarian sudah
+// dipilih variannya dan varian tersebut milik komponennya.
+func resolveBundleSelections(product *entity.Product, selections []dto.BundleSelection) ([]entity.CartItemComponent, error) {
+	if len(product.BundleItems) == 0 {
+		return nil, fmt.Errorf("bundle %s has no components", product.Name)
+	}
+	components := bundleComponents(product, selections)
+	for i, item := range product.BundleItems {
+		if item.Component == nil {
+			return nil, fmt.Errorf("a component of bundle %s is no longer available", product.Name)
+		}
+		if !item.Component.HasVariant {
+			continue
+		}
+		variantID := components[i].ProductVariantID
+		if variantID == nil {
+			return nil, fmt.Errorf("a variant must be selected for %s", item.Component.Name)
+		}
+		if !productHasVariant(item.Component, *variantID) {
+			return nil, fmt.Errorf("variant does not belong to %s", item.Component.Name)
+		}
+	}
+	return components, nil
+}
+
+func productHasVariant(product *entity.Product, variantID uuid.UUID) bool {
+	for _, variant := range product.Variants {
+		if variant.ID == variantID {
+			return true
+		}
+	}
+	return false
+}
+
+func bundleSelectionsOf(components []entity.CartItemComponent) []dto.BundleSelection {
+	selections := []dto.BundleSelection{}
+	for _, component := range components {
+		selections = append(selections, dto.BundleSelection{
+			BundleItemID:     component.BundleItemID,
+			ProductVariantID: component.ProductVariantID,
+		})
+	}
+	return selections
+}
+
+func sameComponents(a, b []entity.CartItemComponent) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	chosen := make(map[uuid.UUID]*uuid.UUID, len(a))
+	for _, component := range a {
+		chosen[component.BundleItemID] = component.ProductVariantID
+	}
+	for _, component := range b {
+		variantID, ok := chosen[component.BundleItemID]
+		if !ok || !sameVariant(variantID, component.ProductVariantID) {
+			return false
+		}
+	}
+	return true
+}
+
+// findBundleCartItem mencari bundle yang sama dengan pilihan varian yang
+// sama di keranjang. Pilihan berbeda disimpan sebagai item terpisah.
+func findBundleCartItem(db *gorm.DB, cartRepo repository.CartRepository, cartID uuid.UUID, productID uuid.UUID, components []entity.CartItemComponent) (*entity.CartItem, error) {
+	cartItems, err := cartRepo.GetCartItemsByProductID(db, cartID, productID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range cartItems {
+		if sameComponents(cartItems[i].Components, components) {
+			return &cartItems[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func variantInfo(variant entity.ProductVariant) dto.ProductVariantInfo {
+	info := dto.ProductVariantInfo{
+		ID:      variant.ID,
+		ColorID: variant.ColorID,
+		SizeID:  variant.SizeID,
+		Stock:   variant.Stock,
+	}
+	if variant.Color != nil {
+		info.Color = variant.Color.Name
+	}
+	if variant.Size != nil {
+		info.Size = variant.Size.Name
+	}
+	applyVariantOptions(&info, variant)
+	return info
+}
+
+func bundleItemInfos(product *entity.Product) []dto.BundleItemInfo {
+	infos := []dto.BundleItemInfo{}
+	for _, item := range product.BundleItems {
+		if item.Component == nil {
+			continue
+		}
+		info := dto.BundleItemInfo{
+			ID:               item.ID,
+			ProductID:        item.ComponentID,
+			ProductName:      item.Component.Name,
+			ProductVariantID: item.ComponentVariantID,
+			Quantity:         item.Quantity,
+			RequiresVariant:  item.ComponentVariantID == nil && item.Component.HasVariant,
+		}
+		for _, variant := range item.Component.Variants {
+			if item.ComponentVariantID != nil && variant.ID != *item.ComponentVariantID {
+				continue
+			}
+			info.Variants = append(info.Variants, variantInfo(variant))
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// cartComponentInfos menggabungkan pilihan varian dengan data komponen bundle.
+func cartComponentInfos(product *entity.Product, components []entity.CartItemComponent) []dto.CartItemComponent {
+	chosen := make(map[uuid.UUID]*uuid.UUID, len(components))
+	for _, component := range components {
+		chosen[component.BundleItemID] = component.ProductVariantID
+	}
+	infos := []dto.CartItemComponent{}
+	for _, item := range product.BundleItems {
+		if item.Component == nil {
+			continue
+		}
+		variantID := item.ComponentVariantID
+		if variantID == nil {
+			variantID = chosen[item.ID]
+		}
+		info := dto.CartItemComponent{
+			BundleItemID:     item.ID,
+			ProductID:        item.ComponentID,
+			ProductName:      item.Component.Name,
+			ProductVariantID: variantID,
+			Quantity:         item.Quantity,
+		}
+		if variantID != nil {
+			for _, variant := range item.Component.Variants {
+				if variant.ID == *variantID {
+					v := variantInfo(variant)
+					info.Color, info.Size = v.Color, v.Size
+				}
+			}
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+func componentVariantName(component dto.CartItemComponent) string {
+	switch {
+	case component.Color != "" && component.Size != "":
+		return component.Color + " - " + component.Size
+	case component.Color != "":
+		return component.Color
+	}
+	return component.Size
+}
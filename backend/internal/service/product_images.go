@@ -0,0 +1,466 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"mola-web/internal/entity"
+	"mola-web/internal/http/dto"
+	"mola-web/pkg/imaging"
+	"path"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	productImageKeyPrefix = "products/images/"
+	// Gambar lama disimpan langsung di /static/products/images/
+	legacyProductImageURLPrefix = "/static/products/images/"
+	maxProductImages            = 20
+)
+
+var ErrProductImageNotFound = errors.New("product image not found")
+
+// saveProductImage memproses upload menjadi rendisi thumbnail, medium dan
+// large lalu menyimpannya ke storage. Nama file dibuat baru sehingga nama
+// asli dari user tidak pernah dipakai di key.
+func (s *productService) saveProductImage(ctx context.Context, file *multipart.FileHeader) (*entity.ProductImage, error) {
+	src, err := file.Open()
+	if err != nil {
+		log.Printf("ERROR OPEN IMAGE: %v", err)
+		return nil, errors.New("failed to open image")
+	}
+	defer src.Close()
+
+	renditions, err := s.imageProcessor.Process(src)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", file.Filename, err)
+	}
+
+	image := &entity.ProductImage{}
+	baseName := uuid.NewString()
+	for _, rendition := range renditions {
+		key := productImageKeyPrefix + baseName + "-" + rendition.Name + "." + rendition.Image.Ext
+		if err := s.putProductImageFile(ctx, key, rendition.Image); err != nil {
+			s.deleteProductImage(ctx, *image)
+			return nil, err
+		}
+		saved := entity.ImageRendition{
+			Name:   rendition.Name,
+			Key:    key,
+			URL:    s.storage.URL(key),
+			Width:  rendition.Width,
+			Height: rendition.Height,
+		}
+		if rendition.WebP != nil {
+			webpKey := productImageKeyPrefix + baseName + "-" + rendition.Name + "." + rendition.WebP.Ext
+			if err := s.putProductImageFile(ctx, webpKey, *rendition.WebP); err != nil {
+				s.deleteProductImage(ctx, *image)
+				return nil, err
+			}
+			webpURL := s.storage.URL(webpKey)
+			saved.WebPKey = &webpKey
+			saved.WebPURL = &webpURL
+		}
+		image.Renditions = append(image.Renditions, saved)
+		if rendition.Name == imaging.RenditionLarge {
+			image.URL = saved.URL
+		}
+	}
+	return image, nil
+}
+
+func (s *productService) putProductImageFile(ctx context.Context, key string, file imaging.Encoded) error {
+	if err := s.storage.Put(ctx, key, bytes.NewReader(file.Data), int64(len(file.Data)), file.ContentType); err != nil {
+		log.Printf("ERROR SAVE IMAGE: %s, err: %v", key, err)
+		return fmt.Errorf("failed to save image: %v", err)
+	}
+	return nil
+}
+
+// productImageKeys key storage semua file milik gambar. Gambar lama yang
+// belum punya key dicari dari URL-nya, URL eksternal diabaikan.
+func (s *productService) productImageKeys(image entity.ProductImage) []string {
+	keys := []string{}
+	for _, rendition := range image.Renditions {
+		if rendition.Key != "" {
+			keys = append(keys, rendition.Key)
+		} else if key, ok := s.productImageKeyFromURL(rendition.URL); ok {
+			keys = append(keys, key)
+		}
+		if rendition.WebPKey != nil {
+			keys = append(keys, *rendition.WebPKey)
+		} else if rendition.WebPURL != nil {
+			if key, ok := s.productImageKeyFromURL(*rendition.WebPURL); ok {
+				keys = append(keys, key)
+			}
+		}
+	}
+	if key, ok := s.productImageKeyFromURL(image.URL); ok {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (s *productService) productImageKeyFromURL(url string) (string, bool) {
+	for _, prefix := range []string{s.storage.URL(productImageKeyPrefix), legacyProductImageURLPrefix} {
+		if strings.HasPrefix(url, prefix) {
+			return productImageKeyPrefix + path.Base(strings.TrimPrefix(url, prefix)), true
+		}
+	}
+	return "", false
+}
+
+// deleteProductImage menghapus file gambar beserta semua rendisinya dari
+// storage. Kegagalan hanya dicatat di log.
+func (s *productService) deleteProductImage(ctx context.Context, image entity.ProductImage) {
+	seen := map[string]struct{}{}
+	for _, key := range s.productImageKeys(image) {
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		if err := s.storage.Delete(ctx, key); err != nil {
+			log.Printf("WARNING: Failed to delete image %s: %v", key, err)
+		}
+	}
+}
+
+func productImageInfos(images []entity.ProductImage) []dto.ProductImageInfo {
+	infos := []dto.ProductImageInfo{}
+	for _, image := range images {
+		info := dto.ProductImageInfo{
+			ID:         image.ID,
+			URL:        image.URL,
+			AltText:    image.AltText,
+			SortOrder:  image.SortOrder,
+			IsPrimary:  image.IsPrimary,
+			Renditions: map[string]dto.ImageRenditionInfo{},
+		}
+		for _, rendition := range image.Renditions {
+			info.Renditions[rendition.Name] = dto.ImageRenditionInfo{
+				URL:     rendition.URL,
+				WebPURL: rendition.WebPURL,
+				Width:   rendition.Width,
+				Height:  rendition.Height,
+			}
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+func primaryImageID(images []entity.ProductImage) uuid.UUID {
+	for _, image := range images {
+		if image.IsPrimary {
+			return image.ID
+		}
+	}
+	return uuid.Nil
+}
+
+// applyImageOrder menyimpan urutan galeri sesuai posisi di slice dan
+// menandai satu gambar utama (gambar pertama jika primaryID kosong), lalu
+// menyalin URL gambar utama ke produk.
+func (s *productService) applyImageOrder(tx *gorm.DB, productID uuid.UUID, images []entity.ProductImage, primaryID uuid.UUID) error {
+	if primaryID == uuid.Nil && len(images) > 0 {
+		primaryID = images[0].ID
+	}
+
+	var imageURL *string
+	for i := range images {
+		image := &images[i]
+		isPrimary := image.ID == primaryID
+		if isPrimary {
+			imageURL = &image.URL
+		}
+		if image.SortOrder == i && image.IsPrimary == isPrimary {
+			continue
+		}
+		image.SortOrder = i
+		image.IsPrimary = isPrimary
+		if err := s.repo.UpdateImage(tx, image); err != nil {
+			return err
+		}
+	}
+	return s.repo.SetImageURL(tx, productID, imageURL)
+}
+
+// syncProductGallery dipanggil saat produk dibuat/diubah. Gambar dari form
+// produk masuk ke galeri sebagai gambar utama. Produk lama tanpa galeri
+// dibiarkan memakai image_url dari form.
+func (s *productService) syncProductGallery(tx *gorm.DB, productID uuid.UUID, newImage *entity.ProductImage) error {
+	images, err := s.repo.GetImagesForUpdate(tx, productID)
+	if err != nil {
+		return err
+	}
+	primaryID := primaryImageID(images)
+	if newImage != nil {
+		newImage.ProductID = productID
+		newImages := []entity.ProductImage{*newImage}
+		if err := s.repo.CreateImages(tx, newImages); err != nil {
+			return err
+		}
+		images = append(newImages, images...)
+		primaryID = newImages[0].ID
+	}
+	if len(images) == 0 {
+		return nil
+	}
+	return s.applyImageOrder(tx, productID, images, primaryID)
+}
+
+func (s *productService) UploadImages(ctx context.Context, req *dto.UploadProductImagesRequest) ([]dto.ProductImageInfo, error) {
+	if len(req.Images) == 0 {
+		return nil, errors.New("no image uploaded")
+	}
+
+	tx := s.DB.WithContext(ctx).Begin()
+	saved := []entity.ProductImage{}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			log.Printf("PANIC RECOVERED: Rolling back transaction due to panic: %v", p)
+			panic(p)
+		} else if tx.Error != nil {
+			tx.Rollback()
+			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
+			// File yang sudah tersimpan tidak lagi dipakai
+			for _, image := range saved {
+				s.deleteProductImage(context.Background(), image)
+			}
+		}
+	}()
+
+	if _, err := s.repo.GetByID(tx, req.ProductID); errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Error = errors.New("product not found")
+		return nil, tx.Error
+	} else if err != nil {
+		tx.Error = err
+		return nil, err
+	}
+
+	images, err := s.repo.GetImagesForUpdate(tx, req.ProductID)
+	if err != nil {
+		tx.Error = err
+		return nil, err
+	}
+	if len(images)+len(req.Images) > maxProductImages {
+		tx.Error = fmt.Errorf("a product can have at most %d images", maxProductImages)
+		return nil, tx.Error
+	}
+
+	newImages := []entity.ProductImage{}
+	for i, file := range req.Images {
+		image, err := s.saveProductImage(ctx, file)
+		if err != nil {
+			tx.Error = err
+			return nil, err
+		}
+		saved = append(saved, *image)
+
+		image.ProductID = req.ProductID
+		image.SortOrder = len(images) + i
+		if i < len(req.AltTexts) && strings.TrimSpace(req.AltTexts[i]) != "" {
+			altText := strings.TrimSpace(req.AltTexts[i])
+			image.AltText = &altText
+		}
+		newImages = append(newImages, *image)
+	}
+	if err := s.repo.CreateImages(tx, newImages); err != nil {
+		tx.Error = err
+		return nil, err
+	}
+
+	primaryID := primaryImageID(images)
+	if req.Primary || primaryID == uuid.Nil {
+		primaryID = newImages[0].ID
+	}
+	images = append(images, newImages...)
+	if err := s.applyImageOrder(tx, req.ProductID, images, primaryID); err != nil {
+		tx.Error = err
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Error = err
+		return nil, err
+	}
+
+	_ = s.invalidateProductListCaches()
+	return productImageInfos(images), nil
+}
+
+// ReorderImages mengurutkan ulang galeri. Daftar ID harus berisi seluruh
+// gambar produk tepat satu kali.
+func (s *productService) ReorderImages(ctx context.Context, req *dto.ReorderProductImagesRequest) ([]dto.ProductImageInfo, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			log.Printf("PANIC RECOVERED: Rolling back transaction due to panic: %v", p)
+			panic(p)
+		} else if tx.Error != nil {
+			tx.Rollback()
+			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
+		}
+	}()
+
+	images, err := s.repo.GetImagesForUpdate(tx, req.ProductID)
+	if err != nil {
+		tx.Error = err
+		return nil, err
+	}
+	if len(req.ImageIDs) != len(images) {
+		tx.Error = errors.New("image_ids must list every image of the product")
+		return nil, tx.Error
+	}
+
+	byID := make(map[uuid.UUID]entity.ProductImage, len(images))
+	for _, image := range images {
+		byID[image.ID] = image
+	}
+	ordered := make([]entity.ProductImage, 0, len(images))
+	for _, id := range req.ImageIDs {
+		image, ok := byID[id]
+		if !ok {
+			tx.Error = fmt.Errorf("image %s does not belong to the product or is listed twice", id)
+			return nil, tx.Error
+		}
+		delete(byID, id)
+		ordered = append(ordered, image)
+	}
+
+	if err := s.applyImageOrder(tx, req.ProductID, ordered, primaryImageID(images)); err != nil {
+		tx.Error = err
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Error = err
+		return nil, err
+	}
+
+	_ = s.invalidateProductListCaches()
+	return productImageInfos(ordered), nil
+}
+
+// UpdateImage mengubah alt text dan/atau menjadikan gambar sebagai gambar
+// utama.
+func (s *productService) UpdateImage(ctx context.Context, req *dto.UpdateProductImageRequest) ([]dto.ProductImageInfo, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			log.Printf("PANIC RECOVERED: Rolling back transaction due to panic: %v", p)
+			panic(p)
+		} else if tx.Error != nil {
+			tx.Rollback()
+			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
+		}
+	}()
+
+	images, err := s.repo.GetImagesForUpdate(tx, req.ProductID)
+	if err != nil {
+		tx.Error = err
+		return nil, err
+	}
+
+	var target *entity.ProductImage
+	for i := range images {
+		if images[i].ID == req.ImageID {
+			target = &images[i]
+		}
+	}
+	if target == nil {
+		tx.Error = ErrProductImageNotFound
+		return nil, tx.Error
+	}
+
+	target.AltText = nil
+	if req.AltText != nil && strings.TrimSpace(*req.AltText) != "" {
+		altText := strings.TrimSpace(*req.AltText)
+		target.AltText = &altText
+	}
+	if err := s.repo.UpdateImage(tx, target); err != nil {
+		tx.Error = err
+		return nil, err
+	}
+
+	primaryID := primaryImageID(images)
+	if req.IsPrimary {
+		primaryID = target.ID
+	}
+	if err := s.applyImageOrder(tx, req.ProductID, images, primaryID); err != nil {
+		tx.Error = err
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Error = err
+		return nil, err
+	}
+
+	_ = s.invalidateProductListCaches()
+	return productImageInfos(images), nil
+}
+
+// DeleteImage menghapus gambar dari galeri beserta filenya. Jika yang dihapus
+// gambar utama, gambar berikutnya menjadi gambar utama.
+func (s *productService) DeleteImage(ctx context.Context, productID uuid.UUID, imageID uuid.UUID) error {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			log.Printf("PANIC RECOVERED: Rolling back transaction due to panic: %v", p)
+			panic(p)
+		} else if tx.Error != nil {
+			tx.Rollback()
+			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
+		}
+	}()
+
+	images, err := s.repo.GetImagesForUpdate(tx, productID)
+	if err != nil {
+		tx.Error = err
+		return err
+	}
+
+	var removed *entity.ProductImage
+	remaining := []entity.ProductImage{}
+	for i := range images {
+		if images[i].ID == imageID {
+			removed = &images[i]
+			continue
+		}
+		remaining = append(remaining, images[i])
+	}
+	if removed == nil {
+		tx.Error = ErrProductImageNotFound
+		return tx.Error
+	}
+
+	if err := s.repo.DeleteImage(tx, removed.ID); err != nil {
+		tx.Error = err
+		return err
+	}
+	if err := s.applyImageOrder(tx, productID, remaining, primaryImageID(remaining)); err != nil {
+		tx.Error = err
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Error = err
+		return err
+	}
+
+	// File dihapus setelah commit supaya tidak hilang jika transaksi gagal
+	s.deleteProductImage(ctx, *removed)
+	_ = s.invalidateProductListCaches()
+	return nil
+}
@@ -0,0 +1,868 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"mola-web/internal/entity"
+	"mola-web/internal/http/dto"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// productCSVColumns kolom CSV export/import produk. Satu baris per varian
+// dengan kolom produk diulang di setiap baris, produk tanpa varian cukup
+// satu baris. Kolom options berisi opsi selain warna dan ukuran, misalnya
+// "Bahan: Katun; Motif: Polos".
+var productCSVColumns = []string{
+	"product_id", "sku", "barcode", "name", "slug", "category", "product_type",
+	"status", "publish_at", "unpublish_at", "description", "image_url", "price", "weight", "stock",
+	"variant_id", "variant_sku", "variant_barcode", "color", "size", "options",
+	"variant_stock", "variant_price", "variant_compare_at_price", "variant_weight",
+}
+
+// Kolom yang menandakan baris berisi varian
+var productCSVVariantColumns = []string{"variant_id", "variant_sku", "variant_barcode", "color", "size", "options"}
+
+const (
+	maxProductImportRows = 5000
+	// numeric(12,2) di database
+	maxProductImportNumber = 1e10
+	maxProductNameLength   = 100
+)
+
+func (s *productService) ExportCSV(ctx context.Context, w io.Writer) error {
+	products, err := s.repo.GetAllForExport(ctx)
+	if err != nil {
+		return err
+	}
+	categories, err := s.categoryRepo.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	paths := categoryPaths(categories)
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(productCSVColumns); err != nil {
+		return err
+	}
+	for _, product := range products {
+		category := ""
+		if product.CategoryID != nil {
+			category = paths[*product.CategoryID]
+		}
+		row := []string{
+			product.ID.String(), stringOrEmpty(product.SKU), stringOrEmpty(product.Barcode), product.Name,
+			stringOrEmpty(product.Slug), category, product.ProductType, product.Status,
+			formatCSVTime(product.PublishAt), formatCSVTime(product.UnpublishAt), stringOrEmpty(product.Description),
+			stringOrEmpty(product.ImageURL), formatCSVNumber(product.Price), formatCSVNumber(product.Weight),
+			strconv.Itoa(product.Stock),
+		}
+		if !product.HasVariant || len(product.Variants) == 0 {
+			if err := writer.Write(append(row, make([]string, len(productCSVColumns)-len(row))...)); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, variant := range product.Variants {
+			color, size, options := variantCSVOptions(variant.Options)
+			variantRow := append(append([]string{}, row...),
+				variant.ID.String(), stringOrEmpty(variant.SKU), stringOrEmpty(variant.Barcode), color, size, options,
+				strconv.Itoa(variant.Stock), formatOptionalCSVNumber(variant.Price),
+				formatOptionalCSVNumber(variant.CompareAtPrice), formatOptionalCSVNumber(variant.Weight),
+			)
+			if err := writer.Write(variantRow); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// ImportCSV membuat atau mengubah produk dari CSV dalam satu transaksi.
+// Produk dicocokkan lewat product_id lalu sku, varian lewat variant_id,
+// variant_sku lalu kombinasi opsinya. Kolom yang tidak ada di header tidak
+// diubah, dan varian yang tidak ada di CSV tidak dihapus. Dry run
+// menjalankan semua langkah yang sama lalu rollback, jadi laporan dry run
+// sama persis dengan hasil import sebenarnya.
+func (s *productService) ImportCSV(ctx context.Context, r io.Reader, dryRun bool) (*dto.ProductImportReport, error) {
+	report := &dto.ProductImportReport{DryRun: dryRun, Errors: []dto.ProductImportError{}}
+
+	rows, err := readProductCSV(r)
+	var rowErr *productImportError
+	if errors.As(err, &rowErr) {
+		report.Errors = append(report.Errors, rowErr.report())
+		return report, nil
+	} else if err != nil {
+		return nil, err
+	}
+	report.Rows = len(rows)
+
+	categories, err := s.categoryRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	optionTypes, err := s.optionRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := s.DB.WithContext(ctx).Begin()
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			log.Printf("PANIC RECOVERED: Rolling back transaction due to panic: %v", p)
+			panic(p)
+		} else if tx.Error != nil {
+			tx.Rollback()
+			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
+		}
+	}()
+
+	imp := newProductImport(tx, newCodeRegistry(tx, s.inventoryRepo), categories, optionTypes, report)
+	for _, group := range groupProductImportRows(rows) {
+		err := s.importProduct(imp, group)
+		if errors.As(err, &rowErr) {
+			report.Errors = append(report.Errors, rowErr.report())
+			continue
+		} else if err != nil {
+			tx.Error = err
+			return nil, err
+		}
+	}
+
+	// Nomor barcode yang sempat diambil dari sequence tidak ikut rollback,
+	// tidak masalah karena hanya membuat nomor berikutnya melompat.
+	if dryRun || len(report.Errors) > 0 {
+		if err := tx.Rollback().Error; err != nil {
+			return nil, err
+		}
+		return report, nil
+	}
+	if err := tx.Commit().Error; err != nil {
+		tx.Error = err
+		return nil, err
+	}
+	report.Applied = true
+
+	_ = s.invalidateProductListCaches()
+	for _, id := range imp.touched {
+		s.indexProductSuggestion(ctx, id)
+	}
+	return report, nil
+}
+
+type productImportError struct {
+	line    int
+	column  string
+	message string
+}
+
+func (e *productImportError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.line, e.message)
+}
+
+func (e *productImportError) report() dto.ProductImportError {
+	return dto.ProductImportError{Line: e.line, Column: e.column, Message: e.message}
+}
+
+func importErr(line int, column string, format string, args ...interface{}) error {
+	return &productImportError{line: line, column: column, message: fmt.Sprintf(format, args...)}
+}
+
+// importInputErr mengubah error validasi dari helper produk menjadi error
+// baris CSV, error lain (database) tetap menghentikan import.
+func importInputErr(line int, column string, err error) error {
+	for _, inputErr := range []error{
+		ErrInvalidSlug, ErrSlugTaken, ErrSKUTaken, ErrBarcodeTaken, ErrInvalidSKU, ErrInvalidBarcode,
+		ErrOptionValueNotFound, ErrDuplicateOptionType, ErrVariantNoOptions, ErrInvalidVariantOverride,
+		ErrInvalidProductStatus, ErrInvalidPublishWindow,
+	} {
+		if errors.Is(err, inputErr) {
+			if errors.Is(err, ErrBarcodeTaken) || errors.Is(err, ErrInvalidBarcode) {
+				column = strings.Replace(column, "sku", "barcode", 1)
+			}
+			return &productImportError{line: line, column: column, message: err.Error()}
+		}
+	}
+	return err
+}
+
+type productImportRow struct {
+	line   int
+	fields map[string]string
+}
+
+func (r productImportRow) has(column string) bool {
+	_, ok := r.fields[column]
+	return ok
+}
+
+func (r productImportRow) get(column string) string {
+	return r.fields[column]
+}
+
+func (r productImportRow) hasVariant() bool {
+	for _, column := range productCSVVariantColumns {
+		if r.get(column) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// number nil jika kolom kosong
+func (r productImportRow) number(column string) (*float64, error) {
+	value := r.get(column)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(parsed) || math.Abs(parsed) >= maxProductImportNumber {
+		return nil, importErr(r.line, column, "%s must be a number", column)
+	}
+	return &parsed, nil
+}
+
+func (r productImportRow) stock(column string) (*int, error) {
+	value := r.get(column)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		return nil, importErr(r.line, column, "%s must be a whole number of at least 0", column)
+	}
+	return &parsed, nil
+}
+
+// time waktu RFC3339, nil jika kolom kosong
+func (r productImportRow) time(column string) (*time.Time, error) {
+	value := r.get(column)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, importErr(r.line, column, "%s must be an RFC3339 timestamp such as 2006-01-02T15:04:05+07:00", column)
+	}
+	return &parsed, nil
+}
+
+func readProductCSV(r io.Reader) ([]productImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, importErr(1, "", "csv is empty")
+	} else if err != nil {
+		return nil, csvImportErr(err)
+	}
+
+	known := map[string]struct{}{}
+	for _, column := range productCSVColumns {
+		known[column] = struct{}{}
+	}
+	columns := make([]string, len(header))
+	seen := map[string]struct{}{}
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		if _, ok := known[column]; !ok {
+			return nil, importErr(1, column, "unknown column %q", column)
+		}
+		if _, ok := seen[column]; ok {
+			return nil, importErr(1, column, "duplicate column %q", column)
+		}
+		seen[column] = struct{}{}
+		columns[i] = column
+	}
+	_, hasID := seen["product_id"]
+	_, hasSKU := seen["sku"]
+	_, hasName := seen["name"]
+	if !hasID && !hasSKU && !hasName {
+		return nil, importErr(1, "", "csv needs a product_id, sku or name column")
+	}
+
+	rows := []productImportRow{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, csvImportErr(err)
+		}
+		line, _ := reader.FieldPos(0)
+		row := productImportRow{line: line, fields: make(map[string]string, len(columns))}
+		empty := true
+		for i, column := range columns {
+			row.fields[column] = strings.TrimSpace(record[i])
+			if row.fields[column] != "" {
+				empty = false
+			}
+		}
+		if empty {
+			continue
+		}
+		if len(rows) == maxProductImportRows {
+			return nil, importErr(line, "", "csv may contain at most %d rows", maxProductImportRows)
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, importErr(1, "", "csv has no product rows")
+	}
+	return rows, nil
+}
+
+func csvImportErr(err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return importErr(parseErr.Line, "", "%v", parseErr.Err)
+	}
+	return err
+}
+
+// groupProductImportRows mengelompokkan baris varian ke produknya dengan
+// urutan kemunculan pertama tetap dipertahankan.
+func groupProductImportRows(rows []productImportRow) [][]productImportRow {
+	groups := [][]productImportRow{}
+	byKey := map[string]int{}
+	for _, row := range rows {
+		key := ""
+		switch {
+		case row.get("product_id") != "":
+			key = "id:" + strings.ToLower(row.get("product_id"))
+		case row.get("sku") != "":
+			key = "sku:" + row.get("sku")
+		case row.get("name") != "":
+			key = "name:" + strings.ToLower(row.get("name"))
+		}
+		if index, ok := byKey[key]; ok && key != "" {
+			groups[index] = append(groups[index], row)
+			continue
+		}
+		byKey[key] = len(groups)
+		groups = append(groups, []productImportRow{row})
+	}
+	return groups
+}
+
+// productImport data bersama selama satu kali import
+type productImport struct {
+	tx         *gorm.DB
+	codes      *codeRegistry
+	categories map[string]uint   // path lengkap, huruf kecil
+	names      map[string][]uint // nama kategori, huruf kecil
+	options    map[string]map[string]uint
+	report     *dto.ProductImportReport
+	touched    []uuid.UUID
+}
+
+func newProductImport(tx *gorm.DB, codes *codeRegistry, categories []entity.Category, optionTypes []entity.OptionType, report *dto.ProductImportReport) *productImport {
+	imp := &productImport{
+		tx:         tx,
+		codes:      codes,
+		categories: map[string]uint{},
+		names:      map[string][]uint{},
+		options:    map[string]map[string]uint{},
+		report:     report,
+	}
+	for id, path := range categoryPaths(categories) {
+		imp.categories[strings.ToLower(path)] = id
+	}
+	for _, category := range categories {
+		name := strings.ToLower(strings.TrimSpace(category.Name))
+		imp.names[name] = append(imp.names[name], category.ID)
+	}
+	for _, optionType := range optionTypes {
+		values := map[string]uint{}
+		for _, value := range optionType.Values {
+			values[strings.ToLower(value.Value)] = value.ID
+		}
+		imp.options[strings.ToLower(optionType.Name)] = values
+	}
+	return imp
+}
+
+// category menerima path lengkap "Induk > Anak" atau nama kategori yang
+// tidak kembar.
+func (imp *productImport) category(line int, value string) (uint, error) {
+	if value == "" {
+		return 0, importErr(line, "category", "category is required")
+	}
+	parts := strings.Split(value, ">")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	if id, ok := imp.categories[strings.ToLower(strings.Join(parts, " > "))]; ok {
+		return id, nil
+	}
+	switch ids := imp.names[strings.ToLower(value)]; len(ids) {
+	case 0:
+		return 0, importErr(line, "category", "category %q not found", value)
+	case 1:
+		return ids[0], nil
+	}
+	return 0, importErr(line, "category", "category name %q is used more than once, use the full path such as \"Parent > %s\"", value, value)
+}
+
+func (imp *productImport) optionValueIDs(row productImportRow) ([]uint, error) {
+	type pair struct{ column, optionType, value string }
+	pairs := []pair{}
+	if value := row.get("color"); value != "" {
+		pairs = append(pairs, pair{"color", entity.OptionTypeColor, value})
+	}
+	if value := row.get("size"); value != "" {
+		pairs = append(pairs, pair{"size", entity.OptionTypeSize, value})
+	}
+	for _, part := range strings.Split(row.get("options"), ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		optionType, value, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, importErr(row.line, "options", "options must look like \"Type: Value; Type: Value\"")
+		}
+		pairs = append(pairs, pair{"options", strings.TrimSpace(optionType), strings.TrimSpace(value)})
+	}
+
+	ids := make([]uint, 0, len(pairs))
+	for _, p := range pairs {
+		values, ok := imp.options[strings.ToLower(p.optionType)]
+		if !ok {
+			return nil, importErr(row.line, p.column, "option type %q not found", p.optionType)
+		}
+		id, ok := values[strings.ToLower(p.value)]
+		if !ok {
+			return nil, importErr(row.line, p.column, "%s %q not found", strings.ToLower(p.optionType), p.value)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (s *productService) importProduct(imp *productImport, group []productImportRow) error {
+	tx := imp.tx
+	first := group[0]
+
+	var existing *entity.Product
+	if value := first.get("product_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return importErr(first.line, "product_id", "invalid product id")
+		}
+		existing, err = s.repo.GetByID(tx, id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return importErr(first.line, "product_id", "product not found")
+		} else if err != nil {
+			return err
+		}
+	} else if value := first.get("sku"); value != "" {
+		found, err := s.repo.GetBySKU(tx, value)
+		if err == nil {
+			existing = found
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
+
+	variantRows := []productImportRow{}
+	for _, row := range group {
+		if row.hasVariant() {
+			variantRows = append(variantRows, row)
+		} else if len(group) > 1 {
+			return importErr(row.line, "", "product with several rows needs variant columns on every row")
+		}
+	}
+
+	productType := ProductTypeSimple
+	if existing != nil {
+		productType = existing.ProductType
+	}
+	if value := strings.ToLower(first.get("product_type")); value != "" && value != productType {
+		if value != ProductTypeSimple && value != ProductTypeBundle {
+			return importErr(first.line, "product_type", "product type must be %s or %s", ProductTypeSimple, ProductTypeBundle)
+		}
+		return importErr(first.line, "product_type", "product type cannot be changed by import, bundles are managed in the product form")
+	}
+	if productType == ProductTypeBundle && len(variantRows) > 0 {
+		return importErr(variantRows[0].line, "", "bundles cannot have variants")
+	}
+
+	product := &entity.Product{ProductType: productType}
+	if existing != nil {
+		product = &entity.Product{
+			ID:          existing.ID,
+			Name:        existing.Name,
+			Slug:        existing.Slug,
+			SKU:         existing.SKU,
+			Barcode:     existing.Barcode,
+			CategoryID:  existing.CategoryID,
+			Description: existing.Description,
+			ImageURL:    existing.ImageURL,
+			HasVariant:  existing.HasVariant,
+			ProductType: existing.ProductType,
+			Stock:       existing.Stock,
+			Price:       existing.Price,
+			Weight:      existing.Weight,
+			Status:      existing.Status,
+			PublishAt:   existing.PublishAt,
+			UnpublishAt: existing.UnpublishAt,
+		}
+	}
+	creating := existing == nil
+
+	if first.has("name") || creating {
+		name := first.get("name")
+		if name == "" {
+			return importErr(first.line, "name", "name is required")
+		}
+		if utf8.RuneCountInString(name) > maxProductNameLength {
+			return importErr(first.line, "name", "name must be at most %d characters", maxProductNameLength)
+		}
+		product.Name = name
+	}
+	if first.has("category") || creating {
+		categoryID, err := imp.category(first.line, first.get("category"))
+		if err != nil {
+			return err
+		}
+		product.CategoryID = &categoryID
+	}
+	for _, field := range []struct {
+		column string
+		target **time.Time
+	}{
+		{"publish_at", &product.PublishAt},
+		{"unpublish_at", &product.UnpublishAt},
+	} {
+		if !first.has(field.column) {
+			continue
+		}
+		value, err := first.time(field.column)
+		if err != nil {
+			return err
+		}
+		*field.target = value
+	}
+	// Produk baru dari import juga draft jika status tidak diisi
+	defaultStatus := entity.ProductStatusDraft
+	if !creating {
+		defaultStatus = product.Status
+	}
+	status, err := normalizeProductPublication(first.get("status"), product.PublishAt, product.UnpublishAt, defaultStatus)
+	if err != nil {
+		return importInputErr(first.line, "status", err)
+	}
+	product.Status = status
+
+	if first.has("description") {
+		product.Description = trimmedOrNil(stringPtr(first.get("description")))
+	}
+	if first.has("image_url") {
+		product.ImageURL = trimmedOrNil(stringPtr(first.get("image_url")))
+	}
+	for _, field := range []struct {
+		column string
+		target *float64
+	}{
+		{"price", &product.Price},
+		{"weight", &product.Weight},
+	} {
+		if !first.has(field.column) && !creating {
+			continue
+		}
+		value, err := first.number(field.column)
+		if err != nil {
+			return err
+		}
+		if value == nil || *value <= 0 {
+			return importErr(first.line, field.column, "%s must be greater than 0", field.column)
+		}
+		*field.target = *value
+	}
+	if len(variantRows) > 0 {
+		product.HasVariant = true
+	} else if first.has("stock") && !product.HasVariant && productType != ProductTypeBundle {
+		stock, err := first.stock("stock")
+		if err != nil {
+			return err
+		}
+		if stock != nil {
+			product.Stock = *stock
+		}
+	}
+
+	change := slugChange{
+		EntityType: entity.SlugEntityProduct,
+		Name:       product.Name,
+		Requested:  first.get("slug"),
+	}
+	// Import tidak mengubah URL produk kecuali slug diisi
+	if existing != nil {
+		change.EntityID = existing.ID.String()
+		change.Current = existing.Slug
+		change.Keep = true
+	}
+	productSlug, err := assignSlug(tx, s.slugRepo, change)
+	if err != nil {
+		return importInputErr(first.line, "slug", err)
+	}
+	product.Slug = &productSlug
+
+	if err := imp.codes.assignProductCodes(product, first.get("sku"), first.get("barcode"), existing); err != nil {
+		return importInputErr(first.line, "sku", err)
+	}
+
+	if creating {
+		if err := s.repo.Create(tx, product); err != nil {
+			return err
+		}
+		imp.report.ProductsCreated++
+	} else {
+		if err := s.repo.Update(tx, product); err != nil {
+			return err
+		}
+		if err := s.repo.UpdatePublication(tx, product); err != nil {
+			return err
+		}
+		imp.report.ProductsUpdated++
+	}
+	imp.touched = append(imp.touched, product.ID)
+
+	if len(variantRows) == 0 {
+		return nil
+	}
+	return s.importVariants(imp, product, creating, variantRows)
+}
+
+func (s *productService) importVariants(imp *productImport, product *entity.Product, creating bool, rows []productImportRow) error {
+	tx := imp.tx
+
+	existingVariants := []*entity.ProductVariant{}
+	if !creating {
+		found, err := s.repoVariant.GetByProductID(tx, product.ID)
+		if err != nil {
+			return err
+		}
+		existingVariants = found
+	}
+	byID := make(map[uuid.UUID]*entity.ProductVariant, len(existingVariants))
+	bySKU := make(map[string]*entity.ProductVariant, len(existingVariants))
+	byKey := make(map[string]*entity.ProductVariant, len(existingVariants))
+	for _, variant := range existingVariants {
+		byID[variant.ID] = variant
+		if variant.SKU != nil {
+			bySKU[*variant.SKU] = variant
+		}
+		byKey[variantOptionKey(variant.Options)] = variant
+	}
+	processed := map[uuid.UUID]bool{}
+
+	checker, err := newVariantChecker(imp.codes, s.repo, product)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		valueIDs, err := imp.optionValueIDs(row)
+		if err != nil {
+			return err
+		}
+		options, colorID, sizeID, err := resolveVariantOptions(tx, s.optionRepo, nil, nil, valueIDs)
+		if err != nil {
+			return importInputErr(row.line, "options", err)
+		}
+		key := variantOptionKey(options)
+
+		var variant *entity.ProductVariant
+		if value := row.get("variant_id"); value != "" {
+			id, err := uuid.Parse(value)
+			if err != nil {
+				return importErr(row.line, "variant_id", "invalid variant id")
+			}
+			if variant = byID[id]; variant == nil {
+				return importErr(row.line, "variant_id", "variant not found in this product")
+			}
+		} else if found := bySKU[row.get("variant_sku")]; found != nil {
+			variant = found
+		} else {
+			variant = byKey[key]
+		}
+		if variant != nil && processed[variant.ID] {
+			return importErr(row.line, "", "variant is listed more than once")
+		}
+		if other := byKey[key]; variant != nil && other != nil && other.ID != variant.ID {
+			return importErr(row.line, "options", "another variant of this product already has these options")
+		}
+
+		creatingVariant := variant == nil
+		if creatingVariant {
+			variant = &entity.ProductVariant{ProductID: product.ID}
+		}
+
+		// Kolom override yang tidak ada di header mempertahankan nilai lama
+		overrides := dto.ProductVariantOverrides{
+			Price:          variant.Price,
+			CompareAtPrice: variant.CompareAtPrice,
+			Weight:         variant.Weight,
+			SKU:            stringPtr(row.get("variant_sku")),
+			Barcode:        stringPtr(row.get("variant_barcode")),
+			ImageID:        variant.ImageID,
+		}
+		for _, field := range []struct {
+			column string
+			target **float64
+		}{
+			{"variant_price", &overrides.Price},
+			{"variant_compare_at_price", &overrides.CompareAtPrice},
+			{"variant_weight", &overrides.Weight},
+		} {
+			if !row.has(field.column) {
+				continue
+			}
+			value, err := row.number(field.column)
+			if err != nil {
+				return err
+			}
+			*field.target = value
+		}
+		if err := applyVariantOverrideRequest(variant, overrides); err != nil {
+			return importInputErr(row.line, "", err)
+		}
+		stock, err := row.stock("variant_stock")
+		if err != nil {
+			return err
+		}
+		if stock != nil {
+			variant.Stock = *stock
+		}
+
+		if creatingVariant {
+			variant.ColorID, variant.SizeID, variant.Options = colorID, sizeID, options
+			if err := checker.check(variant, nil); err != nil {
+				return importInputErr(row.line, "variant_sku", err)
+			}
+			if err := s.repoVariant.Create(tx, variant); err != nil {
+				return err
+			}
+			byKey[key] = variant
+			processed[variant.ID] = true
+			imp.report.VariantsCreated++
+			continue
+		}
+		processed[variant.ID] = true
+
+		if variantOptionKey(variant.Options) != key {
+			delete(byKey, variantOptionKey(variant.Options))
+			variant.ColorID, variant.SizeID = colorID, sizeID
+			if err := s.repoVariant.ReplaceOptions(tx, variant, options); err != nil {
+				return err
+			}
+			byKey[key] = variant
+		}
+		if err := checker.check(variant, &variant.ID); err != nil {
+			return importInputErr(row.line, "variant_sku", err)
+		}
+		if err := s.repoVariant.Update(tx, variant); err != nil {
+			return err
+		}
+		imp.report.VariantsUpdated++
+	}
+	return nil
+}
+
+// categoryPaths path lengkap setiap kategori, misalnya "Pakaian > Kaos".
+func categoryPaths(categories []entity.Category) map[uint]string {
+	byID := make(map[uint]entity.Category, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+	paths := make(map[uint]string, len(categories))
+	for _, category := range categories {
+		names := []string{}
+		seen := map[uint]struct{}{}
+		current := category
+		for {
+			if _, loop := seen[current.ID]; loop {
+				break
+			}
+			seen[current.ID] = struct{}{}
+			names = append([]string{current.Name}, names...)
+			if current.ParentID == nil {
+				break
+			}
+			parent, ok := byID[*current.ParentID]
+			if !ok {
+				break
+			}
+			current = parent
+		}
+		paths[category.ID] = strings.Join(names, " > ")
+	}
+	return paths
+}
+
+// variantCSVOptions memisahkan warna dan ukuran dari opsi lain varian.
+func variantCSVOptions(values []entity.OptionValue) (string, string, string) {
+	options := append([]entity.OptionValue{}, values...)
+	sort.SliceStable(options, func(i, j int) bool {
+		return optionTypeLess(options[i], options[j])
+	})
+	var color, size string
+	others := []string{}
+	for _, option := range options {
+		if option.OptionType == nil {
+			continue
+		}
+		switch {
+		case option.OptionType.Name == entity.OptionTypeColor && color == "":
+			color = option.Value
+		case option.OptionType.Name == entity.OptionTypeSize && size == "":
+			size = option.Value
+		default:
+			others = append(others, option.OptionType.Name+": "+option.Value)
+		}
+	}
+	return color, size, strings.Join(others, "; ")
+}
+
+func stringOrEmpty(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func stringPtr(value string) *string {
+	return &value
+}
+
+func formatCSVNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func formatCSVTime(value *time.Time) string {
+	if value == nil {
+		return ""
+	}
+	return value.Format(time.RFC3339)
+}
+
+func formatOptionalCSVNumber(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return formatCSVNumber(*value)
+}
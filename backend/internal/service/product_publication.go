@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mola-web/internal/entity"
+	"mola-web/internal/http/dto"
+	"mola-web/internal/repository"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrProductNotFound      = errors.New("products not found")
+	ErrInvalidProductStatus = fmt.Errorf("status must be %s, %s or %s", entity.ProductStatusDraft, entity.ProductStatusPublished, entity.ProductStatusArchived)
+	ErrInvalidPublishWindow = errors.New("unpublish_at must be after publish_at")
+)
+
+// productVisible sama dengan productVisibleCondition di repository.
+func productVisible(product *entity.Product, now time.Time) bool {
+	if product.Status != entity.ProductStatusPublished {
+		return false
+	}
+	if product.PublishAt != nil && product.PublishAt.After(now) {
+		return false
+	}
+	if product.UnpublishAt != nil && !product.UnpublishAt.After(now) {
+		return false
+	}
+	return true
+}
+
+// getVisibleProduct mengambil produk untuk keranjang dan wishlist. Produk
+// yang belum atau sudah tidak tayang diperlakukan seperti tidak ada.
+func getVisibleProduct(db *gorm.DB, productRepo repository.ProductRepository, productID uuid.UUID) (*entity.Product, error) {
+	product, err := productRepo.GetByID(db, productID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrProductNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	if !productVisible(product, time.Now()) {
+		return nil, ErrProductNotFound
+	}
+	return product, nil
+}
+
+// normalizeProductPublication memvalidasi status dan jadwal tayang. Status
+// kosong memakai defaultStatus.
+func normalizeProductPublication(status string, publishAt *time.Time, unpublishAt *time.Time, defaultStatus string) (string, error) {
+	status = strings.ToLower(strings.TrimSpace(status))
+	if status == "" {
+		status = defaultStatus
+	}
+	switch status {
+	case entity.ProductStatusDraft, entity.ProductStatusPublished, entity.ProductStatusArchived:
+	default:
+		return "", ErrInvalidProductStatus
+	}
+	if publishAt != nil && unpublishAt != nil && !unpublishAt.After(*publishAt) {
+		return "", ErrInvalidPublishWindow
+	}
+	return status, nil
+}
+
+// AdminGetAll listing admin tanpa cache, semua status ikut kecuali difilter.
+func (s *productService) AdminGetAll(ctx context.Context, query dto.ProductListQuery) (*dto.ProductListResponse, error) {
+	if query.Status != "" {
+		status, err := normalizeProductPublication(query.Status, nil, nil, "")
+		if err != nil {
+			return nil, err
+		}
+		query.Status = status
+	}
+	if err := normalizeProductListQuery(&query); err != nil {
+		return nil, err
+	}
+	query.Admin = true
+	return s.listProducts(ctx, query)
+}
+
+func (s *productService) AdminGetByID(ctx context.Context, id uuid.UUID) (*dto.GetProductByID, error) {
+	dataProduct, err := s.repo.GetByID(s.DB.WithContext(ctx), id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrProductNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return s.productDetail(ctx, dataProduct)
+}
+
+func (s *productService) UpdatePublication(ctx context.Context, request *dto.UpdateProductPublicationRequest) error {
+	status, err := normalizeProductPublication(request.Status, request.PublishAt, request.UnpublishAt, "")
+	if err != nil {
+		return err
+	}
+
+	tx := s.DB.WithContext(ctx).Begin()
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			log.Printf("PANIC RECOVERED: Rolling back transaction due to panic: %v", p)
+			panic(p)
+		} else if tx.Error != nil {
+			tx.Rollback()
+			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
+		}
+	}()
+
+	product, err := s.repo.GetByID(tx, request.ProductID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Error = ErrProductNotFound
+		return tx.Error
+	} else if err != nil {
+		tx.Error = err
+		return err
+	}
+	product.Status = status
+	product.PublishAt = request.PublishAt
+	product.UnpublishAt = request.UnpublishAt
+	if err := s.repo.UpdatePublication(tx, product); err != nil {
+		tx.Error = err
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Error = err
+		return err
+	}
+
+	_ = s.invalidateProductListCaches()
+	s.indexProductSuggestion(ctx, product.ID)
+	return nil
+}
+
+// InvalidateOnPublishBoundary menghapus cache katalog dan memperbarui indeks
+// autocomplete jika ada produk yang mulai atau berhenti tayang dalam rentang
+// waktu tersebut.
+func (s *productService) InvalidateOnPublishBoundary(ctx context.Context, from time.Time, to time.Time) error {
+	count, err := s.repo.CountPublishBoundariesBetween(s.DB.WithContext(ctx), from, to)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return nil
+	}
+	log.Printf("INFO: %d product(s) were published or unpublished, invalidating product caches", count)
+	if err := s.invalidateProductListCaches(); err != nil {
+		return err
+	}
+	return s.RebuildSuggestions(ctx)
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"mola-web/internal/entity"
+	"mola-web/internal/http/dto"
+	"mola-web/pkg/cache"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	suggestProductLimit  = 5
+	suggestCategoryLimit = 3
+	suggestQueryLimit    = 5
+
+	// Query populer diambil dari log pencarian beberapa hari terakhir
+	suggestQueryWindowDays = 30
+	suggestQueryIndexSize  = 1000
+
+	defaultSearchReportDays = 30
+	searchReportLimit       = 50
+)
+
+// Bobot produk dan kategori: satu terjual bernilai satu, ditambah bobot dasar
+// supaya produk baru tetap muncul.
+func productSuggestionScore(weight dto.ProductSuggestionWeight) float64 {
+	return float64(1 + weight.Sold)
+}
+
+func categorySuggestionScore(weight dto.CategorySuggestionWeight) float64 {
+	return float64(weight.Products + weight.Sold)
+}
+
+// Suggest mengembalikan nama produk, kategori dan query populer yang cocok
+// dengan prefix, langsung dari indeks Redis.
+func (s *productService) Suggest(ctx context.Context, prefix string) (*dto.SuggestResponse, error) {
+	if cache.NormalizeSuggestText(prefix) == "" {
+		return nil, ErrEmptySearchQuery
+	}
+	result := &dto.SuggestResponse{
+		Products:   []dto.ProductSuggestion{},
+		Categories: []dto.CategorySuggestion{},
+		Queries:    []string{},
+	}
+
+	products, err := s.suggestions.Lookup(ctx, cache.SuggestKindProduct, prefix, suggestProductLimit)
+	if err != nil {
+		return nil, err
+	}
+	for _, product := range products {
+		id, err := uuid.Parse(product.ID)
+		if err != nil {
+			continue
+		}
+		result.Products = append(result.Products, dto.ProductSuggestion{ID: id, Name: product.Label})
+	}
+
+	categories, err := s.suggestions.Lookup(ctx, cache.SuggestKindCategory, prefix, suggestCategoryLimit)
+	if err != nil {
+		return nil, err
+	}
+	for _, category := range categories {
+		id, err := strconv.ParseUint(category.ID, 10, 32)
+		if err != nil {
+			continue
+		}
+		result.Categories = append(result.Categories, dto.CategorySuggestion{ID: uint(id), Name: category.Label})
+	}
+
+	queries, err := s.suggestions.Lookup(ctx, cache.SuggestKindQuery, prefix, suggestQueryLimit)
+	if err != nil {
+		return nil, err
+	}
+	for _, query := range queries {
+		result.Queries = append(result.Queries, query.Label)
+	}
+	return result, nil
+}
+
+// RebuildSuggestions menghitung ulang seluruh bobot indeks dari penjualan
+// dan log pencarian, lalu membuang entri yang sudah tidak ada.
+func (s *productService) RebuildSuggestions(ctx context.Context) error {
+	products, err := s.repo.GetSuggestionWeights(ctx, nil)
+	if err != nil {
+		return err
+	}
+	ids := make([]string, 0, len(products))
+	for _, product := range products {
+		if err := s.suggestions.Put(ctx, cache.SuggestKindProduct, product.ID.String(), product.Name, productSuggestionScore(product)); err != nil {
+			return err
+		}
+		ids = append(ids, product.ID.String())
+	}
+	if err := s.pruneSuggestions(ctx, cache.SuggestKindProduct, ids); err != nil {
+		return err
+	}
+
+	if err := s.refreshCategorySuggestions(ctx); err != nil {
+		return err
+	}
+
+	since := time.Now().AddDate(0, 0, -suggestQueryWindowDays)
+	queries, err := s.searchLogRepo.GetTopQueries(ctx, since, false, suggestQueryIndexSize)
+	if err != nil {
+		return err
+	}
+	ids = make([]string, 0, len(queries))
+	for _, query := range queries {
+		if err := s.suggestions.Put(ctx, cache.SuggestKindQuery, query.Query, query.Query, float64(query.Searches)); err != nil {
+			return err
+		}
+		ids = append(ids, query.Query)
+	}
+	return s.pruneSuggestions(ctx, cache.SuggestKindQuery, ids)
+}
+
+func (s *productService) GetSearchReport(ctx context.Context, days int) (*dto.SearchReport, error) {
+	if days < 1 {
+		days = defaultSearchReportDays
+	}
+	since := time.Now().AddDate(0, 0, -days)
+
+	total, err := s.searchLogRepo.Count(ctx, since, false)
+	if err != nil {
+		return nil, err
+	}
+	zeroResult, err := s.searchLogRepo.Count(ctx, since, true)
+	if err != nil {
+		return nil, err
+	}
+	topQueries, err := s.searchLogRepo.GetTopQueries(ctx, since, false, searchReportLimit)
+	if err != nil {
+		return nil, err
+	}
+	zeroResultQueries, err := s.searchLogRepo.GetTopQueries(ctx, since, true, searchReportLimit)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.SearchReport{
+		From:              since,
+		TotalSearches:     total,
+		ZeroResultCount:   zeroResult,
+		TopQueries:        topQueries,
+		ZeroResultQueries: zeroResultQueries,
+	}, nil
+}
+
+// logSearch mencatat pencarian halaman pertama dan menaikkan bobot query
+// yang menemukan produk. Kegagalan hanya dicatat di log.
+func (s *productService) logSearch(ctx context.Context, query dto.ProductListQuery, result *dto.ProductListResponse) {
+	if query.Page != 1 {
+		return
+	}
+	normalized := cache.NormalizeSuggestText(query.Search)
+	if err := s.searchLogRepo.Create(s.DB.WithContext(ctx), &entity.SearchLog{
+		Query:       normalized,
+		ResultCount: result.Pagination.Total,
+		Fuzzy:       result.Fuzzy,
+	}); err != nil {
+		log.Printf("WARNING: Failed to log search %q: %v", normalized, err)
+	}
+	if result.Pagination.Total == 0 {
+		return
+	}
+	if err := s.suggestions.IncrBy(ctx, cache.SuggestKindQuery, normalized, normalized, 1); err != nil {
+		log.Printf("WARNING: Failed to index search query %q: %v", normalized, err)
+	}
+}
+
+// indexProductSuggestion memperbarui produk dan kategori di indeks setelah
+// produk dibuat atau diubah. Produk yang tidak tampil di katalog dibuang
+// dari indeks.
+func (s *productService) indexProductSuggestion(ctx context.Context, id uuid.UUID) {
+	weights, err := s.repo.GetSuggestionWeights(ctx, []uuid.UUID{id})
+	if err != nil {
+		log.Printf("WARNING: Failed to load suggestion weight for product %s: %v", id, err)
+		return
+	}
+	if len(weights) == 0 {
+		s.removeProductSuggestion(ctx, id)
+		return
+	}
+	for _, weight := range weights {
+		if err := s.suggestions.Put(ctx, cache.SuggestKindProduct, weight.ID.String(), weight.Name, productSuggestionScore(weight)); err != nil {
+			log.Printf("WARNING: Failed to index product %s: %v", id, err)
+		}
+	}
+	if err := s.refreshCategorySuggestions(ctx); err != nil {
+		log.Printf("WARNING: Failed to index categories: %v", err)
+	}
+}
+
+func (s *productService) removeProductSuggestion(ctx context.Context, id uuid.UUID) {
+	if err := s.suggestions.Remove(ctx, cache.SuggestKindProduct, id.String()); err != nil {
+		log.Printf("WARNING: Failed to remove product %s from suggestions: %v", id, err)
+	}
+	if err := s.refreshCategorySuggestions(ctx); err != nil {
+		log.Printf("WARNING: Failed to index categories: %v", err)
+	}
+}
+
+// refreshCategorySuggestions menghitung ulang semua kategori sekaligus karena
+// produk bisa pindah kategori.
+func (s *productService) refreshCategorySuggestions(ctx context.Context) error {
+	categories, err := s.repo.GetCategorySuggestionWeights(ctx)
+	if err != nil {
+		return err
+	}
+	ids := make([]string, 0, len(categories))
+	for _, category := range categories {
+		id := fmt.Sprint(category.ID)
+		if err := s.suggestions.Put(ctx, cache.SuggestKindCategory, id, category.Name, categorySuggestionScore(category)); err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+	return s.pruneSuggestions(ctx, cache.SuggestKindCategory, ids)
+}
+
+func (s *productService) pruneSuggestions(ctx context.Context, kind string, keep []string) error {
+	current, err := s.suggestions.IDs(ctx, kind)
+	if err != nil {
+		return err
+	}
+	keepSet := make(map[string]struct{}, len(keep))
+	for _, id := range keep {
+		keepSet[id] = struct{}{}
+	}
+	for _, id := range current {
+		if _, ok := keepSet[id]; ok {
+			continue
+		}
+		if err := s.suggestions.Remove(ctx, kind, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"mola-web/internal/entity"
+	"mola-web/internal/http/dto"
+	"mola-web/internal/repository"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrSKUTaken               = errors.New("sku is already used by another product or variant")
+	ErrInvalidVariantImage    = errors.New("variant image must be one of the product images")
+	ErrInvalidVariantOverride = errors.New("invalid variant override")
+)
+
+// variantPrice harga dasar varian, harga produk jika varian tidak punya
+// harga sendiri.
+func variantPrice(product *entity.Product, variant *entity.ProductVariant) float64 {
+	if variant != nil && variant.Price != nil {
+		return *variant.Price
+	}
+	return product.Price
+}
+
+func variantWeight(product *entity.Product, variant *entity.ProductVariant) float64 {
+	if variant != nil && variant.Weight != nil {
+		return *variant.Weight
+	}
+	return product.Weight
+}
+
+func variantImageURL(product *entity.Product, variant *entity.ProductVariant) *string {
+	if variant != nil && variant.Image != nil {
+		return &variant.Image.URL
+	}
+	return product.ImageURL
+}
+
+func variantIDOf(variant *entity.ProductVariant) *uuid.UUID {
+	if variant == nil {
+		return nil
+	}
+	return &variant.ID
+}
+
+// productVariantByID mencari varian dari product.Variants yang sudah
+// di-preload.
+func productVariantByID(product *entity.Product, id *uuid.UUID) *entity.ProductVariant {
+	if id == nil {
+		return nil
+	}
+	for i := range product.Variants {
+		if product.Variants[i].ID == *id {
+			return &product.Variants[i]
+		}
+	}
+	return nil
+}
+
+// applyVariantOverrides mengisi harga, berat, SKU dan gambar varian di
+// response.
+func applyVariantOverrides(info *dto.ProductVariantInfo, product *entity.Product, variant entity.ProductVariant) {
+	info.Price = variantPrice(product, &variant)
+	info.CompareAtPrice = variant.CompareAtPrice
+	info.Weight = variantWeight(product, &variant)
+	info.SKU = variant.SKU
+	info.Barcode = variant.Barcode
+	info.ImageID = variant.ImageID
+	if variant.Image != nil {
+		info.ImageURL = &variant.Image.URL
+	}
+}
+
+func trimmedOrNil(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+// applyVariantOverrideRequest memvalidasi override dari request lalu
+// menyalinnya ke varian.
+func applyVariantOverrideRequest(variant *entity.ProductVariant, request dto.ProductVariantOverrides) error {
+	if request.Price != nil && *request.Price <= 0 {
+		return fmt.Errorf("%w: price must be greater than 0", ErrInvalidVariantOverride)
+	}
+	if request.CompareAtPrice != nil && *request.CompareAtPrice <= 0 {
+		return fmt.Errorf("%w: compare-at price must be greater than 0", ErrInvalidVariantOverride)
+	}
+	if request.Weight != nil && *request.Weight <= 0 {
+		return fmt.Errorf("%w: weight must be greater than 0", ErrInvalidVariantOverride)
+	}
+
+	variant.Price = request.Price
+	variant.CompareAtPrice = request.CompareAtPrice
+	variant.Weight = request.Weight
+	variant.ImageID = request.ImageID
+	// SKU dan barcode kosong tidak menghapus kode lama, varian baru
+	// mendapat kode buatan sistem di variantChecker
+	if sku := trimmedOrNil(request.SKU); sku != nil {
+		variant.SKU = sku
+	}
+	if barcode := trimmedOrNil(request.Barcode); barcode != nil {
+		variant.Barcode = barcode
+	}
+	return nil
+}
+
+// variantChecker memeriksa gambar varian dan mengisi SKU serta barcode
+// varian dalam satu request simpan produk.
+type variantChecker struct {
+	codes      *codeRegistry
+	productSKU string
+	images     map[uuid.UUID]struct{}
+}
+
+func newVariantChecker(codes *codeRegistry, productRepo repository.ProductRepository, product *entity.Product) (*variantChecker, error) {
+	images, err := productRepo.GetImagesForUpdate(codes.tx, product.ID)
+	if err != nil {
+		return nil, err
+	}
+	checker := &variantChecker{
+		codes:  codes,
+		images: make(map[uuid.UUID]struct{}, len(images)),
+	}
+	if product.SKU != nil {
+		checker.productSKU = *product.SKU
+	}
+	for _, image := range images {
+		checker.images[image.ID] = struct{}{}
+	}
+	return checker, nil
+}
+
+func (c *variantChecker) check(variant *entity.ProductVariant, existingID *uuid.UUID) error {
+	if variant.ImageID != nil {
+		if _, ok := c.images[*variant.ImageID]; !ok {
+			return ErrInvalidVariantImage
+		}
+	}
+	return c.codes.assignVariantCodes(variant, c.productSKU, existingID)
+}
@@ -11,9 +11,12 @@ import (
 	"mola-web/internal/http/dto"
 	"mola-web/internal/repository"
 	"mola-web/pkg/cache"
+	"mola-web/pkg/imaging"
+	"mola-web/pkg/storage"
 	"mola-web/pkg/token"
-	"os"
-	"path/filepath"
+	"net/url"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -21,9 +24,12 @@ import (
 )
 
 type ProductService interface {
-	GetAll(ctx context.Context) ([]*dto.GetAllProducts, error)
+	GetAll(ctx context.Context, query dto.ProductListQuery) (*dto.ProductListResponse, error)
+	Search(ctx context.Context, query dto.ProductListQuery) (*dto.ProductListResponse, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*dto.GetProductByID, error)
-	GetProductByCategoryID(ctx context.Context, categoryID uint) ([]*dto.GetProductByCategoryID, error)
+	GetBySlug(ctx context.Context, slug string) (*dto.GetProductByID, error)
+	GetProductByCategorySlug(ctx context.Context, slug string, includeDescendants bool) (string, []*dto.GetProductByCategoryID, error)
+	GetProductByCategoryID(ctx context.Context, categoryID uint, includeDescendants bool) ([]*dto.GetProductByCategoryID, error)
 	GetProductByName(ctx context.Context, name string) ([]dto.GetProductByName, error)
 	InsertProductReview(ctx context.Context, request *dto.ProductReviewRequest) error
 	GetProductReviews(ctx context.Context, productID uuid.UUID) ([]dto.GetProductReviewResponse, error)
@@ -34,56 +40,279 @@ type ProductService interface {
 	UpdateStockProductOnOrder(tx *gorm.DB, productID uuid.UUID, stock int64) error
 	UpdateStockProductVariantOnOrder(tx *gorm.DB, variantID uuid.UUID, stock int64) error
 	UpdateStockProduct(ctx context.Context, productID uuid.UUID, stock int64) error
+	UpdatePriceTiers(ctx context.Context, req *dto.UpdatePriceTiersRequest) error
+	UploadImages(ctx context.Context, req *dto.UploadProductImagesRequest) ([]dto.ProductImageInfo, error)
+	ReorderImages(ctx context.Context, req *dto.ReorderProductImagesRequest) ([]dto.ProductImageInfo, error)
+	UpdateImage(ctx context.Context, req *dto.UpdateProductImageRequest) ([]dto.ProductImageInfo, error)
+	DeleteImage(ctx context.Context, productID uuid.UUID, imageID uuid.UUID) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	Suggest(ctx context.Context, prefix string) (*dto.SuggestResponse, error)
+	RebuildSuggestions(ctx context.Context) error
+	GetSearchReport(ctx context.Context, days int) (*dto.SearchReport, error)
+	ExportCSV(ctx context.Context, w io.Writer) error
+	ImportCSV(ctx context.Context, r io.Reader, dryRun bool) (*dto.ProductImportReport, error)
+	AdminGetAll(ctx context.Context, query dto.ProductListQuery) (*dto.ProductListResponse, error)
+	AdminGetByID(ctx context.Context, id uuid.UUID) (*dto.GetProductByID, error)
+	UpdatePublication(ctx context.Context, request *dto.UpdateProductPublicationRequest) error
+	InvalidateOnPublishBoundary(ctx context.Context, from time.Time, to time.Time) error
 }
 
 type productService struct {
-	DB           *gorm.DB
-	repo         repository.ProductRepository
-	repoVariant  repository.ProductVariantRepository
-	tokenUseCase token.TokenUseCase
-	cacheable    cache.Cacheable
+	DB                  *gorm.DB
+	repo                repository.ProductRepository
+	repoVariant         repository.ProductVariantRepository
+	searchLogRepo       repository.SearchLogRepository
+	slugRepo            repository.SlugRepository
+	categoryRepo        repository.CategoryRepository
+	optionRepo          repository.OptionRepository
+	inventoryRepo       repository.InventoryRepository
+	saleCampaignService SaleCampaignService
+	tokenUseCase        token.TokenUseCase
+	cacheable           cache.Cacheable
+	suggestions         cache.SuggestionIndex
+	imageProcessor      imaging.Processor
+	storage             storage.Storage
 }
 
-func NewProductService(db *gorm.DB, repo repository.ProductRepository, repoVariant repository.ProductVariantRepository, tokenUseCase token.TokenUseCase, cacheable cache.Cacheable) ProductService {
+func NewProductService(db *gorm.DB, repo repository.ProductRepository, repoVariant repository.ProductVariantRepository, searchLogRepo repository.SearchLogRepository, slugRepo repository.SlugRepository, categoryRepo repository.CategoryRepository, optionRepo repository.OptionRepository, inventoryRepo repository.InventoryRepository, saleCampaignService SaleCampaignService, tokenUseCase token.TokenUseCase, cacheable cache.Cacheable, suggestions cache.SuggestionIndex, imageProcessor imaging.Processor, storage storage.Storage) ProductService {
 	return &productService{
-		DB:           db,
-		repo:         repo,
-		repoVariant:  repoVariant,
-		tokenUseCase: tokenUseCase,
-		cacheable:    cacheable,
+		DB:                  db,
+		repo:                repo,
+		repoVariant:         repoVariant,
+		searchLogRepo:       searchLogRepo,
+		slugRepo:            slugRepo,
+		categoryRepo:        categoryRepo,
+		optionRepo:          optionRepo,
+		inventoryRepo:       inventoryRepo,
+		saleCampaignService: saleCampaignService,
+		tokenUseCase:        tokenUseCase,
+		cacheable:           cacheable,
+		suggestions:         suggestions,
+		imageProcessor:      imageProcessor,
+		storage:             storage,
 	}
 }
 
-func (s *productService) GetAll(ctx context.Context) (results []*dto.GetAllProducts, err error) {
-	key := cache.CacheKeyProductsGetAll
-	data := s.cacheable.Get(key)
-	if data != "" {
-		err = json.Unmarshal([]byte(data), &results)
+const (
+	ProductSortNewest      = "newest"
+	ProductSortPriceAsc    = "price_asc"
+	ProductSortPriceDesc   = "price_desc"
+	ProductSortBestSelling = "best_selling"
+	ProductSortRating      = "rating"
+	ProductSortRelevance   = "relevance"
+)
+
+const (
+	defaultProductPageSize = 20
+	maxProductPageSize     = 100
+	maxProductSearchLength = 100
+)
+
+var ErrEmptySearchQuery = errors.New("search query is required")
+
+func normalizeProductListQuery(query *dto.ProductListQuery) error {
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.Limit < 1 {
+		query.Limit = defaultProductPageSize
+	}
+	if query.Limit > maxProductPageSize {
+		query.Limit = maxProductPageSize
+	}
+	query.Search = strings.TrimSpace(query.Search)
+	if runes := []rune(query.Search); len(runes) > maxProductSearchLength {
+		query.Search = string(runes[:maxProductSearchLength])
+	}
+	switch query.Sort {
+	case "":
+		query.Sort = ProductSortNewest
+		if query.Search != "" {
+			query.Sort = ProductSortRelevance
+		}
+	case ProductSortRelevance:
+		if query.Search == "" {
+			return errors.New("sort relevance requires a search query")
+		}
+	case ProductSortNewest, ProductSortPriceAsc, ProductSortPriceDesc, ProductSortBestSelling, ProductSortRating:
+	default:
+		return fmt.Errorf("invalid sort %q", query.Sort)
+	}
+	if query.MinPrice != nil && query.MaxPrice != nil && *query.MinPrice > *query.MaxPrice {
+		return errors.New("min_price must not be greater than max_price")
+	}
+	sort.Slice(query.ColorIDs, func(i, j int) bool { return query.ColorIDs[i] < query.ColorIDs[j] })
+	sort.Slice(query.SizeIDs, func(i, j int) bool { return query.SizeIDs[i] < query.SizeIDs[j] })
+	return nil
+}
+
+// productListCacheKey membuat key cache per kombinasi query. Semua key
+// diawali CacheKeyProductsGetAll supaya ikut terhapus saat produk berubah.
+func productListCacheKey(query dto.ProductListQuery) string {
+	key := fmt.Sprintf("%s:page=%d&limit=%d&sort=%s", cache.CacheKeyProductsGetAll, query.Page, query.Limit, query.Sort)
+	if query.CategoryID != nil {
+		key += fmt.Sprintf("&category=%d", *query.CategoryID)
+	}
+	if len(query.ColorIDs) > 0 {
+		key += fmt.Sprintf("&colors=%v", query.ColorIDs)
+	}
+	if len(query.SizeIDs) > 0 {
+		key += fmt.Sprintf("&sizes=%v", query.SizeIDs)
+	}
+	if query.MinPrice != nil {
+		key += fmt.Sprintf("&min=%g", *query.MinPrice)
+	}
+	if query.MaxPrice != nil {
+		key += fmt.Sprintf("&max=%g", *query.MaxPrice)
+	}
+	if query.InStock {
+		key += "&in_stock=true"
+	}
+	if query.Search != "" {
+		key += "&q=" + url.QueryEscape(strings.ToLower(query.Search))
+	}
+	return key
+}
+
+// GetAll mengembalikan satu halaman katalog beserta jumlah per facet. Filter
+// dan urutan harga memakai harga dasar produk, harga promo hanya untuk
+// tampilan.
+func (s *productService) GetAll(ctx context.Context, query dto.ProductListQuery) (*dto.ProductListResponse, error) {
+	if err := normalizeProductListQuery(&query); err != nil {
+		return nil, err
+	}
+
+	key := productListCacheKey(query)
+	if data := s.cacheable.Get(key); data != "" {
+		var cached dto.ProductListResponse
+		if err := json.Unmarshal([]byte(data), &cached); err == nil {
+			return &cached, nil
+		}
+	}
+
+	results, err := s.listProducts(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	s.cacheProductList(key, results)
+	return results, nil
+}
+
+// Search mencari produk dengan full-text search yang diurutkan berdasarkan
+// relevansi. Jika tidak ada yang cocok (misal salah ketik), pencarian diulang
+// dengan kemiripan trigram pada nama produk.
+func (s *productService) Search(ctx context.Context, query dto.ProductListQuery) (*dto.ProductListResponse, error) {
+	query.Fuzzy = false
+	if err := normalizeProductListQuery(&query); err != nil {
+		return nil, err
+	}
+	if query.Search == "" {
+		return nil, ErrEmptySearchQuery
+	}
+
+	key := productListCacheKey(query)
+	if data := s.cacheable.Get(key); data != "" {
+		var cached dto.ProductListResponse
+		if err := json.Unmarshal([]byte(data), &cached); err == nil {
+			s.logSearch(ctx, query, &cached)
+			return &cached, nil
+		}
+	}
+
+	results, err := s.listProducts(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if results.Pagination.Total == 0 {
+		query.Fuzzy = true
+		results, err = s.listProducts(ctx, query)
 		if err != nil {
 			return nil, err
 		}
-		return results, nil
+		results.Fuzzy = true
+	} else {
+		ids := make([]uuid.UUID, 0, len(results.Products))
+		for _, product := range results.Products {
+			ids = append(ids, product.ID)
+		}
+		highlights, err := s.repo.GetSearchHighlights(ctx, ids, query.Search)
+		if err != nil {
+			return nil, err
+		}
+		for _, product := range results.Products {
+			if highlight, ok := highlights[product.ID]; ok {
+				product.Highlight = &highlight
+			}
+		}
 	}
 
-	dataProducts, err := s.repo.GetAll(ctx)
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, errors.New("products not found")
-	} else if err != nil {
+	s.logSearch(ctx, query, results)
+	s.cacheProductList(key, results)
+	return results, nil
+}
+
+func (s *productService) cacheProductList(key string, results *dto.ProductListResponse) {
+	marshalledData, err := json.Marshal(results)
+	if err != nil {
+		log.Printf("WARNING: Failed to marshal product list: %v", err)
+		return
+	}
+	if err := s.cacheable.Set(key, marshalledData); err != nil {
+		log.Printf("WARNING: Failed to cache product list: %v", err)
+	}
+}
+
+func (s *productService) listProducts(ctx context.Context, query dto.ProductListQuery) (*dto.ProductListResponse, error) {
+	dataProducts, total, err := s.repo.List(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	facets, err := s.repo.GetListFacets(ctx, query)
+	if err != nil {
 		return nil, err
 	}
 
+	sales, err := s.saleCampaignService.PriceIndex(s.DB.WithContext(ctx), productIDsOf(dataProducts))
+	if err != nil {
+		return nil, err
+	}
+
+	results := &dto.ProductListResponse{
+		Products: []*dto.GetAllProducts{},
+		Pagination: dto.Pagination{
+			Page:       query.Page,
+			Limit:      query.Limit,
+			Total:      total,
+			TotalPages: int((total + int64(query.Limit) - 1) / int64(query.Limit)),
+		},
+		Facets: *facets,
+	}
 	for _, value := range dataProducts {
 		productDTO := &dto.GetAllProducts{
-			ID:          value.ID,
-			Name:        value.Name,
-			Stock:       value.Stock,
-			Weight:      value.Weight,
-			Price:       value.Price,
-			Description: value.Description,
-			ImageURL:    value.ImageURL,
-			CategoryID:  value.CategoryID,
-			HasVariant:  value.HasVariant,
+			ID:            value.ID,
+			Name:          value.Name,
+			Slug:          value.Slug,
+			SKU:           value.SKU,
+			Barcode:       value.Barcode,
+			Stock:         value.Stock,
+			Weight:        value.Weight,
+			Price:         sales.Price(value.ID, nil, value.Price),
+			OriginalPrice: value.Price,
+			Description:   value.Description,
+			ImageURL:      value.ImageURL,
+			Images:        productImageInfos(value.Images),
+			CategoryID:    value.CategoryID,
+			HasVariant:    value.HasVariant,
+			ProductType:   value.ProductType,
+			Status:        value.Status,
+			PublishAt:     value.PublishAt,
+			UnpublishAt:   value.UnpublishAt,
+		}
+		productDTO.SalePrice, productDTO.SaleEndsAt = saleFields(sales.Lookup(value.ID, nil, value.Price))
+		// Stok bundle dihitung dari stok komponennya
+		if isBundle(value) {
+			productDTO.Stock = bundleStock(value, nil)
+			productDTO.BundleItems = bundleItemInfos(value)
 		}
 		if value.Category != nil {
 			productDTO.CategoryName = &value.Category.Name
@@ -94,6 +323,7 @@ func (s *productService) GetAll(ctx context.Context) (results []*dto.GetAllProdu
 				var variantDTO dto.ProductVariantInfo
 				variantDTO.ID = v.ID
 				variantDTO.Stock = v.Stock
+				variantDTO.SalePrice, variantDTO.SaleEndsAt = saleFields(sales.Lookup(value.ID, &v.ID, variantPrice(value, &v)))
 
 				if v.ColorID != nil {
 					variantDTO.ColorID = v.ColorID
@@ -107,29 +337,23 @@ func (s *productService) GetAll(ctx context.Context) (results []*dto.GetAllProdu
 				if v.Size != nil {
 					variantDTO.Size = v.Size.Name
 				}
+				applyVariantOptions(&variantDTO, v)
+				applyVariantOverrides(&variantDTO, value, v)
 
 				productDTO.Variants = append(productDTO.Variants, variantDTO)
 			}
 		}
 
-		results = append(results, productDTO)
-	}
-
-	marshalledData, err := json.Marshal(results)
-	if err != nil {
-		return nil, err
+		results.Products = append(results.Products, productDTO)
 	}
-
-	err = s.cacheable.Set(key, marshalledData)
-	if err != nil {
-		return nil, err
-	}
-
 	return results, nil
 }
 
-func (s *productService) GetProductByCategoryID(ctx context.Context, categoryID uint) (results []*dto.GetProductByCategoryID, err error) {
+func (s *productService) GetProductByCategoryID(ctx context.Context, categoryID uint, includeDescendants bool) (results []*dto.GetProductByCategoryID, err error) {
 	key := cache.CacheKeyProductsGetByCategoryId + fmt.Sprint(categoryID)
+	if includeDescendants {
+		key += ":tree"
+	}
 	data := s.cacheable.Get(key)
 	if data != "" {
 		err := json.Unmarshal([]byte(data), &results)
@@ -139,24 +363,45 @@ func (s *productService) GetProductByCategoryID(ctx context.Context, categoryID
 		return results, nil
 	}
 
-	dataProducts, err := s.repo.GetByCategoryID(ctx, categoryID)
+	dataProducts, err := s.repo.GetByCategoryID(ctx, categoryID, includeDescendants)
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, errors.New("products not found")
 	} else if err != nil {
 		return nil, err
 	}
 
+	categoryProductIDs := []uuid.UUID{}
+	for _, value := range dataProducts {
+		categoryProductIDs = append(categoryProductIDs, value.ID)
+	}
+	sales, err := s.saleCampaignService.PriceIndex(s.DB.WithContext(ctx), categoryProductIDs)
+	if err != nil {
+		return nil, err
+	}
+
 	for _, value := range dataProducts {
 		productDTO := &dto.GetProductByCategoryID{
-			ID:          value.ID,
-			Name:        value.Name,
-			Stock:       value.Stock,
-			Weight:      value.Weight,
-			Price:       value.Price,
-			Description: value.Description,
-			ImageURL:    value.ImageURL,
-			CategoryID:  value.CategoryID,
-			HasVariant:  value.HasVariant,
+			ID:            value.ID,
+			Name:          value.Name,
+			Slug:          value.Slug,
+			SKU:           value.SKU,
+			Barcode:       value.Barcode,
+			Stock:         value.Stock,
+			Weight:        value.Weight,
+			Price:         sales.Price(value.ID, nil, value.Price),
+			OriginalPrice: value.Price,
+			Description:   value.Description,
+			ImageURL:      value.ImageURL,
+			Images:        productImageInfos(value.Images),
+			CategoryID:    value.CategoryID,
+			HasVariant:    value.HasVariant,
+			ProductType:   value.ProductType,
+		}
+		productDTO.SalePrice, productDTO.SaleEndsAt = saleFields(sales.Lookup(value.ID, nil, value.Price))
+		// Stok bundle dihitung dari stok komponennya
+		if isBundle(&value) {
+			productDTO.Stock = bundleStock(&value, nil)
+			productDTO.BundleItems = bundleItemInfos(&value)
 		}
 		if value.Category != nil {
 			productDTO.CategoryName = &value.Category.Name
@@ -167,6 +412,7 @@ func (s *productService) GetProductByCategoryID(ctx context.Context, categoryID
 				var variantDTO dto.ProductVariantInfo
 				variantDTO.ID = v.ID
 				variantDTO.Stock = v.Stock
+				variantDTO.SalePrice, variantDTO.SaleEndsAt = saleFields(sales.Lookup(value.ID, &v.ID, variantPrice(&value, &v)))
 
 				if v.ColorID != nil {
 					variantDTO.ColorID = v.ColorID
@@ -180,6 +426,8 @@ func (s *productService) GetProductByCategoryID(ctx context.Context, categoryID
 				if v.Size != nil {
 					variantDTO.Size = v.Size.Name
 				}
+				applyVariantOptions(&variantDTO, v)
+				applyVariantOverrides(&variantDTO, &value, v)
 
 				productDTO.Variants = append(productDTO.Variants, variantDTO)
 			}
@@ -209,17 +457,33 @@ func (s *productService) GetProductByName(ctx context.Context, name string) (res
 		return nil, err
 	}
 
+	sales, err := s.saleCampaignService.PriceIndex(s.DB.WithContext(ctx), productIDsOf(dataProducts))
+	if err != nil {
+		return nil, err
+	}
+
 	for _, product := range dataProducts {
 		result := dto.GetProductByName{
-			ID:          product.ID,
-			Name:        product.Name,
-			Weight:      product.Weight,
-			Description: product.Description,
-			ImageURL:    product.ImageURL,
-			CategoryID:  product.CategoryID,
-			HasVariant:  product.HasVariant,
-			Price:       product.Price,
-			Stock:       product.Stock,
+			ID:            product.ID,
+			Name:          product.Name,
+			Slug:          product.Slug,
+			SKU:           product.SKU,
+			Barcode:       product.Barcode,
+			Weight:        product.Weight,
+			Description:   product.Description,
+			ImageURL:      product.ImageURL,
+			Images:        productImageInfos(product.Images),
+			CategoryID:    product.CategoryID,
+			HasVariant:    product.HasVariant,
+			ProductType:   product.ProductType,
+			Price:         sales.Price(product.ID, nil, product.Price),
+			OriginalPrice: product.Price,
+			Stock:         product.Stock,
+		}
+		result.SalePrice, result.SaleEndsAt = saleFields(sales.Lookup(product.ID, nil, product.Price))
+		if isBundle(product) {
+			result.Stock = bundleStock(product, nil)
+			result.BundleItems = bundleItemInfos(product)
 		}
 
 		if product.Category != nil {
@@ -231,6 +495,7 @@ func (s *productService) GetProductByName(ctx context.Context, name string) (res
 				var variantDTO dto.ProductVariantInfo
 				variantDTO.ID = v.ID
 				variantDTO.Stock = v.Stock
+				variantDTO.SalePrice, variantDTO.SaleEndsAt = saleFields(sales.Lookup(product.ID, &v.ID, variantPrice(product, &v)))
 
 				if v.ColorID != nil {
 					variantDTO.ColorID = v.ColorID
@@ -244,6 +509,8 @@ func (s *productService) GetProductByName(ctx context.Context, name string) (res
 				if v.Size != nil {
 					variantDTO.Size = v.Size.Name
 				}
+				applyVariantOptions(&variantDTO, v)
+				applyVariantOverrides(&variantDTO, product, v)
 
 				result.Variants = append(result.Variants, variantDTO)
 			}
@@ -267,23 +534,69 @@ func (s *productService) GetByID(ctx context.Context, id uuid.UUID) (result *dto
 
 	dataProduct, err := s.repo.GetByID(s.DB.WithContext(ctx), id)
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, errors.New("products not found")
+		return nil, ErrProductNotFound
 	} else if err != nil {
 		return nil, err
 	}
+	// Produk yang belum atau sudah tidak tayang tidak tampil di katalog
+	if !productVisible(dataProduct, time.Now()) {
+		return nil, ErrProductNotFound
+	}
+
+	result, err = s.productDetail(ctx, dataProduct)
+	if err != nil {
+		return nil, err
+	}
+
+	marshalledData, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
 
-	result = &dto.GetProductByID{
-		ID:          dataProduct.ID,
-		Name:        dataProduct.Name,
-		Description: dataProduct.Description,
-		ImageURL:    dataProduct.ImageURL,
-		HasVariant:  dataProduct.HasVariant,
-		Price:       dataProduct.Price,
-		Weight:      dataProduct.Weight,
-		CategoryID:  dataProduct.CategoryID,
-		Stock:       dataProduct.Stock,
+	err = s.cacheable.Set(key, marshalledData)
+	if err != nil {
+		return nil, err
 	}
 
+	return result, nil
+}
+
+// productDetail detail produk untuk katalog maupun admin.
+func (s *productService) productDetail(ctx context.Context, dataProduct *entity.Product) (*dto.GetProductByID, error) {
+	sales, err := s.saleCampaignService.PriceIndex(s.DB.WithContext(ctx), []uuid.UUID{dataProduct.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.GetProductByID{
+		ID:            dataProduct.ID,
+		Name:          dataProduct.Name,
+		Slug:          dataProduct.Slug,
+		SKU:           dataProduct.SKU,
+		Barcode:       dataProduct.Barcode,
+		Description:   dataProduct.Description,
+		ImageURL:      dataProduct.ImageURL,
+		Images:        productImageInfos(dataProduct.Images),
+		HasVariant:    dataProduct.HasVariant,
+		ProductType:   dataProduct.ProductType,
+		Status:        dataProduct.Status,
+		PublishAt:     dataProduct.PublishAt,
+		UnpublishAt:   dataProduct.UnpublishAt,
+		Price:         sales.Price(dataProduct.ID, nil, dataProduct.Price),
+		OriginalPrice: dataProduct.Price,
+		Weight:        dataProduct.Weight,
+		CategoryID:    dataProduct.CategoryID,
+		Stock:         dataProduct.Stock,
+	}
+
+	result.SalePrice, result.SaleEndsAt = saleFields(sales.Lookup(dataProduct.ID, nil, dataProduct.Price))
+	if isBundle(dataProduct) {
+		result.Stock = bundleStock(dataProduct, nil)
+		result.BundleItems = bundleItemInfos(dataProduct)
+	}
+	if len(dataProduct.PriceTiers) > 0 {
+		result.PriceTiers = priceTierInfos(dataProduct)
+	}
 	if dataProduct.Category != nil {
 		result.CategoryName = &dataProduct.Category.Name
 	}
@@ -294,6 +607,7 @@ func (s *productService) GetByID(ctx context.Context, id uuid.UUID) (result *dto
 			var variantDTO dto.ProductVariantInfo
 			variantDTO.ID = v.ID
 			variantDTO.Stock = v.Stock
+			variantDTO.SalePrice, variantDTO.SaleEndsAt = saleFields(sales.Lookup(dataProduct.ID, &v.ID, variantPrice(dataProduct, &v)))
 
 			if v.ColorID != nil {
 				variantDTO.ColorID = v.ColorID
@@ -307,21 +621,13 @@ func (s *productService) GetByID(ctx context.Context, id uuid.UUID) (result *dto
 			if v.Size != nil {
 				variantDTO.Size = v.Size.Name
 			}
+			applyVariantOptions(&variantDTO, v)
+			applyVariantOverrides(&variantDTO, dataProduct, v)
 
 			result.Variants = append(result.Variants, variantDTO)
 		}
 	}
 
-	marshalledData, err := json.Marshal(result)
-	if err != nil {
-		return nil, err
-	}
-
-	err = s.cacheable.Set(key, marshalledData)
-	if err != nil {
-		return nil, err
-	}
-
 	return result, nil
 }
 
@@ -487,45 +793,30 @@ func (s *productService) GetProductReviews(ctx context.Context, productID uuid.U
 }
 
 func (s *productService) Create(ctx context.Context, request *dto.CreateProductRequest) error {
-	// ✅ Cek apakah file image nil
-	if request.Image == nil {
-		log.Println("ERROR: request.Image is nil")
-		return errors.New("no image uploaded")
+	if request.ProductType == "" {
+		request.ProductType = ProductTypeSimple
 	}
-
-	// ✅ Coba buka image
-	src, err := request.Image.Open()
+	if err := validateProductType(request.ProductType, request.BundleItems); err != nil {
+		return err
+	}
+	status, err := normalizeProductPublication(request.Status, request.PublishAt, request.UnpublishAt, entity.ProductStatusDraft)
 	if err != nil {
-		log.Printf("ERROR OPEN IMAGE: %v", err)
-		return errors.New("failed to open image")
+		return err
 	}
-	defer src.Close()
 
-	// ✅ Buat folder kalau belum ada
-	imageDir := "/var/www/mola-web/backend/public/products/images"
-	err = os.MkdirAll(imageDir, os.ModePerm)
-	if err != nil {
-		log.Printf("ERROR CREATE DIR: %v", err)
-		return fmt.Errorf("failed to create image directory: %v", err)
+	// ✅ Cek apakah file image nil
+	if request.Image == nil {
+		log.Println("ERROR: request.Image is nil")
+		return errors.New("no image uploaded")
 	}
 
 	// ✅ Simpan image ke folder
-	fileName := fmt.Sprintf("%d_%s", time.Now().Unix(), request.Image.Filename)
-	imagePath := filepath.Join(imageDir, fileName)
-	dst, err := os.Create(imagePath)
+	image, err := s.saveProductImage(ctx, request.Image)
 	if err != nil {
-		log.Printf("ERROR CREATE FILE: %s, err: %v", imagePath, err)
-		return fmt.Errorf("failed to create image file: %v", err)
-	}
-	defer dst.Close()
-
-	if _, err := io.Copy(dst, src); err != nil {
-		log.Printf("ERROR COPY IMAGE: %v", err)
-		return fmt.Errorf("failed to save image: %v", err)
+		return err
 	}
-
-	log.Printf("SUKSES SIMPAN GAMBAR DI: %s", imagePath)
-	*request.ImageURL = "/static/products/images/" + fileName
+	log.Printf("SUKSES SIMPAN GAMBAR DI: %s", image.URL)
+	request.ImageURL = &image.URL
 
 	// Mulai transaksi DB
 	log.Println("req", request)
@@ -540,19 +831,48 @@ func (s *productService) Create(ctx context.Context, request *dto.CreateProductR
 		} else if tx.Error != nil {
 			tx.Rollback()
 			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
+			// Gambar yang sudah tersimpan tidak lagi dipakai
+			s.deleteProductImage(context.Background(), *image)
 		}
 	}()
 
 	hasVariant := request.HasVariant
+	stock := request.Stock
+	// Bundle tidak punya varian dan stok sendiri
+	if request.ProductType == ProductTypeBundle {
+		hasVariant = false
+		stock = 0
+	}
 	product := &entity.Product{
 		Name:        request.Name,
 		CategoryID:  request.CategoryID,
 		Description: request.Description,
 		ImageURL:    request.ImageURL,
 		HasVariant:  hasVariant,
+		ProductType: request.ProductType,
 		Price:       request.Price,
 		Weight:      request.Weight,
-		Stock:       request.Stock,
+		Stock:       stock,
+		Status:      status,
+		PublishAt:   request.PublishAt,
+		UnpublishAt: request.UnpublishAt,
+	}
+
+	productSlug, err := assignSlug(tx, s.slugRepo, slugChange{
+		EntityType: entity.SlugEntityProduct,
+		Name:       request.Name,
+		Requested:  request.Slug,
+	})
+	if err != nil {
+		tx.Error = err
+		return err
+	}
+	product.Slug = &productSlug
+
+	codes := newCodeRegistry(tx, s.inventoryRepo)
+	if err := codes.assignProductCodes(product, request.SKU, request.Barcode, nil); err != nil {
+		tx.Error = err
+		return err
 	}
 
 	err = s.repo.Create(tx, product)
@@ -561,13 +881,37 @@ func (s *productService) Create(ctx context.Context, request *dto.CreateProductR
 		return err
 	}
 
+	if err := s.syncProductGallery(tx, product.ID, image); err != nil {
+		tx.Error = err
+		return err
+	}
+
 	if hasVariant {
+		checker, err := newVariantChecker(codes, s.repo, product)
+		if err != nil {
+			tx.Error = err
+			return err
+		}
 		for _, v := range request.Variants {
+			options, colorID, sizeID, err := resolveVariantOptions(tx, s.optionRepo, v.ColorID, v.SizeID, v.OptionValueIDs)
+			if err != nil {
+				tx.Error = err
+				return err
+			}
 			var variant = &entity.ProductVariant{
 				ProductID: product.ID,
-				ColorID:   v.ColorID,
-				SizeID:    v.SizeID,
+				ColorID:   colorID,
+				SizeID:    sizeID,
 				Stock:     v.Stock,
+				Options:   options,
+			}
+			if err := applyVariantOverrideRequest(variant, v.ProductVariantOverrides); err != nil {
+				tx.Error = err
+				return err
+			}
+			if err := checker.check(variant, nil); err != nil {
+				tx.Error = err
+				return err
 			}
 			err = s.repoVariant.Create(tx, variant)
 			if err != nil {
@@ -577,17 +921,37 @@ func (s *productService) Create(ctx context.Context, request *dto.CreateProductR
 		}
 	}
 
+	if request.ProductType == ProductTypeBundle {
+		if err := s.replaceBundleItems(tx, product.ID, request.BundleItems); err != nil {
+			tx.Error = err
+			return err
+		}
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		tx.Error = err
 		return err
 	}
 
 	_ = s.invalidateProductListCaches()
+	s.indexProductSuggestion(ctx, product.ID)
 	return nil
 }
 
 func (s *productService) Update(ctx context.Context, request *dto.UpdateProductRequest) error {
+	// ProductType kosong berarti tipe produk tidak diubah
+	if request.ProductType != "" {
+		if err := validateProductType(request.ProductType, request.BundleItems); err != nil {
+			return err
+		}
+		if request.ProductType == ProductTypeBundle {
+			request.HasVariant = false
+			request.Stock = 0
+		}
+	}
+
 	tx := s.DB.WithContext(ctx).Begin()
+	var newImage *entity.ProductImage
 	defer func() {
 		if p := recover(); p != nil {
 			tx.Rollback()
@@ -596,32 +960,43 @@ func (s *productService) Update(ctx context.Context, request *dto.UpdateProductR
 		} else if tx.Error != nil {
 			tx.Rollback()
 			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
+			// Gambar baru yang sudah tersimpan tidak lagi dipakai
+			if newImage != nil {
+				s.deleteProductImage(context.Background(), *newImage)
+			}
 		}
 	}()
 
-	// Upload gambar baru jika ada
-	if request.Image != nil {
-		src, err := request.Image.Open()
-		if err != nil {
-			tx.Error = err
-			return errors.New("failed to open image")
-		}
-		defer src.Close()
+	current, err := s.repo.GetByID(tx, request.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Error = errors.New("product not found")
+		return tx.Error
+	} else if err != nil {
+		tx.Error = err
+		return err
+	}
+	productSlug, err := assignSlug(tx, s.slugRepo, slugChange{
+		EntityType: entity.SlugEntityProduct,
+		EntityID:   current.ID.String(),
+		Current:    current.Slug,
+		Name:       request.Name,
+		Requested:  request.Slug,
+		Keep:       request.KeepSlug,
+	})
+	if err != nil {
+		tx.Error = err
+		return err
+	}
 
-		fileName := fmt.Sprintf("%d_%s", time.Now().Unix(), request.Image.Filename)
-		imagePath := filepath.Join("/var/www/mola-web/backend/public/products/images", fileName)
-		dst, err := os.Create(imagePath)
+	// Upload gambar baru jika ada, gambar baru menjadi gambar utama galeri
+	if request.Image != nil {
+		image, err := s.saveProductImage(ctx, request.Image)
 		if err != nil {
 			tx.Error = err
 			return err
 		}
-		defer dst.Close()
-
-		if _, err := io.Copy(dst, src); err != nil {
-			tx.Error = err
-			return err
-		}
-		*request.ImageURL = "/static/products/images/" + fileName
+		newImage = image
+		request.ImageURL = &image.URL
 	}
 
 	// Update produk utama
@@ -632,20 +1007,46 @@ func (s *productService) Update(ctx context.Context, request *dto.UpdateProductR
 		Description: request.Description,
 		ImageURL:    request.ImageURL,
 		HasVariant:  request.HasVariant,
+		ProductType: request.ProductType,
 		Price:       request.Price,
 		Weight:      request.Weight,
+		Slug:        &productSlug,
 	}
 
 	if !request.HasVariant {
 		product.Stock = request.Stock
 	}
 
-	err := s.repo.Update(tx, product)
+	codes := newCodeRegistry(tx, s.inventoryRepo)
+	if err := codes.assignProductCodes(product, request.SKU, request.Barcode, current); err != nil {
+		tx.Error = err
+		return err
+	}
+
+	err = s.repo.Update(tx, product)
 	if err != nil {
 		tx.Error = err
 		return err
 	}
 
+	if err := s.syncProductGallery(tx, product.ID, newImage); err != nil {
+		tx.Error = err
+		return err
+	}
+
+	switch request.ProductType {
+	case ProductTypeBundle:
+		if err := s.replaceBundleItems(tx, product.ID, request.BundleItems); err != nil {
+			tx.Error = err
+			return err
+		}
+	case ProductTypeSimple:
+		if err := s.repo.ReplaceBundleItems(tx, product.ID, nil); err != nil {
+			tx.Error = err
+			return err
+		}
+	}
+
 	// Jika memiliki variant, lakukan sinkronisasi varian
 	if request.HasVariant {
 		// Ambil semua varian lama
@@ -655,70 +1056,87 @@ func (s *productService) Update(ctx context.Context, request *dto.UpdateProductR
 			return err
 		}
 
-		// Map untuk melacak varian yang sudah diproses
-		processed := make(map[string]bool)
-
-		for i, newVar := range request.Variants {
-			log.Println("==========================", request.Variants[i].ID)
-			key := fmt.Sprintf("%v:%v", newVar.ColorID, newVar.SizeID)
-			processed[key] = true
-			found := false
-
-			// Cek apakah varian baru ini sudah ada di varian lama
-			for _, oldVar := range existingVariants {
-				if (oldVar.ColorID == nil && newVar.ColorID == nil || oldVar.ColorID != nil && newVar.ColorID != nil && *oldVar.ColorID == *newVar.ColorID) &&
-					(oldVar.SizeID == nil && newVar.SizeID == nil || oldVar.SizeID != nil && newVar.SizeID != nil && *oldVar.SizeID == *newVar.SizeID) {
-
-					// Update stok jika berubah
-					if oldVar.Stock != newVar.Stock {
-						oldVar.Stock = newVar.Stock
-						if err := s.repoVariant.Update(tx, oldVar); err != nil {
-							tx.Error = err
-							return err
-						}
-					}
-					found = true
-					break
-				}
+		// Varian dicocokkan lewat ID, lalu lewat kombinasi opsinya
+		byID := make(map[uuid.UUID]*entity.ProductVariant, len(existingVariants))
+		byKey := make(map[string]*entity.ProductVariant, len(existingVariants))
+		for _, oldVar := range existingVariants {
+			byID[oldVar.ID] = oldVar
+			byKey[variantOptionKey(oldVar.Options)] = oldVar
+		}
+		processed := make(map[uuid.UUID]bool)
+		checker, err := newVariantChecker(codes, s.repo, product)
+		if err != nil {
+			tx.Error = err
+			return err
+		}
+
+		for _, newVar := range request.Variants {
+			options, colorID, sizeID, err := resolveVariantOptions(tx, s.optionRepo, newVar.ColorID, newVar.SizeID, newVar.OptionValueIDs)
+			if err != nil {
+				tx.Error = err
+				return err
+			}
+			key := variantOptionKey(options)
+
+			var oldVar *entity.ProductVariant
+			if newVar.ID != nil {
+				oldVar = byID[*newVar.ID]
+			}
+			if oldVar == nil {
+				oldVar = byKey[key]
 			}
 
 			// Jika varian tidak ditemukan, tambahkan sebagai varian baru
-			if !found {
+			if oldVar == nil || processed[oldVar.ID] {
 				newEntity := &entity.ProductVariant{
 					ProductID: product.ID,
-					ColorID:   newVar.ColorID,
-					SizeID:    newVar.SizeID,
+					ColorID:   colorID,
+					SizeID:    sizeID,
 					Stock:     newVar.Stock,
+					Options:   options,
+				}
+				if err := applyVariantOverrideRequest(newEntity, newVar.ProductVariantOverrides); err != nil {
+					tx.Error = err
+					return err
+				}
+				if err := checker.check(newEntity, nil); err != nil {
+					tx.Error = err
+					return err
 				}
 				if err := s.repoVariant.Create(tx, newEntity); err != nil {
 					tx.Error = err
 					return err
 				}
+				continue
 			}
-		}
-		// for i, value := range existingVariants {
-		// 	if request.Variants[i].ID == &value.ID {
-		// 		continue
-		// 	} else {
-		// 		if err := s.repoVariant.DeleteByID(tx, value.ID); err != nil {
-		// 			tx.Error = err
-		// 			return err
-		// 		}
-		// 	}
-		// }
-		// Helper: bandingkan UUID dengan aman, termasuk nil
-
-		variantIDsFromRequest := make(map[uuid.UUID]bool)
-		log.Println("variantID", request.Variants[0].ID)
-		for _, newVar := range request.Variants {
-			if newVar.ID != nil {
-				variantIDsFromRequest[*newVar.ID] = true
+			processed[oldVar.ID] = true
+
+			if variantOptionKey(oldVar.Options) != key {
+				oldVar.ColorID, oldVar.SizeID = colorID, sizeID
+				if err := s.repoVariant.ReplaceOptions(tx, oldVar, options); err != nil {
+					tx.Error = err
+					return err
+				}
+			}
+			// Stok dan override selalu ditimpa dengan nilai dari request
+			oldVar.Stock = newVar.Stock
+			if err := applyVariantOverrideRequest(oldVar, newVar.ProductVariantOverrides); err != nil {
+				tx.Error = err
+				return err
+			}
+			if err := checker.check(oldVar, &oldVar.ID); err != nil {
+				tx.Error = err
+				return err
+			}
+			if err := s.repoVariant.Update(tx, oldVar); err != nil {
+				tx.Error = err
+				return err
 			}
 		}
-		log.Println("variantIDsFromRequest", variantIDsFromRequest)
+
 		// Hapus varian lama yang tidak ada di permintaan update
 		for _, oldVar := range existingVariants {
-			if _, exists := variantIDsFromRequest[oldVar.ID]; !exists {
+			if !processed[oldVar.ID] {
 				if err := s.repoVariant.DeleteByID(tx, oldVar.ID); err != nil {
 					tx.Error = err
 					return err
@@ -734,6 +1152,7 @@ func (s *productService) Update(ctx context.Context, request *dto.UpdateProductR
 	}
 
 	_ = s.invalidateProductListCaches()
+	s.indexProductSuggestion(ctx, request.ID)
 
 	return nil
 }
@@ -775,10 +1194,56 @@ func (s *productService) Delete(ctx context.Context, id uuid.UUID) error {
 	}
 
 	_ = s.invalidateProductListCaches()
+	s.removeProductSuggestion(ctx, id)
 
 	return nil
 }
 
+func validateProductType(productType string, bundleItems []dto.BundleItemRequest) error {
+	switch productType {
+	case ProductTypeSimple:
+		return nil
+	case ProductTypeBundle:
+		if len(bundleItems) == 0 {
+			return errors.New("bundle must have at least one component")
+		}
+		return nil
+	}
+	return errors.New("product type must be simple or bundle")
+}
+
+// replaceBundleItems memvalidasi komponen bundle lalu menyimpannya.
+func (s *productService) replaceBundleItems(tx *gorm.DB, bundleID uuid.UUID, requests []dto.BundleItemRequest) error {
+	items := []entity.ProductBundleItem{}
+	for _, request := range requests {
+		if request.Quantity < 1 {
+			return errors.New("bundle component quantity must be at least 1")
+		}
+		if request.ProductID == bundleID {
+			return errors.New("bundle cannot contain itself")
+		}
+		component, err := s.repo.GetByID(tx, request.ProductID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("bundle component %s not found", request.ProductID)
+		} else if err != nil {
+			return err
+		}
+		if isBundle(component) {
+			return fmt.Errorf("%s is a bundle and cannot be a component", component.Name)
+		}
+		if request.ProductVariantID != nil && !productHasVariant(component, *request.ProductVariantID) {
+			return fmt.Errorf("variant does not belong to %s", component.Name)
+		}
+		items = append(items, entity.ProductBundleItem{
+			BundleID:           bundleID,
+			ComponentID:        component.ID,
+			ComponentVariantID: request.ProductVariantID,
+			Quantity:           request.Quantity,
+		})
+	}
+	return s.repo.ReplaceBundleItems(tx, bundleID, items)
+}
+
 func (s *productService) invalidateProductListCaches() error {
 	for _, key := range cache.ListCacheKeysProductToInvalidate {
 		err := s.cacheable.DeleteByPrefix(key)
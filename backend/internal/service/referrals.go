@@ -0,0 +1,318 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"mola-web/internal/entity"
+	"mola-web/internal/http/dto"
+	"mola-web/internal/repository"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	ReferralStatusPending   = "pending"
+	ReferralStatusConverted = "converted"
+	ReferralStatusReversed  = "reversed"
+)
+
+var ErrInvalidReferralCode = errors.New("invalid referral code")
+
+type ReferralService interface {
+	ResolveCode(db *gorm.DB, code string) (*entity.User, error)
+	RecordReferral(db *gorm.DB, referrer *entity.User, referee *entity.User) error
+	ConvertOnPaid(db *gorm.DB, order *entity.Order) error
+	ReverseOrder(db *gorm.DB, orderID uuid.UUID) error
+	GetSummary(ctx context.Context, user *entity.User) (*dto.ReferralSummary, error)
+	GetReport(ctx context.Context) (*dto.ReferralReport, error)
+	GetSettings(ctx context.Context) (*dto.ReferralSettingsResponse, error)
+	UpdateSettings(ctx context.Context, req *dto.ReferralSettingsRequest) error
+}
+
+type referralService struct {
+	DB           *gorm.DB
+	referralRepo repository.ReferralRepository
+	userRepo     repository.UserRepository
+	voucherRepo  repository.VoucherRepository
+}
+
+func NewReferralService(db *gorm.DB, referralRepo repository.ReferralRepository, userRepo repository.UserRepository, voucherRepo repository.VoucherRepository) ReferralService {
+	return &referralService{
+		DB:           db,
+		referralRepo: referralRepo,
+		userRepo:     userRepo,
+		voucherRepo:  voucherRepo,
+	}
+}
+
+// newReferralCode membuat kode undangan 8 karakter huruf besar.
+func newReferralCode() string {
+	return strings.ToUpper(generateToken())
+}
+
+func (s *referralService) ResolveCode(db *gorm.DB, code string) (*entity.User, error) {
+	referrer, err := s.userRepo.FindByReferralCode(db, strings.ToUpper(strings.TrimSpace(code)))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidReferralCode
+	} else if err != nil {
+		return nil, err
+	}
+	return referrer, nil
+}
+
+func (s *referralService) RecordReferral(db *gorm.DB, referrer *entity.User, referee *entity.User) error {
+	if referrer.ID == referee.ID {
+		return ErrInvalidReferralCode
+	}
+	return s.referralRepo.Create(db, &entity.Referral{
+		ReferrerID: referrer.ID,
+		RefereeID:  referee.ID,
+		Code:       *referrer.ReferralCode,
+		Status:     ReferralStatusPending,
+	})
+}
+
+// ConvertOnPaid memberi voucher hadiah ke pengundang dan yang diundang saat
+// order pertama yang memenuhi syarat sudah lunas.
+func (s *referralService) ConvertOnPaid(db *gorm.DB, order *entity.Order) error {
+	referral, err := s.referralRepo.GetPendingByRefereeForUpdate(db, order.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	settings, err := s.referralRepo.GetSettings(db)
+	if err != nil {
+		return err
+	}
+	if !settings.IsActive || order.TotalAmount < settings.MinOrderAmount {
+		return nil
+	}
+
+	referrerVoucher, err := s.createRewardVoucher(db, settings, referral.ReferrerID, settings.ReferrerReward)
+	if err != nil {
+		return err
+	}
+	refereeVoucher, err := s.createRewardVoucher(db, settings, referral.RefereeID, settings.RefereeReward)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	referral.Status = ReferralStatusConverted
+	referral.OrderID = &order.ID
+	referral.ConvertedAt = &now
+	referral.ReferrerReward = settings.ReferrerReward
+	referral.RefereeReward = settings.RefereeReward
+	if referrerVoucher != nil {
+		referral.ReferrerVoucherID = &referrerVoucher.ID
+	}
+	if refereeVoucher != nil {
+		referral.RefereeVoucherID = &refereeVoucher.ID
+	}
+	return s.referralRepo.Update(db, referral)
+}
+
+// ReverseOrder menarik hadiah referral dari order yang direfund atau batal.
+// Voucher hadiah yang belum dipakai dinonaktifkan, yang sudah terpakai
+// dibiarkan.
+func (s *referralService) ReverseOrder(db *gorm.DB, orderID uuid.UUID) error {
+	referral, err := s.referralRepo.GetConvertedByOrderIDForUpdate(db, orderID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for _, voucherID := range []*uuid.UUID{referral.ReferrerVoucherID, referral.RefereeVoucherID} {
+		if voucherID == nil {
+			continue
+		}
+		if err := s.revokeRewardVoucher(db, *voucherID); err != nil {
+			return err
+		}
+	}
+
+	referral.Status = ReferralStatusReversed
+	return s.referralRepo.Update(db, referral)
+}
+
+func (s *referralService) revokeRewardVoucher(db *gorm.DB, voucherID uuid.UUID) error {
+	used, err := s.voucherRepo.CountRedemptions(db, voucherID)
+	if err != nil {
+		return err
+	}
+	if used > 0 {
+		return nil
+	}
+	voucher, err := s.voucherRepo.GetByID(db, voucherID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !voucher.IsActive {
+		return nil
+	}
+	voucher.IsActive = false
+	return s.voucherRepo.Update(db, voucher)
+}
+
+// createRewardVoucher membuat voucher potongan tetap sekali pakai yang hanya
+// bisa dipakai oleh user tersebut.
+func (s *referralService) createRewardVoucher(db *gorm.DB, settings *entity.ReferralSettings, userID uuid.UUID, amount float64) (*entity.Voucher, error) {
+	if amount <= 0 {
+		return nil, nil
+	}
+	description := "Referral reward"
+	limit := 1
+	voucher := &entity.Voucher{
+		Code:         "REF-" + newReferralCode(),
+		Description:  &description,
+		DiscountType: VoucherTypeFixed,
+		Value:        amount,
+		MinSpend:     settings.RewardMinSpend,
+		UsageLimit:   &limit,
+		PerUserLimit: &limit,
+		IsActive:     true,
+		UserID:       &userID,
+	}
+	if settings.RewardValidDays > 0 {
+		endsAt := time.Now().AddDate(0, 0, settings.RewardValidDays)
+		voucher.EndsAt = &endsAt
+	}
+	if err := s.voucherRepo.Create(db, voucher); err != nil {
+		return nil, err
+	}
+	return voucher, nil
+}
+
+func (s *referralService) GetSummary(ctx context.Context, user *entity.User) (*dto.ReferralSummary, error) {
+	referrals, err := s.referralRepo.GetByUserID(s.DB.WithContext(ctx), user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.ReferralSummary{
+		Rewards: []dto.ReferralReward{},
+	}
+	if user.ReferralCode != nil {
+		result.Code = *user.ReferralCode
+	}
+	for _, referral := range referrals {
+		voucher := referral.RefereeVoucher
+		if referral.ReferrerID == user.ID {
+			result.Invited++
+			if referral.Status == ReferralStatusConverted {
+				result.Converted++
+			}
+			voucher = referral.ReferrerVoucher
+		}
+		// Hadiah dari referral yang ditarik dan belum dipakai tidak ditampilkan
+		if voucher != nil && (referral.Status != ReferralStatusReversed || voucher.IsActive) {
+			result.Rewards = append(result.Rewards, dto.ReferralReward{
+				VoucherCode: voucher.Code,
+				Amount:      voucher.Value,
+				MinSpend:    voucher.MinSpend,
+				ExpiresAt:   voucher.EndsAt,
+			})
+		}
+	}
+	return result, nil
+}
+
+func (s *referralService) GetReport(ctx context.Context) (*dto.ReferralReport, error) {
+	referrals, err := s.referralRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.ReferralReport{
+		Referrals: []dto.ReferralReportItem{},
+	}
+	for _, referral := range referrals {
+		item := dto.ReferralReportItem{
+			ID:             referral.ID,
+			ReferrerID:     referral.ReferrerID,
+			RefereeID:      referral.RefereeID,
+			Code:           referral.Code,
+			Status:         referral.Status,
+			ReferrerReward: referral.ReferrerReward,
+			RefereeReward:  referral.RefereeReward,
+			CreatedAt:      referral.CreatedAt,
+			ConvertedAt:    referral.ConvertedAt,
+		}
+		if referral.Referrer != nil {
+			item.ReferrerName = referral.Referrer.Name
+		}
+		if referral.Referee != nil {
+			item.RefereeName = referral.Referee.Name
+		}
+		if referral.Order != nil {
+			item.OrderCode = &referral.Order.OrderCode
+		}
+
+		result.TotalReferrals++
+		if referral.Status == ReferralStatusConverted {
+			result.Converted++
+			result.TotalRewards += referral.ReferrerReward + referral.RefereeReward
+		}
+		result.Referrals = append(result.Referrals, item)
+	}
+	if result.TotalReferrals > 0 {
+		result.ConversionRate = float64(result.Converted) / float64(result.TotalReferrals) * 100
+	}
+	return result, nil
+}
+
+func (s *referralService) GetSettings(ctx context.Context) (*dto.ReferralSettingsResponse, error) {
+	settings, err := s.referralRepo.GetSettings(s.DB.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return &dto.ReferralSettingsResponse{
+		IsActive:        settings.IsActive,
+		ReferrerReward:  settings.ReferrerReward,
+		RefereeReward:   settings.RefereeReward,
+		MinOrderAmount:  settings.MinOrderAmount,
+		RewardMinSpend:  settings.RewardMinSpend,
+		RewardValidDays: settings.RewardValidDays,
+		UpdatedAt:       settings.UpdatedAt,
+	}, nil
+}
+
+func (s *referralService) UpdateSettings(ctx context.Context, req *dto.ReferralSettingsRequest) error {
+	if req.ReferrerReward < 0 || req.RefereeReward < 0 {
+		return errors.New("reward must not be negative")
+	}
+	if req.MinOrderAmount < 0 || req.RewardMinSpend < 0 {
+		return errors.New("minimum amount must not be negative")
+	}
+	if req.RewardValidDays < 0 {
+		return errors.New("reward validity must not be negative")
+	}
+
+	db := s.DB.WithContext(ctx)
+	settings, err := s.referralRepo.GetSettings(db)
+	if err != nil {
+		return err
+	}
+	if req.IsActive != nil {
+		settings.IsActive = *req.IsActive
+	}
+	settings.ReferrerReward = req.ReferrerReward
+	settings.RefereeReward = req.RefereeReward
+	settings.MinOrderAmount = req.MinOrderAmount
+	settings.RewardMinSpend = req.RewardMinSpend
+	settings.RewardValidDays = req.RewardValidDays
+	if err := s.referralRepo.UpdateSettings(db, settings); err != nil {
+		log.Printf("ERROR: failed to update referral settings: %v", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,380 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"mola-web/internal/entity"
+	"mola-web/internal/http/dto"
+	"mola-web/internal/repository"
+	"mola-web/pkg/cache"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSaleCampaignNotFound = errors.New("sale campaign not found")
+	ErrSaleStockExhausted   = errors.New("sale stock for this campaign has run out")
+)
+
+type SaleCampaignService interface {
+	GetAll(ctx context.Context) ([]dto.SaleCampaignResponse, error)
+	Create(ctx context.Context, req *dto.CreateSaleCampaignRequest) error
+	Update(ctx context.Context, req *dto.UpdateSaleCampaignRequest) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	PriceIndex(db *gorm.DB, productIDs []uuid.UUID) (*SalePriceIndex, error)
+	RecordSold(db *gorm.DB, campaignID uuid.UUID, quantity int) error
+	ReleaseSold(db *gorm.DB, orderID uuid.UUID) error
+	InvalidateOnBoundary(ctx context.Context, from time.Time, to time.Time) error
+}
+
+type saleCampaignService struct {
+	DB               *gorm.DB
+	saleCampaignRepo repository.SaleCampaignRepository
+	cacheable        cache.Cacheable
+}
+
+func NewSaleCampaignService(db *gorm.DB, saleCampaignRepo repository.SaleCampaignRepository, cacheable cache.Cacheable) SaleCampaignService {
+	return &saleCampaignService{
+		DB:               db,
+		saleCampaignRepo: saleCampaignRepo,
+		cacheable:        cacheable,
+	}
+}
+
+// ActiveSale adalah harga promo yang berlaku untuk satu produk atau varian.
+type ActiveSale struct {
+	CampaignID uuid.UUID
+	Price      float64
+	EndsAt     time.Time
+}
+
+// SalePriceIndex menyimpan item promo aktif per produk. Index nil aman
+// dipakai dan selalu mengembalikan harga dasar.
+type SalePriceIndex struct {
+	items map[uuid.UUID][]entity.SaleCampaignItem
+}
+
+// Lookup mencari harga promo termurah untuk produk. Tanpa variantID hanya
+// item promo tingkat produk yang dipertimbangkan.
+func (idx *SalePriceIndex) Lookup(productID uuid.UUID, variantID *uuid.UUID, basePrice float64) *ActiveSale {
+	if idx == nil {
+		return nil
+	}
+	var best *ActiveSale
+	for _, item := range idx.items[productID] {
+		if item.ProductVariantID != nil && (variantID == nil || *item.ProductVariantID != *variantID) {
+			continue
+		}
+		price := salePriceOf(item, basePrice)
+		if price >= basePrice {
+			continue
+		}
+		if best == nil || price < best.Price {
+			best = &ActiveSale{CampaignID: item.SaleCampaignID, Price: price, EndsAt: item.SaleCampaign.EndsAt}
+		}
+	}
+	return best
+}
+
+// Price mengembalikan harga efektif, yaitu harga promo jika ada.
+func (idx *SalePriceIndex) Price(productID uuid.UUID, variantID *uuid.UUID, basePrice float64) float64 {
+	if sale := idx.Lookup(productID, variantID, basePrice); sale != nil {
+		return sale.Price
+	}
+	return basePrice
+}
+
+func saleFields(sale *ActiveSale) (*float64, *time.Time) {
+	if sale == nil {
+		return nil, nil
+	}
+	return &sale.Price, &sale.EndsAt
+}
+
+func productIDsOf(products []*entity.Product) []uuid.UUID {
+	ids := []uuid.UUID{}
+	for _, product := range products {
+		ids = append(ids, product.ID)
+	}
+	return ids
+}
+
+func salePriceOf(item entity.SaleCampaignItem, basePrice float64) float64 {
+	if item.SalePrice != nil {
+		return *item.SalePrice
+	}
+	if item.DiscountPercent != nil {
+		return math.Floor(basePrice * (100 - *item.DiscountPercent) / 100)
+	}
+	return basePrice
+}
+
+func (s *saleCampaignService) PriceIndex(db *gorm.DB, productIDs []uuid.UUID) (*SalePriceIndex, error) {
+	items, err := s.saleCampaignRepo.GetActiveItems(db, productIDs, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	idx := &SalePriceIndex{items: map[uuid.UUID][]entity.SaleCampaignItem{}}
+	for _, item := range items {
+		idx.items[item.ProductID] = append(idx.items[item.ProductID], item)
+	}
+	return idx, nil
+}
+
+func (s *saleCampaignService) RecordSold(db *gorm.DB, campaignID uuid.UUID, quantity int) error {
+	updated, err := s.saleCampaignRepo.IncrementSold(db, campaignID, quantity)
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return ErrSaleStockExhausted
+	}
+	// Kuota bisa saja baru habis, harga katalog harus kembali normal
+	s.invalidateCaches()
+	return nil
+}
+
+func (s *saleCampaignService) ReleaseSold(db *gorm.DB, orderID uuid.UUID) error {
+	if err := s.saleCampaignRepo.ReleaseSoldByOrderID(db, orderID); err != nil {
+		return err
+	}
+	s.invalidateCaches()
+	return nil
+}
+
+// InvalidateOnBoundary menghapus cache katalog dan keranjang jika ada
+// kampanye yang mulai atau berakhir dalam rentang waktu tersebut.
+func (s *saleCampaignService) InvalidateOnBoundary(ctx context.Context, from time.Time, to time.Time) error {
+	count, err := s.saleCampaignRepo.CountBoundariesBetween(s.DB.WithContext(ctx), from, to)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		log.Printf("INFO: %d sale campaign(s) started or ended, invalidating product caches", count)
+		s.invalidateCaches()
+	}
+	return nil
+}
+
+func (s *saleCampaignService) invalidateCaches() {
+	for _, key := range cache.ListCacheKeysProductToInvalidate {
+		if err := s.cacheable.DeleteByPrefix(key); err != nil {
+			log.Printf("WARNING: Failed to invalidate cache key %s: %v", key, err)
+		}
+	}
+	// Total keranjang juga ikut berubah
+	if err := s.cacheable.DeleteByPrefix("carts:"); err != nil {
+		log.Printf("WARNING: Failed to invalidate cart caches: %v", err)
+	}
+}
+
+func (s *saleCampaignService) GetAll(ctx context.Context) ([]dto.SaleCampaignResponse, error) {
+	campaigns, err := s.saleCampaignRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	results := []dto.SaleCampaignResponse{}
+	for _, campaign := range campaigns {
+		result := dto.SaleCampaignResponse{
+			ID:        campaign.ID,
+			Name:      campaign.Name,
+			StartsAt:  campaign.StartsAt,
+			EndsAt:    campaign.EndsAt,
+			StockCap:  campaign.StockCap,
+			SoldCount: campaign.SoldCount,
+			IsActive:  campaign.IsActive,
+			Status:    saleCampaignStatus(&campaign, now),
+			Items:     []dto.SaleCampaignItemResponse{},
+		}
+		for _, item := range campaign.Items {
+			itemResult := dto.SaleCampaignItemResponse{
+				ID:               item.ID,
+				ProductID:        item.ProductID,
+				ProductVariantID: item.ProductVariantID,
+				SalePrice:        item.SalePrice,
+				DiscountPercent:  item.DiscountPercent,
+			}
+			if item.Product != nil {
+				itemResult.ProductName = item.Product.Name
+			}
+			result.Items = append(result.Items, itemResult)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func saleCampaignStatus(campaign *entity.SaleCampaign, now time.Time) string {
+	switch {
+	case !campaign.IsActive:
+		return "inactive"
+	case now.Before(campaign.StartsAt):
+		return "scheduled"
+	case !now.Before(campaign.EndsAt):
+		return "ended"
+	case campaign.StockCap != nil && campaign.SoldCount >= *campaign.StockCap:
+		return "sold_out"
+	}
+	return "running"
+}
+
+func validateSaleCampaignRequest(req *dto.CreateSaleCampaignRequest) error {
+	if req.Name == "" {
+		return errors.New("campaign name is required")
+	}
+	if req.StartsAt.IsZero() || req.EndsAt.IsZero() {
+		return errors.New("start and end time are required")
+	}
+	if !req.EndsAt.After(req.StartsAt) {
+		return errors.New("end time must be after start time")
+	}
+	if req.StockCap != nil && *req.StockCap < 1 {
+		return errors.New("stock cap must be at least 1")
+	}
+	if len(req.Items) == 0 {
+		return errors.New("campaign must have at least one item")
+	}
+	for i, item := range req.Items {
+		if item.ProductID == uuid.Nil {
+			return fmt.Errorf("item %d: product is required", i+1)
+		}
+		if (item.SalePrice == nil) == (item.DiscountPercent == nil) {
+			return fmt.Errorf("item %d: set either sale price or discount percent", i+1)
+		}
+		if item.SalePrice != nil && *item.SalePrice <= 0 {
+			return fmt.Errorf("item %d: sale price must be greater than 0", i+1)
+		}
+		if item.DiscountPercent != nil && (*item.DiscountPercent <= 0 || *item.DiscountPercent >= 100) {
+			return fmt.Errorf("item %d: discount percent must be between 0 and 100", i+1)
+		}
+	}
+	return nil
+}
+
+func saleCampaignFromRequest(req *dto.CreateSaleCampaignRequest) *entity.SaleCampaign {
+	campaign := &entity.SaleCampaign{
+		Name:     req.Name,
+		StartsAt: req.StartsAt,
+		EndsAt:   req.EndsAt,
+		StockCap: req.StockCap,
+		IsActive: true,
+	}
+	if req.IsActive != nil {
+		campaign.IsActive = *req.IsActive
+	}
+	return campaign
+}
+
+func saleCampaignItems(campaignID uuid.UUID, req *dto.CreateSaleCampaignRequest) []entity.SaleCampaignItem {
+	items := []entity.SaleCampaignItem{}
+	for _, item := range req.Items {
+		items = append(items, entity.SaleCampaignItem{
+			SaleCampaignID:   campaignID,
+			ProductID:        item.ProductID,
+			ProductVariantID: item.ProductVariantID,
+			SalePrice:        item.SalePrice,
+			DiscountPercent:  item.DiscountPercent,
+		})
+	}
+	return items
+}
+
+func (s *saleCampaignService) Create(ctx context.Context, req *dto.CreateSaleCampaignRequest) error {
+	if err := validateSaleCampaignRequest(req); err != nil {
+		return err
+	}
+
+	tx := s.DB.WithContext(ctx).Begin()
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			log.Printf("PANIC RECOVERED: Rolling back transaction due to panic: %v", p)
+			panic(p)
+		} else if tx.Error != nil {
+			tx.Rollback()
+			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
+		}
+	}()
+
+	campaign := saleCampaignFromRequest(req)
+	if err := s.saleCampaignRepo.Create(tx, campaign); err != nil {
+		tx.Error = err
+		return err
+	}
+	if err := s.saleCampaignRepo.ReplaceItems(tx, campaign.ID, saleCampaignItems(campaign.ID, req)); err != nil {
+		tx.Error = err
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Error = err
+		return err
+	}
+	s.invalidateCaches()
+	return nil
+}
+
+func (s *saleCampaignService) Update(ctx context.Context, req *dto.UpdateSaleCampaignRequest) error {
+	if err := validateSaleCampaignRequest(&req.CreateSaleCampaignRequest); err != nil {
+		return err
+	}
+
+	tx := s.DB.WithContext(ctx).Begin()
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			log.Printf("PANIC RECOVERED: Rolling back transaction due to panic: %v", p)
+			panic(p)
+		} else if tx.Error != nil {
+			tx.Rollback()
+			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
+		}
+	}()
+
+	if _, err := s.saleCampaignRepo.GetByID(tx, req.ID); errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Error = ErrSaleCampaignNotFound
+		return tx.Error
+	} else if err != nil {
+		tx.Error = err
+		return err
+	}
+
+	campaign := saleCampaignFromRequest(&req.CreateSaleCampaignRequest)
+	campaign.ID = req.ID
+	if err := s.saleCampaignRepo.Update(tx, campaign); err != nil {
+		tx.Error = err
+		return err
+	}
+	if err := s.saleCampaignRepo.ReplaceItems(tx, campaign.ID, saleCampaignItems(campaign.ID, &req.CreateSaleCampaignRequest)); err != nil {
+		tx.Error = err
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Error = err
+		return err
+	}
+	s.invalidateCaches()
+	return nil
+}
+
+func (s *saleCampaignService) Delete(ctx context.Context, id uuid.UUID) error {
+	db := s.DB.WithContext(ctx)
+	if _, err := s.saleCampaignRepo.GetByID(db, id); errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrSaleCampaignNotFound
+	} else if err != nil {
+		return err
+	}
+	if err := s.saleCampaignRepo.Delete(db, id); err != nil {
+		return err
+	}
+	s.invalidateCaches()
+	return nil
+}
@@ -24,14 +24,16 @@ type SizeService interface {
 type sizeService struct {
 	DB           *gorm.DB
 	repo         repository.SizeRepository
+	optionRepo   repository.OptionRepository
 	tokenUseCase token.TokenUseCase
 	cacheable    cache.Cacheable
 }
 
-func NewSizeService(db *gorm.DB, repo repository.SizeRepository, tokenUseCase token.TokenUseCase, cacheable cache.Cacheable) SizeService {
+func NewSizeService(db *gorm.DB, repo repository.SizeRepository, optionRepo repository.OptionRepository, tokenUseCase token.TokenUseCase, cacheable cache.Cacheable) SizeService {
 	return &sizeService{
 		DB:           db,
 		repo:         repo,
+		optionRepo:   optionRepo,
 		tokenUseCase: tokenUseCase,
 		cacheable:    cacheable,
 	}
@@ -89,12 +91,17 @@ func (s *sizeService) Create(ctx context.Context, request *dto.CreateSizeRequest
 		tx.Error = err
 		return err
 	}
+	if err := s.optionRepo.UpsertLegacyValue(tx, entity.OptionTypeSize, size.ID, size.Name); err != nil {
+		tx.Error = err
+		return err
+	}
 	if err := tx.Commit().Error; err != nil {
 		tx.Error = err
 		return err
 	}
 	key := "sizes:Get-all"
 	_ = s.cacheable.Delete(key)
+	_ = s.cacheable.Delete(optionsCacheKey)
 	return nil
 }
 
@@ -119,12 +126,17 @@ func (s *sizeService) Update(ctx context.Context, request *dto.UpdateSizeRequest
 		tx.Error = err
 		return err
 	}
+	if err := s.optionRepo.UpsertLegacyValue(tx, entity.OptionTypeSize, size.ID, size.Name); err != nil {
+		tx.Error = err
+		return err
+	}
 	if err := tx.Commit().Error; err != nil {
 		tx.Error = err
 		return err
 	}
 	key := "sizes:Get-all"
 	_ = s.cacheable.Delete(key)
+	_ = s.cacheable.Delete(optionsCacheKey)
 	
 	return nil
 }
@@ -149,12 +161,17 @@ func (s *sizeService) Delete(ctx context.Context, id uint) error {
 		tx.Error = err
 		return err
 	}
+	if err := deleteLegacyOptionValue(tx, s.optionRepo, entity.OptionTypeSize, size.ID); err != nil {
+		tx.Error = err
+		return err
+	}
 	if err := tx.Commit().Error; err != nil {
 		tx.Error = err
 		return err
 	}
 	key := "sizes:Get-all"
 	_ = s.cacheable.Delete(key)
+	_ = s.cacheable.Delete(optionsCacheKey)
 	
 	return nil
 }
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mola-web/internal/entity"
+	"mola-web/internal/http/dto"
+	"mola-web/internal/repository"
+	"mola-web/pkg/slug"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Batas percobaan akhiran angka sebelum slug dianggap bentrok
+const maxSlugAttempts = 100
+
+var (
+	ErrInvalidSlug  = errors.New("slug may only contain lowercase letters, numbers and single dashes")
+	ErrSlugTaken    = errors.New("slug is already used")
+	ErrSlugNotFound = errors.New("slug not found")
+)
+
+// slugChange permintaan slug untuk entitas yang dibuat atau diubah.
+type slugChange struct {
+	EntityType string
+	EntityID   string  // kosong untuk entitas baru
+	Current    *string // slug saat ini, nil untuk entitas baru
+	Name       string
+	Requested  string // slug pilihan admin, kosong berarti dibuat dari Name
+	Keep       bool   // pertahankan slug lama walaupun nama berubah
+}
+
+// assignSlug menentukan slug entitas dan mencatat redirect dari slug lama.
+// Slug dibuat ulang dari nama kecuali admin memilih slug sendiri atau
+// meminta slug lama dipertahankan.
+func assignSlug(tx *gorm.DB, repo repository.SlugRepository, change slugChange) (string, error) {
+	next, err := nextSlug(tx, repo, change)
+	if err != nil {
+		return "", err
+	}
+
+	if change.Current != nil && *change.Current != next {
+		if err := repo.CreateRedirect(tx, &entity.SlugRedirect{
+			EntityType: change.EntityType,
+			OldSlug:    *change.Current,
+			EntityID:   change.EntityID,
+		}); err != nil {
+			return "", err
+		}
+	}
+	// Slug yang dipakai lagi tidak boleh tetap menjadi redirect
+	if err := repo.DeleteRedirect(tx, change.EntityType, next); err != nil {
+		return "", err
+	}
+	return next, nil
+}
+
+func nextSlug(tx *gorm.DB, repo repository.SlugRepository, change slugChange) (string, error) {
+	if change.Requested != "" {
+		if !slug.Valid(change.Requested) {
+			return "", ErrInvalidSlug
+		}
+		taken, err := repo.Exists(tx, change.EntityType, change.Requested, change.EntityID)
+		if err != nil {
+			return "", err
+		}
+		if taken {
+			return "", ErrSlugTaken
+		}
+		return change.Requested, nil
+	}
+
+	base := slug.Make(change.Name)
+	if base == "" {
+		base = change.EntityType
+	}
+	if change.Current != nil && *change.Current != "" {
+		if change.Keep || slugMatchesBase(*change.Current, base) {
+			return *change.Current, nil
+		}
+	}
+
+	for i := 1; i <= maxSlugAttempts; i++ {
+		candidate := base
+		if i > 1 {
+			suffix := "-" + strconv.Itoa(i)
+			candidate = slug.Truncate(base, slug.MaxLength-len(suffix)) + suffix
+		}
+		taken, err := repo.Exists(tx, change.EntityType, candidate, change.EntityID)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("could not generate a unique slug for %q", change.Name)
+}
+
+// slugMatchesBase true jika slug dibuat dari nama yang sama, misal
+// "kaos-polos-2" untuk "kaos-polos", sehingga slug tidak berubah saat nama
+// tidak berubah.
+func slugMatchesBase(current string, base string) bool {
+	if current == base {
+		return true
+	}
+	idx := strings.LastIndex(current, "-")
+	if idx < 0 {
+		return false
+	}
+	if _, err := strconv.Atoi(current[idx+1:]); err != nil {
+		return false
+	}
+	return current[:idx] == slug.Truncate(base, slug.MaxLength-(len(current)-idx))
+}
+
+// GetBySlug mencari produk dari slug aktif atau slug lama. Slug di hasil
+// selalu slug aktif sehingga pemanggil bisa mengarahkan URL lama.
+func (s *productService) GetBySlug(ctx context.Context, productSlug string) (*dto.GetProductByID, error) {
+	id, _, err := s.slugRepo.Resolve(ctx, entity.SlugEntityProduct, productSlug)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSlugNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	productID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetByID(ctx, productID)
+}
+
+// GetProductByCategorySlug mengembalikan slug aktif kategori beserta
+// produknya, termasuk produk subkategori jika includeDescendants.
+func (s *productService) GetProductByCategorySlug(ctx context.Context, categorySlug string, includeDescendants bool) (string, []*dto.GetProductByCategoryID, error) {
+	id, current, err := s.slugRepo.Resolve(ctx, entity.SlugEntityCategory, categorySlug)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil, ErrSlugNotFound
+	} else if err != nil {
+		return "", nil, err
+	}
+	categoryID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return "", nil, err
+	}
+	products, err := s.GetProductByCategoryID(ctx, uint(categoryID), includeDescendants)
+	if err != nil {
+		return "", nil, err
+	}
+	return current, products, nil
+}
@@ -0,0 +1,526 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"mola-web/configs"
+	"mola-web/internal/entity"
+	"mola-web/internal/http/dto"
+	"mola-web/internal/repository"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/midtrans/midtrans-go"
+	"github.com/midtrans/midtrans-go/snap"
+	"gorm.io/gorm"
+)
+
+const (
+	StoreCreditTypeGiftCard = "gift_card"
+	StoreCreditTypeRefund   = "refund"
+	StoreCreditTypeCheckout = "checkout"
+	StoreCreditTypeRestore  = "restore" // kredit yang dipakai dikembalikan karena order batal
+)
+
+const (
+	GiftCardStatusPending   = "pending" // menunggu pembayaran Midtrans
+	GiftCardStatusActive    = "active"
+	GiftCardStatusRedeemed  = "redeemed"
+	GiftCardStatusDisabled  = "disabled"
+	GiftCardStatusCancelled = "cancelled" // pembayaran gagal atau kedaluwarsa
+)
+
+const (
+	storeCreditHistoryLimit = 20
+	minGiftCardAmount       = 10000
+	maxGiftCardAmount       = 10000000
+	maxGiftCardBatch        = 100
+)
+
+var (
+	ErrInsufficientCredit = errors.New("insufficient store credit")
+	ErrGiftCardNotFound   = errors.New("gift card not found")
+)
+
+type StoreCreditService interface {
+	GetSummary(ctx context.Context, userID uuid.UUID) (*dto.StoreCreditSummary, error)
+	GetBalance(db *gorm.DB, userID uuid.UUID) (float64, error)
+	Debit(db *gorm.DB, userID uuid.UUID, orderID uuid.UUID, amount float64) error
+	RestoreOrder(db *gorm.DB, orderID uuid.UUID) error
+	RefundOrder(db *gorm.DB, order *entity.Order, paidAmount float64, amount float64, reason string) (float64, error)
+
+	RedeemGiftCard(ctx context.Context, userID uuid.UUID, req *dto.RedeemGiftCardRequest) (*dto.StoreCreditSummary, error)
+	IssueGiftCards(ctx context.Context, req *dto.IssueGiftCardRequest) ([]dto.GiftCardResponse, error)
+	GetAllGiftCards(ctx context.Context) ([]dto.GiftCardResponse, error)
+	GetPurchasedGiftCards(ctx context.Context, userID uuid.UUID) ([]dto.GiftCardResponse, error)
+	DisableGiftCard(ctx context.Context, id uuid.UUID) error
+	PurchaseGiftCard(ctx context.Context, userID uuid.UUID, email string, name string, req *dto.PurchaseGiftCardRequest) (*dto.GiftCardPurchaseResponse, error)
+	IsGiftCardPayment(db *gorm.DB, id uuid.UUID) bool
+	HandleGiftCardPayment(db *gorm.DB, id uuid.UUID, transactionStatus string, fraudStatus string) error
+}
+
+type storeCreditService struct {
+	DB              *gorm.DB
+	storeCreditRepo repository.StoreCreditRepository
+	config          configs.MidtransConfig
+}
+
+func NewStoreCreditService(db *gorm.DB, storeCreditRepo repository.StoreCreditRepository, config configs.MidtransConfig) StoreCreditService {
+	return &storeCreditService{
+		DB:              db,
+		storeCreditRepo: storeCreditRepo,
+		config:          config,
+	}
+}
+
+func newGiftCardCode() string {
+	raw := strings.ToUpper(strings.ReplaceAll(uuid.NewString(), "-", ""))
+	return "GC-" + raw[:4] + "-" + raw[4:8] + "-" + raw[8:12]
+}
+
+func giftCardResponse(card *entity.GiftCard) dto.GiftCardResponse {
+	result := dto.GiftCardResponse{
+		ID:         card.ID,
+		Code:       card.Code,
+		Amount:     card.Amount,
+		Status:     card.Status,
+		RedeemedAt: card.RedeemedAt,
+		ExpiresAt:  card.ExpiresAt,
+		Note:       card.Note,
+		CreatedAt:  card.CreatedAt,
+	}
+	if card.PurchasedBy != nil {
+		result.PurchasedBy = &card.PurchasedBy.Name
+	}
+	if card.RedeemedBy != nil {
+		result.RedeemedBy = &card.RedeemedBy.Name
+	}
+	return result
+}
+
+// post mencatat satu baris buku besar dan memperbarui saldo dompet dalam
+// transaksi yang sama. Saldo tidak boleh menjadi negatif.
+func (s *storeCreditService) post(db *gorm.DB, entry *entity.StoreCreditTransaction) error {
+	wallet, err := s.storeCreditRepo.GetWalletForUpdate(db, entry.UserID)
+	if err != nil {
+		return err
+	}
+	balance := wallet.Balance + entry.Amount
+	if balance < 0 {
+		return ErrInsufficientCredit
+	}
+	if err := s.storeCreditRepo.UpdateBalance(db, entry.UserID, balance); err != nil {
+		return err
+	}
+	entry.BalanceAfter = balance
+	return s.storeCreditRepo.CreateTransaction(db, entry)
+}
+
+func (s *storeCreditService) GetSummary(ctx context.Context, userID uuid.UUID) (*dto.StoreCreditSummary, error) {
+	db := s.DB.WithContext(ctx)
+	balance, err := s.storeCreditRepo.GetBalance(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.storeCreditRepo.GetHistory(db, userID, storeCreditHistoryLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.StoreCreditSummary{
+		Balance: balance,
+		History: []dto.StoreCreditTransactionResponse{},
+	}
+	for _, entry := range entries {
+		result.History = append(result.History, dto.StoreCreditTransactionResponse{
+			ID:           entry.ID,
+			OrderID:      entry.OrderID,
+			GiftCardID:   entry.GiftCardID,
+			Type:         entry.Type,
+			Amount:       entry.Amount,
+			BalanceAfter: entry.BalanceAfter,
+			Description:  entry.Description,
+			CreatedAt:    entry.CreatedAt,
+		})
+	}
+	return result, nil
+}
+
+func (s *storeCreditService) GetBalance(db *gorm.DB, userID uuid.UUID) (float64, error) {
+	return s.storeCreditRepo.GetBalance(db, userID)
+}
+
+func (s *storeCreditService) Debit(db *gorm.DB, userID uuid.UUID, orderID uuid.UUID, amount float64) error {
+	if amount <= 0 {
+		return nil
+	}
+	return s.post(db, &entity.StoreCreditTransaction{
+		UserID:      userID,
+		Type:        StoreCreditTypeCheckout,
+		Amount:      -amount,
+		OrderID:     &orderID,
+		Description: "Paid at checkout",
+	})
+}
+
+// RestoreOrder mengembalikan kredit yang dipakai membayar order yang batal
+// atau kedaluwarsa. Aman dipanggil berulang.
+func (s *storeCreditService) RestoreOrder(db *gorm.DB, orderID uuid.UUID) error {
+	entries, err := s.storeCreditRepo.GetByOrderID(db, orderID)
+	if err != nil {
+		return err
+	}
+	var spent *entity.StoreCreditTransaction
+	for i := range entries {
+		switch entries[i].Type {
+		case StoreCreditTypeCheckout:
+			spent = &entries[i]
+		case StoreCreditTypeRestore:
+			return nil
+		}
+	}
+	if spent == nil {
+		return nil
+	}
+	return s.post(db, &entity.StoreCreditTransaction{
+		UserID:      spent.UserID,
+		Type:        StoreCreditTypeRestore,
+		Amount:      -spent.Amount,
+		OrderID:     &orderID,
+		Description: "Returned because the order was cancelled",
+	})
+}
+
+// RefundOrder membayar refund sebagai kredit toko. Total refund untuk satu
+// order tidak boleh melebihi yang benar-benar dibayar pelanggan, yaitu
+// nominal yang diterima lewat Midtrans ditambah kredit toko yang dipakai.
+// Mengembalikan sisa yang masih bisa direfund setelah refund ini.
+func (s *storeCreditService) RefundOrder(db *gorm.DB, order *entity.Order, paidAmount float64, amount float64, reason string) (float64, error) {
+	if amount <= 0 {
+		return 0, errors.New("refund amount must be greater than 0")
+	}
+	entries, err := s.storeCreditRepo.GetByOrderID(db, order.ID)
+	if err != nil {
+		return 0, err
+	}
+	collected := paidAmount
+	var refunded float64
+	for _, entry := range entries {
+		switch entry.Type {
+		case StoreCreditTypeRefund:
+			refunded += entry.Amount
+		case StoreCreditTypeCheckout, StoreCreditTypeRestore:
+			// checkout bernilai negatif, restore membatalkannya
+			collected -= entry.Amount
+		}
+	}
+	remaining := collected - refunded
+	if amount > remaining {
+		return 0, fmt.Errorf("refund exceeds the amount paid, at most %.0f can still be refunded", math.Max(remaining, 0))
+	}
+
+	description := "Refund for order " + order.OrderCode
+	if reason != "" {
+		description += ": " + reason
+	}
+	if err := s.post(db, &entity.StoreCreditTransaction{
+		UserID:      order.UserID,
+		Type:        StoreCreditTypeRefund,
+		Amount:      amount,
+		OrderID:     &order.ID,
+		Description: description,
+	}); err != nil {
+		return 0, err
+	}
+	return remaining - amount, nil
+}
+
+func (s *storeCreditService) RedeemGiftCard(ctx context.Context, userID uuid.UUID, req *dto.RedeemGiftCardRequest) (*dto.StoreCreditSummary, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			log.Printf("PANIC RECOVERED: Rolling back transaction due to panic: %v", p)
+			panic(p)
+		} else if tx.Error != nil {
+			tx.Rollback()
+			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
+		}
+	}()
+
+	card, err := s.storeCreditRepo.GetGiftCardByCodeForUpdate(tx, strings.TrimSpace(req.Code))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Error = ErrGiftCardNotFound
+		return nil, tx.Error
+	} else if err != nil {
+		tx.Error = err
+		return nil, err
+	}
+	switch card.Status {
+	case GiftCardStatusActive:
+	case GiftCardStatusRedeemed:
+		tx.Error = errors.New("gift card has already been redeemed")
+		return nil, tx.Error
+	default:
+		tx.Error = errors.New("gift card is not active")
+		return nil, tx.Error
+	}
+	now := time.Now()
+	if card.ExpiresAt != nil && now.After(*card.ExpiresAt) {
+		tx.Error = errors.New("gift card has expired")
+		return nil, tx.Error
+	}
+
+	card.Status = GiftCardStatusRedeemed
+	card.RedeemedByID = &userID
+	card.RedeemedAt = &now
+	if err := s.storeCreditRepo.UpdateGiftCard(tx, card); err != nil {
+		tx.Error = err
+		return nil, err
+	}
+	if err := s.post(tx, &entity.StoreCreditTransaction{
+		UserID:      userID,
+		Type:        StoreCreditTypeGiftCard,
+		Amount:      card.Amount,
+		GiftCardID:  &card.ID,
+		Description: "Redeemed gift card " + card.Code,
+	}); err != nil {
+		tx.Error = err
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		tx.Error = err
+		return nil, err
+	}
+	return s.GetSummary(ctx, userID)
+}
+
+func validateGiftCardAmount(amount float64) error {
+	if amount < minGiftCardAmount || amount > maxGiftCardAmount {
+		return fmt.Errorf("gift card amount must be between %d and %d", minGiftCardAmount, maxGiftCardAmount)
+	}
+	if amount != math.Trunc(amount) {
+		return errors.New("gift card amount must be a whole number")
+	}
+	return nil
+}
+
+func (s *storeCreditService) IssueGiftCards(ctx context.Context, req *dto.IssueGiftCardRequest) ([]dto.GiftCardResponse, error) {
+	if err := validateGiftCardAmount(req.Amount); err != nil {
+		return nil, err
+	}
+	quantity := req.Quantity
+	if quantity <= 0 {
+		quantity = 1
+	}
+	if quantity > maxGiftCardBatch {
+		return nil, fmt.Errorf("at most %d gift cards can be issued at once", maxGiftCardBatch)
+	}
+	if req.ExpiresAt != nil && req.ExpiresAt.Before(time.Now()) {
+		return nil, errors.New("expiry date must be in the future")
+	}
+
+	tx := s.DB.WithContext(ctx).Begin()
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			log.Printf("PANIC RECOVERED: Rolling back transaction due to panic: %v", p)
+			panic(p)
+		} else if tx.Error != nil {
+			tx.Rollback()
+			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
+		}
+	}()
+
+	results := []dto.GiftCardResponse{}
+	for i := 0; i < quantity; i++ {
+		card := &entity.GiftCard{
+			Code:       newGiftCardCode(),
+			Amount:     req.Amount,
+			Status:     GiftCardStatusActive,
+			IssuedByID: &req.IssuedByID,
+			ExpiresAt:  req.ExpiresAt,
+			Note:       req.Note,
+		}
+		if err := s.storeCreditRepo.CreateGiftCard(tx, card); err != nil {
+			tx.Error = err
+			return nil, err
+		}
+		results = append(results, giftCardResponse(card))
+	}
+	if err := tx.Commit().Error; err != nil {
+		tx.Error = err
+		return nil, err
+	}
+	return results, nil
+}
+
+func (s *storeCreditService) GetAllGiftCards(ctx context.Context) ([]dto.GiftCardResponse, error) {
+	cards, err := s.storeCreditRepo.GetAllGiftCards(ctx)
+	if err != nil {
+		return nil, err
+	}
+	results := []dto.GiftCardResponse{}
+	for i := range cards {
+		results = append(results, giftCardResponse(&cards[i]))
+	}
+	return results, nil
+}
+
+func (s *storeCreditService) GetPurchasedGiftCards(ctx context.Context, userID uuid.UUID) ([]dto.GiftCardResponse, error) {
+	cards, err := s.storeCreditRepo.GetGiftCardsByPurchaser(s.DB.WithContext(ctx), userID)
+	if err != nil {
+		return nil, err
+	}
+	results := []dto.GiftCardResponse{}
+	for i := range cards {
+		results = append(results, giftCardResponse(&cards[i]))
+	}
+	return results, nil
+}
+
+func (s *storeCreditService) DisableGiftCard(ctx context.Context, id uuid.UUID) error {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			log.Printf("PANIC RECOVERED: Rolling back transaction due to panic: %v", p)
+			panic(p)
+		} else if tx.Error != nil {
+			tx.Rollback()
+			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
+		}
+	}()
+
+	card, err := s.storeCreditRepo.GetGiftCardForUpdate(tx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Error = ErrGiftCardNotFound
+		return tx.Error
+	} else if err != nil {
+		tx.Error = err
+		return err
+	}
+	if card.Status != GiftCardStatusActive {
+		tx.Error = errors.New("only active gift cards can be disabled")
+		return tx.Error
+	}
+	card.Status = GiftCardStatusDisabled
+	if err := s.storeCreditRepo.UpdateGiftCard(tx, card); err != nil {
+		tx.Error = err
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// PurchaseGiftCard membuat gift card berstatus pending dan transaksi Snap
+// dengan ID kartu sebagai order_id. Kartu aktif setelah notifikasi
+// pembayaran diterima.
+func (s *storeCreditService) PurchaseGiftCard(ctx context.Context, userID uuid.UUID, email string, name string, req *dto.PurchaseGiftCardRequest) (*dto.GiftCardPurchaseResponse, error) {
+	if err := validateGiftCardAmount(req.Amount); err != nil {
+		return nil, err
+	}
+
+	tx := s.DB.WithContext(ctx).Begin()
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			log.Printf("PANIC RECOVERED: Rolling back transaction due to panic: %v", p)
+			panic(p)
+		} else if tx.Error != nil {
+			tx.Rollback()
+			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
+		}
+	}()
+
+	card := &entity.GiftCard{
+		Code:          newGiftCardCode(),
+		Amount:        req.Amount,
+		Status:        GiftCardStatusPending,
+		PurchasedByID: &userID,
+	}
+	if err := s.storeCreditRepo.CreateGiftCard(tx, card); err != nil {
+		tx.Error = err
+		return nil, err
+	}
+
+	m := snap.Client{}
+	if s.config.IsProduction == "true" {
+		m.New(s.config.ServerKey, midtrans.Production)
+	} else {
+		m.New(s.config.ServerKey, midtrans.Sandbox)
+	}
+	items := []midtrans.ItemDetails{{
+		ID:    "GIFT-CARD",
+		Name:  fmt.Sprintf("Gift card %.0f", card.Amount),
+		Price: int64(card.Amount),
+		Qty:   1,
+	}}
+	snapResp, snapErr := m.CreateTransaction(&snap.Request{
+		TransactionDetails: midtrans.TransactionDetails{
+			OrderID:  card.ID.String(),
+			GrossAmt: int64(card.Amount),
+		},
+		CustomerDetail: &midtrans.CustomerDetails{
+			FName: name,
+			Email: email,
+		},
+		Items:           &items,
+		EnabledPayments: snap.AllSnapPaymentType,
+	})
+	if snapErr != nil {
+		tx.Error = errors.New("failed to create payment: " + snapErr.GetMessage())
+		return nil, tx.Error
+	}
+
+	card.TokenMidtrans = &snapResp.Token
+	card.PaymentUrl = &snapResp.RedirectURL
+	if err := s.storeCreditRepo.UpdateGiftCard(tx, card); err != nil {
+		tx.Error = err
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		tx.Error = err
+		return nil, err
+	}
+	return &dto.GiftCardPurchaseResponse{
+		GiftCardID:  card.ID,
+		Token:       snapResp.Token,
+		RedirectURL: snapResp.RedirectURL,
+	}, nil
+}
+
+func (s *storeCreditService) IsGiftCardPayment(db *gorm.DB, id uuid.UUID) bool {
+	_, err := s.storeCreditRepo.GetGiftCardByID(db, id)
+	return err == nil
+}
+
+// HandleGiftCardPayment mengaktifkan atau membatalkan gift card yang dibeli
+// sesuai status pembayaran dari Midtrans.
+func (s *storeCreditService) HandleGiftCardPayment(db *gorm.DB, id uuid.UUID, transactionStatus string, fraudStatus string) error {
+	card, err := s.storeCreditRepo.GetGiftCardForUpdate(db, id)
+	if err != nil {
+		return err
+	}
+	if card.Status != GiftCardStatusPending {
+		return nil
+	}
+
+	switch transactionStatus {
+	case "capture":
+		if fraudStatus != "accept" {
+			return nil
+		}
+		card.Status = GiftCardStatusActive
+	case "settlement":
+		card.Status = GiftCardStatusActive
+	case "deny", "cancel", "expire":
+		card.Status = GiftCardStatusCancelled
+	default:
+		return nil
+	}
+	return s.storeCreditRepo.UpdateGiftCard(db, card)
+}
@@ -25,6 +25,7 @@ type TransactionService interface {
 	PaymentNotification(ctx context.Context, request *dto.MidtransNotification) error
 	Refund(ctx context.Context, request *dto.RefundRequest) error
 	Cancel(ctx context.Context, request *dto.CancelRequest) error
+	RefundToCredit(ctx context.Context, request *dto.RefundToCreditRequest) error
 	GetAll(ctx context.Context) ([]dto.GetAllPayments, error)
 }
 
@@ -33,19 +34,29 @@ type transactionService struct {
 	transactionRepo repository.TransactionRepository
 	orderRepo       repository.OrderRepository
 	repoVariant     repository.ProductVariantRepository
+	voucherRepo     repository.VoucherRepository
+	saleCampaignService SaleCampaignService
+	loyaltyService  LoyaltyService
+	referralService ReferralService
+	storeCreditService StoreCreditService
 	DB              *gorm.DB
 	cacheable       cache.Cacheable
 	tokenUseCase    token.TokenUseCase
 	config          configs.MidtransConfig
 }
 
-func NewTransactionService(db *gorm.DB, productRepo repository.ProductRepository, transactionRepo repository.TransactionRepository, orderRepo repository.OrderRepository, repoVariant repository.ProductVariantRepository, tokenUseCase token.TokenUseCase, cacheable cache.Cacheable, config configs.MidtransConfig) TransactionService {
+func NewTransactionService(db *gorm.DB, productRepo repository.ProductRepository, transactionRepo repository.TransactionRepository, orderRepo repository.OrderRepository, repoVariant repository.ProductVariantRepository, voucherRepo repository.VoucherRepository, saleCampaignService SaleCampaignService, loyaltyService LoyaltyService, referralService ReferralService, storeCreditService StoreCreditService, tokenUseCase token.TokenUseCase, cacheable cache.Cacheable, config configs.MidtransConfig) TransactionService {
 	return &transactionService{
 		DB:              db,
 		productRepo:     productRepo,
 		transactionRepo: transactionRepo,
 		orderRepo:       orderRepo,
 		repoVariant:     repoVariant,
+		voucherRepo:     voucherRepo,
+		saleCampaignService: saleCampaignService,
+		loyaltyService:  loyaltyService,
+		referralService: referralService,
+		storeCreditService: storeCreditService,
 		tokenUseCase:    tokenUseCase,
 		cacheable:       cacheable,
 		config:          config,
@@ -100,8 +111,24 @@ func CalculateMidtransSignature(
 	return hex.EncodeToString(hashBytes)
 }
 
-func (s *transactionService) PaymentNotification(ctx context.Context, request *dto.MidtransNotification) error {
+func (s *transactionService) checkTransaction(orderID string) (*coreapi.TransactionStatusResponse, error) {
 	var c coreapi.Client
+	if s.config.IsProduction == "true" {
+		c.New(s.config.ServerKey, midtrans.Production)
+	} else {
+		c.New(s.config.ServerKey, midtrans.Sandbox)
+	}
+	transactionStatusResp, e := c.CheckTransaction(orderID)
+	if e != nil {
+		return nil, e
+	}
+	if transactionStatusResp == nil {
+		return nil, errors.New("transaction status response is nil")
+	}
+	return transactionStatusResp, nil
+}
+
+func (s *transactionService) PaymentNotification(ctx context.Context, request *dto.MidtransNotification) error {
 	tx := s.DB.WithContext(ctx).Begin()
 	defer func() {
 		if p := recover(); p != nil {
@@ -127,6 +154,21 @@ func (s *transactionService) PaymentNotification(ctx context.Context, request *d
 	}
 
 	orderID, _ := uuid.Parse(request.OrderID)
+
+	// Pembelian gift card memakai ID kartu sebagai order_id Midtrans
+	if s.storeCreditService.IsGiftCardPayment(tx, orderID) {
+		status, err := s.checkTransaction(request.OrderID)
+		if err != nil {
+			tx.Error = err
+			return err
+		}
+		if err := s.storeCreditService.HandleGiftCardPayment(tx, orderID, status.TransactionStatus, status.FraudStatus); err != nil {
+			tx.Error = err
+			return err
+		}
+		return tx.Commit().Error
+	}
+
 	grossAmount, _ := strconv.ParseFloat(request.GrossAmount, 64)
 	payment := &entity.Payment{
 		OrderID:           orderID,
@@ -151,18 +193,10 @@ func (s *transactionService) PaymentNotification(ctx context.Context, request *d
 		tx.Error = err
 		return errors.New("failed to create payment")
 	}
-	if s.config.IsProduction == "true" {
-		c.New(s.config.ServerKey, midtrans.Production)
-	} else {
-		c.New(s.config.ServerKey, midtrans.Sandbox)
-	}
-	transactionStatusResp, e := c.CheckTransaction(request.OrderID)
-	if e != nil {
-		return e
-	}
-
-	if transactionStatusResp == nil {
-		return errors.New("transaction status response is nil")
+	transactionStatusResp, err := s.checkTransaction(request.OrderID)
+	if err != nil {
+		tx.Error = err
+		return err
 	}
 
 	updateOrder := func(status string, isPaid bool) error {
@@ -185,9 +219,15 @@ func (s *transactionService) PaymentNotification(ctx context.Context, request *d
 			result = updateOrder("challenge", true)
 		case "accept":
 			result = updateOrder("lunas", true)
+			if result == nil {
+				result = s.creditPaidOrder(tx, dataOrder)
+			}
 		}
 	case "settlement":
 		result = updateOrder("lunas", true)
+		if result == nil {
+			result = s.creditPaidOrder(tx, dataOrder)
+		}
 	case "deny":
 		result = updateOrder("lunas", true)
 	case "cancel":
@@ -201,7 +241,13 @@ func (s *transactionService) PaymentNotification(ctx context.Context, request *d
 			return errors.New("order not found")
 		}
 		for _, item := range dataOrder.OrderItems {
-			if item.Product.HasVariant {
+			if len(item.Components) > 0 {
+				// Bundle: kembalikan stok setiap komponen
+				if err := s.restoreComponentStock(tx, item.Components); err != nil {
+					tx.Error = err
+					return err
+				}
+			} else if item.Product.HasVariant {
 				for _, variant := range item.Product.Variants {
 					stock := int64(variant.Stock + item.Quantity)
 					err = s.repoVariant.UpdateStock(tx, variant.ID, int(stock))
@@ -218,6 +264,31 @@ func (s *transactionService) PaymentNotification(ctx context.Context, request *d
 				}
 			}
 		}
+		// Voucher bisa dipakai lagi karena order batal
+		if err := s.voucherRepo.CancelRedemptionsByOrderID(tx, orderID); err != nil {
+			tx.Error = err
+			return err
+		}
+		// Kuota promo dikembalikan
+		if err := s.saleCampaignService.ReleaseSold(tx, orderID); err != nil {
+			tx.Error = err
+			return err
+		}
+		// Poin loyalti dari dan untuk order ini dikembalikan
+		if err := s.loyaltyService.ReverseOrder(tx, orderID); err != nil {
+			tx.Error = err
+			return err
+		}
+		// Voucher hadiah referral yang belum dipakai ditarik kembali
+		if err := s.referralService.ReverseOrder(tx, orderID); err != nil {
+			tx.Error = err
+			return err
+		}
+		// Kredit toko yang dipakai membayar dikembalikan
+		if err := s.storeCreditService.RestoreOrder(tx, orderID); err != nil {
+			tx.Error = err
+			return err
+		}
 		result = updateOrder("cancel", false)
 	case "expire":
 		dataOrder, err := s.orderRepo.GetOrderByID(ctx, orderID)
@@ -230,7 +301,13 @@ func (s *transactionService) PaymentNotification(ctx context.Context, request *d
 			return errors.New("order not found")
 		}
 		for _, item := range dataOrder.OrderItems {
-			if item.Product.HasVariant {
+			if len(item.Components) > 0 {
+				// Bundle: kembalikan stok setiap komponen
+				if err := s.restoreComponentStock(tx, item.Components); err != nil {
+					tx.Error = err
+					return err
+				}
+			} else if item.Product.HasVariant {
 				for _, variant := range item.Product.Variants {
 					stock := int64(variant.Stock + item.Quantity)
 					err = s.repoVariant.UpdateStock(tx, variant.ID, int(stock))
@@ -247,7 +324,43 @@ func (s *transactionService) PaymentNotification(ctx context.Context, request *d
 				}
 			}
 		}
+		// Voucher bisa dipakai lagi karena order batal
+		if err := s.voucherRepo.CancelRedemptionsByOrderID(tx, orderID); err != nil {
+			tx.Error = err
+			return err
+		}
+		// Kuota promo dikembalikan
+		if err := s.saleCampaignService.ReleaseSold(tx, orderID); err != nil {
+			tx.Error = err
+			return err
+		}
+		// Poin loyalti dari dan untuk order ini dikembalikan
+		if err := s.loyaltyService.ReverseOrder(tx, orderID); err != nil {
+			tx.Error = err
+			return err
+		}
+		// Kredit toko yang dipakai membayar dikembalikan
+		if err := s.storeCreditService.RestoreOrder(tx, orderID); err != nil {
+			tx.Error = err
+			return err
+		}
 		result = updateOrder("expired", false)
+	case "refund":
+		if err := s.loyaltyService.ReverseOrder(tx, orderID); err != nil {
+			tx.Error = err
+			return err
+		}
+		// Voucher hadiah referral yang belum dipakai ditarik kembali
+		if err := s.referralService.ReverseOrder(tx, orderID); err != nil {
+			tx.Error = err
+			return err
+		}
+		// Kredit toko yang dipakai membayar dikembalikan
+		if err := s.storeCreditService.RestoreOrder(tx, orderID); err != nil {
+			tx.Error = err
+			return err
+		}
+		result = updateOrder("refund", false)
 	}
 
 	if err := tx.Commit().Error; err != nil {
@@ -260,14 +373,66 @@ func (s *transactionService) PaymentNotification(ctx context.Context, request *d
 
 func (s *transactionService) Refund(ctx context.Context, request *dto.RefundRequest) error {
 	var c coreapi.Client
+	if s.config.IsProduction == "true" {
+		c.New(s.config.ServerKey, midtrans.Production)
+	} else {
+		c.New(s.config.ServerKey, midtrans.Sandbox)
+	}
 	refundRequest := &coreapi.RefundReq{
 		Amount: request.Amount,
 		Reason: request.Reason,
 	}
-	res, _ := c.RefundTransaction(request.TransactionID, refundRequest)
-	if res != nil {
+	res, e := c.RefundTransaction(request.TransactionID, refundRequest)
+	if e != nil || res == nil {
 		return errors.New("failed to refund transaction")
 	}
+
+	tx := s.DB.WithContext(ctx).Begin()
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			log.Printf("PANIC RECOVERED: Rolling back transaction due to panic: %v", p)
+			panic(p)
+		} else if tx.Error != nil {
+			tx.Rollback()
+			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
+		}
+	}()
+
+	payment, err := s.transactionRepo.GetByTransactionID(tx, request.TransactionID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("WARNING: no payment found for refunded transaction %s", request.TransactionID)
+		tx.Rollback()
+		return nil
+	} else if err != nil {
+		tx.Error = err
+		return err
+	}
+
+	// Poin loyalti dan hadiah referral hanya ditarik jika refund menutup
+	// seluruh nominal yang dibayar. Refund sebagian tidak menarik apa pun,
+	// sama seperti RefundToCredit.
+	paidAmount, err := s.transactionRepo.GetSettledAmount(tx, payment.OrderID)
+	if err != nil {
+		tx.Error = err
+		return err
+	}
+	if float64(request.Amount) < paidAmount {
+		tx.Rollback()
+		return nil
+	}
+	if err := s.loyaltyService.ReverseOrder(tx, payment.OrderID); err != nil {
+		tx.Error = err
+		return err
+	}
+	if err := s.referralService.ReverseOrder(tx, payment.OrderID); err != nil {
+		tx.Error = err
+		return err
+	}
+	if err := tx.Commit().Error; err != nil {
+		tx.Error = err
+		return err
+	}
 	return nil
 }
 
@@ -306,7 +471,13 @@ func (s *transactionService) Cancel(ctx context.Context, request *dto.CancelRequ
 		return nil
 	}
 	for _, item := range dataOrder.OrderItems {
-		if item.Product.HasVariant {
+		if len(item.Components) > 0 {
+			// Bundle: kembalikan stok setiap komponen
+			if err := s.restoreComponentStock(tx, item.Components); err != nil {
+				tx.Error = err
+				return err
+			}
+		} else if item.Product.HasVariant {
 			for _, variant := range item.Product.Variants {
 				stock := int64(variant.Stock + item.Quantity)
 				err = s.repoVariant.UpdateStock(tx, variant.ID, int(stock))
@@ -323,6 +494,142 @@ func (s *transactionService) Cancel(ctx context.Context, request *dto.CancelRequ
 			}
 		}
 	}
-	result := updateOrder("cancel", false)
-	return result
+	// Voucher bisa dipakai lagi karena order batal
+	if err := s.voucherRepo.CancelRedemptionsByOrderID(tx, orderID); err != nil {
+		tx.Error = err
+		return err
+	}
+	// Kuota promo dikembalikan
+	if err := s.saleCampaignService.ReleaseSold(tx, orderID); err != nil {
+		tx.Error = err
+		return err
+	}
+	if err := s.loyaltyService.ReverseOrder(tx, orderID); err != nil {
+		tx.Error = err
+		return err
+	}
+	if err := s.referralService.ReverseOrder(tx, orderID); err != nil {
+		tx.Error = err
+		return err
+	}
+	if err := s.storeCreditService.RestoreOrder(tx, orderID); err != nil {
+		tx.Error = err
+		return err
+	}
+	if err := updateOrder("cancel", false); err != nil {
+		return err
+	}
+	if err := tx.Commit().Error; err != nil {
+		tx.Error = err
+		return err
+	}
+	return nil
+}
+
+// RefundToCredit membayar refund order sebagai kredit toko, tidak lewat
+// Midtrans. Order ditandai refund dan poin loyaltinya ditarik setelah
+// seluruh nilai yang dibayar dikembalikan.
+func (s *transactionService) RefundToCredit(ctx context.Context, request *dto.RefundToCreditRequest) error {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			log.Printf("PANIC RECOVERED: Rolling back transaction due to panic: %v", p)
+			panic(p)
+		} else if tx.Error != nil {
+			tx.Rollback()
+			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
+		}
+	}()
+
+	// Baris order dikunci supaya dua refund bersamaan tidak melewati batas
+	dataOrder, err := s.orderRepo.GetOrderForUpdate(tx, request.OrderID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = errors.New("order not found")
+		tx.Error = err
+		return err
+	} else if err != nil {
+		tx.Error = err
+		return errors.New("order not found")
+	}
+	if dataOrder.PaymentStatus == "refund" {
+		tx.Error = errors.New("order has already been refunded")
+		return tx.Error
+	}
+	if !dataOrder.IsPaid {
+		tx.Error = errors.New("only paid orders can be refunded")
+		return tx.Error
+	}
+
+	paidAmount, err := s.transactionRepo.GetSettledAmount(tx, dataOrder.ID)
+	if err != nil {
+		tx.Error = err
+		return err
+	}
+	remaining, err := s.storeCreditService.RefundOrder(tx, dataOrder, paidAmount, request.Amount, request.Reason)
+	if err != nil {
+		tx.Error = err
+		return err
+	}
+	if remaining <= 0 {
+		// Poin loyalti order yang direfund penuh ditarik kembali
+		if err := s.loyaltyService.ReverseOrder(tx, dataOrder.ID); err != nil {
+			tx.Error = err
+			return err
+		}
+		// Hadiah referral dari order ini juga ditarik
+		if err := s.referralService.ReverseOrder(tx, dataOrder.ID); err != nil {
+			tx.Error = err
+			return err
+		}
+		dataOrder.PaymentStatus = "refund"
+		dataOrder.IsPaid = false
+		if err := s.orderRepo.Update(tx, dataOrder); err != nil {
+			tx.Error = errors.New("failed to update order")
+			return tx.Error
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		tx.Error = err
+		return err
+	}
+	_ = s.cacheable.Delete("orders:show-order:" + dataOrder.UserID.String())
+	_ = s.cacheable.Delete("orders:all-orders")
+	return nil
+}
+
+// creditPaidOrder memberi poin dan hadiah referral untuk order yang lunas.
+func (s *transactionService) creditPaidOrder(tx *gorm.DB, order *entity.Order) error {
+	if err := s.loyaltyService.CreditOrder(tx, order); err != nil {
+		tx.Error = err
+		return err
+	}
+	if err := s.referralService.ConvertOnPaid(tx, order); err != nil {
+		tx.Error = err
+		return err
+	}
+	return nil
+}
+
+func (s *transactionService) restoreComponentStock(tx *gorm.DB, components []entity.OrderItemComponent) error {
+	for _, component := range components {
+		if component.ProductVariantID != nil {
+			variant, err := s.repoVariant.GetByID(tx, *component.ProductVariantID)
+			if err != nil {
+				return err
+			}
+			if err := s.repoVariant.UpdateStock(tx, variant.ID, variant.Stock+component.Quantity); err != nil {
+				return err
+			}
+			continue
+		}
+		stock, err := s.productRepo.GetStockProduct(tx, component.ProductID)
+		if err != nil {
+			return err
+		}
+		if err := s.productRepo.UpdateStockProduct(tx, stock+int64(component.Quantity), component.ProductID); err != nil {
+			return err
+		}
+	}
+	return nil
 }
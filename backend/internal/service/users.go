@@ -32,6 +32,7 @@ type UserService interface {
 	GetAll(ctx context.Context) ([]dto.GetAllUserResponse, error)
 	GetUserProfile(ctx context.Context, userID uuid.UUID) (*dto.GetUserProfileResponse, error)
 	UpdateUserProfile(ctx context.Context, UserID uuid.UUID, request *dto.UpdateUserProfileRequest) error
+	UpdateCustomerGroup(ctx context.Context, request *dto.UpdateCustomerGroupRequest) error
 	// GetUserAddress(ctx context.Context, userID uuid.UUID) (*dto.GetUserAddressResponse, error)
 	// UpdateUserAddress(ctx context.Context, userID uuid.UUID, userAddress *dto.UpdateUserAddressRequest) error
 	ForgotPassword(ctx context.Context, request string) error
@@ -45,6 +46,9 @@ type userService struct {
 	cacheable      cache.Cacheable
 	GoogleConfigs  configs.GoogleConfig
 	SMTPConfigs    configs.SMPTGmailConfig
+	guestCart      GuestCartService
+	loyaltyService LoyaltyService
+	referralService ReferralService
 }
 
 func NewUserService(
@@ -54,6 +58,9 @@ func NewUserService(
 	cacheable cache.Cacheable,
 	GoogleConfigs configs.GoogleConfig,
 	SMTPConfigs configs.SMPTGmailConfig,
+	guestCart GuestCartService,
+	loyaltyService LoyaltyService,
+	referralService ReferralService,
 ) UserService {
 	return &userService{
 		DB:             db,
@@ -62,6 +69,9 @@ func NewUserService(
 		cacheable:      cacheable,
 		GoogleConfigs:  GoogleConfigs,
 		SMTPConfigs:    SMTPConfigs,
+		guestCart:      guestCart,
+		loyaltyService: loyaltyService,
+		referralService: referralService,
 	}
 }
 
@@ -88,18 +98,37 @@ func (s *userService) Register(ctx context.Context, req *dto.RegisterRequest) er
 			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
 		}
 	}()
+	var referrer *entity.User
+	if strings.TrimSpace(req.ReferralCode) != "" {
+		referrer, err = s.referralService.ResolveCode(tx, req.ReferralCode)
+		if err != nil {
+			tx.Error = err
+			return err
+		}
+	}
+	referralCode := newReferralCode()
 	user := &entity.User{
 		Email:    req.Email,
 		Name:     req.Name,
 		PhoneNumber: req.PhoneNumber,
 		Password: string(hashedPassword),
 		Role:     "user",
+		ReferralCode: &referralCode,
+	}
+	if referrer != nil {
+		user.ReferredByID = &referrer.ID
 	}
 	err = s.userRepository.Create(tx, user)
 	if err != nil {
 		tx.Error = err
 		return err
 	}
+	if referrer != nil {
+		if err := s.referralService.RecordReferral(tx, referrer, user); err != nil {
+			tx.Error = err
+			return err
+		}
+	}
 	// err = s.userRepository.UpdateUser(tx, &entity.UserProfile{
 	// 	UserID:      &user.ID,
 	// 	PhoneNumber: req.PhoneNumber,
@@ -142,6 +171,7 @@ func (s *userService) Login(ctx context.Context, request *dto.LoginRequest) (str
 	if err != nil {
 		return "", errors.New("ada kesalahan di server")
 	}
+	s.mergeGuestCart(ctx, request.GuestToken, user.ID)
 	return token, nil
 }
 
@@ -157,11 +187,13 @@ func (s *userService) GoogleLogin(ctx context.Context, request *dto.GoogleLoginR
 	}
 	user, err := s.userRepository.FindByEmail(ctx, email)
 	if err != nil {
-		s.userRepository.Create(s.DB, &entity.User{
-			Email: email,
-			Name:  payload.Claims["name"].(string),
-			Role:  "user",
-		})
+		name, _ := payload.Claims["name"].(string)
+		user, err = s.registerGoogleUser(ctx, email, name, request.ReferralCode)
+		if errors.Is(err, ErrInvalidReferralCode) {
+			return "", err
+		} else if err != nil {
+			return "", errors.New("ada kesalahan di server")
+		}
 	}
 
 	expiredTime := time.Now().Local().Add(time.Minute * 60)
@@ -180,9 +212,72 @@ func (s *userService) GoogleLogin(ctx context.Context, request *dto.GoogleLoginR
 	if err != nil {
 		return "", errors.New("ada kesalahan di server")
 	}
+	s.mergeGuestCart(ctx, request.GuestToken, user.ID)
 	return token, nil
 }
 
+// registerGoogleUser membuat akun untuk login Google pertama kali. Kode
+// referral hanya berlaku untuk akun baru.
+func (s *userService) registerGoogleUser(ctx context.Context, email string, name string, code string) (*entity.User, error) {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			log.Printf("PANIC RECOVERED: Rolling back transaction due to panic: %v", p)
+			panic(p)
+		} else if tx.Error != nil {
+			tx.Rollback()
+			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
+		}
+	}()
+
+	var referrer *entity.User
+	if strings.TrimSpace(code) != "" {
+		found, err := s.referralService.ResolveCode(tx, code)
+		if err != nil {
+			tx.Error = err
+			return nil, err
+		}
+		referrer = found
+	}
+	referralCode := newReferralCode()
+	user := &entity.User{
+		Email:        email,
+		Name:         name,
+		Role:         "user",
+		ReferralCode: &referralCode,
+	}
+	if referrer != nil {
+		user.ReferredByID = &referrer.ID
+	}
+	if err := s.userRepository.Create(tx, user); err != nil {
+		tx.Error = err
+		return nil, err
+	}
+	if referrer != nil {
+		if err := s.referralService.RecordReferral(tx, referrer, user); err != nil {
+			tx.Error = err
+			return nil, err
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		tx.Error = err
+		return nil, err
+	}
+	return user, nil
+}
+
+// mergeGuestCart memindahkan keranjang tamu ke keranjang user setelah login.
+// Kegagalan merge tidak membatalkan login.
+func (s *userService) mergeGuestCart(ctx context.Context, guestToken string, userID uuid.UUID) {
+	if guestToken == "" || s.guestCart == nil {
+		return
+	}
+	if err := s.guestCart.MergeIntoUserCart(ctx, guestToken, userID); err != nil {
+		log.Printf("WARNING: failed to merge guest cart for user %s: %v", userID, err)
+	}
+}
+
 func (s *userService) GetAll(ctx context.Context) ([]dto.GetAllUserResponse, error) {
 	key := "users:all"
 
@@ -213,7 +308,7 @@ func (s *userService) GetAll(ctx context.Context) ([]dto.GetAllUserResponse, err
 			Email:     user.Email,
 			Phone:     user.PhoneNumber,
 			Role:      user.Role,
-			
+			CustomerGroup: user.CustomerGroup,
 		}
 	}
 
@@ -234,7 +329,7 @@ func (s *userService) GetUserProfile(ctx context.Context, userID uuid.UUID) (*dt
 	if data != "" {
 		var cached dto.GetUserProfileResponse
 		if err := json.Unmarshal([]byte(data), &cached); err == nil {
-			return &cached, nil
+			return s.withRewards(ctx, userID, &cached)
 		}
 	}
 
@@ -246,12 +341,22 @@ func (s *userService) GetUserProfile(ctx context.Context, userID uuid.UUID) (*dt
 		return nil, err
 	}
 
+	// Akun lama belum punya kode referral
+	if dataUser.ReferralCode == nil {
+		code := newReferralCode()
+		if err := s.userRepository.SetReferralCode(s.DB.WithContext(ctx), userID, code); err != nil {
+			return nil, err
+		}
+		dataUser.ReferralCode = &code
+	}
+
 	// Inisialisasi response
 	results := &dto.GetUserProfileResponse{
 		// ProfileID: uuid.Nil,
 		Name:      dataUser.Name,
 		Email:     dataUser.Email,
 		Phone:     dataUser.PhoneNumber,
+		ReferralCode: *dataUser.ReferralCode,
 	}
 
 	// Simpan ke cache
@@ -260,7 +365,25 @@ func (s *userService) GetUserProfile(ctx context.Context, userID uuid.UUID) (*dt
 		_ = s.cacheable.Set(key, marshalledData)
 	}
 
-	return results, nil
+	return s.withRewards(ctx, userID, results)
+}
+
+// withRewards menambahkan saldo poin dan ringkasan referral. Data ini tidak
+// ikut di-cache karena berubah setiap ada pembayaran.
+func (s *userService) withRewards(ctx context.Context, userID uuid.UUID, profile *dto.GetUserProfileResponse) (*dto.GetUserProfileResponse, error) {
+	loyalty, err := s.loyaltyService.GetSummary(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	profile.Loyalty = loyalty
+
+	code := profile.ReferralCode
+	referral, err := s.referralService.GetSummary(ctx, &entity.User{ID: userID, ReferralCode: &code})
+	if err != nil {
+		return nil, err
+	}
+	profile.Referral = referral
+	return profile, nil
 }
 
 func (s *userService) UpdateUserProfile(ctx context.Context, userID uuid.UUID, request *dto.UpdateUserProfileRequest) error {
@@ -287,6 +410,9 @@ func (s *userService) UpdateUserProfile(ctx context.Context, userID uuid.UUID, r
 		PhoneNumber: request.Phone,
 		Email:       request.Email,
 		Role:        dataUser.Role,
+		CustomerGroup: dataUser.CustomerGroup,
+		ReferralCode: dataUser.ReferralCode,
+		ReferredByID: dataUser.ReferredByID,
 		Password:    dataUser.Password,
 		CreatedAt:   dataUser.CreatedAt,
 	}
@@ -305,6 +431,30 @@ func (s *userService) UpdateUserProfile(ctx context.Context, userID uuid.UUID, r
 	return nil
 }
 
+// UpdateCustomerGroup mengatur grup pelanggan (mis. reseller) yang
+// menentukan tier harga grosir yang berlaku.
+func (s *userService) UpdateCustomerGroup(ctx context.Context, request *dto.UpdateCustomerGroupRequest) error {
+	if _, err := s.userRepository.GetUserProfile(ctx, request.UserID); errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("user not found")
+	} else if err != nil {
+		return err
+	}
+
+	var group *string
+	if request.CustomerGroup != nil && strings.TrimSpace(*request.CustomerGroup) != "" {
+		trimmed := strings.ToLower(strings.TrimSpace(*request.CustomerGroup))
+		group = &trimmed
+	}
+	if err := s.userRepository.SetCustomerGroup(s.DB.WithContext(ctx), request.UserID, group); err != nil {
+		return err
+	}
+
+	_ = s.cacheable.Delete("users:all")
+	// Harga di keranjang user ikut berubah
+	_ = s.cacheable.Delete("carts:" + request.UserID.String())
+	return nil
+}
+
 // func (s *userService) GetUserAddress(ctx context.Context, userID uuid.UUID) (*dto.GetUserAddressResponse, error) {
 // 	key := "users:address:" + userID.String()
 
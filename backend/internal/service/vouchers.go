@@ -0,0 +1,457 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"mola-web/internal/entity"
+	"mola-web/internal/http/dto"
+	"mola-web/internal/repository"
+	"mola-web/pkg/cache"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	VoucherTypePercentage = "percentage"
+	VoucherTypeFixed      = "fixed"
+)
+
+var (
+	ErrVoucherNotFound  = errors.New("voucher not found")
+	ErrVoucherCodeTaken = errors.New("voucher code is already in use")
+)
+
+type VoucherService interface {
+	GetAll(ctx context.Context) ([]dto.VoucherResponse, error)
+	Create(ctx context.Context, req *dto.CreateVoucherRequest) error
+	Update(ctx context.Context, req *dto.UpdateVoucherRequest) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	ApplyToCart(ctx context.Context, userID uuid.UUID, code string) (*dto.VoucherDiscount, error)
+	RemoveFromCart(ctx context.Context, userID uuid.UUID) error
+	CalculateDiscount(db *gorm.DB, code string, userID uuid.UUID, items []dto.CartItems) (*dto.VoucherDiscount, error)
+	Redeem(db *gorm.DB, discount *dto.VoucherDiscount, userID uuid.UUID, orderID uuid.UUID) error
+}
+
+type voucherService struct {
+	DB                  *gorm.DB
+	voucherRepo         repository.VoucherRepository
+	cartRepo            repository.CartRepository
+	saleCampaignService SaleCampaignService
+	cacheable           cache.Cacheable
+}
+
+func NewVoucherService(db *gorm.DB, voucherRepo repository.VoucherRepository, cartRepo repository.CartRepository, saleCampaignService SaleCampaignService, cacheable cache.Cacheable) VoucherService {
+	return &voucherService{
+		DB:                  db,
+		voucherRepo:         voucherRepo,
+		cartRepo:            cartRepo,
+		saleCampaignService: saleCampaignService,
+		cacheable:           cacheable,
+	}
+}
+
+func normalizeVoucherCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func (s *voucherService) GetAll(ctx context.Context) ([]dto.VoucherResponse, error) {
+	vouchers, err := s.voucherRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []dto.VoucherResponse{}
+	for _, voucher := range vouchers {
+		usedCount, err := s.voucherRepo.CountRedemptions(s.DB.WithContext(ctx), voucher.ID)
+		if err != nil {
+			return nil, err
+		}
+		result := dto.VoucherResponse{
+			ID:           voucher.ID,
+			Code:         voucher.Code,
+			Description:  voucher.Description,
+			DiscountType: voucher.DiscountType,
+			Value:        voucher.Value,
+			MinSpend:     voucher.MinSpend,
+			MaxDiscount:  voucher.MaxDiscount,
+			StartsAt:     voucher.StartsAt,
+			EndsAt:       voucher.EndsAt,
+			UsageLimit:   voucher.UsageLimit,
+			PerUserLimit: voucher.PerUserLimit,
+			IsActive:     voucher.IsActive,
+			UserID:       voucher.UserID,
+			UsedCount:    usedCount,
+			ProductIDs:   []uuid.UUID{},
+			CategoryIDs:  []uint{},
+		}
+		for _, product := range voucher.Products {
+			result.ProductIDs = append(result.ProductIDs, product.ProductID)
+		}
+		for _, category := range voucher.Categories {
+			result.CategoryIDs = append(result.CategoryIDs, category.CategoryID)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func validateVoucherRequest(req *dto.CreateVoucherRequest) error {
+	code := normalizeVoucherCode(req.Code)
+	if code == "" {
+		return errors.New("voucher code is required")
+	}
+	// Nama item voucher di Midtrans dibatasi 50 karakter
+	if len(code) > 30 {
+		return errors.New("voucher code must be at most 30 characters")
+	}
+	switch req.DiscountType {
+	case VoucherTypePercentage:
+		if req.Value <= 0 || req.Value > 100 {
+			return errors.New("percentage value must be between 0 and 100")
+		}
+	case VoucherTypeFixed:
+		if req.Value <= 0 {
+			return errors.New("fixed value must be greater than 0")
+		}
+	default:
+		return errors.New("discount type must be percentage or fixed")
+	}
+	if req.MinSpend < 0 {
+		return errors.New("minimum spend cannot be negative")
+	}
+	if req.MaxDiscount != nil && *req.MaxDiscount <= 0 {
+		return errors.New("maximum discount must be greater than 0")
+	}
+	if req.StartsAt != nil && req.EndsAt != nil && !req.EndsAt.After(*req.StartsAt) {
+		return errors.New("end time must be after start time")
+	}
+	if req.UsageLimit != nil && *req.UsageLimit < 1 {
+		return errors.New("usage limit must be at least 1")
+	}
+	if req.PerUserLimit != nil && *req.PerUserLimit < 1 {
+		return errors.New("per user limit must be at least 1")
+	}
+	return nil
+}
+
+// checkVoucherCode memastikan kode belum dipakai voucher lain. Voucher yang
+// sudah dihapus tidak dihitung sehingga kodenya bisa dibuat ulang.
+func (s *voucherService) checkVoucherCode(db *gorm.DB, code string, voucherID uuid.UUID) error {
+	existing, err := s.voucherRepo.GetByCode(db, code)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if existing.ID != voucherID {
+		return ErrVoucherCodeTaken
+	}
+	return nil
+}
+
+func voucherFromRequest(req *dto.CreateVoucherRequest) *entity.Voucher {
+	voucher := &entity.Voucher{
+		Code:         normalizeVoucherCode(req.Code),
+		Description:  req.Description,
+		DiscountType: req.DiscountType,
+		Value:        req.Value,
+		MinSpend:     req.MinSpend,
+		MaxDiscount:  req.MaxDiscount,
+		StartsAt:     req.StartsAt,
+		EndsAt:       req.EndsAt,
+		UsageLimit:   req.UsageLimit,
+		PerUserLimit: req.PerUserLimit,
+		IsActive:     true,
+	}
+	if req.IsActive != nil {
+		voucher.IsActive = *req.IsActive
+	}
+	return voucher
+}
+
+func voucherScopes(voucherID uuid.UUID, req *dto.CreateVoucherRequest) ([]entity.VoucherProduct, []entity.VoucherCategory) {
+	products := []entity.VoucherProduct{}
+	for _, productID := range req.ProductIDs {
+		products = append(products, entity.VoucherProduct{VoucherID: voucherID, ProductID: productID})
+	}
+	categories := []entity.VoucherCategory{}
+	for _, categoryID := range req.CategoryIDs {
+		categories = append(categories, entity.VoucherCategory{VoucherID: voucherID, CategoryID: categoryID})
+	}
+	return products, categories
+}
+
+func (s *voucherService) Create(ctx context.Context, req *dto.CreateVoucherRequest) error {
+	if err := validateVoucherRequest(req); err != nil {
+		return err
+	}
+
+	tx := s.DB.WithContext(ctx).Begin()
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			log.Printf("PANIC RECOVERED: Rolling back transaction due to panic: %v", p)
+			panic(p)
+		} else if tx.Error != nil {
+			tx.Rollback()
+			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
+		}
+	}()
+
+	voucher := voucherFromRequest(req)
+	if err := s.checkVoucherCode(tx, voucher.Code, uuid.Nil); err != nil {
+		tx.Error = err
+		return err
+	}
+	if err := s.voucherRepo.Create(tx, voucher); err != nil {
+		tx.Error = err
+		return err
+	}
+	products, categories := voucherScopes(voucher.ID, req)
+	if err := s.voucherRepo.ReplaceScopes(tx, voucher.ID, products, categories); err != nil {
+		tx.Error = err
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Error = err
+		return err
+	}
+	return nil
+}
+
+func (s *voucherService) Update(ctx context.Context, req *dto.UpdateVoucherRequest) error {
+	if err := validateVoucherRequest(&req.CreateVoucherRequest); err != nil {
+		return err
+	}
+
+	tx := s.DB.WithContext(ctx).Begin()
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			log.Printf("PANIC RECOVERED: Rolling back transaction due to panic: %v", p)
+			panic(p)
+		} else if tx.Error != nil {
+			tx.Rollback()
+			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
+		}
+	}()
+
+	existing, err := s.voucherRepo.GetByID(tx, req.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Error = ErrVoucherNotFound
+		return tx.Error
+	} else if err != nil {
+		tx.Error = err
+		return err
+	}
+
+	voucher := voucherFromRequest(&req.CreateVoucherRequest)
+	voucher.ID = req.ID
+	voucher.UserID = existing.UserID
+	if err := s.checkVoucherCode(tx, voucher.Code, voucher.ID); err != nil {
+		tx.Error = err
+		return err
+	}
+	if err := s.voucherRepo.Update(tx, voucher); err != nil {
+		tx.Error = err
+		return err
+	}
+	products, categories := voucherScopes(voucher.ID, &req.CreateVoucherRequest)
+	if err := s.voucherRepo.ReplaceScopes(tx, voucher.ID, products, categories); err != nil {
+		tx.Error = err
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Error = err
+		return err
+	}
+	return nil
+}
+
+func (s *voucherService) Delete(ctx context.Context, id uuid.UUID) error {
+	db := s.DB.WithContext(ctx)
+	if _, err := s.voucherRepo.GetByID(db, id); errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrVoucherNotFound
+	} else if err != nil {
+		return err
+	}
+	return s.voucherRepo.Delete(db, id)
+}
+
+// checkVoucher memastikan voucher aktif, dalam masa berlaku dan belum
+// melewati batas pemakaian total maupun per user.
+func (s *voucherService) checkVoucher(db *gorm.DB, voucher *entity.Voucher, userID uuid.UUID) error {
+	now := time.Now()
+	if voucher.UserID != nil && *voucher.UserID != userID {
+		return ErrVoucherNotFound
+	}
+	if !voucher.IsActive {
+		return errors.New("voucher is not active")
+	}
+	if voucher.StartsAt != nil && now.Before(*voucher.StartsAt) {
+		return errors.New("voucher is not yet valid")
+	}
+	if voucher.EndsAt != nil && now.After(*voucher.EndsAt) {
+		return errors.New("voucher has expired")
+	}
+	if voucher.UsageLimit != nil {
+		used, err := s.voucherRepo.CountRedemptions(db, voucher.ID)
+		if err != nil {
+			return err
+		}
+		if used >= int64(*voucher.UsageLimit) {
+			return errors.New("voucher usage limit reached")
+		}
+	}
+	if voucher.PerUserLimit != nil {
+		used, err := s.voucherRepo.CountUserRedemptions(db, voucher.ID, userID)
+		if err != nil {
+			return err
+		}
+		if used >= int64(*voucher.PerUserLimit) {
+			return errors.New("you have already used this voucher")
+		}
+	}
+	return nil
+}
+
+// voucherAppliesTo mengecek scope produk dan kategori. Voucher tanpa scope
+// berlaku untuk semua produk.
+func voucherAppliesTo(voucher *entity.Voucher, product *dto.GetProductByID) bool {
+	if len(voucher.Products) == 0 && len(voucher.Categories) == 0 {
+		return true
+	}
+	for _, scope := range voucher.Products {
+		if scope.ProductID == product.ID {
+			return true
+		}
+	}
+	if product.CategoryID != nil {
+		for _, scope := range voucher.Categories {
+			if scope.CategoryID == *product.CategoryID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *voucherService) CalculateDiscount(db *gorm.DB, code string, userID uuid.UUID, items []dto.CartItems) (*dto.VoucherDiscount, error) {
+	voucher, err := s.voucherRepo.GetByCode(db, normalizeVoucherCode(code))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrVoucherNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	if err := s.checkVoucher(db, voucher, userID); err != nil {
+		return nil, err
+	}
+
+	// Minimum belanja dihitung dari item yang masuk scope voucher
+	var eligible float64
+	for _, item := range items {
+		if item.Product != nil && voucherAppliesTo(voucher, item.Product) {
+			eligible += item.Subtotal
+		}
+	}
+	if eligible == 0 {
+		return nil, errors.New("voucher does not apply to any item in the cart")
+	}
+	if eligible < voucher.MinSpend {
+		return nil, fmt.Errorf("minimum spend for this voucher is %.0f", voucher.MinSpend)
+	}
+
+	var discount float64
+	if voucher.DiscountType == VoucherTypePercentage {
+		discount = eligible * voucher.Value / 100
+	} else {
+		discount = voucher.Value
+	}
+	if voucher.MaxDiscount != nil && discount > *voucher.MaxDiscount {
+		discount = *voucher.MaxDiscount
+	}
+	if discount > eligible {
+		discount = eligible
+	}
+
+	return &dto.VoucherDiscount{
+		VoucherID:        voucher.ID,
+		Code:             voucher.Code,
+		EligibleSubtotal: eligible,
+		DiscountAmount:   math.Floor(discount),
+	}, nil
+}
+
+func (s *voucherService) ApplyToCart(ctx context.Context, userID uuid.UUID, code string) (*dto.VoucherDiscount, error) {
+	db := s.DB.WithContext(ctx)
+	cart, err := s.cartRepo.GetCartItemsByUserID(db, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("cart not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	sales, err := s.saleCampaignService.PriceIndex(db, cartProductIDs(cart.CartItems))
+	if err != nil {
+		return nil, err
+	}
+	cartData := buildCartResponse(cart.ID, cart.CartItems, sales, customerGroupOf(cart.User))
+	discount, err := s.CalculateDiscount(db, code, userID, cartData.CartItems)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cartRepo.SetVoucherCode(db, cart.ID, &discount.Code); err != nil {
+		return nil, err
+	}
+	_ = s.cacheable.Delete("carts:" + userID.String())
+
+	return discount, nil
+}
+
+func (s *voucherService) RemoveFromCart(ctx context.Context, userID uuid.UUID) error {
+	db := s.DB.WithContext(ctx)
+	cart, err := s.cartRepo.GetCartByUserID(db, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("cart not found")
+	} else if err != nil {
+		return err
+	}
+
+	if err := s.cartRepo.SetVoucherCode(db, cart.ID, nil); err != nil {
+		return err
+	}
+	_ = s.cacheable.Delete("carts:" + userID.String())
+	return nil
+}
+
+// Redeem mencatat pemakaian voucher pada order. Batas pemakaian dicek ulang
+// dengan baris voucher terkunci.
+func (s *voucherService) Redeem(db *gorm.DB, discount *dto.VoucherDiscount, userID uuid.UUID, orderID uuid.UUID) error {
+	voucher, err := s.voucherRepo.GetByCodeForUpdate(db, discount.Code)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrVoucherNotFound
+	} else if err != nil {
+		return err
+	}
+	if err := s.checkVoucher(db, voucher, userID); err != nil {
+		return err
+	}
+
+	return s.voucherRepo.CreateRedemption(db, &entity.VoucherRedemption{
+		VoucherID:      voucher.ID,
+		UserID:         userID,
+		OrderID:        orderID,
+		Code:           voucher.Code,
+		DiscountAmount: discount.DiscountAmount,
+		Status:         "applied",
+	})
+}
@@ -0,0 +1,402 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"mola-web/internal/entity"
+	"mola-web/internal/http/dto"
+	"mola-web/internal/repository"
+	"mola-web/pkg/cache"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrWishlistItemNotFound = errors.New("wishlist item not found")
+	ErrWishlistNotFound     = errors.New("wishlist not found")
+)
+
+type WishlistService interface {
+	GetWishlist(ctx context.Context, userID uuid.UUID) (*dto.WishlistResponse, error)
+	AddItem(ctx context.Context, userID uuid.UUID, req *dto.AddWishlistItemRequest) error
+	RemoveItem(ctx context.Context, userID uuid.UUID, itemID uuid.UUID) error
+	MoveToCart(ctx context.Context, userID uuid.UUID, itemID uuid.UUID, req *dto.MoveWishlistItemToCartRequest) error
+	SaveForLater(ctx context.Context, userID uuid.UUID, cartItemID uuid.UUID) error
+	EnableShare(ctx context.Context, userID uuid.UUID) (string, error)
+	DisableShare(ctx context.Context, userID uuid.UUID) error
+	GetSharedWishlist(ctx context.Context, shareToken string) (*dto.WishlistResponse, error)
+}
+
+type wishlistService struct {
+	DB                  *gorm.DB
+	wishlistRepo        repository.WishlistRepository
+	cartRepo            repository.CartRepository
+	productRepo         repository.ProductRepository
+	variantRepo         repository.ProductVariantRepository
+	cartService         CartService
+	saleCampaignService SaleCampaignService
+	cacheable           cache.Cacheable
+}
+
+func NewWishlistService(db *gorm.DB, wishlistRepo repository.WishlistRepository, cartRepo repository.CartRepository, productRepo repository.ProductRepository, variantRepo repository.ProductVariantRepository, cartService CartService, saleCampaignService SaleCampaignService, cacheable cache.Cacheable) WishlistService {
+	return &wishlistService{
+		DB:                  db,
+		wishlistRepo:        wishlistRepo,
+		cartRepo:            cartRepo,
+		productRepo:         productRepo,
+		variantRepo:         variantRepo,
+		cartService:         cartService,
+		saleCampaignService: saleCampaignService,
+		cacheable:           cacheable,
+	}
+}
+
+// getOrCreateWishlist mengambil wishlist user, membuatnya jika belum ada.
+func (s *wishlistService) getOrCreateWishlist(db *gorm.DB, userID uuid.UUID) (*entity.Wishlist, error) {
+	wishlist, err := s.wishlistRepo.GetByUserID(db, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		wishlist = &entity.Wishlist{UserID: userID}
+		if err := s.wishlistRepo.Create(db, wishlist); err != nil {
+			return nil, err
+		}
+		return wishlist, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return wishlist, nil
+}
+
+func (s *wishlistService) GetWishlist(ctx context.Context, userID uuid.UUID) (*dto.WishlistResponse, error) {
+	wishlist, err := s.wishlistRepo.GetItemsByUserID(s.DB.WithContext(ctx), userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &dto.WishlistResponse{Items: []dto.WishlistItemResponse{}}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return s.buildWishlistResponse(s.DB.WithContext(ctx), wishlist, false)
+}
+
+func (s *wishlistService) AddItem(ctx context.Context, userID uuid.UUID, req *dto.AddWishlistItemRequest) error {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			log.Printf("PANIC RECOVERED: Rolling back transaction due to panic: %v", p)
+			panic(p)
+		} else if tx.Error != nil {
+			tx.Rollback()
+			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
+		}
+	}()
+
+	if err := s.addItem(tx, userID, req.ProductID, req.ProductVariantID, req.Note); err != nil {
+		tx.Error = err
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Error = err
+		return err
+	}
+	return nil
+}
+
+func (s *wishlistService) addItem(tx *gorm.DB, userID uuid.UUID, productID uuid.UUID, variantID *uuid.UUID, note string) error {
+	product, err := getVisibleProduct(tx, s.productRepo, productID)
+	if err != nil {
+		return err
+	}
+
+	// Varian boleh kosong, user bisa menyimpan produknya saja
+	if !product.HasVariant {
+		variantID = nil
+	} else if variantID != nil {
+		variant, err := s.variantRepo.GetByID(tx, *variantID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("product variant not found")
+		} else if err != nil {
+			return err
+		}
+		if variant.ProductID != productID {
+			return errors.New("variant does not belong to this product")
+		}
+	}
+
+	wishlist, err := s.getOrCreateWishlist(tx, userID)
+	if err != nil {
+		return err
+	}
+
+	// Produk yang sama tidak disimpan dua kali
+	_, err = s.wishlistRepo.GetItemByProduct(tx, wishlist.ID, productID, variantID)
+	if err == nil {
+		return nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	return s.wishlistRepo.AddItem(tx, &entity.WishlistItem{
+		WishlistID:       wishlist.ID,
+		ProductID:        productID,
+		ProductVariantID: variantID,
+		Note:             note,
+	})
+}
+
+func (s *wishlistService) getItem(db *gorm.DB, userID uuid.UUID, itemID uuid.UUID) (*entity.WishlistItem, error) {
+	wishlist, err := s.wishlistRepo.GetByUserID(db, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWishlistItemNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	item, err := s.wishlistRepo.GetItemByID(db, wishlist.ID, itemID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWishlistItemNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (s *wishlistService) RemoveItem(ctx context.Context, userID uuid.UUID, itemID uuid.UUID) error {
+	db := s.DB.WithContext(ctx)
+	item, err := s.getItem(db, userID, itemID)
+	if err != nil {
+		return err
+	}
+	return s.wishlistRepo.RemoveItem(db, item.ID)
+}
+
+// MoveToCart memindahkan item wishlist ke keranjang dalam satu transaksi,
+// item tidak pernah tertinggal di keduanya.
+func (s *wishlistService) MoveToCart(ctx context.Context, userID uuid.UUID, itemID uuid.UUID, req *dto.MoveWishlistItemToCartRequest) error {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			log.Printf("PANIC RECOVERED: Rolling back transaction due to panic: %v", p)
+			panic(p)
+		} else if tx.Error != nil {
+			tx.Rollback()
+			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
+		}
+	}()
+
+	item, err := s.getItem(tx, userID, itemID)
+	if err != nil {
+		tx.Error = err
+		return err
+	}
+
+	quantity := req.Quantity
+	if quantity < 1 {
+		quantity = 1
+	}
+
+	// CartService yang memvalidasi varian dan stok
+	if err := s.cartService.AddItem(tx, userID, &dto.AddToCartRequest{
+		ProductID:        item.ProductID,
+		ProductVariantID: item.ProductVariantID,
+		Quantity:         quantity,
+		Note:             item.Note,
+		BundleSelections: req.BundleSelections,
+	}); err != nil {
+		tx.Error = err
+		return err
+	}
+	if err := s.wishlistRepo.RemoveItem(tx, item.ID); err != nil {
+		tx.Error = err
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Error = err
+		return err
+	}
+	_ = s.cacheable.Delete("carts:" + userID.String())
+	return nil
+}
+
+func (s *wishlistService) SaveForLater(ctx context.Context, userID uuid.UUID, cartItemID uuid.UUID) error {
+	tx := s.DB.WithContext(ctx).Begin()
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			log.Printf("PANIC RECOVERED: Rolling back transaction due to panic: %v", p)
+			panic(p)
+		} else if tx.Error != nil {
+			tx.Rollback()
+			log.Printf("ERROR: Rolling back transaction due to service error: %v", tx.Error)
+		}
+	}()
+
+	cartItem, err := s.cartRepo.GetCartItemByUserID(tx, userID, cartItemID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Error = ErrCartItemNotFound
+		return tx.Error
+	} else if err != nil {
+		tx.Error = err
+		return err
+	}
+
+	if err := s.addItem(tx, userID, cartItem.ProductID, cartItem.ProductVariantID, cartItem.Note); err != nil {
+		tx.Error = err
+		return err
+	}
+	if err := s.cartService.RemoveItem(tx, userID, cartItem.ID); err != nil {
+		tx.Error = err
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Error = err
+		return err
+	}
+	_ = s.cacheable.Delete("carts:" + userID.String())
+	return nil
+}
+
+func (s *wishlistService) EnableShare(ctx context.Context, userID uuid.UUID) (string, error) {
+	db := s.DB.WithContext(ctx)
+	wishlist, err := s.getOrCreateWishlist(db, userID)
+	if err != nil {
+		return "", err
+	}
+	if wishlist.ShareToken != nil {
+		return *wishlist.ShareToken, nil
+	}
+
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	shareToken := hex.EncodeToString(bytes)
+	if err := s.wishlistRepo.UpdateShareToken(db, wishlist.ID, &shareToken); err != nil {
+		return "", err
+	}
+	return shareToken, nil
+}
+
+func (s *wishlistService) DisableShare(ctx context.Context, userID uuid.UUID) error {
+	db := s.DB.WithContext(ctx)
+	wishlist, err := s.wishlistRepo.GetByUserID(db, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return s.wishlistRepo.UpdateShareToken(db, wishlist.ID, nil)
+}
+
+func (s *wishlistService) GetSharedWishlist(ctx context.Context, shareToken string) (*dto.WishlistResponse, error) {
+	wishlist, err := s.wishlistRepo.GetItemsByShareToken(s.DB.WithContext(ctx), shareToken)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWishlistNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	result, err := s.buildWishlistResponse(s.DB.WithContext(ctx), wishlist, true)
+	if err != nil {
+		return nil, err
+	}
+	// Token tidak ditampilkan ulang ke pengunjung
+	result.ShareToken = nil
+	if wishlist.User != nil {
+		result.OwnerName = wishlist.User.Name
+	}
+	return result, nil
+}
+
+// buildWishlistResponse menyusun isi wishlist. Produk yang tidak tayang
+// ditandai tidak tersedia untuk pemilik dan disembunyikan dari wishlist
+// yang dibagikan.
+func (s *wishlistService) buildWishlistResponse(db *gorm.DB, wishlist *entity.Wishlist, shared bool) (*dto.WishlistResponse, error) {
+	productIDs := []uuid.UUID{}
+	for _, dataItem := range wishlist.Items {
+		productIDs = append(productIDs, dataItem.ProductID)
+	}
+	sales, err := s.saleCampaignService.PriceIndex(db, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	items := []dto.WishlistItemResponse{}
+	for _, dataItem := range wishlist.Items {
+		// Produk sudah dihapus
+		if dataItem.Product == nil {
+			continue
+		}
+		available := productVisible(dataItem.Product, now)
+		if shared && !available {
+			continue
+		}
+		note := dataItem.Note
+		basePrice := variantPrice(dataItem.Product, dataItem.ProductVariant)
+		sale := sales.Lookup(dataItem.ProductID, dataItem.ProductVariantID, basePrice)
+		price := basePrice
+		if sale != nil {
+			price = sale.Price
+		}
+		item := dto.WishlistItemResponse{
+			ID:      dataItem.ID,
+			Price:   price,
+			Stock:   dataItem.Product.Stock,
+			Note:    &note,
+			AddedAt: dataItem.CreatedAt,
+			Product: &dto.GetProductByID{
+				ID:            dataItem.Product.ID,
+				Name:          dataItem.Product.Name,
+				CategoryID:    dataItem.Product.CategoryID,
+				Description:   dataItem.Product.Description,
+				ImageURL:      variantImageURL(dataItem.Product, dataItem.ProductVariant),
+				HasVariant:    dataItem.Product.HasVariant,
+				Price:         price,
+				OriginalPrice: basePrice,
+				Stock:         dataItem.Product.Stock,
+				Weight:        variantWeight(dataItem.Product, dataItem.ProductVariant),
+			},
+		}
+		item.Product.SalePrice, item.Product.SaleEndsAt = saleFields(sale)
+		if dataItem.Product.Category != nil {
+			item.Product.CategoryName = &dataItem.Product.Category.Name
+		}
+
+		if dataItem.Product.HasVariant && dataItem.ProductVariant != nil {
+			var variantDTO dto.ProductVariantInfo
+			variantDTO.ID = dataItem.ProductVariant.ID
+			variantDTO.Stock = dataItem.ProductVariant.Stock
+			variantDTO.ColorID = dataItem.ProductVariant.ColorID
+			variantDTO.SizeID = dataItem.ProductVariant.SizeID
+			variantDTO.SalePrice, variantDTO.SaleEndsAt = saleFields(sale)
+			if dataItem.ProductVariant.Color != nil {
+				variantDTO.Color = dataItem.ProductVariant.Color.Name
+			}
+			if dataItem.ProductVariant.Size != nil {
+				variantDTO.Size = dataItem.ProductVariant.Size.Name
+			}
+			applyVariantOverrides(&variantDTO, dataItem.Product, *dataItem.ProductVariant)
+
+			item.Variant = &variantDTO
+			item.Product.Variants = append(item.Product.Variants, variantDTO)
+			item.Stock = variantDTO.Stock
+		}
+		item.Available = available
+		item.InStock = available && item.Stock > 0
+
+		items = append(items, item)
+	}
+
+	return &dto.WishlistResponse{
+		ID:         wishlist.ID,
+		ShareToken: wishlist.ShareToken,
+		Items:      items,
+	}, nil
+}
@@ -0,0 +1,181 @@
+package barcode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	FormatEAN13   = "ean13"
+	FormatCode128 = "code128"
+
+	MaxLength = 64
+
+	// InStorePrefix prefix GS1 untuk barcode internal toko (20-29) supaya
+	// tidak bentrok dengan barcode dari pabrik.
+	InStorePrefix = "20"
+)
+
+var ErrInvalid = errors.New("barcode must be a valid EAN-13 or printable ASCII (Code128), at most 64 characters")
+
+var (
+	eanL = [10]string{"0001101", "0011001", "0010011", "0111101", "0100011", "0110001", "0101111", "0111011", "0110111", "0001011"}
+	eanG = [10]string{"0100111", "0110011", "0011011", "0100001", "0011101", "0111001", "0000101", "0010001", "0001001", "0010111"}
+	eanR = [10]string{"1110010", "1100110", "1101100", "1000010", "1011100", "1001110", "1010000", "1000100", "1001000", "1110100"}
+
+	// eanParity pola L/G enam digit kiri berdasarkan digit pertama
+	eanParity = [10]string{"LLLLLL", "LLGLGG", "LLGGLG", "LLGGGL", "LGLLGG", "LGGLLG", "LGGGLL", "LGLGLG", "LGLGGL", "LGGLGL"}
+)
+
+// code128Patterns lebar bar dan spasi setiap simbol Code128, indeks sama
+// dengan nilai simbol. 103-105 simbol start, 106 simbol stop.
+var code128Patterns = [107]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+const code128StartB = 104
+
+func isDigits(code string) bool {
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return code != ""
+}
+
+// EAN13CheckDigit menghitung digit cek dari 12 digit pertama.
+func EAN13CheckDigit(digits string) (byte, error) {
+	if len(digits) != 12 || !isDigits(digits) {
+		return 0, errors.New("EAN-13 check digit needs 12 digits")
+	}
+	sum := 0
+	for i := 0; i < 12; i++ {
+		d := int(digits[i] - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10), nil
+}
+
+func ValidEAN13(code string) bool {
+	if len(code) != 13 || !isDigits(code) {
+		return false
+	}
+	check, _ := EAN13CheckDigit(code[:12])
+	return code[12] == check
+}
+
+// MakeEAN13 membuat EAN-13 dari prefix dan nomor urut, misalnya prefix "20"
+// dan nomor 15 menjadi 2000000000152.
+func MakeEAN13(prefix string, number int64) (string, error) {
+	width := 12 - len(prefix)
+	if !isDigits(prefix) || width < 1 || number < 0 {
+		return "", fmt.Errorf("invalid EAN-13 prefix %q or number %d", prefix, number)
+	}
+	body := fmt.Sprintf("%s%0*d", prefix, width, number)
+	if len(body) != 12 {
+		return "", fmt.Errorf("number %d does not fit EAN-13 with prefix %s", number, prefix)
+	}
+	check, err := EAN13CheckDigit(body)
+	if err != nil {
+		return "", err
+	}
+	return body + string(check), nil
+}
+
+// Detect menentukan format barcode. Kode 13 digit harus EAN-13 yang valid,
+// selain itu dicetak sebagai Code128.
+func Detect(code string) (string, error) {
+	if code == "" || len(code) > MaxLength || strings.TrimSpace(code) != code {
+		return "", ErrInvalid
+	}
+	if len(code) == 13 && isDigits(code) {
+		if !ValidEAN13(code) {
+			return "", fmt.Errorf("%w: wrong EAN-13 check digit", ErrInvalid)
+		}
+		return FormatEAN13, nil
+	}
+	for i := 0; i < len(code); i++ {
+		if code[i] < 32 || code[i] > 126 {
+			return "", ErrInvalid
+		}
+	}
+	return FormatCode128, nil
+}
+
+// Modules pola modul barcode dari kiri ke kanan, true berarti bar hitam.
+// Quiet zone tidak termasuk.
+func Modules(code string) ([]bool, error) {
+	format, err := Detect(code)
+	if err != nil {
+		return nil, err
+	}
+	if format == FormatEAN13 {
+		return ean13Modules(code), nil
+	}
+	return code128Modules(code), nil
+}
+
+func appendBits(modules []bool, bits string) []bool {
+	for i := 0; i < len(bits); i++ {
+		modules = append(modules, bits[i] == '1')
+	}
+	return modules
+}
+
+func ean13Modules(code string) []bool {
+	modules := make([]bool, 0, 95)
+	modules = appendBits(modules, "101")
+	parity := eanParity[code[0]-'0']
+	for i := 1; i <= 6; i++ {
+		d := code[i] - '0'
+		if parity[i-1] == 'L' {
+			modules = appendBits(modules, eanL[d])
+		} else {
+			modules = appendBits(modules, eanG[d])
+		}
+	}
+	modules = appendBits(modules, "01010")
+	for i := 7; i <= 12; i++ {
+		modules = appendBits(modules, eanR[code[i]-'0'])
+	}
+	return appendBits(modules, "101")
+}
+
+// code128Modules memakai code set B yang mencakup semua ASCII cetak.
+func code128Modules(code string) []bool {
+	symbols := []int{code128StartB}
+	checksum := code128StartB
+	for i := 0; i < len(code); i++ {
+		value := int(code[i]) - 32
+		symbols = append(symbols, value)
+		checksum += (i + 1) * value
+	}
+	symbols = append(symbols, checksum%103, 106)
+
+	modules := []bool{}
+	for _, symbol := range symbols {
+		bar := true
+		for _, width := range code128Patterns[symbol] {
+			for n := 0; n < int(width-'0'); n++ {
+				modules = append(modules, bar)
+			}
+			bar = !bar
+		}
+	}
+	return modules
+}
@@ -0,0 +1,156 @@
+package barcode
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEAN13CheckDigit(t *testing.T) {
+	tests := []struct {
+		digits string
+		want   byte
+	}{
+		{"400638133393", '1'},
+		{"590123412345", '7'},
+		{"200000000015", '2'},
+		{"000000000000", '0'},
+	}
+	for _, tt := range tests {
+		got, err := EAN13CheckDigit(tt.digits)
+		if err != nil {
+			t.Errorf("EAN13CheckDigit(%q) returned %v", tt.digits, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("EAN13CheckDigit(%q) = %c, want %c", tt.digits, got, tt.want)
+		}
+	}
+
+	for _, digits := range []string{"", "40063813339", "4006381333931", "40063813339a"} {
+		if _, err := EAN13CheckDigit(digits); err == nil {
+			t.Errorf("EAN13CheckDigit(%q) accepted invalid input", digits)
+		}
+	}
+}
+
+func TestValidEAN13(t *testing.T) {
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{"4006381333931", true},
+		{"5901234123457", true},
+		{"4006381333932", false},
+		{"400638133393", false},
+		{"40063813339311", false},
+		{"400638133393a", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := ValidEAN13(tt.code); got != tt.want {
+			t.Errorf("ValidEAN13(%q) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestMakeEAN13(t *testing.T) {
+	got, err := MakeEAN13(InStorePrefix, 15)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "2000000000152" {
+		t.Errorf("MakeEAN13(%q, 15) = %q, want 2000000000152", InStorePrefix, got)
+	}
+	if _, err := MakeEAN13(InStorePrefix, 10000000000); err == nil {
+		t.Error("MakeEAN13 accepted a number that does not fit")
+	}
+	if _, err := MakeEAN13("2a", 1); err == nil {
+		t.Error("MakeEAN13 accepted a non-numeric prefix")
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		code    string
+		want    string
+		invalid bool
+	}{
+		{"4006381333931", FormatEAN13, false},
+		{"4006381333932", "", true},
+		{"KAOS-POLOS-XL", FormatCode128, false},
+		{"123456789012", FormatCode128, false},
+		{" KAOS", "", true},
+		{"KAOS\n", "", true},
+		{"KAOS\x7f", "", true},
+		{strings.Repeat("A", MaxLength+1), "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		got, err := Detect(tt.code)
+		if tt.invalid {
+			if !errors.Is(err, ErrInvalid) {
+				t.Errorf("Detect(%q) error = %v, want ErrInvalid", tt.code, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Detect(%q) = %q, %v, want %q", tt.code, got, err, tt.want)
+		}
+	}
+}
+
+func moduleString(modules []bool) string {
+	var b strings.Builder
+	for _, bar := range modules {
+		if bar {
+			b.WriteByte('1')
+		} else {
+			b.WriteByte('0')
+		}
+	}
+	return b.String()
+}
+
+func TestModulesEAN13(t *testing.T) {
+	modules, err := Modules("4006381333931")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := moduleString(modules)
+	if len(got) != 95 {
+		t.Fatalf("EAN-13 has %d modules, want 95", len(got))
+	}
+	// Guard kiri, digit 0 (paritas L untuk digit pertama 4), dan guard tengah
+	if got[:3] != "101" || got[3:10] != "0001101" || got[45:50] != "01010" || got[92:] != "101" {
+		t.Errorf("unexpected EAN-13 modules %s", got)
+	}
+	// Digit terakhir (1) di sisi kanan memakai pola R
+	if got[85:92] != "1100110" {
+		t.Errorf("last digit modules = %s, want 1100110", got[85:92])
+	}
+}
+
+func TestModulesCode128(t *testing.T) {
+	// Start B, "A", checksum (104+33)%103 = 34, stop
+	want := "11010010000" + "10100011000" + "10001011000" + "1100011101011"
+	modules, err := Modules("A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := moduleString(modules); got != want {
+		t.Errorf("Modules(\"A\") = %s, want %s", got, want)
+	}
+
+	// Setiap simbol 11 modul, stop 13 modul
+	for _, code := range []string{"KAOS-POLOS-XL", "sku_01/a.b", "~ }"} {
+		modules, err := Modules(code)
+		if err != nil {
+			t.Errorf("Modules(%q) returned %v", code, err)
+			continue
+		}
+		if want := 11*(len(code)+2) + 13; len(modules) != want {
+			t.Errorf("Modules(%q) has %d modules, want %d", code, len(modules), want)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package barcode
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Ukuran dalam point (1/72 inci). Lembar A4 berisi 3 x 8 label 70 x 37 mm,
+// ukuran label stiker yang umum dijual.
+const (
+	pageWidth     = 595.28
+	pageHeight    = 841.89
+	labelColumns  = 3
+	labelRows     = 8
+	labelWidth    = pageWidth / labelColumns
+	labelHeight   = pageHeight / labelRows
+	labelPadding  = 8.0
+	barHeight     = 38.0
+	maxModule     = 1.4
+	labelsPerPage = labelColumns * labelRows
+)
+
+type Label struct {
+	Title    string // nama produk
+	Subtitle string // opsi varian, boleh kosong
+	Code     string // kode yang dijadikan barcode
+	Caption  string // teks kecil di bawah barcode, misalnya SKU
+}
+
+// WriteLabelSheetPDF menulis lembar label barcode siap cetak dalam format
+// PDF. Teks memakai font bawaan Helvetica sehingga tidak perlu embed font.
+func WriteLabelSheetPDF(w io.Writer, labels []Label) error {
+	if len(labels) == 0 {
+		return fmt.Errorf("no labels to print")
+	}
+	var pages [][]byte
+	for start := 0; start < len(labels); start += labelsPerPage {
+		end := start + labelsPerPage
+		if end > len(labels) {
+			end = len(labels)
+		}
+		var content bytes.Buffer
+		for i, label := range labels[start:end] {
+			x := float64(i%labelColumns) * labelWidth
+			y := pageHeight - float64(i/labelColumns+1)*labelHeight
+			if err := drawLabel(&content, label, x, y); err != nil {
+				return fmt.Errorf("label %d: %w", start+i+1, err)
+			}
+		}
+		pages = append(pages, content.Bytes())
+	}
+
+	// Objek 1 katalog, 2 daftar halaman, 3 font, lalu pasangan halaman dan
+	// content stream
+	var out bytes.Buffer
+	offsets := []int{}
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+i*2)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	for i, content := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 5+i*2))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+func drawLabel(content *bytes.Buffer, label Label, x, y float64) error {
+	modules, err := Modules(label.Code)
+	if err != nil {
+		return err
+	}
+	left := x + labelPadding
+	top := y + labelHeight - labelPadding
+
+	writeText(content, label.Title, 8, left, top-8, 42)
+	if label.Subtitle != "" {
+		writeText(content, label.Subtitle, 7, left, top-17, 48)
+	}
+
+	// Lebar modul menyesuaikan panjang kode, barcode diletakkan di tengah
+	available := labelWidth - 2*labelPadding
+	module := available / float64(len(modules))
+	if module > maxModule {
+		module = maxModule
+	}
+	barX := x + (labelWidth-module*float64(len(modules)))/2
+	barY := y + labelPadding + 18
+	for i := 0; i < len(modules); {
+		if !modules[i] {
+			i++
+			continue
+		}
+		run := 1
+		for i+run < len(modules) && modules[i+run] {
+			run++
+		}
+		fmt.Fprintf(content, "%.3f %.3f %.3f %.3f re\n", barX+float64(i)*module, barY, float64(run)*module, barHeight)
+		i += run
+	}
+	content.WriteString("f\n")
+
+	writeText(content, label.Code, 8, barX, y+labelPadding+8, MaxLength)
+	if label.Caption != "" && label.Caption != label.Code {
+		writeText(content, label.Caption, 6, barX, y+labelPadding, MaxLength)
+	}
+	return nil
+}
+
+// writeText menulis satu baris teks, dipotong jika lebih dari max karakter.
+func writeText(content *bytes.Buffer, text string, size float64, x, y float64, max int) {
+	runes := []rune(text)
+	if len(runes) > max {
+		runes = append(runes[:max-1], '…')
+	}
+	fmt.Fprintf(content, "BT /F1 %.0f Tf %.2f %.2f Td (%s) Tj ET\n", size, x, y, pdfString(runes))
+}
+
+// pdfString mengubah teks ke WinAnsi dan meng-escape karakter khusus PDF.
+// Karakter di luar Latin-1 diganti tanda tanya.
+func pdfString(runes []rune) string {
+	var b strings.Builder
+	for _, r := range runes {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '…':
+			b.WriteString("\\205")
+		case r >= 32 && r < 127:
+			b.WriteRune(r)
+		case r >= 160 && r < 256:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
@@ -20,6 +20,7 @@ func InitCache(cfg configs.RedisConfig) *redis.Client {
 
 type Cacheable interface {
 	Set(key string, value interface{}) error
+	SetWithTTL(key string, value interface{}, ttl time.Duration) error
 	Get(key string) string
 	Delete(key string) error
 	DeleteByPrefix(prefix string) error
@@ -44,6 +45,10 @@ func (c *cacheable) Set(key string, value interface{}) error {
 	return nil
 }
 
+func (c *cacheable) SetWithTTL(key string, value interface{}, ttl time.Duration) error {
+	return c.rdb.Set(context.Background(), key, value, ttl).Err()
+}
+
 func (c *cacheable) Get(key string) string {
 	value, err := c.rdb.Get(context.Background(), key).Result()
 	if err == redis.Nil {
@@ -0,0 +1,191 @@
+package cache
+
+import (
+	"context"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	SuggestKindProduct  = "product"
+	SuggestKindCategory = "category"
+	SuggestKindQuery    = "query"
+
+	suggestKeyPrefix = "suggest:"
+	// maxSuggestPrefixLength panjang prefix terpanjang yang disimpan, prefix
+	// yang lebih panjang dicari dengan potongan sepanjang ini.
+	maxSuggestPrefixLength = 20
+)
+
+type Suggestion struct {
+	ID    string
+	Label string
+	Score float64
+}
+
+// SuggestionIndex indeks autocomplete di Redis. Setiap prefix dari setiap
+// kata pada label disimpan sebagai sorted set berisi ID dengan skor bobot,
+// sehingga pencarian cukup satu ZREVRANGE.
+type SuggestionIndex interface {
+	Put(ctx context.Context, kind string, id string, label string, score float64) error
+	IncrBy(ctx context.Context, kind string, id string, label string, by float64) error
+	Remove(ctx context.Context, kind string, id string) error
+	Lookup(ctx context.Context, kind string, prefix string, limit int) ([]Suggestion, error)
+	IDs(ctx context.Context, kind string) ([]string, error)
+}
+
+type suggestionIndex struct {
+	rdb *redis.Client
+}
+
+func NewSuggestionIndex(rdb *redis.Client) SuggestionIndex {
+	return &suggestionIndex{
+		rdb: rdb,
+	}
+}
+
+func suggestPrefixKey(kind string, prefix string) string {
+	return suggestKeyPrefix + kind + ":p:" + prefix
+}
+
+func suggestLabelKey(kind string) string {
+	return suggestKeyPrefix + kind + ":labels"
+}
+
+// NormalizeSuggestText huruf kecil dengan spasi dirapikan.
+func NormalizeSuggestText(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
+
+// suggestPrefixes semua prefix mulai dari setiap awal kata, jadi "kaos polos"
+// bisa ditemukan dengan "ka" maupun "pol".
+func suggestPrefixes(label string) []string {
+	text := []rune(NormalizeSuggestText(label))
+	seen := map[string]struct{}{}
+	prefixes := []string{}
+	for start := range text {
+		if start > 0 && text[start-1] != ' ' {
+			continue
+		}
+		for end := start + 1; end <= len(text) && end-start <= maxSuggestPrefixLength; end++ {
+			prefix := string(text[start:end])
+			if _, ok := seen[prefix]; ok {
+				continue
+			}
+			seen[prefix] = struct{}{}
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes
+}
+
+func (s *suggestionIndex) Put(ctx context.Context, kind string, id string, label string, score float64) error {
+	oldLabel, err := s.rdb.HGet(ctx, suggestLabelKey(kind), id).Result()
+	if err != nil && err != redis.Nil {
+		return err
+	}
+
+	_, err = s.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if oldLabel != "" && oldLabel != label {
+			for _, prefix := range suggestPrefixes(oldLabel) {
+				pipe.ZRem(ctx, suggestPrefixKey(kind, prefix), id)
+			}
+		}
+		for _, prefix := range suggestPrefixes(label) {
+			pipe.ZAdd(ctx, suggestPrefixKey(kind, prefix), redis.Z{Score: score, Member: id})
+		}
+		pipe.HSet(ctx, suggestLabelKey(kind), id, label)
+		return nil
+	})
+	return err
+}
+
+func (s *suggestionIndex) IncrBy(ctx context.Context, kind string, id string, label string, by float64) error {
+	_, err := s.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, prefix := range suggestPrefixes(label) {
+			pipe.ZIncrBy(ctx, suggestPrefixKey(kind, prefix), by, id)
+		}
+		pipe.HSet(ctx, suggestLabelKey(kind), id, label)
+		return nil
+	})
+	return err
+}
+
+func (s *suggestionIndex) Remove(ctx context.Context, kind string, id string) error {
+	label, err := s.rdb.HGet(ctx, suggestLabelKey(kind), id).Result()
+	if err == redis.Nil {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	_, err = s.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, prefix := range suggestPrefixes(label) {
+			pipe.ZRem(ctx, suggestPrefixKey(kind, prefix), id)
+		}
+		pipe.HDel(ctx, suggestLabelKey(kind), id)
+		return nil
+	})
+	return err
+}
+
+func (s *suggestionIndex) Lookup(ctx context.Context, kind string, prefix string, limit int) ([]Suggestion, error) {
+	query := NormalizeSuggestText(prefix)
+	key := []rune(query)
+	if len(key) == 0 {
+		return []Suggestion{}, nil
+	}
+	if len(key) > maxSuggestPrefixLength {
+		key = key[:maxSuggestPrefixLength]
+	}
+
+	// Ambil lebih banyak untuk prefix yang terpotong karena sebagian
+	// hasilnya akan tersaring
+	fetch := limit
+	if len(key) < len([]rune(query)) {
+		fetch = limit * 5
+	}
+	members, err := s.rdb.ZRevRangeWithScores(ctx, suggestPrefixKey(kind, string(key)), 0, int64(fetch-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		return []Suggestion{}, nil
+	}
+
+	ids := make([]string, 0, len(members))
+	for _, member := range members {
+		ids = append(ids, member.Member.(string))
+	}
+	labels, err := s.rdb.HMGet(ctx, suggestLabelKey(kind), ids...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	results := []Suggestion{}
+	for i, member := range members {
+		label, ok := labels[i].(string)
+		if !ok {
+			continue
+		}
+		if len(key) < len([]rune(query)) && !strings.Contains(NormalizeSuggestText(label), query) {
+			continue
+		}
+		results = append(results, Suggestion{
+			ID:    ids[i],
+			Label: label,
+			Score: member.Score,
+		})
+		if len(results) == limit {
+			break
+		}
+	}
+	return results, nil
+}
+
+// IDs semua ID yang sedang terindeks, dipakai saat membangun ulang indeks
+// untuk membuang entri yang sudah tidak ada.
+func (s *suggestionIndex) IDs(ctx context.Context, kind string) ([]string, error) {
+	return s.rdb.HKeys(ctx, suggestLabelKey(kind)).Result()
+}
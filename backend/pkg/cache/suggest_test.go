@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeSuggestText(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Kaos Polos", "kaos polos"},
+		{"  KAOS \t  polos\n", "kaos polos"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeSuggestText(tt.text); got != tt.want {
+			t.Errorf("NormalizeSuggestText(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSuggestPrefixes(t *testing.T) {
+	tests := []struct {
+		label string
+		want  []string
+	}{
+		{"Kaos", []string{"k", "ka", "kao", "kaos"}},
+		{"Kaos  Polos", []string{
+			"k", "ka", "kao", "kaos", "kaos ", "kaos p", "kaos po", "kaos pol", "kaos polo", "kaos polos",
+			"p", "po", "pol", "polo", "polos",
+		}},
+		// Prefix yang sama dari kata berikutnya tidak diulang
+		{"aa aa", []string{"a", "aa", "aa ", "aa a", "aa aa"}},
+		{"Café", []string{"c", "ca", "caf", "café"}},
+		{"  ", []string{}},
+	}
+	for _, tt := range tests {
+		if got := suggestPrefixes(tt.label); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("suggestPrefixes(%q) = %q, want %q", tt.label, got, tt.want)
+		}
+	}
+}
+
+func TestSuggestPrefixesMaxLength(t *testing.T) {
+	label := strings.Repeat("a", maxSuggestPrefixLength+10)
+	prefixes := suggestPrefixes(label)
+	if len(prefixes) != maxSuggestPrefixLength {
+		t.Fatalf("got %d prefixes, want %d", len(prefixes), maxSuggestPrefixLength)
+	}
+	if last := prefixes[len(prefixes)-1]; len(last) != maxSuggestPrefixLength {
+		t.Errorf("longest prefix has %d characters, want %d", len(last), maxSuggestPrefixLength)
+	}
+}
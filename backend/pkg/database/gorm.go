@@ -19,7 +19,10 @@ func InitDatabase(cfg configs.PostgresConfig) (*gorm.DB, error) {
 }
 
 func AutoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(
+	if err := migrateVoucherCodes(db); err != nil {
+		return err
+	}
+	if err := db.AutoMigrate(
 		&entity.Product{},
 		&entity.Color{},
 		&entity.Category{},
@@ -35,5 +38,45 @@ func AutoMigrate(db *gorm.DB) error {
 		&entity.Cart{},
 		&entity.CartItem{},
         &entity.ProductVariant{},
-	)
+		&entity.CartAbandonment{},
+		&entity.CartReminder{},
+		&entity.Wishlist{},
+		&entity.WishlistItem{},
+		&entity.Voucher{},
+		&entity.VoucherProduct{},
+		&entity.VoucherCategory{},
+		&entity.VoucherRedemption{},
+		&entity.SaleCampaign{},
+		&entity.SaleCampaignItem{},
+		&entity.ProductBundleItem{},
+		&entity.CartItemComponent{},
+		&entity.OrderItemComponent{},
+		&entity.ProductPriceTier{},
+		&entity.LoyaltyTransaction{},
+		&entity.Referral{},
+		&entity.ReferralSettings{},
+		&entity.GiftCard{},
+		&entity.StoreCreditWallet{},
+		&entity.StoreCreditTransaction{},
+		&entity.SearchLog{},
+		&entity.ProductImage{},
+		&entity.SlugRedirect{},
+		&entity.OptionType{},
+		&entity.OptionValue{},
+	); err != nil {
+		return err
+	}
+	if err := migrateProductSearch(db); err != nil {
+		return err
+	}
+	if err := migrateProductImages(db); err != nil {
+		return err
+	}
+	if err := migrateSlugs(db); err != nil {
+		return err
+	}
+	if err := migrateVariantOptions(db); err != nil {
+		return err
+	}
+	return migrateInventoryCodes(db)
 }
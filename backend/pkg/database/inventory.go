@@ -0,0 +1,143 @@
+package database
+
+import (
+	"fmt"
+	"mola-web/pkg/barcode"
+	"mola-web/pkg/sku"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// migrateInventoryCodes membuat SKU dan barcode untuk produk dan varian lama
+// dengan aturan yang sama seperti produk baru.
+func migrateInventoryCodes(db *gorm.DB) error {
+	if err := db.Exec("CREATE SEQUENCE IF NOT EXISTS public.inventory_barcode_seq").Error; err != nil {
+		return err
+	}
+	// Index barcode varian lama belum unik, sudah diganti index unik
+	if err := db.Exec("DROP INDEX IF EXISTS public.idx_product_variants_barcode").Error; err != nil {
+		return err
+	}
+
+	var existing []string
+	if err := db.Raw(`SELECT sku FROM public.products WHERE sku IS NOT NULL AND deleted_at IS NULL
+		UNION ALL SELECT barcode FROM public.products WHERE barcode IS NOT NULL AND deleted_at IS NULL
+		UNION ALL SELECT sku FROM public.product_variants WHERE sku IS NOT NULL AND deleted_at IS NULL
+		UNION ALL SELECT barcode FROM public.product_variants WHERE barcode IS NOT NULL AND deleted_at IS NULL`).
+		Scan(&existing).Error; err != nil {
+		return err
+	}
+	taken := make(map[string]struct{}, len(existing))
+	for _, code := range existing {
+		taken[code] = struct{}{}
+	}
+	claim := func(base string) string {
+		candidate := base
+		for i := 2; ; i++ {
+			if _, ok := taken[candidate]; !ok {
+				break
+			}
+			candidate = sku.WithSuffix(base, i)
+		}
+		taken[candidate] = struct{}{}
+		return candidate
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		nextBarcode := func() (string, error) {
+			for {
+				var number int64
+				if err := tx.Raw("SELECT nextval('public.inventory_barcode_seq')").Scan(&number).Error; err != nil {
+					return "", err
+				}
+				code, err := barcode.MakeEAN13(barcode.InStorePrefix, number)
+				if err != nil {
+					return "", err
+				}
+				if _, ok := taken[code]; !ok {
+					taken[code] = struct{}{}
+					return code, nil
+				}
+			}
+		}
+
+		var products []struct {
+			ID      string
+			Name    string
+			SKU     *string
+			Barcode *string
+		}
+		if err := tx.Raw(`SELECT id::text AS id, name, sku, barcode FROM public.products
+			WHERE deleted_at IS NULL AND (sku IS NULL OR barcode IS NULL)
+			ORDER BY created_at`).Scan(&products).Error; err != nil {
+			return err
+		}
+		for _, product := range products {
+			updates := map[string]interface{}{}
+			if product.SKU == nil {
+				base := sku.Make(product.Name)
+				if base == "" {
+					base = "PRODUCT"
+				}
+				updates["sku"] = claim(base)
+			}
+			if product.Barcode == nil {
+				code, err := nextBarcode()
+				if err != nil {
+					return err
+				}
+				updates["barcode"] = code
+			}
+			if err := tx.Table("public.products").Where("id::text = ?", product.ID).Updates(updates).Error; err != nil {
+				return fmt.Errorf("failed to set inventory codes for product %s: %v", product.ID, err)
+			}
+		}
+
+		// SKU varian diturunkan dari SKU produk dan nilai opsinya
+		var variants []struct {
+			ID         string
+			ProductSKU *string
+			Options    string
+			SKU        *string
+			Barcode    *string
+		}
+		if err := tx.Raw(`SELECT v.id::text AS id, p.sku AS product_sku, v.sku, v.barcode,
+				COALESCE(string_agg(ov.value, '|' ORDER BY ot.sort_order, ot.id), '') AS options
+			FROM public.product_variants v
+			JOIN public.products p ON p.id = v.product_id
+			LEFT JOIN public.product_variant_options pvo ON pvo.product_variant_id = v.id
+			LEFT JOIN public.option_values ov ON ov.id = pvo.option_value_id
+			LEFT JOIN public.option_types ot ON ot.id = ov.option_type_id
+			WHERE v.deleted_at IS NULL AND (v.sku IS NULL OR v.barcode IS NULL)
+			GROUP BY v.id, p.sku
+			ORDER BY MIN(v.created_at)`).Scan(&variants).Error; err != nil {
+			return err
+		}
+		for _, variant := range variants {
+			updates := map[string]interface{}{}
+			if variant.SKU == nil {
+				base := "VARIANT"
+				if variant.ProductSKU != nil {
+					base = *variant.ProductSKU
+				}
+				options := []string{}
+				if variant.Options != "" {
+					options = strings.Split(variant.Options, "|")
+				}
+				updates["sku"] = claim(sku.Make(base, options...))
+			}
+			if variant.Barcode == nil {
+				code, err := nextBarcode()
+				if err != nil {
+					return err
+				}
+				updates["barcode"] = code
+			}
+			if err := tx.Table("public.product_variants").Where("id::text = ?", variant.ID).Updates(updates).Error; err != nil {
+				return fmt.Errorf("failed to set inventory codes for variant %s: %v", variant.ID, err)
+			}
+		}
+		return nil
+	})
+}
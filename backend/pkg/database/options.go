@@ -0,0 +1,50 @@
+package database
+
+import "gorm.io/gorm"
+
+// migrateVariantOptions memindahkan warna dan ukuran ke tipe opsi Color dan
+// Size, lalu menghubungkan varian lama ke nilai opsinya. Varian yang sudah
+// punya opsi tidak disentuh sehingga aman dijalankan setiap start.
+func migrateVariantOptions(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`INSERT INTO public.option_types (name, sort_order, created_at, updated_at)
+			VALUES ('Color', 0, NOW(), NOW()), ('Size', 1, NOW(), NOW())
+			ON CONFLICT (name) DO NOTHING`).Error; err != nil {
+			return err
+		}
+
+		for _, legacy := range []struct {
+			Type  string
+			Table string
+		}{
+			{"Color", "public.colors"},
+			{"Size", "public.sizes"},
+		} {
+			// Nama yang sama dipakai satu nilai, baris yang belum dihapus didahulukan
+			if err := tx.Exec(`INSERT INTO public.option_values (option_type_id, value, sort_order, legacy_id, created_at, updated_at)
+				SELECT t.id, l.name, 0, l.id, NOW(), NOW()
+				FROM `+legacy.Table+` l
+				JOIN public.option_types t ON t.name = ?
+				ORDER BY l.deleted_at NULLS FIRST, l.id
+				ON CONFLICT (option_type_id, value) DO NOTHING`, legacy.Type).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Exec(`INSERT INTO public.product_variant_options (product_variant_id, option_value_id)
+			SELECT v.id, ov.id
+			FROM public.product_variants v
+			JOIN public.colors c ON c.id = v.color_id
+			JOIN public.option_types t ON t.name = 'Color'
+			JOIN public.option_values ov ON ov.option_type_id = t.id AND ov.value = c.name
+			WHERE NOT EXISTS (SELECT 1 FROM public.product_variant_options o WHERE o.product_variant_id = v.id)
+			UNION ALL
+			SELECT v.id, ov.id
+			FROM public.product_variants v
+			JOIN public.sizes s ON s.id = v.size_id
+			JOIN public.option_types t ON t.name = 'Size'
+			JOIN public.option_values ov ON ov.option_type_id = t.id AND ov.value = s.name
+			WHERE NOT EXISTS (SELECT 1 FROM public.product_variant_options o WHERE o.product_variant_id = v.id)
+			ON CONFLICT DO NOTHING`).Error
+	})
+}
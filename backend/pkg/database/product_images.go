@@ -0,0 +1,13 @@
+package database
+
+import "gorm.io/gorm"
+
+// migrateProductImages memindahkan gambar tunggal produk lama ke galeri
+// sebagai gambar utama. Hanya produk yang belum punya galeri yang diisi.
+func migrateProductImages(db *gorm.DB) error {
+	return db.Exec(`INSERT INTO public.product_images (product_id, url, sort_order, is_primary, created_at, updated_at)
+		SELECT p.id, p.image_url, 0, true, NOW(), NOW()
+		FROM public.products p
+		WHERE p.image_url IS NOT NULL AND p.image_url NOT IN ('', '-')
+		AND NOT EXISTS (SELECT 1 FROM public.product_images i WHERE i.product_id = p.id)`).Error
+}
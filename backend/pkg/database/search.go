@@ -0,0 +1,50 @@
+package database
+
+import "gorm.io/gorm"
+
+// productSearchStatements menyiapkan pencarian produk: kolom tsvector yang
+// diisi trigger dari nama (bobot A), kategori (B) dan deskripsi (C), indeks
+// GIN untuk full-text, serta indeks trigram untuk pencarian salah ketik.
+// Konfigurasi 'simple' dipakai karena nama produk campuran bahasa.
+var productSearchStatements = []string{
+	`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+	`ALTER TABLE public.products ADD COLUMN IF NOT EXISTS search_vector tsvector`,
+	`CREATE OR REPLACE FUNCTION products_search_vector_update() RETURNS trigger AS $$
+	BEGIN
+		NEW.search_vector :=
+			setweight(to_tsvector('simple', coalesce(NEW.name, '')), 'A') ||
+			setweight(to_tsvector('simple', coalesce((SELECT name FROM public.categories WHERE id = NEW.category_id), '')), 'B') ||
+			setweight(to_tsvector('simple', coalesce(NEW.description, '')), 'C');
+		RETURN NEW;
+	END
+	$$ LANGUAGE plpgsql`,
+	`DROP TRIGGER IF EXISTS products_search_vector_trigger ON public.products`,
+	`CREATE TRIGGER products_search_vector_trigger
+		BEFORE INSERT OR UPDATE OF name, description, category_id ON public.products
+		FOR EACH ROW EXECUTE FUNCTION products_search_vector_update()`,
+	// Nama kategori berubah, vektor produk di kategori itu ikut dihitung ulang
+	`CREATE OR REPLACE FUNCTION categories_search_vector_update() RETURNS trigger AS $$
+	BEGIN
+		IF NEW.name IS DISTINCT FROM OLD.name THEN
+			UPDATE public.products SET name = name WHERE category_id = NEW.id;
+		END IF;
+		RETURN NEW;
+	END
+	$$ LANGUAGE plpgsql`,
+	`DROP TRIGGER IF EXISTS categories_search_vector_trigger ON public.categories`,
+	`CREATE TRIGGER categories_search_vector_trigger
+		AFTER UPDATE OF name ON public.categories
+		FOR EACH ROW EXECUTE FUNCTION categories_search_vector_update()`,
+	`UPDATE public.products SET name = name WHERE search_vector IS NULL`,
+	`CREATE INDEX IF NOT EXISTS idx_products_search_vector ON public.products USING GIN (search_vector)`,
+	`CREATE INDEX IF NOT EXISTS idx_products_name_trgm ON public.products USING GIN (name gin_trgm_ops)`,
+}
+
+func migrateProductSearch(db *gorm.DB) error {
+	for _, statement := range productSearchStatements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package database
+
+import (
+	"fmt"
+	"mola-web/pkg/slug"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// migrateSlugs membuat slug untuk produk dan kategori lama yang belum punya
+// slug. Slug yang sama diberi akhiran angka.
+func migrateSlugs(db *gorm.DB) error {
+	for _, table := range []struct {
+		Name     string
+		Fallback string
+	}{
+		{"public.products", "product"},
+		{"public.categories", "category"},
+	} {
+		if err := backfillSlugs(db, table.Name, table.Fallback); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func backfillSlugs(db *gorm.DB, table string, fallback string) error {
+	var rows []struct {
+		ID   string
+		Name string
+	}
+	if err := db.Table(table).Select("id::text AS id, name").Where("slug IS NULL OR slug = ''").Order("created_at ASC").Scan(&rows).Error; err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	var existing []string
+	if err := db.Table(table).Where("slug IS NOT NULL AND slug <> ''").Pluck("slug", &existing).Error; err != nil {
+		return err
+	}
+	taken := make(map[string]struct{}, len(existing)+len(rows))
+	for _, value := range existing {
+		taken[value] = struct{}{}
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, row := range rows {
+			base := slug.Make(row.Name)
+			if base == "" {
+				base = fallback
+			}
+			candidate := base
+			for i := 2; ; i++ {
+				if _, ok := taken[candidate]; !ok {
+					break
+				}
+				suffix := "-" + strconv.Itoa(i)
+				candidate = slug.Truncate(base, slug.MaxLength-len(suffix)) + suffix
+			}
+			taken[candidate] = struct{}{}
+			if err := tx.Table(table).Where("id::text = ?", row.ID).Update("slug", candidate).Error; err != nil {
+				return fmt.Errorf("failed to set slug for %s %s: %v", table, row.ID, err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,15 @@
+package database
+
+import "gorm.io/gorm"
+
+// migrateVoucherCodes menghapus index unik kode voucher lama yang ikut
+// menghitung voucher terhapus. AutoMigrate lalu membuat ulang index dengan
+// nama yang sama sebagai index parsial.
+func migrateVoucherCodes(db *gorm.DB) error {
+	return db.Exec(`DO $$
+	BEGIN
+		IF EXISTS (SELECT 1 FROM pg_indexes WHERE schemaname = 'public' AND indexname = 'idx_vouchers_code' AND indexdef NOT LIKE '%WHERE%') THEN
+			DROP INDEX public.idx_vouchers_code;
+		END IF;
+	END $$`).Error
+}
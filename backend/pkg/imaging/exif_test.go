@@ -0,0 +1,80 @@
+package imaging
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// exifJPEG membuat awal file JPEG dengan segmen EXIF berisi satu tag
+// orientasi, diikuti marker start of scan.
+func exifJPEG(order binary.ByteOrder, orientation uint16) []byte {
+	tiff := make([]byte, 26)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:4], 42)
+	order.PutUint32(tiff[4:8], 8)
+	order.PutUint16(tiff[8:10], 1)
+	order.PutUint16(tiff[10:12], 0x0112)
+	order.PutUint16(tiff[12:14], 3)
+	order.PutUint32(tiff[14:18], 1)
+	order.PutUint16(tiff[18:20], orientation)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	data := []byte{0xff, 0xd8, 0xff, 0xe1, 0, 0}
+	binary.BigEndian.PutUint16(data[4:6], uint16(len(segment)+2))
+	data = append(data, segment...)
+	return append(data, 0xff, 0xda, 0x00, 0x02)
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"little endian", exifJPEG(binary.LittleEndian, 6), 6},
+		{"big endian", exifJPEG(binary.BigEndian, 8), 8},
+		{"out of range", exifJPEG(binary.BigEndian, 9), 1},
+		{"not a jpeg", []byte("\x89PNG\r\n\x1a\n"), 1},
+		{"no exif", []byte{0xff, 0xd8, 0xff, 0xda, 0x00, 0x02}, 1},
+		{"empty", nil, 1},
+	}
+	for _, tt := range tests {
+		if got := jpegOrientation(tt.data); got != tt.want {
+			t.Errorf("%s: jpegOrientation = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestJPEGOrientationTruncated(t *testing.T) {
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		data := exifJPEG(order, 6)
+		// Segmen EXIF selesai 4 byte sebelum akhir data (marker SOS)
+		exifEnd := len(data) - 4
+		for n := 0; n < len(data); n++ {
+			want := 1
+			if n >= exifEnd {
+				want = 6
+			}
+			if got := jpegOrientation(data[:n]); got != want {
+				t.Errorf("%v truncated to %d bytes: jpegOrientation = %d, want %d", order, n, got, want)
+			}
+		}
+	}
+}
+
+func TestTIFFOrientationBadOffsets(t *testing.T) {
+	tests := [][]byte{
+		[]byte("II*\x00\xff\xff\xff\xff"),
+		[]byte("MM\x00*\x00\x00\x00\x08\x00\x05"),
+		[]byte("XX\x00*\x00\x00\x00\x08\x00\x00"),
+	}
+	for _, tiff := range tests {
+		if got := tiffOrientation(tiff); got != 1 {
+			t.Errorf("tiffOrientation(%q) = %d, want 1", tiff, got)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log"
+	"mola-web/configs"
+	"net/http"
+	"os"
+	"os/exec"
+)
+
+const (
+	RenditionThumbnail = "thumbnail"
+	RenditionMedium    = "medium"
+	RenditionLarge     = "large"
+)
+
+// renditionSizes sisi terpanjang setiap rendisi dalam piksel.
+var renditionSizes = []struct {
+	Name    string
+	MaxSide int
+}{
+	{RenditionThumbnail, 300},
+	{RenditionMedium, 800},
+	{RenditionLarge, 1600},
+}
+
+var ErrUnsupportedImage = errors.New("file must be a JPEG, PNG or GIF image")
+
+type Encoded struct {
+	Data        []byte
+	Ext         string // tanpa titik, misal "jpg"
+	ContentType string
+}
+
+// Rendition satu ukuran gambar hasil proses. WebP nil jika encoder WebP
+// tidak tersedia.
+type Rendition struct {
+	Name   string
+	Width  int
+	Height int
+	Image  Encoded
+	WebP   *Encoded
+}
+
+type Processor interface {
+	// Process memvalidasi dan mendekode upload, lalu membuat ulang setiap
+	// rendisi dari piksel sehingga metadata (EXIF, GPS) tidak ikut tersimpan.
+	Process(r io.Reader) ([]Rendition, error)
+}
+
+type processor struct {
+	cfg       configs.ImageConfig
+	cwebpPath string
+}
+
+func NewProcessor(cfg configs.ImageConfig) Processor {
+	p := &processor{cfg: cfg}
+	if cfg.CwebpPath != "" {
+		if path, err := exec.LookPath(cfg.CwebpPath); err == nil {
+			p.cwebpPath = path
+		} else {
+			log.Printf("WARNING: cwebp not found, WebP renditions are disabled: %v", err)
+		}
+	}
+	return p
+}
+
+func (p *processor) Process(r io.Reader) ([]Rendition, error) {
+	maxBytes := int64(p.cfg.MaxUploadMB) << 20
+	data, err := io.ReadAll(io.LimitReader(r, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("image must not be larger than %d MB", p.cfg.MaxUploadMB)
+	}
+
+	// Tipe file ditentukan dari isinya, bukan dari nama atau header upload
+	contentType := http.DetectContentType(data)
+	var decode func(io.Reader) (image.Image, error)
+	var decodeConfig func(io.Reader) (image.Config, error)
+	switch contentType {
+	case "image/jpeg":
+		decode, decodeConfig = jpeg.Decode, jpeg.DecodeConfig
+	case "image/png":
+		decode, decodeConfig = png.Decode, png.DecodeConfig
+	case "image/gif":
+		// Hanya frame pertama yang dipakai
+		decode, decodeConfig = gif.Decode, gif.DecodeConfig
+	default:
+		return nil, ErrUnsupportedImage
+	}
+
+	// Cek ukuran dulu sebelum dekode penuh supaya gambar raksasa tidak
+	// menghabiskan memori
+	config, err := decodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	if config.Width < p.cfg.MinDimension || config.Height < p.cfg.MinDimension {
+		return nil, fmt.Errorf("image must be at least %dx%d pixels", p.cfg.MinDimension, p.cfg.MinDimension)
+	}
+	if config.Width > p.cfg.MaxDimension || config.Height > p.cfg.MaxDimension {
+		return nil, fmt.Errorf("image must not be larger than %dx%d pixels", p.cfg.MaxDimension, p.cfg.MaxDimension)
+	}
+
+	decoded, err := decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	src := toNRGBA(decoded)
+	if contentType == "image/jpeg" {
+		src = orient(src, jpegOrientation(data))
+	}
+	opaque := isOpaque(src)
+
+	renditions := []Rendition{}
+	for _, size := range renditionSizes {
+		width, height := fitSize(src.Rect.Dx(), src.Rect.Dy(), size.MaxSide)
+		resized := resize(src, width, height)
+
+		encoded, err := p.encode(resized, opaque)
+		if err != nil {
+			return nil, err
+		}
+		rendition := Rendition{
+			Name:   size.Name,
+			Width:  width,
+			Height: height,
+			Image:  *encoded,
+		}
+		if p.cwebpPath != "" {
+			webp, err := p.encodeWebP(encoded)
+			if err != nil {
+				log.Printf("WARNING: failed to encode WebP %s rendition: %v", size.Name, err)
+			} else {
+				rendition.WebP = webp
+			}
+		}
+		renditions = append(renditions, rendition)
+	}
+	return renditions, nil
+}
+
+// encode menyimpan gambar tanpa transparansi sebagai JPEG, sisanya PNG.
+func (p *processor) encode(img *image.NRGBA, opaque bool) (*Encoded, error) {
+	var buf bytes.Buffer
+	if opaque {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: p.cfg.JPEGQuality}); err != nil {
+			return nil, err
+		}
+		return &Encoded{Data: buf.Bytes(), Ext: "jpg", ContentType: "image/jpeg"}, nil
+	}
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return &Encoded{Data: buf.Bytes(), Ext: "png", ContentType: "image/png"}, nil
+}
+
+// encodeWebP mengonversi hasil encode ke WebP memakai cwebp karena library
+// standar Go tidak punya encoder WebP.
+func (p *processor) encodeWebP(src *Encoded) (*Encoded, error) {
+	input, err := os.CreateTemp("", "image-*."+src.Ext)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(input.Name())
+	if _, err := input.Write(src.Data); err != nil {
+		input.Close()
+		return nil, err
+	}
+	if err := input.Close(); err != nil {
+		return nil, err
+	}
+
+	output := input.Name() + ".webp"
+	defer os.Remove(output)
+	cmd := exec.Command(p.cwebpPath, "-quiet", "-metadata", "none", "-q", fmt.Sprint(p.cfg.JPEGQuality), input.Name(), "-o", output)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("%v: %s", err, out)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		return nil, err
+	}
+	return &Encoded{Data: data, Ext: "webp", ContentType: "image/webp"}, nil
+}
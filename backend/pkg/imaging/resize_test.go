@@ -0,0 +1,24 @@
+package imaging
+
+import "testing"
+
+func TestFitSize(t *testing.T) {
+	tests := []struct {
+		width, height, maxSide int
+		wantW, wantH           int
+	}{
+		{800, 600, 1200, 800, 600},
+		{1200, 1200, 1200, 1200, 1200},
+		{4000, 3000, 1200, 1200, 900},
+		{3000, 4000, 1200, 900, 1200},
+		{2400, 2400, 300, 300, 300},
+		{10000, 10, 300, 300, 1},
+		{10, 10000, 300, 1, 300},
+	}
+	for _, tt := range tests {
+		w, h := fitSize(tt.width, tt.height, tt.maxSide)
+		if w != tt.wantW || h != tt.wantH {
+			t.Errorf("fitSize(%d, %d, %d) = %dx%d, want %dx%d", tt.width, tt.height, tt.maxSide, w, h, tt.wantW, tt.wantH)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Job adalah pekerjaan latar belakang yang dijalankan setiap Interval.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+	// RunOnStart menjalankan job sekali saat scheduler mulai
+	RunOnStart bool
+}
+
+type Scheduler struct {
+	jobs   []Job
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewScheduler(jobs []Job) *Scheduler {
+	return &Scheduler{jobs: jobs}
+}
+
+func (s *Scheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	for _, job := range s.jobs {
+		if job.Interval <= 0 {
+			log.Printf("WARNING: job %s has no interval, skipping", job.Name)
+			continue
+		}
+		s.wg.Add(1)
+		go s.run(ctx, job)
+	}
+}
+
+// Stop menghentikan semua job dan menunggu job yang sedang berjalan selesai.
+func (s *Scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+func (s *Scheduler) run(ctx context.Context, job Job) {
+	defer s.wg.Done()
+
+	if job.RunOnStart {
+		if err := job.Run(ctx); err != nil {
+			log.Printf("ERROR: job %s failed: %v", job.Name, err)
+		}
+	}
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := job.Run(ctx); err != nil {
+				log.Printf("ERROR: job %s failed: %v", job.Name, err)
+			}
+		}
+	}
+}
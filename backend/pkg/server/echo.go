@@ -20,13 +20,14 @@ type Server struct {
 func NewServer(cfg *configs.Config,
 	publicRoutes, privateRoutes []route.Route) *Server {
 	e := echo.New()
-	e.Static("/static", "public")
+	// File upload driver local (dan gambar lama) disajikan dari root storage
+	e.Static(cfg.Storage.LocalBaseURL, cfg.Storage.LocalRoot)
 	e.HideBanner = true
 
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
    		AllowOrigins: []string{"http://molla.my.id"}, // atau gunakan "*" jika masih testing
     	AllowMethods: []string{echo.GET, echo.POST, echo.PUT, echo.DELETE, echo.OPTIONS},
-    	AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
+    	AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, "X-Guest-Token"},
 	}))
 	v1 := e.Group("/api/v1")
 
@@ -0,0 +1,54 @@
+package sku
+
+import (
+	"mola-web/pkg/slug"
+	"strconv"
+	"strings"
+)
+
+const (
+	MaxLength = 64
+
+	// Panjang maksimal bagian nama produk dan setiap nilai opsi di SKU
+	// buatan sistem
+	baseLength   = 24
+	optionLength = 12
+)
+
+// Make membuat SKU dari nama produk dan nilai opsi varian, misalnya
+// "Kaos Polos", "Merah", "XL" menjadi "KAOS-POLOS-MERAH-XL". Hasilnya bisa
+// kosong jika nama tidak punya huruf atau angka latin.
+func Make(name string, options ...string) string {
+	parts := []string{}
+	if base := slug.Truncate(slug.Make(name), baseLength); base != "" {
+		parts = append(parts, base)
+	}
+	for _, option := range options {
+		if part := slug.Truncate(slug.Make(option), optionLength); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.ToUpper(slug.Truncate(strings.Join(parts, "-"), MaxLength))
+}
+
+// WithSuffix menambahkan akhiran angka untuk SKU yang sudah dipakai.
+func WithSuffix(base string, n int) string {
+	suffix := "-" + strconv.Itoa(n)
+	return slug.Truncate(base, MaxLength-len(suffix)) + suffix
+}
+
+// Valid SKU hanya berisi huruf, angka, '-', '_', '.' dan '/'.
+func Valid(code string) bool {
+	if code == "" || len(code) > MaxLength {
+		return false
+	}
+	for _, c := range code {
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+		case c == '-' || c == '_' || c == '.' || c == '/':
+		default:
+			return false
+		}
+	}
+	return true
+}
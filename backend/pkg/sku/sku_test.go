@@ -0,0 +1,54 @@
+package sku
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []string
+		want    string
+	}{
+		{"Kaos Polos", []string{"Merah", "XL"}, "KAOS-POLOS-MERAH-XL"},
+		{"Kaos Polos", nil, "KAOS-POLOS"},
+		{"Kaos Polos", []string{"", "日本"}, "KAOS-POLOS"},
+		{"Kemeja Flanel Kotak Kotak Lengan Panjang", []string{"Biru Dongker Tua"}, "KEMEJA-FLANEL-KOTAK-KOTA-BIRU-DONGKER"},
+		{"日本", nil, ""},
+	}
+	for _, tt := range tests {
+		if got := Make(tt.name, tt.options...); got != tt.want {
+			t.Errorf("Make(%q, %q) = %q, want %q", tt.name, tt.options, got, tt.want)
+		}
+	}
+}
+
+func TestWithSuffix(t *testing.T) {
+	if got := WithSuffix("KAOS-POLOS", 2); got != "KAOS-POLOS-2" {
+		t.Errorf("WithSuffix = %q, want KAOS-POLOS-2", got)
+	}
+	long := strings.Repeat("A", MaxLength)
+	if got := WithSuffix(long, 12); len(got) != MaxLength || !strings.HasSuffix(got, "-12") {
+		t.Errorf("WithSuffix on a full-length SKU = %q", got)
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{"KAOS-POLOS-XL", true},
+		{"sku_01/a.b", true},
+		{"KAOS POLOS", false},
+		{"KAOS#1", false},
+		{"", false},
+		{strings.Repeat("A", MaxLength+1), false},
+	}
+	for _, tt := range tests {
+		if got := Valid(tt.code); got != tt.want {
+			t.Errorf("Valid(%q) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
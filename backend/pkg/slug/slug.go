@@ -0,0 +1,57 @@
+package slug
+
+import (
+	"strings"
+	"unicode"
+)
+
+const MaxLength = 100
+
+// transliterations huruf latin beraksen yang sering muncul di nama produk.
+var transliterations = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u",
+	'ñ': "n", 'ç': "c", 'ý': "y", 'ÿ': "y", 'ß': "ss", 'æ': "ae",
+}
+
+// Make mengubah teks menjadi slug URL, misal "Kaos Polos (Hitam)" menjadi
+// "kaos-polos-hitam". Hasilnya bisa kosong jika teks tidak punya huruf
+// atau angka latin.
+func Make(text string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(text) {
+		part, ok := transliterations[r]
+		if !ok && r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			part, ok = string(r), true
+		}
+		if !ok {
+			// Karakter lain menjadi pemisah, beberapa pemisah berturut-turut
+			// cukup satu tanda hubung
+			dash = b.Len() > 0
+			continue
+		}
+		if dash {
+			b.WriteByte('-')
+			dash = false
+		}
+		b.WriteString(part)
+	}
+	return Truncate(b.String(), MaxLength)
+}
+
+// Truncate memotong slug tanpa meninggalkan tanda hubung di ujung.
+func Truncate(value string, max int) string {
+	if len(value) > max {
+		value = value[:max]
+	}
+	return strings.Trim(value, "-")
+}
+
+// Valid true jika value sudah berbentuk slug.
+func Valid(value string) bool {
+	return value != "" && len(value) <= MaxLength && Make(value) == value
+}
@@ -0,0 +1,67 @@
+package slug
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Kaos Polos (Hitam)", "kaos-polos-hitam"},
+		{"Café Crème Brûlée", "cafe-creme-brulee"},
+		{"Straße & Smørrebrød", "strasse-smorrebrod"},
+		{"  --Hello,   World!!--  ", "hello-world"},
+		{"Kemeja 100% Katun", "kemeja-100-katun"},
+		{"日本語", ""},
+		{"", ""},
+		{strings.Repeat("a", 99) + " b", strings.Repeat("a", 99)},
+		{strings.Repeat("ab", 60), strings.Repeat("ab", 50)},
+	}
+	for _, tt := range tests {
+		if got := Make(tt.text); got != tt.want {
+			t.Errorf("Make(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		value string
+		max   int
+		want  string
+	}{
+		{"kaos-polos", 20, "kaos-polos"},
+		{"kaos-polos", 5, "kaos"},
+		{"kaos-polos", 6, "kaos-p"},
+		{"-kaos-", 10, "kaos"},
+	}
+	for _, tt := range tests {
+		if got := Truncate(tt.value, tt.max); got != tt.want {
+			t.Errorf("Truncate(%q, %d) = %q, want %q", tt.value, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"kaos-polos", true},
+		{"kaos-polos-2", true},
+		{"Kaos-Polos", false},
+		{"kaos--polos", false},
+		{"-kaos", false},
+		{"kaos polos", false},
+		{"", false},
+		{strings.Repeat("a", MaxLength+1), false},
+	}
+	for _, tt := range tests {
+		if got := Valid(tt.value); got != tt.want {
+			t.Errorf("Valid(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
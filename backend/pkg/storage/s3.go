@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mola-web/configs"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+const s3MaxSignExpiry = 7 * 24 * time.Hour
+
+// s3Storage driver untuk storage S3-compatible (AWS S3, MinIO) memakai
+// client minio-go.
+type s3Storage struct {
+	client    *minio.Client
+	endpoint  *url.URL
+	bucket    string
+	pathStyle bool
+	publicURL string
+}
+
+func NewS3Storage(cfg configs.StorageConfig) (Storage, error) {
+	endpoint, err := url.Parse(cfg.S3Endpoint)
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", cfg.S3Endpoint)
+	}
+	if cfg.S3Bucket == "" {
+		return nil, errors.New("S3 bucket is required")
+	}
+	if cfg.S3AccessKey == "" || cfg.S3SecretKey == "" {
+		return nil, errors.New("S3 access key and secret key are required")
+	}
+
+	lookup := minio.BucketLookupDNS
+	if cfg.S3UsePathStyle {
+		lookup = minio.BucketLookupPath
+	}
+	client, err := minio.New(endpoint.Host, &minio.Options{
+		Creds:        credentials.NewStaticV4(cfg.S3AccessKey, cfg.S3SecretKey, ""),
+		Secure:       endpoint.Scheme == "https",
+		Region:       cfg.S3Region,
+		BucketLookup: lookup,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &s3Storage{
+		client:    client,
+		endpoint:  endpoint,
+		bucket:    cfg.S3Bucket,
+		pathStyle: cfg.S3UsePathStyle,
+		publicURL: strings.TrimSuffix(cfg.S3PublicURL, "/"),
+	}, nil
+}
+
+// Put mengirim body langsung ke S3 tanpa ditampung di memori. size -1
+// berarti ukuran tidak diketahui, body dikirim sebagai multipart upload.
+func (s *s3Storage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{ContentType: contentType})
+	return err
+}
+
+func (s *s3Storage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+	object, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	// GetObject baru mengirim request saat dibaca, Stat dipakai untuk
+	// mengetahui object ada atau tidak
+	if _, err := object.Stat(); err != nil {
+		object.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return object, nil
+}
+
+// Delete tidak mengembalikan error untuk object yang sudah tidak ada.
+func (s *s3Storage) Delete(ctx context.Context, key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+// URL alamat object lewat S3_PUBLIC_URL jika diisi, selain itu langsung ke
+// endpoint dengan gaya path (endpoint/bucket/key) atau virtual host
+// (bucket.endpoint/key).
+func (s *s3Storage) URL(key string) string {
+	key = strings.TrimPrefix(key, "/")
+	if s.publicURL != "" {
+		return s.publicURL + "/" + key
+	}
+	u := *s.endpoint
+	if s.pathStyle {
+		u.Path = "/" + s.bucket + "/" + key
+	} else {
+		u.Host = s.bucket + "." + s.endpoint.Host
+		u.Path = "/" + key
+	}
+	u.RawPath = ""
+	return u.String()
+}
+
+// SignedURL presigned GET URL, maksimal 7 hari.
+func (s *s3Storage) SignedURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	if expires < time.Second || expires > s3MaxSignExpiry {
+		return "", fmt.Errorf("signed URL expiry must be between 1s and %s", s3MaxSignExpiry)
+	}
+	u, err := s.client.PresignedGetObject(ctx, s.bucket, key, expires, nil)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mola-web/configs"
+	"strings"
+	"time"
+)
+
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+var ErrNotFound = errors.New("object not found")
+
+// Storage penyimpanan file upload (gambar produk, dll). Key memakai "/"
+// sebagai pemisah, misal "products/images/abc-large.jpg".
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	// URL alamat publik object
+	URL(key string) string
+	// SignedURL alamat sementara yang berlaku selama expires
+	SignedURL(ctx context.Context, key string, expires time.Duration) (string, error)
+}
+
+// New memilih driver sesuai STORAGE_DRIVER.
+func New(cfg configs.StorageConfig) (Storage, error) {
+	switch cfg.Driver {
+	case DriverLocal, "":
+		return NewLocalStorage(cfg.LocalRoot, cfg.LocalBaseURL), nil
+	case DriverS3:
+		return NewS3Storage(cfg)
+	}
+	return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+}
+
+// cleanKey menolak key yang bisa keluar dari root (misal "../").
+func cleanKey(key string) (string, error) {
+	key = strings.TrimPrefix(key, "/")
+	if key == "" {
+		return "", errors.New("storage key is empty")
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return "", fmt.Errorf("invalid storage key %q", key)
+		}
+	}
+	return key, nil
+}
@@ -1,12 +1,19 @@
 package token
 
 import (
+	"errors"
+	"time"
+
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
+const guestTokenIssuer = "mola-web-guest"
+
 type TokenUseCase interface {
 	GenerateAccessToken(claims JwtCustomClaims) (string, error)
+	GenerateGuestToken(guestID uuid.UUID, expiresAt time.Time) (string, error)
+	ParseGuestToken(tokenString string) (uuid.UUID, error)
 }
 
 type tokenUseCase struct {
@@ -25,6 +32,13 @@ type JwtCustomClaims struct {
 	jwt.RegisteredClaims
 }
 
+// GuestClaims identifies an anonymous shopper. It carries no role, so it can
+// never pass the RBAC middleware as an access token.
+type GuestClaims struct {
+	GuestID uuid.UUID `json:"guest_id"`
+	jwt.RegisteredClaims
+}
+
 func (t *tokenUseCase) GenerateAccessToken(claims JwtCustomClaims) (string, error) {
 	plainToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
@@ -35,3 +49,30 @@ func (t *tokenUseCase) GenerateAccessToken(claims JwtCustomClaims) (string, erro
 
 	return encodedToken, nil
 }
+
+func (t *tokenUseCase) GenerateGuestToken(guestID uuid.UUID, expiresAt time.Time) (string, error) {
+	claims := GuestClaims{
+		GuestID: guestID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    guestTokenIssuer,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+	plainToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	return plainToken.SignedString([]byte(t.secretKey))
+}
+
+func (t *tokenUseCase) ParseGuestToken(tokenString string) (uuid.UUID, error) {
+	claims := new(GuestClaims)
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(t.secretKey), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithIssuer(guestTokenIssuer))
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if claims.GuestID == uuid.Nil {
+		return uuid.Nil, errors.New("invalid guest token")
+	}
+	return claims.GuestID, nil
+}